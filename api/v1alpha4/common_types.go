@@ -63,6 +63,10 @@ const (
 	// when KCP or a machineset scales down. This annotation is given top priority on all delete policies.
 	DeleteMachineAnnotation = "cluster.x-k8s.io/delete-machine"
 
+	// DisableMachineCreateAnnotation marks machinesets that should not create new machines to replace the deleted ones,
+	// e.g. old machinesets of a MachineDeployment using the OnDelete strategy.
+	DisableMachineCreateAnnotation = "cluster.x-k8s.io/disable-machine-create"
+
 	// TemplateClonedFromNameAnnotation is the infrastructure machine annotation that stores the name of the infrastructure template resource
 	// that was cloned for the machine. This annotation is set only during cloning a template. Older/adopted machines will not have this annotation.
 	TemplateClonedFromNameAnnotation = "cluster.x-k8s.io/cloned-from-name"
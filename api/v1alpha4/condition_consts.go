@@ -101,7 +101,7 @@ const (
 	// PreDrainDeleteHookSucceededCondition reports a machine waiting for a PreDrainDeleteHook before being delete.
 	PreDrainDeleteHookSucceededCondition ConditionType = "PreDrainDeleteHookSucceeded"
 
-	// PreTerminateDeleteHookSucceededCondition reports a machine waiting for a PreDrainDeleteHook before being delete.
+	// PreTerminateDeleteHookSucceededCondition reports a machine waiting for a PreTerminateDeleteHook before being delete.
 	PreTerminateDeleteHookSucceededCondition ConditionType = "PreTerminateDeleteHookSucceeded"
 
 	// WaitingExternalHookReason (Severity=Info) provide evidence that we are waiting for an external hook to complete.
@@ -121,6 +121,9 @@ const (
 
 	// UnhealthyNodeConditionReason is the reason used when a machine's node has one of the MachineHealthCheck's unhealthy conditions.
 	UnhealthyNodeConditionReason = "UnhealthyNode"
+
+	// UnhealthyProbeReason is the reason used when one of the MachineHealthCheck's unhealthy probes fails on a machine's node.
+	UnhealthyProbeReason = "UnhealthyProbe"
 )
 
 const (
@@ -95,10 +95,72 @@ type MachineSpec struct {
 	// NOTE: NodeDrainTimeout is different from `kubectl drain --timeout`
 	// +optional
 	NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+	// DrainPolicy defines how the controller drains the node before deleting the machine.
+	// When not set, all the pods are evicted in parallel and emptyDir data is deleted.
+	// +optional
+	DrainPolicy *MachineDrainPolicy `json:"drainPolicy,omitempty"`
 }
 
 // ANCHOR_END: MachineSpec
 
+// MachineDrainOrder defines the order in which the pods running on a node are evicted.
+type MachineDrainOrder string
+
+const (
+	// ParallelMachineDrainOrder evicts all the pods at the same time.
+	ParallelMachineDrainOrder MachineDrainOrder = "Parallel"
+
+	// PriorityMachineDrainOrder evicts the pods in ascending order of priority;
+	// pods with a higher priority are evicted only after all the pods with a lower priority are gone.
+	PriorityMachineDrainOrder MachineDrainOrder = "Priority"
+
+	// NamespaceMachineDrainOrder evicts the pods namespace by namespace, in the order defined by
+	// MachineDrainPolicy.Namespaces; pods in namespaces not listed there are evicted last.
+	NamespaceMachineDrainOrder MachineDrainOrder = "Namespace"
+)
+
+// ANCHOR: MachineDrainPolicy
+
+// MachineDrainPolicy defines how the node of a Machine is drained.
+type MachineDrainPolicy struct {
+	// Order defines the order in which the pods are evicted.
+	// Valid values are "Parallel", "Priority" and "Namespace"; defaults to "Parallel".
+	// +kubebuilder:validation:Enum=Parallel;Priority;Namespace
+	// +optional
+	Order MachineDrainOrder `json:"order,omitempty"`
+
+	// Namespaces is the list of namespaces in the order their pods are evicted.
+	// It must be set when Order is "Namespace".
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// ExcludePodSelector selects the pods that are not evicted, e.g. pods which are expected
+	// to run until the machine is terminated.
+	// +optional
+	ExcludePodSelector *metav1.LabelSelector `json:"excludePodSelector,omitempty"`
+
+	// DeleteEmptyDirData defines whether pods using emptyDir volumes are evicted, deleting their data.
+	// When false, the drain is blocked until those pods are removed from the node.
+	// Defaults to true.
+	// +optional
+	DeleteEmptyDirData *bool `json:"deleteEmptyDirData,omitempty"`
+
+	// WaitForVolumeDetach defines whether the controller waits for all the volumes to be detached
+	// from the node after the pods have been evicted, before deleting the machine.
+	// +optional
+	WaitForVolumeDetach bool `json:"waitForVolumeDetach,omitempty"`
+
+	// ForceDeleteGracePeriod is the amount of time after the drain started after which pods
+	// that cannot be evicted, e.g. because of a PodDisruptionBudget, or are stuck terminating are
+	// deleted without waiting for their graceful termination.
+	// When not set, pods are never force-deleted.
+	// +optional
+	ForceDeleteGracePeriod *metav1.Duration `json:"forceDeleteGracePeriod,omitempty"`
+}
+
+// ANCHOR_END: MachineDrainPolicy
+
 // ANCHOR: MachineStatus
 
 // MachineStatus defines the observed state of Machine
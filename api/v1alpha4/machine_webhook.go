@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -127,8 +128,37 @@ func (m *Machine) validate(old *Machine) error {
 		}
 	}
 
+	allErrs = append(allErrs, validateDrainPolicy(m.Spec.DrainPolicy, field.NewPath("spec", "drainPolicy"))...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("Machine").GroupKind(), m.Name, allErrs)
 }
+
+// validateDrainPolicy validates a MachineDrainPolicy; it is shared by all the types embedding a MachineSpec.
+func validateDrainPolicy(p *MachineDrainPolicy, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if p == nil {
+		return allErrs
+	}
+
+	if p.Order == NamespaceMachineDrainOrder && len(p.Namespaces) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("namespaces"), "must be set when order is Namespace"))
+	}
+	if p.Order != NamespaceMachineDrainOrder && len(p.Namespaces) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("namespaces"), "can be set only when order is Namespace"))
+	}
+
+	if p.ExcludePodSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(p.ExcludePodSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("excludePodSelector"), p.ExcludePodSelector, err.Error()))
+		}
+	}
+
+	if p.ForceDeleteGracePeriod != nil && p.ForceDeleteGracePeriod.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("forceDeleteGracePeriod"), p.ForceDeleteGracePeriod.Duration.String(), "must be greater than or equal to 0"))
+	}
+
+	return allErrs
+}
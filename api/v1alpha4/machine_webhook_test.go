@@ -18,6 +18,7 @@ package v1alpha4
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
@@ -246,3 +247,86 @@ func TestMachineVersionValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineDrainPolicyValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		drainPolicy *MachineDrainPolicy
+		expectErr   bool
+	}{
+		{
+			name:        "should succeed when drainPolicy is not set",
+			drainPolicy: nil,
+			expectErr:   false,
+		},
+		{
+			name: "should succeed when given a valid drainPolicy",
+			drainPolicy: &MachineDrainPolicy{
+				Order:                  PriorityMachineDrainOrder,
+				ExcludePodSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "node-exporter"}},
+				DeleteEmptyDirData:     pointer.BoolPtr(false),
+				WaitForVolumeDetach:    true,
+				ForceDeleteGracePeriod: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+			expectErr: false,
+		},
+		{
+			name: "should succeed when namespaces are set with the Namespace order",
+			drainPolicy: &MachineDrainPolicy{
+				Order:      NamespaceMachineDrainOrder,
+				Namespaces: []string{"apps", "monitoring"},
+			},
+			expectErr: false,
+		},
+		{
+			name:        "should return error when namespaces are not set with the Namespace order",
+			drainPolicy: &MachineDrainPolicy{Order: NamespaceMachineDrainOrder},
+			expectErr:   true,
+		},
+		{
+			name: "should return error when namespaces are set without the Namespace order",
+			drainPolicy: &MachineDrainPolicy{
+				Order:      PriorityMachineDrainOrder,
+				Namespaces: []string{"apps"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error when given an invalid excludePodSelector",
+			drainPolicy: &MachineDrainPolicy{
+				ExcludePodSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Unknown"}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error when given a negative forceDeleteGracePeriod",
+			drainPolicy: &MachineDrainPolicy{
+				ForceDeleteGracePeriod: &metav1.Duration{Duration: -time.Minute},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &Machine{
+				Spec: MachineSpec{
+					Bootstrap:   Bootstrap{ConfigRef: nil, DataSecretName: pointer.StringPtr("test")},
+					DrainPolicy: tt.drainPolicy,
+				},
+			}
+
+			if tt.expectErr {
+				g.Expect(m.ValidateCreate()).NotTo(Succeed())
+				g.Expect(m.ValidateUpdate(m)).NotTo(Succeed())
+			} else {
+				g.Expect(m.ValidateCreate()).To(Succeed())
+				g.Expect(m.ValidateUpdate(m)).To(Succeed())
+			}
+		})
+	}
+}
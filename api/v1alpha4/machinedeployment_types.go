@@ -28,6 +28,14 @@ const (
 	// i.e. gradually scale down the old MachineSet and scale up the new one.
 	RollingUpdateMachineDeploymentStrategyType MachineDeploymentStrategyType = "RollingUpdate"
 
+	// Replace the old MachineSet by new one only when old machines are deleted,
+	// i.e. the new MachineSet is scaled up only when machines of the old MachineSet are deleted by the user.
+	OnDeleteMachineDeploymentStrategyType MachineDeploymentStrategyType = "OnDelete"
+
+	// Replace the old MachineSet by new one starting with a limited number of canary machines,
+	// and continue with a rolling update only after the canary machines are available for a soak period.
+	CanaryMachineDeploymentStrategyType MachineDeploymentStrategyType = "Canary"
+
 	// RevisionAnnotation is the revision annotation of a machine deployment's machine sets which records its rollout sequence
 	RevisionAnnotation = "machinedeployment.clusters.x-k8s.io/revision"
 	// RevisionHistoryAnnotation maintains the history of all old revisions that a machine set has served for a machine deployment.
@@ -40,6 +48,17 @@ const (
 	// is machinedeployment.spec.replicas + maxSurge. Used by the underlying machine sets to estimate their
 	// proportions in case the deployment has surge replicas.
 	MaxReplicasAnnotation = "machinedeployment.clusters.x-k8s.io/max-replicas"
+
+	// CanaryReadyAnnotation is set on the new machine set of a deployment using the Canary strategy, and it records
+	// the time when the canary machines became available, which is used for computing the soak period.
+	CanaryReadyAnnotation = "machinedeployment.clusters.x-k8s.io/canary-ready"
+	// CanaryCompletedAnnotation is set on the new machine set of a deployment using the Canary strategy once the canary
+	// machines have been available for the soak period, and the rollout continues as a rolling update.
+	CanaryCompletedAnnotation = "machinedeployment.clusters.x-k8s.io/canary-completed"
+	// CanaryAbortedAnnotation is set on the new machine set of a deployment using the Canary strategy when its machines
+	// fail health checks, and it records the reason why the rollout has been stopped. The rollout can be resumed by removing
+	// the annotation, or it is superseded by a new rollout when the machine template is changed.
+	CanaryAbortedAnnotation = "machinedeployment.clusters.x-k8s.io/canary-aborted"
 )
 
 // ANCHOR: MachineDeploymentSpec
@@ -101,20 +120,57 @@ type MachineDeploymentSpec struct {
 // MachineDeploymentStrategy describes how to replace existing machines
 // with new ones.
 type MachineDeploymentStrategy struct {
-	// Type of deployment. Currently the only supported strategy is
-	// "RollingUpdate".
+	// Type of deployment. Allowed values are "RollingUpdate", "OnDelete"
+	// and "Canary".
 	// Default is RollingUpdate.
+	// +kubebuilder:validation:Enum=RollingUpdate;OnDelete;Canary
 	// +optional
 	Type MachineDeploymentStrategyType `json:"type,omitempty"`
 
 	// Rolling update config params. Present only if
-	// MachineDeploymentStrategyType = RollingUpdate.
+	// MachineDeploymentStrategyType = RollingUpdate or Canary; in case of
+	// Canary, these params are used once canary machines are verified.
 	// +optional
 	RollingUpdate *MachineRollingUpdateDeployment `json:"rollingUpdate,omitempty"`
+
+	// Canary config params. Present only if
+	// MachineDeploymentStrategyType = Canary.
+	// +optional
+	Canary *MachineCanaryDeployment `json:"canary,omitempty"`
 }
 
 // ANCHOR_END: MachineDeploymentStrategy
 
+// ANCHOR: MachineCanaryDeployment
+
+// MachineCanaryDeployment is used to control the desired behavior of canary rollouts.
+type MachineCanaryDeployment struct {
+	// The number of new machines to be rolled out first, before
+	// replacing any old machine.
+	// Value can be an absolute number (ex: 1) or a percentage of desired
+	// machines (ex: 10%).
+	// Absolute number is calculated from percentage by rounding up.
+	// Defaults to 1.
+	// +optional
+	Replicas *intstr.IntOrString `json:"replicas,omitempty"`
+
+	// The number of seconds canary machines must be available before
+	// continuing the rollout with a rolling update.
+	// Defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SoakSeconds *int32 `json:"soakSeconds,omitempty"`
+
+	// Indicates that the rollout should be stopped if machines of the new
+	// MachineSet fail health checks, i.e. the HealthCheckSucceeded condition
+	// set by MachineHealthChecks is false.
+	// Defaults to true.
+	// +optional
+	AbortOnUnhealthy *bool `json:"abortOnUnhealthy,omitempty"`
+}
+
+// ANCHOR_END: MachineCanaryDeployment
+
 // ANCHOR: MachineRollingUpdateDeployment
 
 // MachineRollingUpdateDeployment is used to control the desired behavior of rolling update.
@@ -85,6 +85,31 @@ func (m *MachineDeployment) validate(old *MachineDeployment) error {
 		)
 	}
 
+	if m.Spec.Strategy != nil && m.Spec.Strategy.Canary != nil {
+		if m.Spec.Strategy.Type != CanaryMachineDeploymentStrategyType {
+			allErrs = append(
+				allErrs,
+				field.Forbidden(field.NewPath("spec", "strategy", "canary"), "may only be set when strategy type is Canary"),
+			)
+		}
+
+		if m.Spec.Strategy.Canary.Replicas != nil {
+			if _, err := intstr.GetValueFromIntOrPercent(m.Spec.Strategy.Canary.Replicas, 1, true); err != nil {
+				allErrs = append(
+					allErrs,
+					field.Invalid(field.NewPath("spec", "strategy", "canary", "replicas"), m.Spec.Strategy.Canary.Replicas, fmt.Sprintf("must be either an int or a percentage: %v", err.Error())),
+				)
+			}
+		}
+
+		if m.Spec.Strategy.Canary.SoakSeconds != nil && *m.Spec.Strategy.Canary.SoakSeconds < 0 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "strategy", "canary", "soakSeconds"), *m.Spec.Strategy.Canary.SoakSeconds, "must be greater than or equal to 0"),
+			)
+		}
+	}
+
 	if old != nil && old.Spec.ClusterName != m.Spec.ClusterName {
 		allErrs = append(
 			allErrs,
@@ -92,6 +117,8 @@ func (m *MachineDeployment) validate(old *MachineDeployment) error {
 		)
 	}
 
+	allErrs = append(allErrs, validateDrainPolicy(m.Spec.Template.Spec.DrainPolicy, field.NewPath("spec", "template", "spec", "drainPolicy"))...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -135,8 +162,9 @@ func PopulateDefaultsMachineDeployment(d *MachineDeployment) {
 		d.Spec.Template.Labels = make(map[string]string)
 	}
 
-	// Default RollingUpdate strategy only if strategy type is RollingUpdate.
-	if d.Spec.Strategy.Type == RollingUpdateMachineDeploymentStrategyType {
+	// Default RollingUpdate strategy only if strategy type is RollingUpdate or Canary;
+	// in case of Canary, the rollout continues with a rolling update once canary machines are verified.
+	if d.Spec.Strategy.Type == RollingUpdateMachineDeploymentStrategyType || d.Spec.Strategy.Type == CanaryMachineDeploymentStrategyType {
 		if d.Spec.Strategy.RollingUpdate == nil {
 			d.Spec.Strategy.RollingUpdate = &MachineRollingUpdateDeployment{}
 		}
@@ -150,6 +178,23 @@ func PopulateDefaultsMachineDeployment(d *MachineDeployment) {
 		}
 	}
 
+	// Default Canary strategy only if strategy type is Canary.
+	if d.Spec.Strategy.Type == CanaryMachineDeploymentStrategyType {
+		if d.Spec.Strategy.Canary == nil {
+			d.Spec.Strategy.Canary = &MachineCanaryDeployment{}
+		}
+		if d.Spec.Strategy.Canary.Replicas == nil {
+			ios1 := intstr.FromInt(1)
+			d.Spec.Strategy.Canary.Replicas = &ios1
+		}
+		if d.Spec.Strategy.Canary.SoakSeconds == nil {
+			d.Spec.Strategy.Canary.SoakSeconds = pointer.Int32Ptr(0)
+		}
+		if d.Spec.Strategy.Canary.AbortOnUnhealthy == nil {
+			d.Spec.Strategy.Canary.AbortOnUnhealthy = pointer.BoolPtr(true)
+		}
+	}
+
 	// If no selector has been provided, add label and selector for the
 	// MachineDeployment's name as a default way of providing uniqueness.
 	if len(d.Spec.Selector.MatchLabels) == 0 && len(d.Spec.Selector.MatchExpressions) == 0 {
@@ -22,6 +22,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 )
 
@@ -48,6 +49,30 @@ func TestMachineDeploymentDefault(t *testing.T) {
 	g.Expect(md.Spec.Strategy.RollingUpdate.MaxUnavailable.IntValue()).To(Equal(0))
 }
 
+func TestMachineDeploymentDefaultCanary(t *testing.T) {
+	g := NewWithT(t)
+	md := &MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-md",
+		},
+		Spec: MachineDeploymentSpec{
+			Strategy: &MachineDeploymentStrategy{
+				Type: CanaryMachineDeploymentStrategyType,
+			},
+		},
+	}
+
+	md.Default()
+
+	g.Expect(md.Spec.Strategy.RollingUpdate).ToNot(BeNil())
+	g.Expect(md.Spec.Strategy.RollingUpdate.MaxSurge.IntValue()).To(Equal(1))
+	g.Expect(md.Spec.Strategy.RollingUpdate.MaxUnavailable.IntValue()).To(Equal(0))
+	g.Expect(md.Spec.Strategy.Canary).ToNot(BeNil())
+	g.Expect(md.Spec.Strategy.Canary.Replicas.IntValue()).To(Equal(1))
+	g.Expect(md.Spec.Strategy.Canary.SoakSeconds).To(Equal(pointer.Int32Ptr(0)))
+	g.Expect(md.Spec.Strategy.Canary.AbortOnUnhealthy).To(Equal(pointer.BoolPtr(true)))
+}
+
 func TestMachineDeploymentValidation(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -113,6 +138,71 @@ func TestMachineDeploymentValidation(t *testing.T) {
 	}
 }
 
+func TestMachineDeploymentStrategyValidation(t *testing.T) {
+	percent := intstr.FromString("10%")
+	invalid := intstr.FromString("foo")
+
+	tests := []struct {
+		name      string
+		strategy  *MachineDeploymentStrategy
+		expectErr bool
+	}{
+		{
+			name:      "should not return error for OnDelete strategy",
+			strategy:  &MachineDeploymentStrategy{Type: OnDeleteMachineDeploymentStrategyType},
+			expectErr: false,
+		},
+		{
+			name: "should not return error for valid Canary strategy",
+			strategy: &MachineDeploymentStrategy{
+				Type:   CanaryMachineDeploymentStrategyType,
+				Canary: &MachineCanaryDeployment{Replicas: &percent, SoakSeconds: pointer.Int32Ptr(300)},
+			},
+			expectErr: false,
+		},
+		{
+			name: "should return error if canary is set for other strategies",
+			strategy: &MachineDeploymentStrategy{
+				Type:   RollingUpdateMachineDeploymentStrategyType,
+				Canary: &MachineCanaryDeployment{},
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error for invalid canary replicas",
+			strategy: &MachineDeploymentStrategy{
+				Type:   CanaryMachineDeploymentStrategyType,
+				Canary: &MachineCanaryDeployment{Replicas: &invalid},
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error for negative soak seconds",
+			strategy: &MachineDeploymentStrategy{
+				Type:   CanaryMachineDeploymentStrategyType,
+				Canary: &MachineCanaryDeployment{SoakSeconds: pointer.Int32Ptr(-1)},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			md := &MachineDeployment{
+				Spec: MachineDeploymentSpec{
+					Strategy: tt.strategy,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(md.ValidateCreate()).NotTo(Succeed())
+			} else {
+				g.Expect(md.ValidateCreate()).To(Succeed())
+			}
+		})
+	}
+}
+
 func TestMachineDeploymentWithSpec(t *testing.T) {
 	g := NewWithT(t)
 	md := MachineDeployment{
@@ -40,11 +40,32 @@ type MachineHealthCheckSpec struct {
 	// +kubebuilder:validation:MinItems=1
 	UnhealthyConditions []UnhealthyCondition `json:"unhealthyConditions"`
 
+	// UnhealthyProbes contains a list of additional checks, run against the workload cluster,
+	// that determine whether a node is considered unhealthy. The probes are combined with
+	// the unhealthy conditions in a logical OR.
+	// +optional
+	UnhealthyProbes []UnhealthyProbe `json:"unhealthyProbes,omitempty"`
+
 	// Any further remediation is only allowed if at most "MaxUnhealthy" machines selected by
 	// "selector" are not healthy.
 	// +optional
 	MaxUnhealthy *intstr.IntOrString `json:"maxUnhealthy,omitempty"`
 
+	// Any further remediation is only allowed if the number of machines selected by "selector" as not healthy
+	// is within the range of "UnhealthyRange". Takes precedence over MaxUnhealthy.
+	// Eg. "[3-5]" - This means that remediation will be allowed only when:
+	// (a) there are at least 3 unhealthy machines (and)
+	// (b) there are at most 5 unhealthy machines
+	// +optional
+	// +kubebuilder:validation:Pattern=^\[[0-9]+-[0-9]+\]$
+	UnhealthyRange *string `json:"unhealthyRange,omitempty"`
+
+	// RemediationRateLimit limits the number of machines this machine health check can mark for remediation
+	// within a time window; unhealthy machines exceeding the limit are remediated once the window allows it.
+	// If not set, all the unhealthy machines are remediated at once.
+	// +optional
+	RemediationRateLimit *RemediationRateLimit `json:"remediationRateLimit,omitempty"`
+
 	// Machines older than this duration without a node will be considered to have
 	// failed and will be remediated.
 	// +optional
@@ -81,6 +102,89 @@ type UnhealthyCondition struct {
 
 // ANCHOR_END: UnhealthyCondition
 
+// ANCHOR: UnhealthyProbe
+
+// UnhealthyProbeType defines the type of an UnhealthyProbe.
+type UnhealthyProbeType string
+
+const (
+	// PodNotReadyUnhealthyProbeType checks that the pods selected by the probe, e.g. the pods of
+	// a DaemonSet, are Ready on the node.
+	PodNotReadyUnhealthyProbeType = UnhealthyProbeType("PodNotReady")
+
+	// NodeLabelUnhealthyProbeType checks that the label defined by the probe is not present on the node.
+	NodeLabelUnhealthyProbeType = UnhealthyProbeType("NodeLabel")
+
+	// NodeTaintUnhealthyProbeType checks that the taint defined by the probe is not present on the node.
+	NodeTaintUnhealthyProbeType = UnhealthyProbeType("NodeTaint")
+
+	// NodeLeaseExpiredUnhealthyProbeType checks that the lease of the node in the kube-node-lease namespace
+	// is renewed by the kubelet.
+	NodeLeaseExpiredUnhealthyProbeType = UnhealthyProbeType("NodeLeaseExpired")
+)
+
+// UnhealthyProbe represents a check, run against the workload cluster, which considers a node unhealthy
+// when it fails for at least the timeout value.
+type UnhealthyProbe struct {
+	// Type is the type of the probe.
+	// +kubebuilder:validation:Enum=PodNotReady;NodeLabel;NodeTaint;NodeLeaseExpired
+	Type UnhealthyProbeType `json:"type"`
+
+	// Pod selects the pods checked by a PodNotReady probe; only the pods running on the node are checked.
+	// +optional
+	Pod *PodProbeSelector `json:"pod,omitempty"`
+
+	// Key is the key of the label or of the taint checked by a NodeLabel or NodeTaint probe.
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// Value is the value of the label or of the taint checked by a NodeLabel or NodeTaint probe.
+	// If empty, the probe fails for any value.
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// Timeout is how long the probe must be failing before the node is considered unhealthy;
+	// for NodeLeaseExpired probes, how long since the last renewal of the node lease.
+	// Labels and taints without a timestamp consider the node unhealthy as soon as they are observed.
+	// +optional
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// PodProbeSelector selects the pods checked by a PodNotReady probe.
+type PodProbeSelector struct {
+	// Namespace is the namespace of the pods.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Selector is the label selector of the pods.
+	Selector metav1.LabelSelector `json:"selector"`
+}
+
+// ANCHOR_END: UnhealthyProbe
+
+// ANCHOR: RemediationRateLimit
+
+// RemediationRateLimit defines the maximum number of remediations allowed within a time window.
+type RemediationRateLimit struct {
+	// MaxRemediations is the maximum number of machines that can be marked for remediation within Window.
+	// +kubebuilder:validation:Minimum=1
+	MaxRemediations int32 `json:"maxRemediations"`
+
+	// Window is the length of the sliding time window the remediations are counted in.
+	Window metav1.Duration `json:"window"`
+}
+
+// ANCHOR_END: RemediationRateLimit
+
+// MachineHealthCheckRemediation records a machine marked for remediation by a machine health check.
+type MachineHealthCheckRemediation struct {
+	// Machine is the name of the machine marked for remediation.
+	Machine string `json:"machine"`
+
+	// Timestamp is when the machine was marked for remediation.
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
 // ANCHOR: MachineHealthCheckStatus
 
 // MachineHealthCheckStatus defines the observed state of MachineHealthCheck
@@ -94,10 +198,21 @@ type MachineHealthCheckStatus struct {
 	CurrentHealthy int32 `json:"currentHealthy,omitempty"`
 
 	// RemediationsAllowed is the number of further remediations allowed by this machine health check before
-	// maxUnhealthy short circuiting will be applied
+	// maxUnhealthy short circuiting or remediation rate limiting will be applied
 	// +kubebuilder:validation:Minimum=0
 	RemediationsAllowed int32 `json:"remediationsAllowed,omitempty"`
 
+	// RemediationsInWindow is the number of machines marked for remediation within the window
+	// of the remediation rate limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RemediationsInWindow int32 `json:"remediationsInWindow,omitempty"`
+
+	// RecentRemediations lists the machines marked for remediation within the window
+	// of the remediation rate limit, oldest first.
+	// +optional
+	RecentRemediations []MachineHealthCheckRemediation `json:"recentRemediations,omitempty"`
+
 	// ObservedGeneration is the latest generation observed by the controller.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
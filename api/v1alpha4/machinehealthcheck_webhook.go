@@ -18,8 +18,11 @@ package v1alpha4
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"time"
 
+	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -38,6 +41,8 @@ var (
 	defaultNodeStartupTimeout = metav1.Duration{Duration: 10 * time.Minute}
 	// Minimum time allowed for a node to start up
 	minNodeStartupTimeout = metav1.Duration{Duration: 30 * time.Second}
+	// Format of the range of unhealthy machines allowing remediation, e.g. "[1-3]"
+	unhealthyRangeRegexp = regexp.MustCompile(`^\[([0-9]+)-([0-9]+)\]$`)
 )
 
 // SetMinNodeStartupTimeout allows users to optionally set a custom timeout
@@ -153,8 +158,96 @@ func (m *MachineHealthCheck) validate(old *MachineHealthCheck) error {
 		}
 	}
 
+	for i, probe := range m.Spec.UnhealthyProbes {
+		allErrs = append(allErrs, validateUnhealthyProbe(probe, field.NewPath("spec", "unhealthyProbes").Index(i))...)
+	}
+
+	if m.Spec.UnhealthyRange != nil {
+		if _, _, err := ParseUnhealthyRange(*m.Spec.UnhealthyRange); err != nil {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "unhealthyRange"), *m.Spec.UnhealthyRange, err.Error()),
+			)
+		}
+	}
+
+	if m.Spec.RemediationRateLimit != nil {
+		if m.Spec.RemediationRateLimit.MaxRemediations < 1 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "remediationRateLimit", "maxRemediations"), m.Spec.RemediationRateLimit.MaxRemediations, "must be at least 1"),
+			)
+		}
+		if m.Spec.RemediationRateLimit.Window.Duration <= 0 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "remediationRateLimit", "window"), m.Spec.RemediationRateLimit.Window.Duration.String(), "must be greater than 0"),
+			)
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("MachineHealthCheck").GroupKind(), m.Name, allErrs)
 }
+
+func validateUnhealthyProbe(probe UnhealthyProbe, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if probe.Timeout.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeout"), probe.Timeout.Duration.String(), "must not be negative"))
+	}
+
+	switch probe.Type {
+	case PodNotReadyUnhealthyProbeType:
+		if probe.Pod == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("pod"), "must be set for PodNotReady probes"))
+			break
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&probe.Pod.Selector)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("pod", "selector"), probe.Pod.Selector, err.Error()))
+		} else if selector.Empty() {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("pod", "selector"), probe.Pod.Selector, "selector must not be empty"))
+		}
+	case NodeLabelUnhealthyProbeType, NodeTaintUnhealthyProbeType:
+		if probe.Key == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("key"), fmt.Sprintf("must be set for %s probes", probe.Type)))
+		}
+	case NodeLeaseExpiredUnhealthyProbeType:
+		if probe.Timeout.Duration <= 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("timeout"), "must be set for NodeLeaseExpired probes"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), probe.Type, []string{
+			string(PodNotReadyUnhealthyProbeType),
+			string(NodeLabelUnhealthyProbeType),
+			string(NodeTaintUnhealthyProbeType),
+			string(NodeLeaseExpiredUnhealthyProbeType),
+		}))
+	}
+
+	return allErrs
+}
+
+// ParseUnhealthyRange parses a range of unhealthy machines in the "[min-max]" format,
+// returning its lower and upper bounds.
+func ParseUnhealthyRange(unhealthyRange string) (int, int, error) {
+	parts := unhealthyRangeRegexp.FindStringSubmatch(unhealthyRange)
+	if parts == nil {
+		return 0, 0, errors.Errorf("must match the %q format", "[min-max]")
+	}
+	min, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid lower bound")
+	}
+	max, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid upper bound")
+	}
+	if max < min {
+		return 0, 0, errors.Errorf("upper bound %d must not be smaller than lower bound %d", max, min)
+	}
+	return min, max, nil
+}
@@ -253,6 +253,200 @@ func TestMachineHealthCheckMaxUnhealthy(t *testing.T) {
 	}
 }
 
+func TestMachineHealthCheckUnhealthyRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		expectErr bool
+	}{
+		{
+			name:      "when the range is valid",
+			value:     "[1-3]",
+			expectErr: false,
+		},
+		{
+			name:      "when the bounds of the range are equal",
+			value:     "[2-2]",
+			expectErr: false,
+		},
+		{
+			name:      "when the upper bound is smaller than the lower bound",
+			value:     "[3-1]",
+			expectErr: true,
+		},
+		{
+			name:      "when the value is not a range",
+			value:     "3",
+			expectErr: true,
+		},
+		{
+			name:      "when the range has negative bounds",
+			value:     "[-1-3]",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		g := NewWithT(t)
+
+		unhealthyRange := tt.value
+		mhc := &MachineHealthCheck{
+			Spec: MachineHealthCheckSpec{
+				UnhealthyRange: &unhealthyRange,
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "test",
+					},
+				},
+			},
+		}
+
+		if tt.expectErr {
+			g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+		} else {
+			g.Expect(mhc.ValidateCreate()).To(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+		}
+	}
+}
+
+func TestMachineHealthCheckRemediationRateLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		rateLimit *RemediationRateLimit
+		expectErr bool
+	}{
+		{
+			name:      "when the rate limit is not given",
+			rateLimit: nil,
+			expectErr: false,
+		},
+		{
+			name:      "when the rate limit is valid",
+			rateLimit: &RemediationRateLimit{MaxRemediations: 1, Window: metav1.Duration{Duration: 10 * time.Minute}},
+			expectErr: false,
+		},
+		{
+			name:      "when maxRemediations is 0",
+			rateLimit: &RemediationRateLimit{MaxRemediations: 0, Window: metav1.Duration{Duration: 10 * time.Minute}},
+			expectErr: true,
+		},
+		{
+			name:      "when the window is 0",
+			rateLimit: &RemediationRateLimit{MaxRemediations: 1},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		g := NewWithT(t)
+
+		mhc := &MachineHealthCheck{
+			Spec: MachineHealthCheckSpec{
+				RemediationRateLimit: tt.rateLimit,
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "test",
+					},
+				},
+			},
+		}
+
+		if tt.expectErr {
+			g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+		} else {
+			g.Expect(mhc.ValidateCreate()).To(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+		}
+	}
+}
+
+func TestMachineHealthCheckUnhealthyProbes(t *testing.T) {
+	podSelector := &PodProbeSelector{
+		Namespace: "kube-system",
+		Selector: metav1.LabelSelector{
+			MatchLabels: map[string]string{"k8s-app": "cni"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		probe     UnhealthyProbe
+		expectErr bool
+	}{
+		{
+			name:      "when a PodNotReady probe is valid",
+			probe:     UnhealthyProbe{Type: PodNotReadyUnhealthyProbeType, Pod: podSelector, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+			expectErr: false,
+		},
+		{
+			name:      "when a PodNotReady probe has no pod selector",
+			probe:     UnhealthyProbe{Type: PodNotReadyUnhealthyProbeType},
+			expectErr: true,
+		},
+		{
+			name:      "when a PodNotReady probe has an empty pod selector",
+			probe:     UnhealthyProbe{Type: PodNotReadyUnhealthyProbeType, Pod: &PodProbeSelector{Namespace: "kube-system"}},
+			expectErr: true,
+		},
+		{
+			name:      "when a NodeTaint probe is valid",
+			probe:     UnhealthyProbe{Type: NodeTaintUnhealthyProbeType, Key: "storage.example.com/unavailable"},
+			expectErr: false,
+		},
+		{
+			name:      "when a NodeLabel probe has no key",
+			probe:     UnhealthyProbe{Type: NodeLabelUnhealthyProbeType},
+			expectErr: true,
+		},
+		{
+			name:      "when a NodeLeaseExpired probe is valid",
+			probe:     UnhealthyProbe{Type: NodeLeaseExpiredUnhealthyProbeType, Timeout: metav1.Duration{Duration: 2 * time.Minute}},
+			expectErr: false,
+		},
+		{
+			name:      "when a NodeLeaseExpired probe has no timeout",
+			probe:     UnhealthyProbe{Type: NodeLeaseExpiredUnhealthyProbeType},
+			expectErr: true,
+		},
+		{
+			name:      "when the timeout is negative",
+			probe:     UnhealthyProbe{Type: NodeLabelUnhealthyProbeType, Key: "foo", Timeout: metav1.Duration{Duration: -1 * time.Minute}},
+			expectErr: true,
+		},
+		{
+			name:      "when the probe type is not supported",
+			probe:     UnhealthyProbe{Type: "Unknown"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		g := NewWithT(t)
+
+		mhc := &MachineHealthCheck{
+			Spec: MachineHealthCheckSpec{
+				UnhealthyProbes: []UnhealthyProbe{tt.probe},
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "test",
+					},
+				},
+			},
+		}
+
+		if tt.expectErr {
+			g.Expect(mhc.ValidateCreate()).NotTo(Succeed(), tt.name)
+			g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed(), tt.name)
+		} else {
+			g.Expect(mhc.ValidateCreate()).To(Succeed(), tt.name)
+			g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed(), tt.name)
+		}
+	}
+}
+
 func TestMachineHealthCheckSelectorValidation(t *testing.T) {
 	g := NewWithT(t)
 	mhc := &MachineHealthCheck{}
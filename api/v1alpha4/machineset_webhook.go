@@ -111,6 +111,8 @@ func (m *MachineSet) validate(old *MachineSet) error {
 		)
 	}
 
+	allErrs = append(allErrs, validateDrainPolicy(m.Spec.Template.Spec.DrainPolicy, field.NewPath("spec", "template", "spec", "drainPolicy"))...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -31,6 +31,13 @@ const (
 	CloudConfig Format = "cloud-config"
 )
 
+const (
+	// ReinitializeControlPlaneAnnotation is set on the KubeadmConfig of a control plane machine which must run kubeadm init
+	// even if the control plane of the cluster has already been initialized, e.g. when a control plane provider replaces
+	// the only machine of a control plane. The annotation is ignored for the other machines.
+	ReinitializeControlPlaneAnnotation = "bootstrap.cluster.x-k8s.io/reinitialize-control-plane"
+)
+
 // KubeadmConfigSpec defines the desired state of KubeadmConfig.
 // Either ClusterConfiguration and InitConfiguration should be defined or the JoinConfiguration should be defined.
 type KubeadmConfigSpec struct {
@@ -246,6 +246,16 @@ func (r *KubeadmConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return r.handleClusterNotInitialized(ctx, scope)
 	}
 
+	// If the control plane is initialized again, e.g. because its only machine has been replaced, release the lock
+	// left over from the previous initialization, and proceed like when the control plane is not initialized.
+	if _, ok := config.Annotations[bootstrapv1.ReinitializeControlPlaneAnnotation]; ok && configOwner.IsControlPlaneMachine() {
+		log.Info("Reinitializing the control plane")
+		if !r.KubeadmInitLock.Unlock(ctx, cluster) {
+			return ctrl.Result{}, errors.New("failed to unlock the kubeadm init lock")
+		}
+		return r.handleClusterNotInitialized(ctx, scope)
+	}
+
 	// Every other case it's a join scenario
 	// Nb. in this case ClusterConfiguration and InitConfiguration should not be defined by users, but in case of misconfigurations, CABPK simply ignore them
 
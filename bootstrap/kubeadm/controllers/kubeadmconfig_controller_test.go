@@ -417,6 +417,56 @@ func TestKubeadmConfigReconciler_Reconcile_GenerateCloudConfigData(t *testing.T)
 	g.Expect(err).NotTo(HaveOccurred())
 }
 
+// If the control plane is reinitialized, the init data are generated even if the control plane has already been initialized.
+func TestKubeadmConfigReconciler_Reconcile_GenerateCloudConfigDataForReinitializedControlPlane(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+	cluster.Status.ControlPlaneInitialized = true
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
+
+	controlPlaneInitMachine := newControlPlaneMachine(cluster, "control-plane-init-machine")
+	controlPlaneInitConfig := newControlPlaneInitKubeadmConfig(controlPlaneInitMachine, "control-plane-init-cfg")
+	controlPlaneInitConfig.Annotations = map[string]string{bootstrapv1.ReinitializeControlPlaneAnnotation: ""}
+
+	objects := []client.Object{
+		cluster,
+		controlPlaneInitMachine,
+		controlPlaneInitConfig,
+	}
+	objects = append(objects, createSecrets(t, cluster, controlPlaneInitConfig)...)
+
+	myclient := helpers.NewFakeClientWithScheme(setupScheme(), objects...)
+
+	k := &KubeadmConfigReconciler{
+		Client: myclient,
+		// The lock left over from the previous initialization of the control plane.
+		KubeadmInitLock: &myInitLocker{locked: true},
+	}
+
+	request := ctrl.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: "default",
+			Name:      "control-plane-init-cfg",
+		},
+	}
+	result, err := k.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Requeue).To(BeFalse())
+	g.Expect(result.RequeueAfter).To(Equal(time.Duration(0)))
+
+	cfg, err := getKubeadmConfig(myclient, "control-plane-init-cfg")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Status.Ready).To(BeTrue())
+	g.Expect(cfg.Status.DataSecretName).NotTo(BeNil())
+	g.Expect(cfg.Spec.JoinConfiguration).To(BeNil())
+
+	s := &corev1.Secret{}
+	g.Expect(myclient.Get(ctx, client.ObjectKey{Namespace: "default", Name: *cfg.Status.DataSecretName}, s)).To(Succeed())
+	g.Expect(string(s.Data["value"])).To(ContainSubstring("kubeadm init"))
+}
+
 // If a control plane has no JoinConfiguration, then we will create a default and no error will occur
 func TestKubeadmConfigReconciler_Reconcile_ErrorIfJoiningControlPlaneHasInvalidConfiguration(t *testing.T) {
 	g := NewWithT(t)
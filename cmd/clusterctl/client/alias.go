@@ -38,6 +38,9 @@ type ComponentsOptions repository.ComponentsOptions
 // Template wraps a YAML file that defines the cluster objects (Cluster, Machines etc.).
 type Template repository.Template
 
+// VariableSchema defines type, default value, validation rules and description of a cluster template variable.
+type VariableSchema repository.VariableSchema
+
 // UpgradePlan defines a list of possible upgrade targets for a management group.
 type UpgradePlan cluster.UpgradePlan
 
@@ -30,6 +30,9 @@ type Rollout interface {
 	ObjectRestarter(cluster.Proxy, util.ResourceTuple, string) error
 	ObjectPauser(cluster.Proxy, util.ResourceTuple, string) error
 	ObjectResumer(cluster.Proxy, util.ResourceTuple, string) error
+	ObjectHistoryViewer(cluster.Proxy, util.ResourceTuple, string) ([]RolloutRevision, error)
+	ObjectRollbacker(cluster.Proxy, util.ResourceTuple, string, int64) error
+	ObjectStatusViewer(cluster.Proxy, util.ResourceTuple, string) (string, bool, error)
 }
 
 var _ Rollout = &rollout{}
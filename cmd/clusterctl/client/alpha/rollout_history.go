@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alpha
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RolloutRevision defines a revision of a cluster-api resource.
+type RolloutRevision struct {
+	// Revision number, as tracked by the revision annotation.
+	Revision int64
+
+	// MachineSet implementing the revision.
+	MachineSet string
+
+	// CreationTimestamp of the MachineSet implementing the revision.
+	CreationTimestamp metav1.Time
+
+	// Template of the machines for the revision.
+	Template clusterv1.MachineTemplateSpec
+
+	// Diff of the template compared to the previous revision; it is empty for the first revision.
+	Diff string
+}
+
+// ObjectHistoryViewer returns the revisions of the specified cluster-api resource, sorted by revision number.
+func (r *rollout) ObjectHistoryViewer(proxy cluster.Proxy, tuple util.ResourceTuple, namespace string) ([]RolloutRevision, error) {
+	switch tuple.Resource {
+	case machineDeployment:
+		deployment, err := getMachineDeployment(proxy, tuple.Name, namespace)
+		if err != nil || deployment == nil {
+			return nil, errors.Wrapf(err, "failed to fetch %v/%v", tuple.Resource, tuple.Name)
+		}
+		msList, err := getMachineSetsForDeployment(proxy, deployment)
+		if err != nil {
+			return nil, err
+		}
+		return machineDeploymentRevisions(msList), nil
+	default:
+		return nil, errors.Errorf("Invalid resource type %q, valid values are %v", tuple.Resource, validResourceTypes)
+	}
+}
+
+// machineDeploymentRevisions returns the revisions implemented by a list of MachineSets, sorted by revision number.
+func machineDeploymentRevisions(msList []*clusterv1.MachineSet) []RolloutRevision {
+	revisions := make([]RolloutRevision, 0, len(msList))
+	for _, ms := range msList {
+		revision, err := mdutil.Revision(ms)
+		if err != nil {
+			// MachineSets without a valid revision annotation are not part of the history.
+			continue
+		}
+		revisions = append(revisions, RolloutRevision{
+			Revision:          revision,
+			MachineSet:        ms.Name,
+			CreationTimestamp: ms.CreationTimestamp,
+			Template:          templateWithoutHash(ms.Spec.Template),
+		})
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+
+	for i := 1; i < len(revisions); i++ {
+		revisions[i].Diff = cmp.Diff(revisions[i-1].Template, revisions[i].Template)
+	}
+	return revisions
+}
+
+// templateWithoutHash returns a copy of a MachineSet template without the label added by the MachineDeployment
+// controller to make the template unique.
+func templateWithoutHash(template clusterv1.MachineTemplateSpec) clusterv1.MachineTemplateSpec {
+	t := template.DeepCopy()
+	delete(t.Labels, mdutil.DefaultMachineDeploymentUniqueLabelKey)
+	if len(t.Labels) == 0 {
+		t.Labels = nil
+	}
+	return *t
+}
+
+// getMachineSetsForDeployment returns the MachineSets controlled by a MachineDeployment.
+func getMachineSetsForDeployment(proxy cluster.Proxy, deployment *clusterv1.MachineDeployment) ([]*clusterv1.MachineSet, error) {
+	c, err := proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsMap(&deployment.Spec.Selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert the selector of MachineDeployment %s/%s", deployment.Namespace, deployment.Name)
+	}
+
+	msList := &clusterv1.MachineSetList{}
+	if err := c.List(context.TODO(), msList, client.InNamespace(deployment.Namespace), client.MatchingLabels(selector)); err != nil {
+		return nil, errors.Wrapf(err, "failed to list MachineSets for MachineDeployment %s/%s", deployment.Namespace, deployment.Name)
+	}
+
+	machineSets := make([]*clusterv1.MachineSet, 0, len(msList.Items))
+	for i := range msList.Items {
+		ms := &msList.Items[i]
+		if !isControlledBy(ms, deployment) {
+			continue
+		}
+		machineSets = append(machineSets, ms)
+	}
+	return machineSets, nil
+}
+
+// isControlledBy returns true if the MachineSet is controlled by the MachineDeployment.
+// NB. The UID is not checked, so the check works also for objects built without it, e.g. in tests.
+func isControlledBy(ms *clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) bool {
+	ref := metav1.GetControllerOf(ms)
+	return ref != nil && ref.Kind == "MachineDeployment" && ref.Name == deployment.Name
+}
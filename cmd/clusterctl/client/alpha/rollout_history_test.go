@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alpha
+
+import (
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rolloutHistoryObjs returns a MachineDeployment with a MachineSet for each version, where the revision
+// of each MachineSet is its position in the list.
+func rolloutHistoryObjs(versions ...string) []client.Object {
+	md := &clusterv1.MachineDeployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "MachineDeployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "md-1",
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{clusterv1.MachineDeploymentLabelName: "md-1"}},
+			Template: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{Labels: map[string]string{clusterv1.MachineDeploymentLabelName: "md-1"}},
+				Spec:       clusterv1.MachineSpec{Version: pointer.StringPtr(versions[len(versions)-1])},
+			},
+		},
+	}
+	objs := []client.Object{md}
+	for i, version := range versions {
+		objs = append(objs, &clusterv1.MachineSet{
+			TypeMeta: metav1.TypeMeta{
+				Kind: "MachineSet",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "md-1-" + version,
+				Labels: map[string]string{
+					clusterv1.MachineDeploymentLabelName:          "md-1",
+					mdutil.DefaultMachineDeploymentUniqueLabelKey: version,
+				},
+				Annotations:     map[string]string{clusterv1.RevisionAnnotation: strconv.Itoa(i + 1)},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "MachineDeployment", Name: "md-1", Controller: pointer.BoolPtr(true)}},
+			},
+			Spec: clusterv1.MachineSetSpec{
+				Template: clusterv1.MachineTemplateSpec{
+					ObjectMeta: clusterv1.ObjectMeta{Labels: map[string]string{
+						clusterv1.MachineDeploymentLabelName:          "md-1",
+						mdutil.DefaultMachineDeploymentUniqueLabelKey: version,
+					}},
+					Spec: clusterv1.MachineSpec{Version: pointer.StringPtr(version)},
+				},
+			},
+		})
+	}
+	return objs
+}
+
+func Test_ObjectHistoryViewer(t *testing.T) {
+	g := NewWithT(t)
+
+	objs := append(rolloutHistoryObjs("v1.19.1", "v1.20.1"),
+		// MachineSets not controlled by the MachineDeployment are not part of the history.
+		&clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "orphan",
+				Labels:      map[string]string{clusterv1.MachineDeploymentLabelName: "md-1"},
+				Annotations: map[string]string{clusterv1.RevisionAnnotation: "3"},
+			},
+		},
+	)
+
+	r := newRolloutClient()
+	proxy := test.NewFakeProxy().WithObjs(objs...)
+	revisions, err := r.ObjectHistoryViewer(proxy, util.ResourceTuple{Resource: "machinedeployment", Name: "md-1"}, "default")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(revisions).To(HaveLen(2))
+	g.Expect(revisions[0].Revision).To(Equal(int64(1)))
+	g.Expect(revisions[0].MachineSet).To(Equal("md-1-v1.19.1"))
+	g.Expect(revisions[0].Template.Labels).ToNot(HaveKey(mdutil.DefaultMachineDeploymentUniqueLabelKey))
+	g.Expect(revisions[0].Diff).To(BeEmpty())
+	g.Expect(revisions[1].Revision).To(Equal(int64(2)))
+	g.Expect(revisions[1].MachineSet).To(Equal("md-1-v1.20.1"))
+	g.Expect(revisions[1].Diff).To(ContainSubstring("v1.19.1"))
+	g.Expect(revisions[1].Diff).To(ContainSubstring("v1.20.1"))
+
+	_, err = r.ObjectHistoryViewer(proxy, util.ResourceTuple{Resource: "machineset", Name: "md-1-v1.20.1"}, "default")
+	g.Expect(err).To(HaveOccurred())
+}
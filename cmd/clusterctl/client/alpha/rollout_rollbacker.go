@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alpha
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ObjectRollbacker will issue a rollback on the specified cluster-api resource, restoring the template
+// of the given revision; if the revision is 0, the previous revision is restored.
+func (r *rollout) ObjectRollbacker(proxy cluster.Proxy, tuple util.ResourceTuple, namespace string, toRevision int64) error {
+	switch tuple.Resource {
+	case machineDeployment:
+		deployment, err := getMachineDeployment(proxy, tuple.Name, namespace)
+		if err != nil || deployment == nil {
+			return errors.Wrapf(err, "failed to fetch %v/%v", tuple.Resource, tuple.Name)
+		}
+		if deployment.Spec.Paused {
+			return errors.Errorf("can't rollback paused machinedeployment (run rollout resume first): %v/%v", tuple.Resource, tuple.Name)
+		}
+		msList, err := getMachineSetsForDeployment(proxy, deployment)
+		if err != nil {
+			return err
+		}
+		revision, err := findRevision(machineDeploymentRevisions(msList), toRevision)
+		if err != nil {
+			return errors.Wrapf(err, "failed to rollback %v/%v", tuple.Resource, tuple.Name)
+		}
+		if err := rollbackMachineDeployment(proxy, deployment, revision); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("Invalid resource type %q, valid values are %v", tuple.Resource, validResourceTypes)
+	}
+	return nil
+}
+
+// findRevision returns the revision with the given number, or the previous revision if the number is 0.
+func findRevision(revisions []RolloutRevision, toRevision int64) (*RolloutRevision, error) {
+	if toRevision < 0 {
+		return nil, errors.Errorf("invalid revision %d", toRevision)
+	}
+	if toRevision == 0 {
+		// NB. revisions are sorted, and the last one is the current revision.
+		if len(revisions) < 2 {
+			return nil, errors.New("no previous revision found")
+		}
+		return &revisions[len(revisions)-2], nil
+	}
+	for i := range revisions {
+		if revisions[i].Revision == toRevision {
+			return &revisions[i], nil
+		}
+	}
+	return nil, errors.Errorf("unable to find revision %d in history", toRevision)
+}
+
+// rollbackMachineDeployment sets the template of the MachineDeployment to the template of the revision.
+func rollbackMachineDeployment(proxy cluster.Proxy, deployment *clusterv1.MachineDeployment, revision *RolloutRevision) error {
+	log := logf.Log
+
+	if mdutil.EqualMachineTemplate(&deployment.Spec.Template, &revision.Template) {
+		log.Info("Skipped rollback, the current template already matches the revision", "MachineDeployment", deployment.Name, "revision", revision.Revision)
+		return nil
+	}
+
+	c, err := proxy.NewClient()
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(deployment.DeepCopy())
+	deployment.Spec.Template = *revision.Template.DeepCopy()
+	if err := c.Patch(context.TODO(), deployment, patch); err != nil {
+		return errors.Wrapf(err, "error while patching %s/%s", deployment.GetNamespace(), deployment.GetName())
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alpha
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_ObjectRollbacker(t *testing.T) {
+	tests := []struct {
+		name        string
+		objs        []client.Object
+		paused      bool
+		toRevision  int64
+		wantErr     bool
+		wantVersion string
+	}{
+		{
+			name:        "rollback to the previous revision",
+			objs:        rolloutHistoryObjs("v1.19.1", "v1.20.1", "v1.21.1"),
+			toRevision:  0,
+			wantVersion: "v1.20.1",
+		},
+		{
+			name:        "rollback to a specific revision",
+			objs:        rolloutHistoryObjs("v1.19.1", "v1.20.1", "v1.21.1"),
+			toRevision:  1,
+			wantVersion: "v1.19.1",
+		},
+		{
+			name:       "return error if the revision does not exist",
+			objs:       rolloutHistoryObjs("v1.19.1", "v1.20.1"),
+			toRevision: 5,
+			wantErr:    true,
+		},
+		{
+			name:       "return error if there is no previous revision",
+			objs:       rolloutHistoryObjs("v1.19.1"),
+			toRevision: 0,
+			wantErr:    true,
+		},
+		{
+			name:       "return error if the machinedeployment is paused",
+			objs:       rolloutHistoryObjs("v1.19.1", "v1.20.1"),
+			paused:     true,
+			toRevision: 0,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			tt.objs[0].(*clusterv1.MachineDeployment).Spec.Paused = tt.paused
+
+			r := newRolloutClient()
+			proxy := test.NewFakeProxy().WithObjs(tt.objs...)
+			err := r.ObjectRollbacker(proxy, util.ResourceTuple{Resource: "machinedeployment", Name: "md-1"}, "default", tt.toRevision)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			cl, err := proxy.NewClient()
+			g.Expect(err).ToNot(HaveOccurred())
+			md := &clusterv1.MachineDeployment{}
+			g.Expect(cl.Get(context.TODO(), client.ObjectKeyFromObject(tt.objs[0]), md)).To(Succeed())
+			g.Expect(*md.Spec.Template.Spec.Version).To(Equal(tt.wantVersion))
+			g.Expect(md.Spec.Template.Labels).ToNot(HaveKey(mdutil.DefaultMachineDeploymentUniqueLabelKey))
+		})
+	}
+}
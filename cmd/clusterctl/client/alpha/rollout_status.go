@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alpha
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+)
+
+// ObjectStatusViewer returns a message describing the rollout status of the specified cluster-api resource,
+// and if the rollout is completed. An error is returned if the rollout failed.
+func (r *rollout) ObjectStatusViewer(proxy cluster.Proxy, tuple util.ResourceTuple, namespace string) (string, bool, error) {
+	switch tuple.Resource {
+	case machineDeployment:
+		deployment, err := getMachineDeployment(proxy, tuple.Name, namespace)
+		if err != nil || deployment == nil {
+			return "", false, errors.Wrapf(err, "failed to fetch %v/%v", tuple.Resource, tuple.Name)
+		}
+		return machineDeploymentStatus(deployment)
+	default:
+		return "", false, errors.Errorf("Invalid resource type %q, valid values are %v", tuple.Resource, validResourceTypes)
+	}
+}
+
+// machineDeploymentStatus returns the rollout status of a MachineDeployment.
+func machineDeploymentStatus(deployment *clusterv1.MachineDeployment) (string, bool, error) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return fmt.Sprintf("Waiting for MachineDeployment %q spec update to be observed...", deployment.Name), false, nil
+	}
+	if deployment.Status.GetTypedPhase() == clusterv1.MachineDeploymentPhaseFailed {
+		return "", false, errors.Errorf("rollout of MachineDeployment %q failed", deployment.Name)
+	}
+
+	var replicas int32 = 1
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	if status.UpdatedReplicas < replicas {
+		return fmt.Sprintf("Waiting for MachineDeployment %q rollout to finish: %d out of %d new replicas have been updated...", deployment.Name, status.UpdatedReplicas, replicas), false, nil
+	}
+	if status.Replicas > status.UpdatedReplicas {
+		return fmt.Sprintf("Waiting for MachineDeployment %q rollout to finish: %d old replicas are pending termination...", deployment.Name, status.Replicas-status.UpdatedReplicas), false, nil
+	}
+	if status.AvailableReplicas < status.UpdatedReplicas {
+		return fmt.Sprintf("Waiting for MachineDeployment %q rollout to finish: %d of %d updated replicas are available...", deployment.Name, status.AvailableReplicas, status.UpdatedReplicas), false, nil
+	}
+	return fmt.Sprintf("MachineDeployment %q successfully rolled out", deployment.Name), true, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alpha
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+)
+
+func Test_ObjectStatusViewer(t *testing.T) {
+	tests := []struct {
+		name        string
+		generation  int64
+		status      clusterv1.MachineDeploymentStatus
+		wantErr     bool
+		wantDone    bool
+		wantMessage string
+	}{
+		{
+			name:        "spec update not observed",
+			generation:  2,
+			status:      clusterv1.MachineDeploymentStatus{ObservedGeneration: 1},
+			wantMessage: "spec update to be observed",
+		},
+		{
+			name:        "replicas not updated",
+			generation:  2,
+			status:      clusterv1.MachineDeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1},
+			wantMessage: "1 out of 3 new replicas have been updated",
+		},
+		{
+			name:        "old replicas pending termination",
+			generation:  2,
+			status:      clusterv1.MachineDeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 3},
+			wantMessage: "1 old replicas are pending termination",
+		},
+		{
+			name:        "updated replicas not available",
+			generation:  2,
+			status:      clusterv1.MachineDeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2},
+			wantMessage: "2 of 3 updated replicas are available",
+		},
+		{
+			name:        "rollout completed",
+			generation:  2,
+			status:      clusterv1.MachineDeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			wantDone:    true,
+			wantMessage: "successfully rolled out",
+		},
+		{
+			name:       "rollout failed",
+			generation: 2,
+			status:     clusterv1.MachineDeploymentStatus{ObservedGeneration: 2, Phase: string(clusterv1.MachineDeploymentPhaseFailed)},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			md := &clusterv1.MachineDeployment{
+				TypeMeta: metav1.TypeMeta{
+					Kind: "MachineDeployment",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  "default",
+					Name:       "md-1",
+					Generation: tt.generation,
+				},
+				Spec: clusterv1.MachineDeploymentSpec{
+					Replicas: pointer.Int32Ptr(3),
+				},
+				Status: tt.status,
+			}
+
+			r := newRolloutClient()
+			proxy := test.NewFakeProxy().WithObjs(md)
+			message, done, err := r.ObjectStatusViewer(proxy, util.ResourceTuple{Resource: "machinedeployment", Name: "md-1"}, "default")
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(done).To(Equal(tt.wantDone))
+			g.Expect(message).To(ContainSubstring(tt.wantMessage))
+		})
+	}
+}
@@ -51,6 +51,12 @@ type Client interface {
 	// Move moves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster.
 	Move(options MoveOptions) error
 
+	// Backup saves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a directory or to a tarball.
+	Backup(options BackupOptions) error
+
+	// Restore restores all the Cluster API objects saved by Backup into a target management cluster.
+	Restore(options RestoreOptions) error
+
 	// PlanUpgrade returns a set of suggested Upgrade plans for the cluster, and more specifically:
 	// - Each management group gets separated upgrade plans.
 	// - For each management group, an upgrade plan is generated for each API Version of Cluster API (contract) available, e.g.
@@ -83,6 +89,12 @@ type AlphaClient interface {
 	RolloutPause(options RolloutOptions) error
 	// RolloutResume provides rollout resume of paused cluster-api resources
 	RolloutResume(options RolloutOptions) error
+	// RolloutHistory provides the rollout history of cluster-api resources
+	RolloutHistory(options RolloutOptions) ([]RolloutHistory, error)
+	// RolloutUndo provides rollout undo of cluster-api resources to a previous revision
+	RolloutUndo(options RolloutUndoOptions) error
+	// RolloutStatus waits for the rollout of cluster-api resources to complete
+	RolloutStatus(options RolloutStatusOptions) error
 }
 
 // YamlPrinter exposes methods that prints the processed template and
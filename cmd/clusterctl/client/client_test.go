@@ -103,6 +103,14 @@ func (f fakeClient) Move(options MoveOptions) error {
 	return f.internalClient.Move(options)
 }
 
+func (f fakeClient) Backup(options BackupOptions) error {
+	return f.internalClient.Backup(options)
+}
+
+func (f fakeClient) Restore(options RestoreOptions) error {
+	return f.internalClient.Restore(options)
+}
+
 func (f fakeClient) PlanUpgrade(options PlanUpgradeOptions) ([]UpgradePlan, error) {
 	return f.internalClient.PlanUpgrade(options)
 }
@@ -135,6 +143,18 @@ func (f fakeClient) RolloutResume(options RolloutOptions) error {
 	return f.internalClient.RolloutResume(options)
 }
 
+func (f fakeClient) RolloutHistory(options RolloutOptions) ([]RolloutHistory, error) {
+	return f.internalClient.RolloutHistory(options)
+}
+
+func (f fakeClient) RolloutUndo(options RolloutUndoOptions) error {
+	return f.internalClient.RolloutUndo(options)
+}
+
+func (f fakeClient) RolloutStatus(options RolloutStatusOptions) error {
+	return f.internalClient.RolloutStatus(options)
+}
+
 // newFakeClient returns a clusterctl client that allows to execute tests on a set of fake config, fake repositories and fake clusters.
 // you can use WithCluster and WithRepository to prepare for the test case.
 func newFakeClient(configClient config.Client) *fakeClient {
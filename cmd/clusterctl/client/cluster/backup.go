@@ -0,0 +1,210 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const backupFileExtension = ".yaml"
+
+// backupPausedAnnotation is set on the Clusters saved to a backup which were paused by the backup operation, as opposed to
+// the Clusters already paused by the user; restore resumes only the Clusters with this annotation, and removes it.
+const backupPausedAnnotation = "clusterctl.cluster.x-k8s.io/backup-paused"
+
+// isTarball returns true if the backup path refers to a tarball instead of a directory.
+func isTarball(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// backupFileName returns the name of the backup file for an object, in the format <Kind>.<group>_<namespace>_<name>.yaml,
+// or <Kind>_<namespace>_<name>.yaml for objects in the core API group; the group is included so objects with the same
+// Kind in different API groups do not overwrite each other.
+// NB. the namespace is empty for global objects.
+func backupFileName(obj *unstructured.Unstructured) string {
+	kind := obj.GetKind()
+	if group := obj.GroupVersionKind().Group; group != "" {
+		kind = fmt.Sprintf("%s.%s", kind, group)
+	}
+	return fmt.Sprintf("%s_%s_%s%s", kind, obj.GetNamespace(), obj.GetName(), backupFileExtension)
+}
+
+// writeBackup saves a list of objects to a directory or to a tarball, one file for each object.
+func writeBackup(path string, objs []unstructured.Unstructured) error {
+	files := map[string][]byte{}
+	for i := range objs {
+		obj := &objs[i]
+		content, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal %q %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+		}
+		files[backupFileName(obj)] = content
+	}
+
+	if isTarball(path) {
+		return writeBackupTarball(path, files)
+	}
+	return writeBackupDirectory(path, files)
+}
+
+func writeBackupDirectory(path string, files map[string][]byte) error {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create backup directory %q", path)
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(path, name), content, 0600); err != nil {
+			return errors.Wrapf(err, "failed to write backup file %q", name)
+		}
+	}
+	return nil
+}
+
+func writeBackupTarball(path string, files map[string][]byte) (reterr error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory for backup tarball %q", path)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create backup tarball %q", path)
+	}
+	defer func() {
+		if err := f.Close(); err != nil && reterr == nil {
+			reterr = errors.Wrapf(err, "failed to write backup tarball %q", path)
+		}
+	}()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	// Nb. files are written in a stable order, so the same set of objects always generates the same tarball.
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content))}); err != nil {
+			return errors.Wrapf(err, "failed to write backup file %q to tarball", name)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return errors.Wrapf(err, "failed to write backup file %q to tarball", name)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write backup tarball %q", path)
+	}
+	if err := gw.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write backup tarball %q", path)
+	}
+	return nil
+}
+
+// readBackup reads the list of objects saved to a directory or to a tarball by writeBackup.
+func readBackup(path string) ([]unstructured.Unstructured, error) {
+	var files map[string][]byte
+	var err error
+	if isTarball(path) {
+		files, err = readBackupTarball(path)
+	} else {
+		files, err = readBackupDirectory(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objs := []unstructured.Unstructured{}
+	for name, content := range files {
+		obj := unstructured.Unstructured{}
+		if err := yaml.Unmarshal(content, &obj.Object); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal backup file %q", name)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+func readBackupDirectory(path string) (map[string][]byte, error) {
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read backup directory %q", path)
+	}
+
+	files := map[string][]byte{}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != backupFileExtension {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(path, e.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read backup file %q", e.Name())
+		}
+		files[e.Name()] = content
+	}
+	return files, nil
+}
+
+func readBackupTarball(path string) (map[string][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open backup tarball %q", path)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read backup tarball %q", path)
+	}
+	defer gr.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read backup tarball %q", path)
+		}
+		if header.Typeflag != tar.TypeReg || filepath.Ext(header.Name) != backupFileExtension {
+			continue
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read backup file %q from tarball", header.Name)
+		}
+		files[header.Name] = content
+	}
+	return files, nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_backupFileName(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+		kind       string
+		namespace  string
+		want       string
+	}{
+		{
+			name:       "core API group",
+			apiVersion: "v1",
+			kind:       "Secret",
+			namespace:  "ns1",
+			want:       "Secret_ns1_foo.yaml",
+		},
+		{
+			name:       "named API group",
+			apiVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+			kind:       "Machine",
+			namespace:  "ns1",
+			want:       "Machine.infrastructure.cluster.x-k8s.io_ns1_foo.yaml",
+		},
+		{
+			name:       "global object",
+			apiVersion: "cluster.x-k8s.io/v1alpha4",
+			kind:       "Machine",
+			want:       "Machine.cluster.x-k8s.io__foo.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion(tt.apiVersion)
+			obj.SetKind(tt.kind)
+			obj.SetNamespace(tt.namespace)
+			obj.SetName("foo")
+			g.Expect(backupFileName(obj)).To(Equal(tt.want))
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -36,7 +37,21 @@ import (
 // ObjectMover defines methods for moving Cluster API objects to another management cluster.
 type ObjectMover interface {
 	// Move moves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster.
-	Move(namespace string, toCluster Client, dryRun bool) error
+	Move(namespace string, toCluster Client, dryRun bool, options ...MoveOption) error
+
+	// Resume resumes a move operation that failed, using the progress recorded in a move journal.
+	Resume(toCluster Client, journalPath string) error
+
+	// Rollback rolls back a move operation that failed while creating objects in the target management cluster,
+	// using the progress recorded in a move journal.
+	Rollback(toCluster Client, journalPath string) error
+
+	// Backup saves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a directory,
+	// or to a tarball if the path ends with .tar.gz or .tgz.
+	Backup(namespace string, path string) error
+
+	// Restore restores all the Cluster API objects saved by Backup into a target management cluster.
+	Restore(toCluster Client, path string) error
 }
 
 // objectMover implements the ObjectMover interface.
@@ -44,22 +59,61 @@ type objectMover struct {
 	fromProxy             Proxy
 	fromProviderInventory InventoryClient
 	dryRun                bool
+	journalPath           string
+	journal               *moveJournal
+	clusterName           string
+	clusterSelector       labels.Selector
 }
 
 // ensure objectMover implements the ObjectMover interface.
 var _ ObjectMover = &objectMover{}
 
-func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) error {
+// MoveOption is a configuration option supplied to Move.
+type MoveOption func(*objectMover)
+
+// WithMoveJournal persists the progress of the move operation to a journal at the given path,
+// so a move that fails can be resumed or rolled back.
+func WithMoveJournal(path string) MoveOption {
+	return func(o *objectMover) {
+		o.journalPath = path
+	}
+}
+
+// WithClusterName limits the move operation to the Cluster with the given name and all its dependent objects,
+// instead of moving all the Clusters in the namespace.
+func WithClusterName(name string) MoveOption {
+	return func(o *objectMover) {
+		o.clusterName = name
+	}
+}
+
+// WithClusterSelector limits the move operation to the Clusters matching the given label selector and all their dependent objects,
+// instead of moving all the Clusters in the namespace.
+func WithClusterSelector(selector labels.Selector) MoveOption {
+	return func(o *objectMover) {
+		o.clusterSelector = selector
+	}
+}
+
+func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool, options ...MoveOption) error {
 	log := logf.Log
 	log.Info("Performing move...")
 	o.dryRun = dryRun
+	for _, option := range options {
+		option(o)
+	}
 	if o.dryRun {
 		log.Info("********************************************************")
 		log.Info("This is a dry-run move, will not perform any real action")
 		log.Info("********************************************************")
 	}
 
-	objectGraph := newObjectGraph(o.fromProxy)
+	// Checks that the journal does not record a move in progress, which would be overwritten by this move.
+	if !o.dryRun && o.journalPath != "" {
+		if err := checkNoMoveInProgress(o.journalPath); err != nil {
+			return err
+		}
+	}
 
 	// checks that all the required providers in place in the target cluster.
 	if !o.dryRun {
@@ -68,17 +122,175 @@ func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) erro
 		}
 	}
 
+	objectGraph, err := o.getObjectGraph(namespace)
+	if err != nil {
+		return err
+	}
+
+	if o.isSelective() && len(objectGraph.getClusters()) == 0 {
+		return errors.Errorf("failed to find Clusters matching the given name and label selector in namespace %q", namespace)
+	}
+
+	// Starts recording the progress of the move operation, if required.
+	if !o.dryRun && o.journalPath != "" {
+		o.journal = newMoveJournal(o.journalPath, namespace, objectGraph.getClusters())
+		o.journal.ClusterName = o.clusterName
+		if o.clusterSelector != nil {
+			o.journal.ClusterSelector = o.clusterSelector.String()
+		}
+		if err := o.journal.save(); err != nil {
+			return err
+		}
+
+		// Records the Clusters already paused before the move, so they are left paused in case of rollback.
+		unpausedClusters, err := getUnpausedClusters(o.fromProxy, objectGraph.getClusters())
+		if err != nil {
+			return err
+		}
+		if err := o.journal.setPausedClusters(unpausedClusters); err != nil {
+			return err
+		}
+	}
+
+	// Move the objects to the target cluster.
+	var proxy Proxy
+	if !o.dryRun {
+		proxy = toCluster.Proxy()
+	}
+
+	if err := o.move(objectGraph, proxy); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *objectMover) Resume(toCluster Client, journalPath string) error {
+	log := logf.Log
+	log.Info("Resuming move...")
+	o.dryRun = false
+
+	journal, err := readMoveJournal(journalPath)
+	if err != nil {
+		return err
+	}
+	if journal.Phase != moveJournalCreatingPhase && journal.Phase != moveJournalDeletingPhase {
+		return errors.Errorf("the move operation recorded in %q is %s, nothing to resume", journalPath, journal.Phase)
+	}
+	o.journal = journal
+
+	// Restores the Clusters selection of the move operation, if any.
+	o.clusterName = journal.ClusterName
+	if journal.ClusterSelector != "" {
+		o.clusterSelector, err = labels.Parse(journal.ClusterSelector)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse the cluster selector recorded in %q", journalPath)
+		}
+	}
+
+	// Rebuild the object graph from the objects still existing in the source cluster.
+	objectGraph, err := o.getObjectGraph(journal.Namespace)
+	if err != nil {
+		return err
+	}
+
+	// Resume moving the objects to the target cluster.
+	return o.resume(objectGraph, toCluster.Proxy())
+}
+
+func (o *objectMover) Rollback(toCluster Client, journalPath string) error {
+	log := logf.Log
+	log.Info("Rolling back move...")
+	o.dryRun = false
+
+	journal, err := readMoveJournal(journalPath)
+	if err != nil {
+		return err
+	}
+	if journal.Phase != moveJournalCreatingPhase {
+		return errors.Errorf("the move operation recorded in %q is %s, it cannot be rolled back", journalPath, journal.Phase)
+	}
+	o.journal = journal
+
+	// Delete the objects created in the target cluster and resume the source cluster.
+	return o.rollback(toCluster.Proxy())
+}
+
+func (o *objectMover) Backup(namespace string, path string) error {
+	log := logf.Log
+	log.Info("Performing backup...")
+	o.dryRun = false
+
+	objectGraph, err := o.getObjectGraph(namespace)
+	if err != nil {
+		return err
+	}
+
+	// Save the objects to the backup.
+	return o.backup(objectGraph, path)
+}
+
+func (o *objectMover) Restore(toCluster Client, path string) error {
+	log := logf.Log
+	log.Info("Performing restore...")
+	o.dryRun = false
+
+	objs, err := readBackup(path)
+	if err != nil {
+		return err
+	}
+
+	// Build the object graph bound to the target cluster, so the types defined by the CRDs installed by clusterctl
+	// in the target cluster are considered.
+	toProxy := toCluster.Proxy()
+	objectGraph := newObjectGraph(toProxy)
+
+	// Gets all the types defines by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
+	if err := objectGraph.getDiscoveryTypes(); err != nil {
+		return err
+	}
+
+	// Rebuild the object graph from the objects read from the backup:
+	// - Nodes are defined the Kubernetes objects (Clusters, Machines etc.) read from the backup.
+	// - Edges are derived by the OwnerReferences between nodes.
+	for i := range objs {
+		objectGraph.addRestoredObj(&objs[i])
+	}
+
+	// Completes the graph like discovery does, by searching for soft ownership relations and setting for each node the list
+	// of Clusters and ClusterResourceSet the node belong to.
+	objectGraph.setSoftOwnership()
+	objectGraph.setClusterTenants()
+	objectGraph.setCRSTenants()
+
+	// Check whether nodes are not included in GVK considered for restore
+	objectGraph.checkVirtualNode()
+
+	// Restore the objects to the target cluster.
+	return o.restore(objectGraph, toProxy)
+}
+
+// getObjectGraph discovers the object graph for the objects existing in a namespace (or in all the namespaces if empty)
+// of the source management cluster, and checks they can be safely moved.
+func (o *objectMover) getObjectGraph(namespace string) (*objectGraph, error) {
+	objectGraph := newObjectGraph(o.fromProxy)
+
 	// Gets all the types defines by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
 	err := objectGraph.getDiscoveryTypes()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Discovery the object graph for the selected types:
 	// - Nodes are defined the Kubernetes objects (Clusters, Machines etc.) identified during the discovery process.
 	// - Edges are derived by the OwnerReferences between nodes.
 	if err := objectGraph.Discovery(namespace); err != nil {
-		return err
+		return nil, err
+	}
+
+	// Limits the object graph to the selected Clusters, if any.
+	if err := o.filterObjectGraph(objectGraph); err != nil {
+		return nil, err
 	}
 
 	// Checks if Cluster API has already completed the provisioning of the infrastructure for the objects involved in the move operation.
@@ -86,22 +298,49 @@ func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) erro
 	// not currently waiting for long-running reconciliation loops, and so we can safely rely on the pause field on the Cluster object
 	// for blocking any further object reconciliation on the source objects.
 	if err := o.checkProvisioningCompleted(objectGraph); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check whether nodes are not included in GVK considered for move
 	objectGraph.checkVirtualNode()
 
-	// Move the objects to the target cluster.
-	var proxy Proxy
-	if !o.dryRun {
-		proxy = toCluster.Proxy()
+	return objectGraph, nil
+}
+
+// isSelective returns true if the operation is limited to a subset of the Clusters in the namespace.
+func (o *objectMover) isSelective() bool {
+	return o.clusterName != "" || o.clusterSelector != nil
+}
+
+// filterObjectGraph limits the object graph to the Clusters matching the cluster name and the label selector, if any.
+func (o *objectMover) filterObjectGraph(graph *objectGraph) error {
+	if !o.isSelective() {
+		return nil
 	}
 
-	if err := o.move(objectGraph, proxy); err != nil {
-		return err
+	readClusterBackoff := newReadBackoff()
+	selectedClusters := []*node{}
+	for _, cluster := range graph.getClusters() {
+		if o.clusterName != "" && cluster.identity.Name != o.clusterName {
+			continue
+		}
+
+		if o.clusterSelector != nil {
+			clusterObj := &clusterv1.Cluster{}
+			if err := retryWithExponentialBackoff(readClusterBackoff, func() error {
+				return getClusterObj(o.fromProxy, cluster, clusterObj)
+			}); err != nil {
+				return err
+			}
+			if !o.clusterSelector.Matches(labels.Set(clusterObj.GetLabels())) {
+				continue
+			}
+		}
+
+		selectedClusters = append(selectedClusters, cluster)
 	}
 
+	graph.filterClusters(selectedClusters)
 	return nil
 }
 
@@ -233,10 +472,82 @@ func (o *objectMover) move(graph *objectGraph, toProxy Proxy) error {
 
 	// Create all objects group by group, ensuring all the ownerReferences are re-created.
 	log.Info("Creating objects in the target cluster")
-	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
+	if err := o.createGroups(moveSequence, 0, toProxy); err != nil {
+		return err
+	}
+
+	// Delete all objects from the source cluster and resume the target cluster.
+	return o.completeMove(moveSequence, clusters, toProxy)
+}
+
+// resume resumes a move operation from the progress recorded in the move journal, by creating in the target management cluster the move groups
+// not yet completed, and then by deleting from the source management cluster the Kubernetes objects corresponding to the object graph nodes.
+func (o *objectMover) resume(graph *objectGraph, toProxy Proxy) error {
+	log := logf.Log
+
+	// Nb. the Clusters are read from the journal, because during the deleting phase some of them could be already deleted from the source cluster.
+	clusters := o.journal.clusterNodes()
+	log.Info("Resuming the move of Cluster API objects", "Clusters", len(clusters), "Phase", o.journal.Phase)
+
+	moveSequence := getMoveSequence(graph)
+
+	if o.journal.Phase == moveJournalCreatingPhase {
+		if o.journal.CompletedGroups > len(moveSequence.groups) {
+			return errors.Errorf("the move journal records %d move groups as completed, but there are %d move groups in the source cluster", o.journal.CompletedGroups, len(moveSequence.groups))
+		}
+
+		// Ensures the source clusters are still paused.
+		log.V(1).Info("Pausing the source cluster")
+		if err := setClusterPause(o.fromProxy, graph.getClusters(), true, o.dryRun); err != nil {
+			return err
+		}
+
+		log.V(1).Info("Creating target namespaces, if missing")
+		if err := o.ensureNamespaces(graph, toProxy); err != nil {
+			return err
+		}
+
+		// Reads the UID of the objects already created in the target cluster, so the ownerReferences of the objects still to be created can be re-created.
+		log.V(1).Info("Reading objects already created in the target cluster", "Groups", o.journal.CompletedGroups)
+		for groupIndex := 0; groupIndex < o.journal.CompletedGroups; groupIndex++ {
+			if err := o.readTargetGroup(moveSequence.getGroup(groupIndex), toProxy); err != nil {
+				return err
+			}
+		}
+
+		// Create the remaining objects group by group, starting from the first move group not completed.
+		log.Info("Creating objects in the target cluster")
+		if err := o.createGroups(moveSequence, o.journal.CompletedGroups, toProxy); err != nil {
+			return err
+		}
+	}
+
+	// Delete all objects from the source cluster and resume the target cluster.
+	return o.completeMove(moveSequence, clusters, toProxy)
+}
+
+// createGroups creates all the Kubernetes objects into the target management cluster corresponding to the move groups starting from fromGroup,
+// recording the progress in the move journal, if any.
+func (o *objectMover) createGroups(moveSequence *moveSequence, fromGroup int, toProxy Proxy) error {
+	for groupIndex := fromGroup; groupIndex < len(moveSequence.groups); groupIndex++ {
 		if err := o.createGroup(moveSequence.getGroup(groupIndex), toProxy); err != nil {
 			return err
 		}
+		if err := o.journal.completeGroup(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completeMove deletes all the Kubernetes objects from the source management cluster group by group in reverse order,
+// and then resets the pause field on the Cluster objects in the target management cluster.
+func (o *objectMover) completeMove(moveSequence *moveSequence, clusters []*node, toProxy Proxy) error {
+	log := logf.Log
+
+	// From now on the move operation cannot be rolled back anymore.
+	if err := o.journal.setPhase(moveJournalDeletingPhase); err != nil {
+		return err
 	}
 
 	// Delete all objects group by group in reverse order.
@@ -253,6 +564,164 @@ func (o *objectMover) move(graph *objectGraph, toProxy Proxy) error {
 		return err
 	}
 
+	return o.journal.setPhase(moveJournalCompletedPhase)
+}
+
+// rollback deletes from the target management cluster all the Kubernetes objects recorded as created in the move journal, in reverse creation order,
+// and then resets the pause field on the Cluster objects in the source management cluster, except for the ones which were already paused before the move.
+// Nb. namespaces created in the target management cluster are not deleted, because they could be hosting other objects.
+func (o *objectMover) rollback(toProxy Proxy) error {
+	log := logf.Log
+
+	clusters := o.journal.clusterNodes()
+	log.Info("Rolling back the move of Cluster API objects", "Clusters", len(clusters))
+
+	log.Info("Deleting objects from the target cluster")
+	deleteTargetObjectBackoff := newWriteBackoff()
+	errList := []error{}
+	for i := len(o.journal.Created) - 1; i >= 0; i-- {
+		nodeToDelete := &node{identity: o.journal.Created[i]}
+
+		// Nb. The operation is wrapped in a retry loop to make rollback more resilient to unexpected conditions.
+		err := retryWithExponentialBackoff(deleteTargetObjectBackoff, func() error {
+			return deleteObject(toProxy, nodeToDelete)
+		})
+		if err != nil {
+			errList = append(errList, err)
+		}
+	}
+	if len(errList) > 0 {
+		return kerrors.NewAggregate(errList)
+	}
+
+	// Reset the pause field on the Cluster object in the source management cluster, so the controllers start reconciling it again.
+	log.V(1).Info("Resuming the source cluster")
+	if err := setClusterPause(o.fromProxy, o.journal.clusterNodesToResume(), false, o.dryRun); err != nil {
+		return err
+	}
+
+	return o.journal.setPhase(moveJournalRolledBackPhase)
+}
+
+// backup saves all the Kubernetes objects corresponding to the object graph nodes to a directory or to a tarball.
+func (o *objectMover) backup(graph *objectGraph, path string) error {
+	log := logf.Log
+
+	clusters := graph.getClusters()
+	log.Info("Saving Cluster API objects", "Clusters", len(clusters))
+
+	// Sets the pause field on the Cluster object in the source management cluster, so the controllers stop reconciling it
+	// while objects are read; this ensures a consistent snapshot, and restored Clusters are paused until the restore completes.
+	// NB. Clusters already paused are not changed, so they are left paused after backup.
+	clustersToPause, err := getUnpausedClusters(o.fromProxy, clusters)
+	if err != nil {
+		return err
+	}
+	log.V(1).Info("Pausing the source cluster")
+	if err := setClusterPause(o.fromProxy, clustersToPause, true, o.dryRun); err != nil {
+		return err
+	}
+
+	pausedByBackup := map[*node]bool{}
+	for _, cluster := range clustersToPause {
+		pausedByBackup[cluster] = true
+	}
+
+	// Read all the objects in the same order used by move; restore will re-compute the sequence from the graph in any case.
+	objs := []unstructured.Unstructured{}
+	moveSequence := getMoveSequence(graph)
+	readSourceObjectBackoff := newReadBackoff()
+	errList := []error{}
+	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
+		for _, nodeToSave := range moveSequence.getGroup(groupIndex) {
+			log.V(1).Info("Saving", nodeToSave.identity.Kind, nodeToSave.identity.Name, "Namespace", nodeToSave.identity.Namespace)
+
+			// Nb. The operation is wrapped in a retry loop to make backup more resilient to unexpected conditions.
+			var obj *unstructured.Unstructured
+			if err := retryWithExponentialBackoff(readSourceObjectBackoff, func() error {
+				var err error
+				obj, err = o.getSourceObject(nodeToSave)
+				return err
+			}); err != nil {
+				errList = append(errList, err)
+				continue
+			}
+
+			// Marks the Clusters paused by backup, so restore resumes only those ones.
+			if pausedByBackup[nodeToSave] {
+				annotations := obj.GetAnnotations()
+				if annotations == nil {
+					annotations = map[string]string{}
+				}
+				annotations[backupPausedAnnotation] = "true"
+				obj.SetAnnotations(annotations)
+			}
+			objs = append(objs, *obj)
+		}
+	}
+
+	if len(errList) == 0 {
+		if err := writeBackup(path, objs); err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	// Reset the pause field on the Cluster object in the source management cluster, so the controllers start reconciling it again;
+	// this happens also if the backup failed, given that the source objects are not changed by backup.
+	log.V(1).Info("Resuming the source cluster")
+	if err := setClusterPause(o.fromProxy, clustersToPause, false, o.dryRun); err != nil {
+		errList = append(errList, err)
+	}
+
+	return kerrors.NewAggregate(errList)
+}
+
+// restore creates all the Kubernetes objects corresponding to the object graph nodes rebuilt from a backup into the target management cluster.
+func (o *objectMover) restore(graph *objectGraph, toProxy Proxy) error {
+	log := logf.Log
+
+	clusters := graph.getClusters()
+	log.Info("Restoring Cluster API objects", "Clusters", len(clusters))
+
+	// Only the Clusters paused by backup are resumed, so the Clusters paused by the user are left paused;
+	// the annotation marking them is removed before the Clusters are created.
+	clustersToResume := []*node{}
+	for _, cluster := range clusters {
+		if cluster.restoreObject == nil {
+			continue
+		}
+		annotations := cluster.restoreObject.GetAnnotations()
+		if _, ok := annotations[backupPausedAnnotation]; !ok {
+			continue
+		}
+		delete(annotations, backupPausedAnnotation)
+		cluster.restoreObject.SetAnnotations(annotations)
+		clustersToResume = append(clustersToResume, cluster)
+	}
+
+	// Ensure all the expected target namespaces are in place before creating objects.
+	log.V(1).Info("Creating target namespaces, if missing")
+	if err := o.ensureNamespaces(graph, toProxy); err != nil {
+		return err
+	}
+
+	// Define the restore sequence by processing the ownerReference chain, so we ensure that a Kubernetes object is restored only after its owners.
+	moveSequence := getMoveSequence(graph)
+
+	// Create all objects group by group, ensuring all the ownerReferences are re-created.
+	log.Info("Creating objects in the target cluster")
+	for groupIndex := 0; groupIndex < len(moveSequence.groups); groupIndex++ {
+		if err := o.restoreGroup(moveSequence.getGroup(groupIndex), toProxy); err != nil {
+			return err
+		}
+	}
+
+	// Reset the pause field on the Cluster object in the target management cluster, so the controllers start reconciling it.
+	log.V(1).Info("Resuming the target cluster")
+	if err := setClusterPause(toProxy, clustersToResume, false, o.dryRun); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -355,6 +824,37 @@ func setClusterPause(proxy Proxy, clusters []*node, value bool, dryRun bool) err
 	return nil
 }
 
+// getUnpausedClusters returns the nodes referring to Cluster objects which are not paused.
+func getUnpausedClusters(proxy Proxy, clusters []*node) ([]*node, error) {
+	c, err := proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	unpaused := []*node{}
+	readClusterBackoff := newReadBackoff()
+	for i := range clusters {
+		cluster := clusters[i]
+		clusterObj := &clusterv1.Cluster{}
+		clusterObjKey := client.ObjectKey{
+			Namespace: cluster.identity.Namespace,
+			Name:      cluster.identity.Name,
+		}
+
+		// Nb. The operation is wrapped in a retry loop to make getUnpausedClusters more resilient to unexpected conditions.
+		if err := retryWithExponentialBackoff(readClusterBackoff, func() error {
+			return c.Get(ctx, clusterObjKey, clusterObj)
+		}); err != nil {
+			return nil, errors.Wrapf(err, "error reading %q %s/%s",
+				clusterObj.GroupVersionKind(), clusterObjKey.Namespace, clusterObjKey.Name)
+		}
+		if !clusterObj.Spec.Paused {
+			unpaused = append(unpaused, cluster)
+		}
+	}
+	return unpaused, nil
+}
+
 // patchCluster applies a patch to a node referring to a Cluster object.
 func patchCluster(proxy Proxy, cluster *node, patch client.Patch) error {
 	cFrom, err := proxy.NewClient()
@@ -487,11 +987,24 @@ func (o *objectMover) createGroup(group moveGroup, toProxy Proxy) error {
 
 		// Creates the Kubernetes object corresponding to the nodeToCreate.
 		// Nb. The operation is wrapped in a retry loop to make move more resilient to unexpected conditions.
+		created := false
 		err := retryWithExponentialBackoff(createTargetObjectBackoff, func() error {
-			return o.createTargetObject(nodeToCreate, toProxy)
+			var err error
+			created, err = o.createTargetObject(nodeToCreate, toProxy)
+			return err
 		})
 		if err != nil {
 			errList = append(errList, err)
+			continue
+		}
+
+		// Records the object as created, so it can be deleted in case of rollback.
+		// Nb. objects which already existed in the target cluster are not recorded, because they are not owned by the move operation;
+		// the same applies to cluster-wide nodes, because they could be shared with objects already existing in the target cluster.
+		if created && !nodeToCreate.isGlobal {
+			if err := o.journal.addCreated(nodeToCreate); err != nil {
+				errList = append(errList, err)
+			}
 		}
 	}
 
@@ -502,33 +1015,141 @@ func (o *objectMover) createGroup(group moveGroup, toProxy Proxy) error {
 	return nil
 }
 
+// restoreGroup creates all the Kubernetes objects into the target management cluster corresponding to the object graph nodes in a moveGroup,
+// using the objects read from a backup.
+func (o *objectMover) restoreGroup(group moveGroup, toProxy Proxy) error {
+	restoreTargetObjectBackoff := newWriteBackoff()
+	errList := []error{}
+	for i := range group {
+		nodeToRestore := group[i]
+
+		// Creates the Kubernetes object corresponding to the nodeToRestore.
+		// Nb. The operation is wrapped in a retry loop to make restore more resilient to unexpected conditions.
+		err := retryWithExponentialBackoff(restoreTargetObjectBackoff, func() error {
+			return o.restoreTargetObject(nodeToRestore, toProxy)
+		})
+		if err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	return kerrors.NewAggregate(errList)
+}
+
 // createTargetObject creates the Kubernetes object in the target Management cluster corresponding to the object graph node, taking care of restoring the OwnerReference with the owner nodes, if any.
-func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) error {
+// It returns true if the object was created, false if it already existed in the target Management cluster and it was updated.
+func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) (bool, error) {
 	log := logf.Log
 	log.V(1).Info("Creating", nodeToCreate.identity.Kind, nodeToCreate.identity.Name, "Namespace", nodeToCreate.identity.Namespace)
 
+	if o.dryRun {
+		return false, nil
+	}
+
+	obj, err := o.getSourceObject(nodeToCreate)
+	if err != nil {
+		return false, err
+	}
+
+	return o.createOrUpdateTargetObject(nodeToCreate, obj, toProxy)
+}
+
+// restoreTargetObject creates the Kubernetes object in the target Management cluster corresponding to an object graph node rebuilt from a backup,
+// taking care of restoring the OwnerReference with the owner nodes, if any.
+func (o *objectMover) restoreTargetObject(nodeToRestore *node, toProxy Proxy) error {
+	log := logf.Log
+	log.V(1).Info("Restoring", nodeToRestore.identity.Kind, nodeToRestore.identity.Name, "Namespace", nodeToRestore.identity.Namespace)
+
 	if o.dryRun {
 		return nil
 	}
 
-	cFrom, err := o.fromProxy.NewClient()
+	if nodeToRestore.restoreObject == nil {
+		return errors.Errorf("object %q %s/%s not found in the backup",
+			nodeToRestore.identity.GroupVersionKind(), nodeToRestore.identity.Namespace, nodeToRestore.identity.Name)
+	}
+
+	// Nb. use a copy of the object, so the object read from the backup is preserved across retries.
+	_, err := o.createOrUpdateTargetObject(nodeToRestore, nodeToRestore.restoreObject.DeepCopy(), toProxy)
+	return err
+}
+
+// readTargetGroup reads the UID of the Kubernetes objects already created in the target management cluster corresponding to the object graph nodes in a moveGroup.
+func (o *objectMover) readTargetGroup(group moveGroup, toProxy Proxy) error {
+	readTargetObjectBackoff := newReadBackoff()
+	errList := []error{}
+	for i := range group {
+		nodeToRead := group[i]
+
+		// Nb. The operation is wrapped in a retry loop to make resume more resilient to unexpected conditions.
+		err := retryWithExponentialBackoff(readTargetObjectBackoff, func() error {
+			return readTargetObject(nodeToRead, toProxy)
+		})
+		if err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	return kerrors.NewAggregate(errList)
+}
+
+// readTargetObject reads the Kubernetes object in the target management cluster corresponding to the object graph node, and stores its UID as the node's newUID.
+func readTargetObject(nodeToRead *node, toProxy Proxy) error {
+	cTo, err := toProxy.NewClient()
 	if err != nil {
 		return err
 	}
 
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(nodeToRead.identity.APIVersion)
+	obj.SetKind(nodeToRead.identity.Kind)
+	objKey := client.ObjectKey{
+		Namespace: nodeToRead.identity.Namespace,
+		Name:      nodeToRead.identity.Name,
+	}
+
+	if err := cTo.Get(ctx, objKey, obj); err != nil {
+		return errors.Wrapf(err, "error reading %q %s/%s from the target cluster",
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+
+	nodeToRead.newUID = obj.GetUID()
+	return nil
+}
+
+// getSourceObject reads the Kubernetes object corresponding to the object graph node from the source management cluster.
+func (o *objectMover) getSourceObject(sourceNode *node) (*unstructured.Unstructured, error) {
+	cFrom, err := o.fromProxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
 	// Get the source object
 	obj := &unstructured.Unstructured{}
-	obj.SetAPIVersion(nodeToCreate.identity.APIVersion)
-	obj.SetKind(nodeToCreate.identity.Kind)
+	obj.SetAPIVersion(sourceNode.identity.APIVersion)
+	obj.SetKind(sourceNode.identity.Kind)
 	objKey := client.ObjectKey{
-		Namespace: nodeToCreate.identity.Namespace,
-		Name:      nodeToCreate.identity.Name,
+		Namespace: sourceNode.identity.Namespace,
+		Name:      sourceNode.identity.Name,
 	}
 
 	if err := cFrom.Get(ctx, objKey, obj); err != nil {
-		return errors.Wrapf(err, "error reading %q %s/%s",
+		return nil, errors.Wrapf(err, "error reading %q %s/%s",
 			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 	}
+	return obj, nil
+}
+
+// createOrUpdateTargetObject creates a Kubernetes object in the target Management cluster, taking care of restoring the OwnerReference with
+// the owner nodes, if any; if the object already exists, it gets updated.
+// It returns true if the object was created, false if it already existed and it was updated.
+func (o *objectMover) createOrUpdateTargetObject(nodeToCreate *node, obj *unstructured.Unstructured, toProxy Proxy) (bool, error) {
+	log := logf.Log
+
+	objKey := client.ObjectKey{
+		Namespace: nodeToCreate.identity.Namespace,
+		Name:      nodeToCreate.identity.Name,
+	}
 
 	// New objects cannot have a specified resource version. Clear it out.
 	obj.SetResourceVersion("")
@@ -562,17 +1183,19 @@ func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) erro
 	// Creates the targetObj into the target management cluster.
 	cTo, err := toProxy.NewClient()
 	if err != nil {
-		return err
+		return false, err
 	}
 
+	created := true
 	if err := cTo.Create(ctx, obj); err != nil {
 		if !apierrors.IsAlreadyExists(err) {
-			return errors.Wrapf(err, "error creating %q %s/%s",
+			return false, errors.Wrapf(err, "error creating %q %s/%s",
 				obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 		}
 
 		// If the object already exists, try to update it.
 		// Nb. This should not happen, but it is supported to make move more resilient to unexpected interrupt/restarts of the move process.
+		created = false
 		log.V(5).Info("Object already exists, updating", nodeToCreate.identity.Kind, nodeToCreate.identity.Name, "Namespace", nodeToCreate.identity.Namespace)
 
 		// Retrieve the UID and the resource version for the update.
@@ -580,14 +1203,14 @@ func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) erro
 		existingTargetObj.SetAPIVersion(obj.GetAPIVersion())
 		existingTargetObj.SetKind(obj.GetKind())
 		if err := cTo.Get(ctx, objKey, existingTargetObj); err != nil {
-			return errors.Wrapf(err, "error reading resource for %q %s/%s",
+			return false, errors.Wrapf(err, "error reading resource for %q %s/%s",
 				existingTargetObj.GroupVersionKind(), existingTargetObj.GetNamespace(), existingTargetObj.GetName())
 		}
 
 		obj.SetUID(existingTargetObj.GetUID())
 		obj.SetResourceVersion(existingTargetObj.GetResourceVersion())
 		if err := cTo.Update(ctx, obj); err != nil {
-			return errors.Wrapf(err, "error updating %q %s/%s",
+			return false, errors.Wrapf(err, "error updating %q %s/%s",
 				obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 		}
 	}
@@ -595,7 +1218,7 @@ func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) erro
 	// Stores the newUID assigned to the newly created object.
 	nodeToCreate.newUID = obj.GetUID()
 
-	return nil
+	return created, nil
 }
 
 // deleteGroup deletes all the Kubernetes objects from the source management cluster corresponding to the object graph nodes in a moveGroup.
@@ -610,6 +1233,11 @@ func (o *objectMover) deleteGroup(group moveGroup) error {
 			continue
 		}
 
+		// Don't delete nodes required also by Clusters which are not being moved
+		if nodeToDelete.shared {
+			continue
+		}
+
 		// Delete the Kubernetes object corresponding to the current node.
 		// Nb. The operation is wrapped in a retry loop to make move more resilient to unexpected conditions.
 		err := retryWithExponentialBackoff(deleteSourceObjectBackoff, func() error {
@@ -638,40 +1266,48 @@ func (o *objectMover) deleteSourceObject(nodeToDelete *node) error {
 		return nil
 	}
 
-	cFrom, err := o.fromProxy.NewClient()
+	return deleteObject(o.fromProxy, nodeToDelete)
+}
+
+// deleteObject deletes the Kubernetes object corresponding to the node, taking care of removing all the finalizers so
+// the objects gets immediately deleted (force delete).
+func deleteObject(proxy Proxy, nodeToDelete *node) error {
+	log := logf.Log
+
+	c, err := proxy.NewClient()
 	if err != nil {
 		return err
 	}
 
-	// Get the source object
-	sourceObj := &unstructured.Unstructured{}
-	sourceObj.SetAPIVersion(nodeToDelete.identity.APIVersion)
-	sourceObj.SetKind(nodeToDelete.identity.Kind)
-	sourceObjKey := client.ObjectKey{
+	// Get the object
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(nodeToDelete.identity.APIVersion)
+	obj.SetKind(nodeToDelete.identity.Kind)
+	objKey := client.ObjectKey{
 		Namespace: nodeToDelete.identity.Namespace,
 		Name:      nodeToDelete.identity.Name,
 	}
 
-	if err := cFrom.Get(ctx, sourceObjKey, sourceObj); err != nil {
+	if err := c.Get(ctx, objKey, obj); err != nil {
 		if apierrors.IsNotFound(err) {
 			//If the object is already deleted, move on.
 			log.V(5).Info("Object already deleted, skipping delete for", nodeToDelete.identity.Kind, nodeToDelete.identity.Name, "Namespace", nodeToDelete.identity.Namespace)
 			return nil
 		}
 		return errors.Wrapf(err, "error reading %q %s/%s",
-			sourceObj.GroupVersionKind(), sourceObj.GetNamespace(), sourceObj.GetName())
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 	}
 
-	if len(sourceObj.GetFinalizers()) > 0 {
-		if err := cFrom.Patch(ctx, sourceObj, removeFinalizersPatch); err != nil {
+	if len(obj.GetFinalizers()) > 0 {
+		if err := c.Patch(ctx, obj, removeFinalizersPatch); err != nil {
 			return errors.Wrapf(err, "error removing finalizers from %q %s/%s",
-				sourceObj.GroupVersionKind(), sourceObj.GetNamespace(), sourceObj.GetName())
+				obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 		}
 	}
 
-	if err := cFrom.Delete(ctx, sourceObj); err != nil {
+	if err := c.Delete(ctx, obj); err != nil {
 		return errors.Wrapf(err, "error deleting %q %s/%s",
-			sourceObj.GroupVersionKind(), sourceObj.GetNamespace(), sourceObj.GetName())
+			obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
 	}
 
 	return nil
@@ -0,0 +1,227 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// moveJournalPhase defines the phase of a move operation tracked by a moveJournal.
+type moveJournalPhase string
+
+const (
+	// moveJournalCreatingPhase is the phase where objects are being created in the target management cluster;
+	// in this phase the source management cluster is not changed, except for the clusters being paused, so
+	// the move can be either resumed or rolled back.
+	moveJournalCreatingPhase = moveJournalPhase("Creating")
+
+	// moveJournalDeletingPhase is the phase where objects are being deleted from the source management cluster;
+	// in this phase the move can only be resumed.
+	moveJournalDeletingPhase = moveJournalPhase("Deleting")
+
+	// moveJournalCompletedPhase is the phase of a move operation that completed.
+	moveJournalCompletedPhase = moveJournalPhase("Completed")
+
+	// moveJournalRolledBackPhase is the phase of a move operation that was rolled back.
+	moveJournalRolledBackPhase = moveJournalPhase("RolledBack")
+)
+
+// moveJournal records the progress of a move operation, so a failed move can be resumed or rolled back.
+type moveJournal struct {
+	// path where the journal is persisted.
+	path string
+
+	// Namespace the move operation is processing (all the namespaces if empty).
+	Namespace string `json:"namespace"`
+
+	// ClusterName the move operation is limited to, if any.
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// ClusterSelector the move operation is limited to, if any.
+	ClusterSelector string `json:"clusterSelector,omitempty"`
+
+	// Phase of the move operation.
+	Phase moveJournalPhase `json:"phase"`
+
+	// Clusters involved in the move operation.
+	Clusters []corev1.ObjectReference `json:"clusters,omitempty"`
+
+	// PausedClusters lists the Clusters which were already paused before the move operation,
+	// so they are left paused in case of rollback.
+	PausedClusters []corev1.ObjectReference `json:"pausedClusters,omitempty"`
+
+	// CompletedGroups is the number of move groups that are already created in the target management cluster.
+	CompletedGroups int `json:"completedGroups"`
+
+	// Created lists the objects created in the target management cluster, in creation order.
+	Created []corev1.ObjectReference `json:"created,omitempty"`
+}
+
+// newMoveJournal returns a moveJournal for a move operation persisted to the given path.
+func newMoveJournal(path string, namespace string, clusters []*node) *moveJournal {
+	j := &moveJournal{
+		path:      path,
+		Namespace: namespace,
+		Phase:     moveJournalCreatingPhase,
+	}
+	for _, c := range clusters {
+		j.Clusters = append(j.Clusters, c.identity)
+	}
+	return j
+}
+
+// readMoveJournal reads a moveJournal persisted to the given path.
+func readMoveJournal(path string) (*moveJournal, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the move journal %q", path)
+	}
+
+	j := &moveJournal{}
+	if err := yaml.Unmarshal(content, j); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the move journal %q", path)
+	}
+	j.path = path
+	return j, nil
+}
+
+// checkNoMoveInProgress returns an error if the journal at the given path records a move operation that is not
+// completed nor rolled back, so the journal required to resume or roll back the move is not overwritten.
+func checkNoMoveInProgress(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	j, err := readMoveJournal(path)
+	if err != nil {
+		return err
+	}
+	if j.Phase == moveJournalCreatingPhase || j.Phase == moveJournalDeletingPhase {
+		return errors.Errorf("the move operation recorded in %q is not completed; use --resume or --rollback to complete it, "+
+			"or use --journal to record this move to a different file", path)
+	}
+	return nil
+}
+
+// save persists the moveJournal.
+func (j *moveJournal) save() error {
+	if j == nil {
+		return nil
+	}
+
+	content, err := yaml.Marshal(j)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the move journal")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create the directory for the move journal %q", j.path)
+	}
+	if err := ioutil.WriteFile(j.path, content, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write the move journal %q", j.path)
+	}
+	return nil
+}
+
+// setPhase sets the phase of the move operation and persists the moveJournal.
+func (j *moveJournal) setPhase(phase moveJournalPhase) error {
+	if j == nil {
+		return nil
+	}
+	j.Phase = phase
+	return j.save()
+}
+
+// addCreated records an object created in the target management cluster and persists the moveJournal,
+// so the object can be deleted in case of rollback even if the move process is interrupted.
+func (j *moveJournal) addCreated(n *node) error {
+	if j == nil {
+		return nil
+	}
+	for _, c := range j.Created {
+		if c.APIVersion == n.identity.APIVersion && c.Kind == n.identity.Kind && c.Namespace == n.identity.Namespace && c.Name == n.identity.Name {
+			return nil
+		}
+	}
+	j.Created = append(j.Created, n.identity)
+	return j.save()
+}
+
+// completeGroup records a move group completed in the target management cluster and persists the moveJournal.
+func (j *moveJournal) completeGroup() error {
+	if j == nil {
+		return nil
+	}
+	j.CompletedGroups++
+	return j.save()
+}
+
+// setPausedClusters records the Clusters involved in the move operation which are not in the list of unpaused Clusters,
+// and persists the moveJournal.
+func (j *moveJournal) setPausedClusters(unpaused []*node) error {
+	if j == nil {
+		return nil
+	}
+	j.PausedClusters = nil
+	for _, c := range j.Clusters {
+		found := false
+		for _, u := range unpaused {
+			if u.identity.APIVersion == c.APIVersion && u.identity.Kind == c.Kind && u.identity.Namespace == c.Namespace && u.identity.Name == c.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			j.PausedClusters = append(j.PausedClusters, c)
+		}
+	}
+	return j.save()
+}
+
+// clusterNodesToResume returns nodes referring to the Clusters involved in the move operation
+// which were not paused before the move operation.
+func (j *moveJournal) clusterNodesToResume() []*node {
+	clusters := []*node{}
+	for _, n := range j.clusterNodes() {
+		paused := false
+		for _, p := range j.PausedClusters {
+			if p.APIVersion == n.identity.APIVersion && p.Kind == n.identity.Kind && p.Namespace == n.identity.Namespace && p.Name == n.identity.Name {
+				paused = true
+				break
+			}
+		}
+		if !paused {
+			clusters = append(clusters, n)
+		}
+	}
+	return clusters
+}
+
+// clusterNodes returns nodes referring to the Clusters involved in the move operation.
+func (j *moveJournal) clusterNodes() []*node {
+	clusters := make([]*node, 0, len(j.Clusters))
+	for _, c := range j.Clusters {
+		clusters = append(clusters, &node{identity: c})
+	}
+	return clusters
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package cluster
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -24,6 +27,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
@@ -620,6 +624,678 @@ func Test_objectMover_move(t *testing.T) {
 	}
 }
 
+func Test_objectMover_move_selective(t *testing.T) {
+	tests := []struct {
+		name            string
+		clusterName     string
+		clusterSelector string
+	}{
+		{
+			name:        "Select cluster by name",
+			clusterName: "cluster1",
+		},
+		{
+			name:            "Select cluster by label selector",
+			clusterSelector: "region=eu",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// Create two clusters sharing an object, with cluster1 labeled region=eu and cluster2 labeled region=us.
+			sharedInfrastructureTemplate := test.NewFakeInfrastructureTemplate("shared")
+			objs := []client.Object{
+				sharedInfrastructureTemplate,
+			}
+			for _, name := range []string{"cluster1", "cluster2"} {
+				objs = append(objs, test.NewFakeCluster("ns1", name).
+					WithMachineSets(
+						test.NewFakeMachineSet(name+"-ms1").
+							WithInfrastructureTemplate(sharedInfrastructureTemplate).
+							WithMachines(
+								test.NewFakeMachine(name+"-m1"),
+							),
+					).Objs()...)
+			}
+			for _, o := range objs {
+				if o.GetObjectKind().GroupVersionKind().Kind != "Cluster" {
+					continue
+				}
+				if o.GetName() == "cluster1" {
+					o.SetLabels(map[string]string{"region": "eu"})
+				} else {
+					o.SetLabels(map[string]string{"region": "us"})
+				}
+			}
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+			graph := getObjectGraphWithObjs(objs)
+
+			// Get all the types to be considered for discovery
+			g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+			// trigger discovery the content of the source cluster
+			g.Expect(graph.Discovery("")).To(Succeed())
+
+			// Run move limited to the selected cluster
+			mover := objectMover{
+				fromProxy:   graph.proxy,
+				clusterName: tt.clusterName,
+			}
+			if tt.clusterSelector != "" {
+				selector, err := labels.Parse(tt.clusterSelector)
+				g.Expect(err).NotTo(HaveOccurred())
+				mover.clusterSelector = selector
+			}
+			g.Expect(mover.filterObjectGraph(graph)).To(Succeed())
+
+			// gets a fakeProxy to an empty cluster with all the required CRDs
+			toProxy := getFakeProxyWithCRDs()
+			g.Expect(mover.move(graph, toProxy)).To(Succeed())
+
+			csFrom, err := graph.proxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			csTo, err := toProxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			exists := func(c client.Client, apiVersion, kind, name string) bool {
+				obj := &unstructured.Unstructured{}
+				obj.SetAPIVersion(apiVersion)
+				obj.SetKind(kind)
+				err := c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: name}, obj)
+				if err != nil && !apierrors.IsNotFound(err) {
+					t.Errorf("error = %v when checking for %s %s", err, kind, name)
+				}
+				return err == nil
+			}
+
+			// objects of the selected cluster are moved
+			g.Expect(exists(csFrom, "cluster.x-k8s.io/v1alpha4", "Cluster", "cluster1")).To(BeFalse())
+			g.Expect(exists(csTo, "cluster.x-k8s.io/v1alpha4", "Cluster", "cluster1")).To(BeTrue())
+			g.Expect(exists(csFrom, "cluster.x-k8s.io/v1alpha4", "Machine", "cluster1-m1")).To(BeFalse())
+			g.Expect(exists(csTo, "cluster.x-k8s.io/v1alpha4", "Machine", "cluster1-m1")).To(BeTrue())
+
+			// objects of the other cluster are kept in the source cluster only
+			g.Expect(exists(csFrom, "cluster.x-k8s.io/v1alpha4", "Cluster", "cluster2")).To(BeTrue())
+			g.Expect(exists(csTo, "cluster.x-k8s.io/v1alpha4", "Cluster", "cluster2")).To(BeFalse())
+			g.Expect(exists(csFrom, "cluster.x-k8s.io/v1alpha4", "Machine", "cluster2-m1")).To(BeTrue())
+			g.Expect(exists(csTo, "cluster.x-k8s.io/v1alpha4", "Machine", "cluster2-m1")).To(BeFalse())
+
+			// shared objects are copied to the target cluster and kept in the source cluster
+			g.Expect(exists(csFrom, "infrastructure.cluster.x-k8s.io/v1alpha4", "GenericInfrastructureMachineTemplate", "shared")).To(BeTrue())
+			g.Expect(exists(csTo, "infrastructure.cluster.x-k8s.io/v1alpha4", "GenericInfrastructureMachineTemplate", "shared")).To(BeTrue())
+
+			// the other cluster is not paused
+			cluster2 := &clusterv1.Cluster{}
+			g.Expect(csFrom.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "cluster2"}, cluster2)).To(Succeed())
+			g.Expect(cluster2.Spec.Paused).To(BeFalse())
+		})
+	}
+}
+
+func Test_objectMover_backupAndRestore(t *testing.T) {
+	// NB. we are testing backup and restore using the same set of moveTests used for move, saving objects both to a directory and to a tarball
+	for _, tt := range moveTests {
+		for _, fileName := range []string{"backup", "backup.tar.gz"} {
+			t.Run(tt.name+" to "+fileName, func(t *testing.T) {
+				g := NewWithT(t)
+
+				dir, err := ioutil.TempDir("", "cluster-client")
+				g.Expect(err).NotTo(HaveOccurred())
+				defer os.RemoveAll(dir)
+				path := filepath.Join(dir, fileName)
+
+				// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+				graph := getObjectGraphWithObjs(tt.fields.objs)
+
+				// Get all the types to be considered for discovery
+				g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+				// trigger discovery the content of the source cluster
+				g.Expect(graph.Discovery("")).To(Succeed())
+
+				// Run backup
+				mover := objectMover{
+					fromProxy: graph.proxy,
+				}
+
+				err = mover.backup(graph, path)
+				if tt.wantErr {
+					g.Expect(err).To(HaveOccurred())
+					return
+				}
+				g.Expect(err).NotTo(HaveOccurred())
+
+				// gets a fakeProxy to an empty cluster with all the required CRDs, and run restore
+				toProxy := getFakeProxyWithCRDs()
+				toCluster := newClusterClient(Kubeconfig{}, &fakeConfigClient{}, InjectProxy(toProxy))
+				g.Expect(toCluster.ObjectMover().Restore(toCluster, path)).To(Succeed())
+
+				// check that the objects are kept in the source cluster and are created in the target cluster
+				csFrom, err := graph.proxy.NewClient()
+				g.Expect(err).NotTo(HaveOccurred())
+
+				csTo, err := toProxy.NewClient()
+				g.Expect(err).NotTo(HaveOccurred())
+
+				for _, node := range graph.getMoveNodes() {
+					key := client.ObjectKey{
+						Namespace: node.identity.Namespace,
+						Name:      node.identity.Name,
+					}
+
+					oFrom := &unstructured.Unstructured{}
+					oFrom.SetAPIVersion(node.identity.APIVersion)
+					oFrom.SetKind(node.identity.Kind)
+					if err := csFrom.Get(ctx, key, oFrom); err != nil {
+						t.Errorf("error = %v when checking for %v kept in source cluster", err, key)
+						continue
+					}
+
+					oTo := &unstructured.Unstructured{}
+					oTo.SetAPIVersion(node.identity.APIVersion)
+					oTo.SetKind(node.identity.Kind)
+					if err := csTo.Get(ctx, key, oTo); err != nil {
+						t.Errorf("error = %v when checking for %v created in target cluster", err, key)
+						continue
+					}
+
+					// owner references are re-created using the UIDs of the objects in the target cluster
+					for _, ref := range oTo.GetOwnerReferences() {
+						if ref.UID == "" {
+							t.Errorf("%v has an owner reference without UID in target cluster", key)
+						}
+					}
+				}
+
+				// check that clusters are not paused, neither in the source nor in the target cluster
+				for _, cluster := range graph.getClusters() {
+					key := client.ObjectKey{
+						Namespace: cluster.identity.Namespace,
+						Name:      cluster.identity.Name,
+					}
+					for _, c := range []client.Client{csFrom, csTo} {
+						clusterObj := &clusterv1.Cluster{}
+						g.Expect(c.Get(ctx, key, clusterObj)).To(Succeed())
+						g.Expect(clusterObj.Spec.Paused).To(BeFalse())
+					}
+				}
+			})
+		}
+	}
+}
+
+func Test_objectMover_backupAndRestore_keepsPausedClusters(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "cluster-client")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	objs := append(test.NewFakeCluster("ns1", "paused").Objs(), test.NewFakeCluster("ns1", "unpaused").Objs()...)
+	for _, o := range objs {
+		if c, ok := o.(*clusterv1.Cluster); ok && c.Name == "paused" {
+			c.Spec.Paused = true
+		}
+	}
+
+	graph := getObjectGraphWithObjs(objs)
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+	g.Expect(graph.Discovery("")).To(Succeed())
+
+	mover := objectMover{
+		fromProxy: graph.proxy,
+	}
+	path := filepath.Join(dir, "backup")
+	g.Expect(mover.backup(graph, path)).To(Succeed())
+
+	// restore the backup to an empty cluster
+	toProxy := getFakeProxyWithCRDs()
+	toCluster := newClusterClient(Kubeconfig{}, &fakeConfigClient{}, InjectProxy(toProxy))
+	g.Expect(toCluster.ObjectMover().Restore(toCluster, path)).To(Succeed())
+
+	// the cluster paused before the backup is still paused, while the other cluster is resumed,
+	// both in the source and in the target cluster
+	csFrom, err := graph.proxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+	csTo, err := toProxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+	for _, c := range []client.Client{csFrom, csTo} {
+		for name, wantPaused := range map[string]bool{"paused": true, "unpaused": false} {
+			clusterObj := &clusterv1.Cluster{}
+			g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: name}, clusterObj)).To(Succeed())
+			g.Expect(clusterObj.Spec.Paused).To(Equal(wantPaused), "cluster %s", name)
+			g.Expect(clusterObj.Annotations).ToNot(HaveKey(backupPausedAnnotation))
+		}
+	}
+}
+
+// startInterruptedMove simulates a move operation recording its progress to a journal at the given path,
+// which is interrupted after creating the first half of the move groups in the target cluster.
+func startInterruptedMove(g *WithT, graph *objectGraph, toProxy Proxy, path string) {
+	mover := objectMover{
+		fromProxy: graph.proxy,
+		journal:   newMoveJournal(path, "", graph.getClusters()),
+	}
+	g.Expect(mover.journal.save()).To(Succeed())
+
+	unpausedClusters, err := getUnpausedClusters(mover.fromProxy, graph.getClusters())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(mover.journal.setPausedClusters(unpausedClusters)).To(Succeed())
+
+	g.Expect(setClusterPause(mover.fromProxy, graph.getClusters(), true, false)).To(Succeed())
+	g.Expect(mover.ensureNamespaces(graph, toProxy)).To(Succeed())
+
+	moveSequence := getMoveSequence(graph)
+	for groupIndex := 0; groupIndex < len(moveSequence.groups)/2; groupIndex++ {
+		g.Expect(mover.createGroup(moveSequence.getGroup(groupIndex), toProxy)).To(Succeed())
+		g.Expect(mover.journal.completeGroup()).To(Succeed())
+	}
+}
+
+func Test_objectMover_resume(t *testing.T) {
+	// NB. we are testing resume using the same set of moveTests used for move, resuming a move interrupted after creating the first half of the move groups
+	for _, tt := range moveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			if tt.wantErr {
+				return
+			}
+
+			dir, err := ioutil.TempDir("", "cluster-client")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "move-journal.yaml")
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+			graph := getObjectGraphWithObjs(tt.fields.objs)
+
+			// Get all the types to be considered for discovery
+			g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+			// trigger discovery the content of the source cluster
+			g.Expect(graph.Discovery("")).To(Succeed())
+
+			// gets a fakeProxy to an empty cluster with all the required CRDs, and start a move that gets interrupted
+			toProxy := getFakeProxyWithCRDs()
+			startInterruptedMove(g, graph, toProxy, path)
+
+			// Rebuild the object graph from the source cluster, like resume does, and resume the move
+			resumeGraph := newObjectGraph(graph.proxy)
+			g.Expect(getFakeDiscoveryTypes(resumeGraph)).To(Succeed())
+			g.Expect(resumeGraph.Discovery("")).To(Succeed())
+
+			journal, err := readMoveJournal(path)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			mover := objectMover{
+				fromProxy: graph.proxy,
+				journal:   journal,
+			}
+			g.Expect(mover.resume(resumeGraph, toProxy)).To(Succeed())
+
+			// check that the move is recorded as completed
+			journal, err = readMoveJournal(path)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(journal.Phase).To(Equal(moveJournalCompletedPhase))
+
+			// check that the objects are removed from the source cluster and are created in the target cluster
+			csFrom, err := graph.proxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			csTo, err := toProxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			for _, node := range graph.getMoveNodes() {
+				key := client.ObjectKey{
+					Namespace: node.identity.Namespace,
+					Name:      node.identity.Name,
+				}
+
+				// objects are deleted from the source cluster
+				oFrom := &unstructured.Unstructured{}
+				oFrom.SetAPIVersion(node.identity.APIVersion)
+				oFrom.SetKind(node.identity.Kind)
+
+				err := csFrom.Get(ctx, key, oFrom)
+				if err == nil {
+					if !node.isGlobal {
+						t.Errorf("%v not deleted in source cluster", key)
+						continue
+					}
+				} else if !apierrors.IsNotFound(err) {
+					t.Errorf("error = %v when checking for %v deleted in source cluster", err, key)
+					continue
+				}
+
+				// objects are created in the target cluster
+				oTo := &unstructured.Unstructured{}
+				oTo.SetAPIVersion(node.identity.APIVersion)
+				oTo.SetKind(node.identity.Kind)
+
+				if err := csTo.Get(ctx, key, oTo); err != nil {
+					t.Errorf("error = %v when checking for %v created in target cluster", err, key)
+					continue
+				}
+
+				// owner references are re-created using the UIDs of the objects in the target cluster, including the ones created before resuming
+				for _, ref := range oTo.GetOwnerReferences() {
+					if ref.UID == "" {
+						t.Errorf("%v has an owner reference without UID in target cluster", key)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_objectMover_rollback(t *testing.T) {
+	// NB. we are testing rollback using the same set of moveTests used for move, rolling back a move interrupted after creating the first half of the move groups
+	for _, tt := range moveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			if tt.wantErr {
+				return
+			}
+
+			dir, err := ioutil.TempDir("", "cluster-client")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "move-journal.yaml")
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+			graph := getObjectGraphWithObjs(tt.fields.objs)
+
+			// Get all the types to be considered for discovery
+			g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+			// trigger discovery the content of the source cluster
+			g.Expect(graph.Discovery("")).To(Succeed())
+
+			// gets a fakeProxy to an empty cluster with all the required CRDs, and start a move that gets interrupted
+			toProxy := getFakeProxyWithCRDs()
+			startInterruptedMove(g, graph, toProxy, path)
+
+			// Rollback the move
+			journal, err := readMoveJournal(path)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			mover := objectMover{
+				fromProxy: graph.proxy,
+				journal:   journal,
+			}
+			g.Expect(mover.rollback(toProxy)).To(Succeed())
+
+			// check that the move is recorded as rolled back
+			journal, err = readMoveJournal(path)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(journal.Phase).To(Equal(moveJournalRolledBackPhase))
+
+			// check that the objects are kept in the source cluster and are removed from the target cluster
+			csFrom, err := graph.proxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			csTo, err := toProxy.NewClient()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			for _, node := range graph.getMoveNodes() {
+				key := client.ObjectKey{
+					Namespace: node.identity.Namespace,
+					Name:      node.identity.Name,
+				}
+
+				oFrom := &unstructured.Unstructured{}
+				oFrom.SetAPIVersion(node.identity.APIVersion)
+				oFrom.SetKind(node.identity.Kind)
+				if err := csFrom.Get(ctx, key, oFrom); err != nil {
+					t.Errorf("error = %v when checking for %v kept in source cluster", err, key)
+					continue
+				}
+
+				if node.isGlobal {
+					continue
+				}
+
+				oTo := &unstructured.Unstructured{}
+				oTo.SetAPIVersion(node.identity.APIVersion)
+				oTo.SetKind(node.identity.Kind)
+				if err := csTo.Get(ctx, key, oTo); !apierrors.IsNotFound(err) {
+					t.Errorf("error = %v when checking for %v deleted in target cluster", err, key)
+					continue
+				}
+			}
+
+			// check that clusters are not paused in the source cluster
+			for _, cluster := range graph.getClusters() {
+				key := client.ObjectKey{
+					Namespace: cluster.identity.Namespace,
+					Name:      cluster.identity.Name,
+				}
+				clusterObj := &clusterv1.Cluster{}
+				g.Expect(csFrom.Get(ctx, key, clusterObj)).To(Succeed())
+				g.Expect(clusterObj.Spec.Paused).To(BeFalse())
+			}
+		})
+	}
+}
+
+func Test_objectMover_rollback_keepsExistingTargetObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "cluster-client")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "move-journal.yaml")
+
+	graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+	g.Expect(graph.Discovery("")).To(Succeed())
+
+	// gets a fakeProxy to a cluster where the Cluster object already exists
+	toProxy := getFakeProxyWithCRDs()
+	for _, o := range test.NewFakeCluster("ns1", "foo").Objs() {
+		if _, ok := o.(*clusterv1.Cluster); ok {
+			toProxy.WithObjs(o)
+		}
+	}
+
+	mover := objectMover{
+		fromProxy: graph.proxy,
+		journal:   newMoveJournal(path, "", graph.getClusters()),
+	}
+	g.Expect(mover.ensureNamespaces(graph, toProxy)).To(Succeed())
+	g.Expect(mover.createGroups(getMoveSequence(graph), 0, toProxy)).To(Succeed())
+
+	// the existing Cluster object is not recorded as created
+	for _, ref := range mover.journal.Created {
+		g.Expect(ref.Kind).ToNot(Equal("Cluster"))
+	}
+	g.Expect(mover.journal.Created).ToNot(BeEmpty())
+
+	g.Expect(mover.rollback(toProxy)).To(Succeed())
+
+	// the existing Cluster object is kept in the target cluster, while the created objects are deleted
+	csTo, err := toProxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(csTo.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "foo"}, &clusterv1.Cluster{})).To(Succeed())
+	for _, ref := range mover.journal.Created {
+		o := &unstructured.Unstructured{}
+		o.SetAPIVersion(ref.APIVersion)
+		o.SetKind(ref.Kind)
+		err := csTo.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, o)
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "%s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+	}
+}
+
+func Test_objectMover_rollback_keepsPausedClusters(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "cluster-client")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "move-journal.yaml")
+
+	objs := append(test.NewFakeCluster("ns1", "paused").Objs(), test.NewFakeCluster("ns1", "unpaused").Objs()...)
+	for _, o := range objs {
+		if c, ok := o.(*clusterv1.Cluster); ok && c.Name == "paused" {
+			c.Spec.Paused = true
+		}
+	}
+
+	graph := getObjectGraphWithObjs(objs)
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+	g.Expect(graph.Discovery("")).To(Succeed())
+
+	// start a move that gets interrupted
+	toProxy := getFakeProxyWithCRDs()
+	startInterruptedMove(g, graph, toProxy, path)
+
+	// Rollback the move
+	journal, err := readMoveJournal(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(journal.PausedClusters).To(HaveLen(1))
+	mover := objectMover{
+		fromProxy: graph.proxy,
+		journal:   journal,
+	}
+	g.Expect(mover.rollback(toProxy)).To(Succeed())
+
+	// the cluster paused before the move is still paused, while the other cluster is resumed
+	csFrom, err := graph.proxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+	for name, wantPaused := range map[string]bool{"paused": true, "unpaused": false} {
+		clusterObj := &clusterv1.Cluster{}
+		g.Expect(csFrom.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: name}, clusterObj)).To(Succeed())
+		g.Expect(clusterObj.Spec.Paused).To(Equal(wantPaused), "cluster %s", name)
+	}
+}
+
+func Test_objectMover_ResumeAndRollback_journalPhase(t *testing.T) {
+	tests := []struct {
+		name     string
+		phase    moveJournalPhase
+		rollback bool
+	}{
+		{
+			name:     "a completed move cannot be resumed",
+			phase:    moveJournalCompletedPhase,
+			rollback: false,
+		},
+		{
+			name:     "a completed move cannot be rolled back",
+			phase:    moveJournalCompletedPhase,
+			rollback: true,
+		},
+		{
+			name:     "a rolled back move cannot be resumed",
+			phase:    moveJournalRolledBackPhase,
+			rollback: false,
+		},
+		{
+			name:     "a move deleting objects from the source cluster cannot be rolled back",
+			phase:    moveJournalDeletingPhase,
+			rollback: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dir, err := ioutil.TempDir("", "cluster-client")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "move-journal.yaml")
+
+			journal := newMoveJournal(path, "ns1", nil)
+			g.Expect(journal.setPhase(tt.phase)).To(Succeed())
+
+			// gets a fakeProxy to an empty source and target clusters with all the required CRDs
+			fromProxy := getFakeProxyWithCRDs()
+			toProxy := getFakeProxyWithCRDs()
+			toCluster := newClusterClient(Kubeconfig{}, &fakeConfigClient{}, InjectProxy(toProxy))
+			mover := newObjectMover(fromProxy, newInventoryClient(fromProxy, nil))
+
+			if tt.rollback {
+				g.Expect(mover.Rollback(toCluster, path)).NotTo(Succeed())
+			} else {
+				g.Expect(mover.Resume(toCluster, path)).NotTo(Succeed())
+			}
+
+			// check the journal is not changed
+			journal, err = readMoveJournal(path)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(journal.Phase).To(Equal(tt.phase))
+		})
+	}
+}
+
+func Test_objectMover_Move_journalInProgress(t *testing.T) {
+	tests := []struct {
+		name    string
+		phase   moveJournalPhase
+		wantErr bool
+	}{
+		{
+			name:    "a move creating objects in the target cluster is not overwritten",
+			phase:   moveJournalCreatingPhase,
+			wantErr: true,
+		},
+		{
+			name:    "a move deleting objects from the source cluster is not overwritten",
+			phase:   moveJournalDeletingPhase,
+			wantErr: true,
+		},
+		{
+			name:  "a completed move is overwritten",
+			phase: moveJournalCompletedPhase,
+		},
+		{
+			name:  "a rolled back move is overwritten",
+			phase: moveJournalRolledBackPhase,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dir, err := ioutil.TempDir("", "cluster-client")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "move-journal.yaml")
+
+			journal := newMoveJournal(path, "ns1", nil)
+			g.Expect(journal.setPhase(tt.phase)).To(Succeed())
+
+			err = checkNoMoveInProgress(path)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+
+				// check Move fails without changing the journal
+				fromProxy := getFakeProxyWithCRDs()
+				toProxy := getFakeProxyWithCRDs()
+				toCluster := newClusterClient(Kubeconfig{}, &fakeConfigClient{}, InjectProxy(toProxy))
+				mover := newObjectMover(fromProxy, newInventoryClient(fromProxy, nil))
+				g.Expect(mover.Move("ns1", toCluster, false, WithMoveJournal(path))).NotTo(Succeed())
+
+				journal, err = readMoveJournal(path)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(journal.Phase).To(Equal(tt.phase))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+
+	t.Run("a missing journal is not a move in progress", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(checkNoMoveInProgress(filepath.Join(os.TempDir(), "missing-move-journal.yaml"))).To(Succeed())
+	})
+}
+
 func Test_objectMover_checkProvisioningCompleted(t *testing.T) {
 	type fields struct {
 		objs []client.Object
@@ -60,6 +60,10 @@ type node struct {
 	// isGlobal gets set to true if this object is a global resource (no namespace).
 	isGlobal bool
 
+	// shared gets set to true if this object is required also by Clusters which are not being moved; shared objects are
+	// created in the target cluster, but they are not deleted from the source cluster.
+	shared bool
+
 	// virtual records if this node was discovered indirectly, e.g. by processing an OwnerRef, but not yet observed as a concrete object.
 	virtual bool
 
@@ -73,6 +77,10 @@ type node struct {
 	// tenantCRSs define the list of ClusterResourceSet which are tenant for the node, no matter if the node has a direct OwnerReference to the ClusterResourceSet or if
 	// the node is linked to a ClusterResourceSet indirectly in the OwnerReference chain.
 	tenantCRSs map[*node]empty
+
+	// restoreObject holds the object read from a backup when rebuilding the graph during restore, so it can be
+	// used later when creating objects in the target management cluster.
+	restoreObject *unstructured.Unstructured
 }
 
 type discoveryTypeInfo struct {
@@ -137,6 +145,14 @@ func (o *objectGraph) addObj(obj *unstructured.Unstructured) {
 	}
 }
 
+// addRestoredObj adds a Kubernetes object read from a backup to the object graph that is generated during restore.
+// During add, OwnerReferences are processed in order to create the dependency graph, and the object is stored in
+// the node so it can be used when creating objects in the target management cluster.
+func (o *objectGraph) addRestoredObj(obj *unstructured.Unstructured) {
+	o.addObj(obj)
+	o.uidToNode[obj.GetUID()].restoreObject = obj
+}
+
 // ownerToVirtualNode creates a virtual node as a placeholder for the Kubernetes owner object received in input.
 // The virtual node will be eventually converted to an actual node when the node will be visited during discovery.
 func (o *objectGraph) ownerToVirtualNode(owner metav1.OwnerReference, namespace string) *node {
@@ -480,6 +496,87 @@ func (o *objectGraph) setCRSTenant(node, tenant *node) {
 	}
 }
 
+// filterClusters limits the nodes to be moved to the given Clusters and all their dependent object tree, plus the ClusterResourceSets
+// applied to the given Clusters and the objects with the "move" label not belonging to any Cluster; all the other nodes are removed from the graph.
+// Nodes required also by Clusters which are not being moved are marked as shared, so they are not deleted from the source cluster.
+func (o *objectGraph) filterClusters(clusters []*node) {
+	log := logf.Log
+
+	selectedClusters := map[*node]empty{}
+	for _, cluster := range clusters {
+		selectedClusters[cluster] = empty{}
+	}
+
+	// Gets the ClusterResourceSets applied to the selected Clusters, and the ones applied also to other Clusters.
+	// NB. ClusterResourceSets are linked to the Clusters they are applied to by the ClusterResourceSetBindings, owned by both.
+	selectedCRSs := map[*node]empty{}
+	sharedCRSs := map[*node]empty{}
+	for _, crs := range o.getCRSs() {
+		for _, other := range o.getNodes() {
+			if !other.isOwnedBy(crs) || len(other.tenantClusters) == 0 {
+				continue
+			}
+			if hasAnyTenant(other.tenantClusters, selectedClusters) {
+				selectedCRSs[crs] = empty{}
+			}
+			if hasOtherTenants(other.tenantClusters, selectedClusters) {
+				sharedCRSs[crs] = empty{}
+			}
+		}
+	}
+
+	for _, n := range o.getMoveNodes() {
+		isSelected := hasAnyTenant(n.tenantClusters, selectedClusters) ||
+			(len(n.tenantClusters) == 0 && (hasAnyTenant(n.tenantCRSs, selectedCRSs) || n.forceMove))
+		if !isSelected {
+			log.V(5).Info("Excluding object from move (not linked with the selected Clusters)", "kind", n.identity.Kind, "name", n.identity.Name, "namespace", n.identity.Namespace)
+			o.removeNode(n)
+			continue
+		}
+
+		// NB. nodes belonging to Clusters are shared only if they belong to other Clusters too (e.g. the ClusterResourceSetBinding for
+		// a selected Cluster is not shared), while nodes not belonging to any Cluster are shared if they are applied to other Clusters
+		// or if they have the "move" label (and so they could be used by other Clusters).
+		if len(n.tenantClusters) > 0 {
+			n.shared = hasOtherTenants(n.tenantClusters, selectedClusters)
+		} else {
+			n.shared = hasAnyTenant(n.tenantCRSs, sharedCRSs) || n.forceMove
+		}
+	}
+}
+
+// removeNode removes a node from the object graph, including all the ownership relations with the node.
+func (o *objectGraph) removeNode(n *node) {
+	for uid, other := range o.uidToNode {
+		if other == n {
+			delete(o.uidToNode, uid)
+			continue
+		}
+		delete(other.owners, n)
+		delete(other.softOwners, n)
+	}
+}
+
+// hasAnyTenant returns true if at least one of the tenants is included in the given set.
+func hasAnyTenant(tenants, set map[*node]empty) bool {
+	for tenant := range tenants {
+		if _, ok := set[tenant]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOtherTenants returns true if at least one of the tenants is not included in the given set.
+func hasOtherTenants(tenants, set map[*node]empty) bool {
+	for tenant := range tenants {
+		if _, ok := set[tenant]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
 // checkVirtualNode logs if nodes are still virtual
 func (o *objectGraph) checkVirtualNode() {
 	log := logf.Log
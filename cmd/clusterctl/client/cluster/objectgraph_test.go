@@ -1643,3 +1643,180 @@ func Test_objectGraph_setCRSTenants(t *testing.T) {
 		})
 	}
 }
+
+func Test_objectGraph_filterClusters(t *testing.T) {
+	type fields struct {
+		objs []client.Object
+	}
+	tests := []struct {
+		name          string
+		fields        fields
+		clusters      []string
+		wantMoveNodes []string
+		wantShared    []string
+	}{
+		{
+			name: "Two clusters, one selected",
+			fields: fields{
+				objs: func() []client.Object {
+					objs := []client.Object{}
+					objs = append(objs, test.NewFakeCluster("ns1", "cluster1").Objs()...)
+					objs = append(objs, test.NewFakeCluster("ns1", "cluster2").Objs()...)
+					return objs
+				}(),
+			},
+			clusters: []string{"cluster1"},
+			wantMoveNodes: []string{
+				"cluster.x-k8s.io/v1alpha4, Kind=Cluster, ns1/cluster1",
+				"/v1, Kind=Secret, ns1/cluster1-ca",
+				"/v1, Kind=Secret, ns1/cluster1-kubeconfig",
+				"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureCluster, ns1/cluster1",
+			},
+			wantShared: []string{},
+		},
+		{
+			name: "Two clusters with a shared object, one selected",
+			fields: fields{
+				objs: func() []client.Object {
+					sharedInfrastructureTemplate := test.NewFakeInfrastructureTemplate("shared")
+
+					objs := []client.Object{
+						sharedInfrastructureTemplate,
+					}
+
+					objs = append(objs, test.NewFakeCluster("ns1", "cluster1").
+						WithMachineSets(
+							test.NewFakeMachineSet("cluster1-ms1").
+								WithInfrastructureTemplate(sharedInfrastructureTemplate),
+						).Objs()...)
+
+					objs = append(objs, test.NewFakeCluster("ns1", "cluster2").
+						WithMachineSets(
+							test.NewFakeMachineSet("cluster2-ms1").
+								WithInfrastructureTemplate(sharedInfrastructureTemplate),
+						).Objs()...)
+
+					return objs
+				}(),
+			},
+			clusters: []string{"cluster1"},
+			wantMoveNodes: []string{
+				"cluster.x-k8s.io/v1alpha4, Kind=Cluster, ns1/cluster1",
+				"/v1, Kind=Secret, ns1/cluster1-ca",
+				"/v1, Kind=Secret, ns1/cluster1-kubeconfig",
+				"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureCluster, ns1/cluster1",
+				"cluster.x-k8s.io/v1alpha4, Kind=MachineSet, ns1/cluster1-ms1",
+				"bootstrap.cluster.x-k8s.io/v1alpha4, Kind=GenericBootstrapConfigTemplate, ns1/cluster1-ms1",
+				"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureMachineTemplate, ns1/shared",
+			},
+			wantShared: []string{
+				"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureMachineTemplate, ns1/shared",
+			},
+		},
+		{
+			name: "A ClusterResourceSet applied to the selected cluster only",
+			fields: fields{
+				objs: func() []client.Object {
+					objs := []client.Object{}
+					objs = append(objs, test.NewFakeCluster("ns1", "cluster1").Objs()...)
+					objs = append(objs, test.NewFakeCluster("ns1", "cluster2").Objs()...)
+
+					objs = append(objs, test.NewFakeClusterResourceSet("ns1", "crs1").
+						WithSecret("resource-s1").
+						WithConfigMap("resource-c1").
+						ApplyToCluster(test.SelectClusterObj(objs, "ns1", "cluster1")).
+						Objs()...)
+
+					return objs
+				}(),
+			},
+			clusters: []string{"cluster1"},
+			wantMoveNodes: []string{
+				"cluster.x-k8s.io/v1alpha4, Kind=Cluster, ns1/cluster1",
+				"/v1, Kind=Secret, ns1/cluster1-ca",
+				"/v1, Kind=Secret, ns1/cluster1-kubeconfig",
+				"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureCluster, ns1/cluster1",
+				"addons.cluster.x-k8s.io/v1alpha4, Kind=ClusterResourceSet, ns1/crs1",
+				"addons.cluster.x-k8s.io/v1alpha4, Kind=ClusterResourceSetBinding, ns1/cluster1",
+				"/v1, Kind=Secret, ns1/resource-s1",
+				"/v1, Kind=ConfigMap, ns1/resource-c1",
+			},
+			wantShared: []string{},
+		},
+		{
+			name: "A ClusterResourceSet applied to two clusters, one selected",
+			fields: fields{
+				objs: func() []client.Object {
+					objs := []client.Object{}
+					objs = append(objs, test.NewFakeCluster("ns1", "cluster1").Objs()...)
+					objs = append(objs, test.NewFakeCluster("ns1", "cluster2").Objs()...)
+
+					objs = append(objs, test.NewFakeClusterResourceSet("ns1", "crs1").
+						WithSecret("resource-s1").
+						WithConfigMap("resource-c1").
+						ApplyToCluster(test.SelectClusterObj(objs, "ns1", "cluster1")).
+						ApplyToCluster(test.SelectClusterObj(objs, "ns1", "cluster2")).
+						Objs()...)
+
+					return objs
+				}(),
+			},
+			clusters: []string{"cluster1"},
+			wantMoveNodes: []string{
+				"cluster.x-k8s.io/v1alpha4, Kind=Cluster, ns1/cluster1",
+				"/v1, Kind=Secret, ns1/cluster1-ca",
+				"/v1, Kind=Secret, ns1/cluster1-kubeconfig",
+				"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureCluster, ns1/cluster1",
+				"addons.cluster.x-k8s.io/v1alpha4, Kind=ClusterResourceSet, ns1/crs1",
+				"addons.cluster.x-k8s.io/v1alpha4, Kind=ClusterResourceSetBinding, ns1/cluster1",
+				"/v1, Kind=Secret, ns1/resource-s1",
+				"/v1, Kind=ConfigMap, ns1/resource-c1",
+			},
+			wantShared: []string{
+				"addons.cluster.x-k8s.io/v1alpha4, Kind=ClusterResourceSet, ns1/crs1",
+				"/v1, Kind=Secret, ns1/resource-s1",
+				"/v1, Kind=ConfigMap, ns1/resource-c1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			gb, err := getDetachedObjectGraphWihObjs(tt.fields.objs)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			gb.setSoftOwnership()
+			gb.setClusterTenants()
+			gb.setCRSTenants()
+
+			selected := []*node{}
+			for _, cluster := range gb.getClusters() {
+				for _, name := range tt.clusters {
+					if cluster.identity.Name == name {
+						selected = append(selected, cluster)
+					}
+				}
+			}
+
+			gb.filterClusters(selected)
+
+			gotMoveNodes := []string{}
+			gotShared := []string{}
+			for _, node := range gb.getMoveNodes() {
+				gotMoveNodes = append(gotMoveNodes, string(node.identity.UID))
+				if node.shared {
+					gotShared = append(gotShared, string(node.identity.UID))
+				}
+
+				// owners not included in the graph are removed
+				for owner := range node.owners {
+					g.Expect(gb.uidToNode).To(HaveKey(owner.identity.UID))
+				}
+			}
+
+			g.Expect(gotMoveNodes).To(ConsistOf(tt.wantMoveNodes))
+			g.Expect(gotShared).To(ConsistOf(tt.wantShared))
+		})
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
 	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -99,12 +100,19 @@ func (t *templateClient) GetFromConfigMap(configMapNamespace, configMapName, con
 		return nil, errors.Errorf("the ConfigMap %s/%s does not have the %q data key", configMapNamespace, configMapName, configMapDataKey)
 	}
 
+	// the variables file, if any, is read from the data key next to the template, e.g. template.variables.yaml.
+	var rawVariablesSchema []byte
+	if schema, ok := configMap.Data[repository.VariablesSchemaFileName(configMapDataKey)]; ok {
+		rawVariablesSchema = []byte(schema)
+	}
+
 	return repository.NewTemplate(repository.TemplateInput{
 		RawArtifact:           []byte(data),
 		ConfigVariablesClient: t.configClient.Variables(),
 		Processor:             t.processor,
 		TargetNamespace:       targetNamespace,
 		ListVariablesOnly:     listVariablesOnly,
+		RawVariablesSchema:    rawVariablesSchema,
 	})
 }
 
@@ -118,15 +126,42 @@ func (t *templateClient) GetFromURL(templateURL, targetNamespace string, listVar
 		return nil, errors.Wrapf(err, "invalid GetFromURL operation")
 	}
 
+	rawVariablesSchema, err := t.getVariablesSchemaURLContent(templateURL)
+	if err != nil {
+		return nil, err
+	}
+
 	return repository.NewTemplate(repository.TemplateInput{
 		RawArtifact:           content,
 		ConfigVariablesClient: t.configClient.Variables(),
 		Processor:             t.processor,
 		TargetNamespace:       targetNamespace,
 		ListVariablesOnly:     listVariablesOnly,
+		RawVariablesSchema:    rawVariablesSchema,
 	})
 }
 
+// getVariablesSchemaURLContent reads the variables file next to the template, e.g. cluster-template.variables.yaml
+// for cluster-template.yaml. Because the variables file is optional, it returns nil if the file does not exist;
+// other errors, e.g. authentication or network errors, are returned.
+func (t *templateClient) getVariablesSchemaURLContent(templateURL string) ([]byte, error) {
+	rURL, err := url.Parse(templateURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %q", templateURL)
+	}
+	rURL.Path = repository.VariablesSchemaFileName(rURL.Path)
+
+	content, err := t.getURLContent(rURL.String())
+	if err != nil {
+		if repository.IsFileNotFound(err) {
+			logf.Log.V(5).Info("Variables file not available, skipping variables validation", "URL", rURL.String())
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read variables file %q", rURL.String())
+	}
+	return content, nil
+}
+
 func (t *templateClient) getURLContent(templateURL string) ([]byte, error) {
 	rURL, err := url.Parse(templateURL)
 	if err != nil {
@@ -147,7 +182,7 @@ func (t *templateClient) getURLContent(templateURL string) ([]byte, error) {
 func (t *templateClient) getLocalFileContent(rURL *url.URL) ([]byte, error) {
 	f, err := os.Stat(rURL.Path)
 	if err != nil {
-		return nil, errors.Errorf("failed to read file %q", rURL.Path)
+		return nil, errors.Wrapf(err, "failed to read file %q", rURL.Path)
 	}
 	if f.IsDir() {
 		return nil, errors.Errorf("invalid path: file %q is actually a directory", rURL.Path)
@@ -19,6 +19,24 @@ package config
 const (
 	// GitHubTokenVariable defines a variable hosting the GitHub access token
 	GitHubTokenVariable = "github-token"
+
+	// GitLabAccessTokenVariable defines a variable hosting the GitLab access token
+	GitLabAccessTokenVariable = "gitlab-access-token"
+
+	// HTTPRepositoryTokenVariable defines a variable hosting the bearer token used to access HTTP repositories
+	HTTPRepositoryTokenVariable = "http-repository-token"
+
+	// OCIRegistryTokenVariable defines a variable hosting the bearer token used to access OCI registries
+	OCIRegistryTokenVariable = "oci-registry-token"
+
+	// OCIRegistryUsernameVariable defines a variable hosting the username used to access OCI registries
+	OCIRegistryUsernameVariable = "oci-registry-username"
+
+	// OCIRegistryPasswordVariable defines a variable hosting the password used to access OCI registries
+	OCIRegistryPasswordVariable = "oci-registry-password"
+
+	// OCIRegistryInsecureVariable defines a variable that, when set to "true", allows to access OCI registries over plain HTTP
+	OCIRegistryInsecureVariable = "oci-registry-insecure"
 )
 
 // VariablesClient has methods to work with environment variables and with variables defined in the clusterctl configuration file.
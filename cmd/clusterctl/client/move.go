@@ -17,6 +17,8 @@ limitations under the License.
 package client
 
 import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 )
 
@@ -34,11 +36,43 @@ type MoveOptions struct {
 	// namespace will be used.
 	Namespace string
 
+	// ClusterName defines the name of the Cluster to be moved together with all its dependent objects. If unspecified,
+	// all the Clusters in the namespace will be moved.
+	ClusterName string
+
+	// ClusterSelector defines a label selector for the Clusters to be moved together with all their dependent objects.
+	// If unspecified, all the Clusters in the namespace will be moved.
+	ClusterSelector string
+
 	// DryRun means the move action is a dry run, no real action will be performed
 	DryRun bool
+
+	// JournalPath defines the file where the progress of the move action is recorded, so a move action
+	// that fails can be resumed or rolled back. If empty, the progress will not be recorded.
+	JournalPath string
+
+	// Resume means the move action recorded in the journal should be resumed.
+	Resume bool
+
+	// Rollback means the move action recorded in the journal should be rolled back, by deleting the objects
+	// created in the target management cluster and by unpausing the source management cluster.
+	Rollback bool
 }
 
 func (c *clusterctlClient) Move(options MoveOptions) error {
+	if options.Resume && options.Rollback {
+		return errors.New("resume and rollback cannot be used together")
+	}
+	if (options.Resume || options.Rollback) && options.JournalPath == "" {
+		return errors.New("a journal is required to resume or roll back a move")
+	}
+	if (options.Resume || options.Rollback) && options.DryRun {
+		return errors.New("dry run cannot be used when resuming or rolling back a move")
+	}
+	if (options.Resume || options.Rollback) && (options.ClusterName != "" || options.ClusterSelector != "") {
+		return errors.New("the Clusters to be moved cannot be selected when resuming or rolling back a move")
+	}
+
 	// Get the client for interacting with the source management cluster.
 	fromCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.FromKubeconfig})
 	if err != nil {
@@ -64,6 +98,73 @@ func (c *clusterctlClient) Move(options MoveOptions) error {
 		}
 	}
 
+	// Resume or roll back the move action recorded in the journal, if required.
+	// Nb. the namespace is read from the journal.
+	if options.Resume {
+		return fromCluster.ObjectMover().Resume(toCluster, options.JournalPath)
+	}
+	if options.Rollback {
+		return fromCluster.ObjectMover().Rollback(toCluster, options.JournalPath)
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if options.Namespace == "" {
+		currentNamespace, err := fromCluster.Proxy().CurrentNamespace()
+		if err != nil {
+			return err
+		}
+		options.Namespace = currentNamespace
+	}
+
+	var moveOptions []cluster.MoveOption
+	if options.JournalPath != "" {
+		moveOptions = append(moveOptions, cluster.WithMoveJournal(options.JournalPath))
+	}
+	if options.ClusterName != "" {
+		moveOptions = append(moveOptions, cluster.WithClusterName(options.ClusterName))
+	}
+	if options.ClusterSelector != "" {
+		selector, err := labels.Parse(options.ClusterSelector)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse cluster selector %q", options.ClusterSelector)
+		}
+		moveOptions = append(moveOptions, cluster.WithClusterSelector(selector))
+	}
+
+	if err := fromCluster.ObjectMover().Move(options.Namespace, toCluster, options.DryRun, moveOptions...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// BackupOptions carries the options supported by backup.
+type BackupOptions struct {
+	// FromKubeconfig defines the kubeconfig to use for accessing the source management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	FromKubeconfig Kubeconfig
+
+	// Namespace where the objects describing the workload cluster exists. If unspecified, the current
+	// namespace will be used.
+	Namespace string
+
+	// Path defines the directory where the Cluster API objects will be saved; if the path ends with .tar.gz or .tgz,
+	// objects will be saved to a tarball instead.
+	Path string
+}
+
+func (c *clusterctlClient) Backup(options BackupOptions) error {
+	// Get the client for interacting with the source management cluster.
+	fromCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.FromKubeconfig})
+	if err != nil {
+		return err
+	}
+
+	// Ensures the custom resource definitions required by clusterctl are in place.
+	if err := fromCluster.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return err
+	}
+
 	// If the option specifying the Namespace is empty, try to detect it.
 	if options.Namespace == "" {
 		currentNamespace, err := fromCluster.Proxy().CurrentNamespace()
@@ -73,7 +174,36 @@ func (c *clusterctlClient) Move(options MoveOptions) error {
 		options.Namespace = currentNamespace
 	}
 
-	if err := fromCluster.ObjectMover().Move(options.Namespace, toCluster, options.DryRun); err != nil {
+	if err := fromCluster.ObjectMover().Backup(options.Namespace, options.Path); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RestoreOptions carries the options supported by restore.
+type RestoreOptions struct {
+	// ToKubeconfig defines the kubeconfig to use for accessing the target management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	ToKubeconfig Kubeconfig
+
+	// Path defines the directory or the tarball where the Cluster API objects were saved by backup.
+	Path string
+}
+
+func (c *clusterctlClient) Restore(options RestoreOptions) error {
+	// Get the client for interacting with the target management cluster.
+	toCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.ToKubeconfig})
+	if err != nil {
+		return err
+	}
+
+	// Ensures the custom resource definitions required by clusterctl are in place
+	if err := toCluster.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return err
+	}
+
+	if err := toCluster.ObjectMover().Restore(toCluster, options.Path); err != nil {
 		return err
 	}
 
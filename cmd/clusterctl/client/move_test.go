@@ -77,6 +77,95 @@ func Test_clusterctlClient_Move(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "does not return error when resuming a move",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					JournalPath:    "move-journal.yaml",
+					Resume:         true,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "does not return error when rolling back a move",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					JournalPath:    "move-journal.yaml",
+					Rollback:       true,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns an error if resuming a move without a journal",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					Resume:         true,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "does not return error when moving a single cluster",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig:  Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:    Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					ClusterName:     "cluster1",
+					ClusterSelector: "region=eu",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns an error if the cluster selector is not valid",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig:  Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:    Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					ClusterSelector: "region in (eu",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if resume and rollback are used together",
+			fields: fields{
+				client: fakeClientForMove(), // core v1.0.0 (v1.0.1 available), infra v2.0.0 (v2.0.1 available)
+			},
+			args: args{
+				options: MoveOptions{
+					FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					ToKubeconfig:   Kubeconfig{Path: "kubeconfig", Context: "worker-context"},
+					JournalPath:    "move-journal.yaml",
+					Resume:         true,
+					Rollback:       true,
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -93,6 +182,82 @@ func Test_clusterctlClient_Move(t *testing.T) {
 	}
 }
 
+func Test_clusterctlClient_Backup(t *testing.T) {
+	tests := []struct {
+		name    string
+		options BackupOptions
+		wantErr bool
+	}{
+		{
+			name: "does not return error if cluster client is found",
+			options: BackupOptions{
+				FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				Path:           "backup",
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns an error if from cluster client is not found",
+			options: BackupOptions{
+				FromKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "does-not-exist"},
+				Path:           "backup",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := fakeClientForMove().Backup(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func Test_clusterctlClient_Restore(t *testing.T) {
+	tests := []struct {
+		name    string
+		options RestoreOptions
+		wantErr bool
+	}{
+		{
+			name: "does not return error if cluster client is found",
+			options: RestoreOptions{
+				ToKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				Path:         "backup",
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns an error if to cluster client is not found",
+			options: RestoreOptions{
+				ToKubeconfig: Kubeconfig{Path: "kubeconfig", Context: "does-not-exist"},
+				Path:         "backup",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := fakeClientForMove().Restore(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
 func fakeClientForMove() *fakeClient {
 	core := config.NewProvider("cluster-api", "https://somewhere.com", clusterctlv1.CoreProviderType)
 	infra := config.NewProvider("infra", "https://somewhere.com", clusterctlv1.InfrastructureProviderType)
@@ -119,9 +284,29 @@ func fakeClientForMove() *fakeClient {
 }
 
 type fakeObjectMover struct {
-	moveErr error
+	moveErr     error
+	resumeErr   error
+	rollbackErr error
+	backupErr   error
+	restoreErr  error
 }
 
-func (f *fakeObjectMover) Move(namespace string, toCluster cluster.Client, dryRun bool) error {
+func (f *fakeObjectMover) Move(namespace string, toCluster cluster.Client, dryRun bool, options ...cluster.MoveOption) error {
 	return f.moveErr
 }
+
+func (f *fakeObjectMover) Resume(toCluster cluster.Client, journalPath string) error {
+	return f.resumeErr
+}
+
+func (f *fakeObjectMover) Rollback(toCluster cluster.Client, journalPath string) error {
+	return f.rollbackErr
+}
+
+func (f *fakeObjectMover) Backup(namespace string, path string) error {
+	return f.backupErr
+}
+
+func (f *fakeObjectMover) Restore(toCluster cluster.Client, path string) error {
+	return f.restoreErr
+}
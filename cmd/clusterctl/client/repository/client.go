@@ -17,8 +17,13 @@ limitations under the License.
 package repository
 
 import (
+	"fmt"
+	"net/http"
 	"net/url"
+	"os"
+	"strings"
 
+	"github.com/google/go-github/github"
 	"github.com/pkg/errors"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
@@ -142,6 +147,7 @@ type Repository interface {
 	ComponentsPath() string
 
 	// GetFile return a file for a given provider version.
+	// If the file does not exist, the error should satisfy IsFileNotFound.
 	GetFile(version string, path string) ([]byte, error)
 
 	// GetVersion return the list of versions that are available in a provider repository
@@ -150,6 +156,48 @@ type Repository interface {
 
 var _ Repository = &test.FakeRepository{}
 
+// fileNotFoundError reports that a file does not exist in a provider repository.
+type fileNotFoundError struct {
+	message string
+}
+
+func (e *fileNotFoundError) Error() string {
+	return e.message
+}
+
+// NotFound returns true, so IsFileNotFound can detect the error.
+func (e *fileNotFoundError) NotFound() bool {
+	return true
+}
+
+// newFileNotFoundError returns an error reporting that a file does not exist in a provider repository.
+func newFileNotFoundError(format string, args ...interface{}) error {
+	return &fileNotFoundError{message: fmt.Sprintf(format, args...)}
+}
+
+// IsFileNotFound returns true if an error, or one of the errors it wraps, reports that a file does not exist,
+// as opposed to e.g. authentication, network or rate limit errors.
+// Errors report it by implementing NotFound() bool; file system and GitHub API not found errors are detected as well.
+func IsFileNotFound(err error) bool {
+	for err != nil {
+		if nf, ok := err.(interface{ NotFound() bool }); ok && nf.NotFound() {
+			return true
+		}
+		if os.IsNotExist(err) {
+			return true
+		}
+		if ghErr, ok := err.(*github.ErrorResponse); ok && ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusNotFound {
+			return true
+		}
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			return false
+		}
+		err = cause.Cause()
+	}
+	return false
+}
+
 //repositoryFactory returns the repository implementation corresponding to the provider URL.
 func repositoryFactory(providerConfig config.Provider, configVariablesClient config.VariablesClient) (Repository, error) {
 	// parse the repository url
@@ -167,6 +215,33 @@ func repositoryFactory(providerConfig config.Provider, configVariablesClient con
 		return repo, err
 	}
 
+	// if the url is a GitLab repository
+	if (rURL.Scheme == httpsScheme || rURL.Scheme == httpScheme) && strings.Contains(rURL.Path, gitlabReleasePathSeparator) {
+		repo, err := newGitLabRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the GitLab repository client")
+		}
+		return repo, err
+	}
+
+	// if the url is a generic HTTP(S) repository
+	if rURL.Scheme == httpsScheme || rURL.Scheme == httpScheme {
+		repo, err := newHTTPRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the HTTP repository client")
+		}
+		return repo, err
+	}
+
+	// if the url is an OCI repository
+	if rURL.Scheme == ociScheme {
+		repo, err := newOCIRepository(providerConfig, configVariablesClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the OCI repository client")
+		}
+		return repo, err
+	}
+
 	// if the url is a local filesystem repository
 	if rURL.Scheme == "file" || rURL.Scheme == "" {
 		repo, err := newLocalRepository(providerConfig, configVariablesClient)
@@ -17,10 +17,13 @@ limitations under the License.
 package repository
 
 import (
+	"net/http"
 	"os"
 	"testing"
 
+	"github.com/google/go-github/github"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
@@ -126,3 +129,98 @@ func Test_newRepositoryClient_YamlProcessor(t *testing.T) {
 		})
 	}
 }
+
+func Test_repositoryFactory(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    Repository
+		wantErr bool
+	}{
+		{
+			name: "creates a GitLab repository for urls pointing to GitLab releases",
+			url:  "https://gitlab.example.com/g/p/-/releases/v1.0.0/bootstrap-components.yaml",
+			want: &gitLabRepository{},
+		},
+		{
+			name: "creates an HTTP repository for other https urls",
+			url:  "https://artifacts.example.com/bootstrap-foo/v1.0.0/bootstrap-components.yaml",
+			want: &httpRepository{},
+		},
+		{
+			name: "creates an HTTP repository for http urls",
+			url:  "http://artifacts.example.com/bootstrap-foo/v1.0.0/bootstrap-components.yaml",
+			want: &httpRepository{},
+		},
+		{
+			name: "creates an OCI repository for oci urls",
+			url:  "oci://registry.example.com/bootstrap-foo:v1.0.0/bootstrap-components.yaml",
+			want: &ociRepository{},
+		},
+		{
+			name:    "fails for unknown schemes",
+			url:     "ftp://artifacts.example.com/bootstrap-foo/v1.0.0/bootstrap-components.yaml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			repo, err := repositoryFactory(config.NewProvider("foo", tt.url, clusterctlv1.BootstrapProviderType), test.NewFakeVariableClient())
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(repo).To(BeAssignableToTypeOf(tt.want))
+		})
+	}
+}
+
+func Test_IsFileNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "file not found error",
+			err:  newFileNotFoundError("failed to get file %q", "foo"),
+			want: true,
+		},
+		{
+			name: "wrapped file not found error",
+			err:  errors.Wrap(newFileNotFoundError("failed to get file %q", "foo"), "failed to read"),
+			want: true,
+		},
+		{
+			name: "file does not exist on the local file system",
+			err:  errors.Wrap(os.ErrNotExist, "failed to read"),
+			want: true,
+		},
+		{
+			name: "GitHub not found error",
+			err:  errors.Wrap(&github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}, "failed to read"),
+			want: true,
+		},
+		{
+			name: "GitHub authentication error",
+			err:  errors.Wrap(&github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnauthorized}}, "failed to read"),
+			want: false,
+		},
+		{
+			name: "other errors",
+			err:  errors.New("connection refused"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(IsFileNotFound(tt.err)).To(Equal(tt.want))
+		})
+	}
+}
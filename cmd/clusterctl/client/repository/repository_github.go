@@ -248,43 +248,7 @@ func (g *gitHubRepository) getLatestRelease() (string, error) {
 
 	// Search for the latest release according to semantic version ordering.
 	// Releases with tag name that are not in semver format are ignored.
-	var latestTag string
-	var latestPrereleaseTag string
-
-	var latestReleaseVersion *version.Version
-	var latestPrereleaseVersion *version.Version
-
-	for _, v := range versions {
-		sv, err := version.ParseSemantic(v)
-		if err != nil {
-			// discard releases with tags that are not a valid semantic versions (the user can point explicitly to such releases)
-			continue
-		}
-
-		// track prereleases separately
-		if sv.PreRelease() != "" {
-			if latestPrereleaseVersion == nil || latestPrereleaseVersion.LessThan(sv) {
-				latestPrereleaseTag = v
-				latestPrereleaseVersion = sv
-			}
-			continue
-		}
-
-		if latestReleaseVersion == nil || latestReleaseVersion.LessThan(sv) {
-			latestTag = v
-			latestReleaseVersion = sv
-		}
-	}
-
-	// Fall back to returning latest prereleases if no release has been cut or bail if it's also empty
-	if latestTag == "" {
-		if latestPrereleaseTag == "" {
-			return "", errors.New("failed to find releases tagged with a valid semantic version number")
-		}
-
-		return latestPrereleaseTag, nil
-	}
-	return latestTag, nil
+	return latestRelease(versions)
 }
 
 // getReleaseByTag returns the github repository release with a specific tag name.
@@ -328,7 +292,7 @@ func (g *gitHubRepository) downloadFilesFromRelease(release *github.RepositoryRe
 		}
 	}
 	if assetID == nil {
-		return nil, errors.Errorf("failed to get file %q from %q release", fileName, *release.TagName)
+		return nil, newFileNotFoundError("failed to get file %q from %q release", fileName, *release.TagName)
 	}
 
 	reader, redirect, err := client.Repositories.DownloadReleaseAsset(context.TODO(), g.owner, g.repository, *assetID)
@@ -0,0 +1,287 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	gitlabReleasePathSeparator = "/-/releases/"
+	gitlabLatestReleaseLabel   = "latest"
+	gitlabAPIPath              = "/api/v4"
+	gitlabTokenHeader          = "PRIVATE-TOKEN"
+	gitlabNextPageHeader       = "X-Next-Page"
+	gitlabReleasesPerPage      = 100
+)
+
+// gitLabRepository provides support for providers hosted on GitLab.
+//
+// We support GitLab projects that use the release feature to publish artifacts and versions; files are read from
+// the release asset links (https://docs.gitlab.com/ee/user/project/releases/#release-assets), matched by name.
+// The repository URL is expected to be in the form https://{host}/{project-path}/-/releases/{latest|version-tag}/{components.yaml},
+// where project-path can include subgroups.
+type gitLabRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	httpClient            *http.Client
+	scheme                string
+	host                  string
+	projectPath           string
+	defaultVersion        string
+	rootPath              string
+	componentsPath        string
+	token                 string
+}
+
+var _ Repository = &gitLabRepository{}
+
+type gitlabRepositoryOption func(*gitLabRepository)
+
+// injectGitLabHTTPClient allows to override the HTTP client used to talk with the GitLab API.
+func injectGitLabHTTPClient(c *http.Client) gitlabRepositoryOption {
+	return func(g *gitLabRepository) {
+		g.httpClient = c
+	}
+}
+
+// gitlabRelease defines the subset of a GitLab release used by gitLabRepository.
+type gitlabRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  struct {
+		Links []gitlabReleaseLink `json:"links"`
+	} `json:"assets"`
+}
+
+// gitlabReleaseLink defines the subset of a GitLab release asset link used by gitLabRepository.
+type gitlabReleaseLink struct {
+	Name           string `json:"name"`
+	URL            string `json:"url"`
+	DirectAssetURL string `json:"direct_asset_url"`
+}
+
+// DefaultVersion returns defaultVersion field of gitLabRepository struct.
+func (g *gitLabRepository) DefaultVersion() string {
+	return g.defaultVersion
+}
+
+// RootPath returns rootPath field of gitLabRepository struct.
+func (g *gitLabRepository) RootPath() string {
+	return g.rootPath
+}
+
+// ComponentsPath returns componentsPath field of gitLabRepository struct.
+func (g *gitLabRepository) ComponentsPath() string {
+	return g.componentsPath
+}
+
+// GetVersions returns the list of versions that are available in a provider repository.
+// All the pages of the GitLab releases API are read, following the X-Next-Page header.
+func (g *gitLabRepository) GetVersions() ([]string, error) {
+	tags := []string{}
+	for page := "1"; page != ""; {
+		releases := []gitlabRelease{}
+		u := fmt.Sprintf("%s?per_page=%d&page=%s", g.projectAPIURL("releases"), gitlabReleasesPerPage, url.QueryEscape(page))
+		header, err := g.getJSON(u, &releases)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the list of releases")
+		}
+		for _, r := range releases {
+			tags = append(tags, r.TagName)
+		}
+		next := header.Get(gitlabNextPageHeader)
+		if next == page {
+			break
+		}
+		page = next
+	}
+	return semanticVersions(tags), nil
+}
+
+// GetFile returns a file for a given provider version.
+func (g *gitLabRepository) GetFile(version, fileName string) ([]byte, error) {
+	if version == "" {
+		version = g.defaultVersion
+	}
+
+	release := &gitlabRelease{}
+	if _, err := g.getJSON(g.projectAPIURL("releases", version), release); err != nil {
+		return nil, errors.Wrapf(err, "failed to get GitLab release %s", version)
+	}
+
+	// search for the file into the release asset links
+	absoluteFileName := path.Join(g.rootPath, fileName)
+	var assetURL string
+	for _, l := range release.Assets.Links {
+		if l.Name != absoluteFileName {
+			continue
+		}
+		assetURL = l.DirectAssetURL
+		if assetURL == "" {
+			assetURL = l.URL
+		}
+		break
+	}
+	if assetURL == "" {
+		return nil, newFileNotFoundError("failed to get file %q from %q release", fileName, version)
+	}
+
+	content, _, err := g.get(assetURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download file %q from %q release", fileName, version)
+	}
+	return content, nil
+}
+
+// newGitLabRepository returns a gitLabRepository implementation.
+func newGitLabRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...gitlabRepositoryOption) (*gitLabRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != httpsScheme && rURL.Scheme != httpScheme {
+		return nil, errors.New("invalid url: a GitLab repository url should start with https://")
+	}
+
+	// Check if the path is in the expected format, and split it into the project path and release path.
+	urlSplit := strings.SplitN(rURL.Path, gitlabReleasePathSeparator, 2)
+	if len(urlSplit) != 2 || strings.Trim(urlSplit[0], "/") == "" {
+		return nil, errors.New("invalid url: a GitLab repository url should be in the form https://{host}/{project-path}/-/releases/{latest|version-tag}/{components.yaml}")
+	}
+	releaseSplit := strings.SplitN(urlSplit[1], "/", 2)
+	if len(releaseSplit) != 2 || releaseSplit[0] == "" || releaseSplit[1] == "" {
+		return nil, errors.New("invalid url: a GitLab repository url should be in the form https://{host}/{project-path}/-/releases/{latest|version-tag}/{components.yaml}")
+	}
+
+	projectPath := strings.Trim(urlSplit[0], "/")
+	defaultVersion := releaseSplit[0]
+
+	// use path's directory as a rootPath
+	rootPath := path.Dir(releaseSplit[1])
+	// use the file name (if any) as componentsPath
+	componentsPath := getComponentsPath(releaseSplit[1], rootPath)
+
+	repo := &gitLabRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		httpClient:            http.DefaultClient,
+		scheme:                rURL.Scheme,
+		host:                  rURL.Host,
+		projectPath:           projectPath,
+		defaultVersion:        defaultVersion,
+		rootPath:              rootPath,
+		componentsPath:        componentsPath,
+	}
+
+	// process gitlabRepositoryOptions
+	for _, o := range opts {
+		o(repo)
+	}
+
+	if token, err := configVariablesClient.Get(config.GitLabAccessTokenVariable); err == nil {
+		repo.token = token
+	}
+
+	if defaultVersion == gitlabLatestReleaseLabel {
+		versions, err := repo.GetVersions()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get GitLab latest version")
+		}
+		repo.defaultVersion, err = latestRelease(versions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get GitLab latest version")
+		}
+	}
+
+	return repo, nil
+}
+
+// projectAPIURL returns the URL of a GitLab API endpoint for the project.
+func (g *gitLabRepository) projectAPIURL(elems ...string) string {
+	// NB. the project path is used as a project ID, so slashes must be encoded
+	escaped := []string{url.PathEscape(g.projectPath)}
+	for _, e := range elems {
+		escaped = append(escaped, url.PathEscape(e))
+	}
+	return fmt.Sprintf("%s://%s%s/projects/%s", g.scheme, g.host, gitlabAPIPath, strings.Join(escaped, "/"))
+}
+
+// getJSON reads an object from the GitLab API and decodes it; it returns the response header.
+func (g *gitLabRepository) getJSON(u string, into interface{}) (http.Header, error) {
+	content, header, err := g.get(u)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, into); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode response from %s", u)
+	}
+	return header, nil
+}
+
+// get reads a resource from GitLab, handling authentication.
+// The access token is sent only to the GitLab host, also when following redirects, because release
+// asset links can point to external hosts.
+func (g *gitLabRepository) get(u string) ([]byte, http.Header, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to create request for %s", u)
+	}
+	if g.token != "" && req.URL.Host == g.host {
+		req.Header.Set(gitlabTokenHeader, g.token)
+	}
+
+	client := *g.httpClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if req.URL.Host != g.host {
+			req.Header.Del(gitlabTokenHeader)
+		}
+		return nil
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get %s", u)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, errors.Errorf("failed to get %s: unexpected status code %d", u, resp.StatusCode)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read response from %s", u)
+	}
+	return content, resp.Header, nil
+}
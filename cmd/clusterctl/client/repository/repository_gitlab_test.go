@@ -0,0 +1,281 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// newFakeGitLab sets up a test GitLab server hosting the g/sg/p project, with releases v0.4.0, v0.4.1,
+// v0.4.2-alpha and foo, listed in two pages; only v0.4.1 has assets.
+func newFakeGitLab(t *testing.T) *httptest.Server {
+	server := httptest.NewUnstartedServer(nil)
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		// NB. the project ID is an encoded path, so the escaped path is used for matching.
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/g%2Fsg%2Fp/releases":
+			switch r.URL.Query().Get("page") {
+			case "1":
+				w.Header().Set(gitlabNextPageHeader, "2")
+				fmt.Fprint(w, `[{"tag_name": "v0.4.0"}, {"tag_name": "v0.4.1"}]`)
+			case "2":
+				fmt.Fprint(w, `[`)
+				fmt.Fprint(w, `{"tag_name": "v0.4.2-alpha"},`) // prerelease
+				fmt.Fprint(w, `{"tag_name": "foo"}`)           // no semantic version tag
+				fmt.Fprint(w, `]`)
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		case "/api/v4/projects/g%2Fsg%2Fp/releases/v0.4.1":
+			if r.Header.Get(gitlabTokenHeader) != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"tag_name": "v0.4.1", "assets": {"links": [{"name": "file.yaml", "url": "%s/g/sg/p/-/releases/v0.4.1/downloads/file.yaml"}]}}`, server.URL)
+		case "/g/sg/p/-/releases/v0.4.1/downloads/file.yaml":
+			fmt.Fprint(w, "content")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server.StartTLS()
+	return server
+}
+
+func Test_gitLabRepository_newGitLabRepository(t *testing.T) {
+	server := newFakeGitLab(t)
+	defer server.Close()
+
+	type field struct {
+		providerConfig config.Provider
+		variableClient config.VariablesClient
+	}
+	tests := []struct {
+		name               string
+		field              field
+		wantProjectPath    string
+		wantDefaultVersion string
+		wantRootPath       string
+		wantComponentsPath string
+		wantErr            bool
+	}{
+		{
+			name: "can create a new GitLab repo",
+			field: field{
+				providerConfig: config.NewProvider("test", server.URL+"/g/sg/p/-/releases/v0.4.1/path", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantProjectPath:    "g/sg/p",
+			wantDefaultVersion: "v0.4.1",
+			wantRootPath:       ".",
+			wantComponentsPath: "path",
+			wantErr:            false,
+		},
+		{
+			name: "resolves the latest version",
+			field: field{
+				providerConfig: config.NewProvider("test", server.URL+"/g/sg/p/-/releases/latest/path", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantProjectPath:    "g/sg/p",
+			wantDefaultVersion: "v0.4.1",
+			wantRootPath:       ".",
+			wantComponentsPath: "path",
+			wantErr:            false,
+		},
+		{
+			name: "missing variableClient",
+			field: field{
+				providerConfig: config.NewProvider("test", server.URL+"/g/sg/p/-/releases/v0.4.1/path", clusterctlv1.CoreProviderType),
+				variableClient: nil,
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url should be in http(s)",
+			field: field{
+				providerConfig: config.NewProvider("test", "oci://gitlab.example.com/g/p/-/releases/v0.4.1/path", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url should be in https://{host}/{project-path}/-/releases/{latest|version-tag}/{components.yaml} format",
+			field: field{
+				providerConfig: config.NewProvider("test", "https://gitlab.example.com/-/releases/v0.4.1", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			gitLab, err := newGitLabRepository(tt.field.providerConfig, tt.field.variableClient, injectGitLabHTTPClient(server.Client()))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(gitLab.projectPath).To(Equal(tt.wantProjectPath))
+			g.Expect(gitLab.DefaultVersion()).To(Equal(tt.wantDefaultVersion))
+			g.Expect(gitLab.RootPath()).To(Equal(tt.wantRootPath))
+			g.Expect(gitLab.ComponentsPath()).To(Equal(tt.wantComponentsPath))
+		})
+	}
+}
+
+func Test_gitLabRepository_GetVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newFakeGitLab(t)
+	defer server.Close()
+
+	providerConfig := config.NewProvider("test", server.URL+"/g/sg/p/-/releases/v0.4.1/file.yaml", clusterctlv1.CoreProviderType)
+
+	gitLab, err := newGitLabRepository(providerConfig, test.NewFakeVariableClient(), injectGitLabHTTPClient(server.Client()))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	got, err := gitLab.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(ConsistOf("v0.4.0", "v0.4.1", "v0.4.2-alpha"))
+}
+
+func Test_gitLabRepository_GetFile(t *testing.T) {
+	server := newFakeGitLab(t)
+	defer server.Close()
+
+	providerConfig := config.NewProvider("test", server.URL+"/g/sg/p/-/releases/v0.4.1/file.yaml", clusterctlv1.CoreProviderType)
+
+	tests := []struct {
+		name           string
+		variableClient config.VariablesClient
+		release        string
+		fileName       string
+		want           []byte
+		wantErr        bool
+	}{
+		{
+			name:           "Release and file exist",
+			variableClient: test.NewFakeVariableClient().WithVar(config.GitLabAccessTokenVariable, "token"),
+			release:        "v0.4.1",
+			fileName:       "file.yaml",
+			want:           []byte("content"),
+			wantErr:        false,
+		},
+		{
+			name:           "Empty release defaults to the default version",
+			variableClient: test.NewFakeVariableClient().WithVar(config.GitLabAccessTokenVariable, "token"),
+			release:        "",
+			fileName:       "file.yaml",
+			want:           []byte("content"),
+			wantErr:        false,
+		},
+		{
+			name:           "Release does not exist",
+			variableClient: test.NewFakeVariableClient().WithVar(config.GitLabAccessTokenVariable, "token"),
+			release:        "not-a-release",
+			fileName:       "file.yaml",
+			wantErr:        true,
+		},
+		{
+			name:           "File does not exist",
+			variableClient: test.NewFakeVariableClient().WithVar(config.GitLabAccessTokenVariable, "token"),
+			release:        "v0.4.1",
+			fileName:       "404.file",
+			wantErr:        true,
+		},
+		{
+			name:           "Missing token",
+			variableClient: test.NewFakeVariableClient(),
+			release:        "v0.4.1",
+			fileName:       "file.yaml",
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			gitLab, err := newGitLabRepository(providerConfig, tt.variableClient, injectGitLabHTTPClient(server.Client()))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := gitLab.GetFile(tt.release, tt.fileName)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_gitLabRepository_GetFile_TokenIsNotSentToOtherHosts(t *testing.T) {
+	g := NewWithT(t)
+
+	// The asset is hosted on another server, reached through a redirect from the GitLab server.
+	external := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(gitlabTokenHeader) != "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, "external content")
+	}))
+	defer external.Close()
+
+	gitLab := httptest.NewUnstartedServer(nil)
+	gitLab.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/g%2Fp/releases/v0.4.1":
+			fmt.Fprintf(w, `{"tag_name": "v0.4.1", "assets": {"links": [{"name": "direct.yaml", "url": "%s/direct.yaml"}, {"name": "redirect.yaml", "url": "%s/redirect.yaml"}]}}`, external.URL, gitLab.URL)
+		case "/redirect.yaml":
+			http.Redirect(w, r, external.URL+"/redirect.yaml", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	gitLab.StartTLS()
+	defer gitLab.Close()
+
+	providerConfig := config.NewProvider("test", gitLab.URL+"/g/p/-/releases/v0.4.1/direct.yaml", clusterctlv1.CoreProviderType)
+	repo, err := newGitLabRepository(providerConfig, test.NewFakeVariableClient().WithVar(config.GitLabAccessTokenVariable, "token"), injectGitLabHTTPClient(gitLab.Client()))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	got, err := repo.GetFile("v0.4.1", "direct.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal([]byte("external content")))
+
+	got, err = repo.GetFile("v0.4.1", "redirect.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal([]byte("external content")))
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	httpScheme             = "http"
+	httpLatestReleaseLabel = "latest"
+
+	// httpIndexFile is the name of the file listing the versions hosted in an HTTP repository.
+	httpIndexFile = "index.yaml"
+)
+
+// httpRepository provides support for providers hosted on a plain HTTP(S) web server.
+//
+// The web server is expected to host a folder for each version, plus an index file listing the available versions, e.g.
+// {basepath}/index.yaml
+// {basepath}/{version}/{components.yaml}
+//
+// The repository URL is expected to be in the form http(s)://{host}/{basepath}/{latest|version}/{components.yaml}, and the
+// index file is expected to be in the form:
+//
+// versions:
+// - v0.3.0
+// - v0.3.1
+type httpRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	httpClient            *http.Client
+	baseURL               url.URL
+	defaultVersion        string
+	componentsPath        string
+	token                 string
+}
+
+var _ Repository = &httpRepository{}
+
+type httpRepositoryOption func(*httpRepository)
+
+// injectHTTPClient allows to override the HTTP client used to talk with the web server.
+func injectHTTPClient(c *http.Client) httpRepositoryOption {
+	return func(h *httpRepository) {
+		h.httpClient = c
+	}
+}
+
+// httpIndex defines the content of the index file of an HTTP repository.
+type httpIndex struct {
+	Versions []string `json:"versions"`
+}
+
+// DefaultVersion returns defaultVersion field of httpRepository struct.
+func (h *httpRepository) DefaultVersion() string {
+	return h.defaultVersion
+}
+
+// RootPath returns the empty string as it is not applicable to HTTP repositories.
+func (h *httpRepository) RootPath() string {
+	return ""
+}
+
+// ComponentsPath returns componentsPath field of httpRepository struct.
+func (h *httpRepository) ComponentsPath() string {
+	return h.componentsPath
+}
+
+// GetVersions returns the list of versions listed in the index file of the HTTP repository.
+func (h *httpRepository) GetVersions() ([]string, error) {
+	content, err := h.get(httpIndexFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the repository index")
+	}
+
+	index := &httpIndex{}
+	if err := yaml.Unmarshal(content, index); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the repository index")
+	}
+	return semanticVersions(index.Versions), nil
+}
+
+// GetFile returns a file for a given provider version.
+func (h *httpRepository) GetFile(version, fileName string) ([]byte, error) {
+	if version == "" {
+		version = h.defaultVersion
+	}
+
+	content, err := h.get(version, fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get file %q from release %s", fileName, version)
+	}
+	return content, nil
+}
+
+// newHTTPRepository returns a httpRepository implementation.
+func newHTTPRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...httpRepositoryOption) (*httpRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != httpsScheme && rURL.Scheme != httpScheme {
+		return nil, errors.New("invalid url: an HTTP repository url should start with http:// or https://")
+	}
+
+	// Extracts basepath, version, componentsPath from the url
+	// NB. format is {basepath}/{version}/{components.yaml}
+	urlSplit := strings.Split(strings.TrimPrefix(rURL.Path, "/"), "/")
+	if len(urlSplit) < 2 || urlSplit[len(urlSplit)-1] == "" || urlSplit[len(urlSplit)-2] == "" {
+		return nil, errors.New("invalid url: an HTTP repository url should be in the form http(s)://{host}/{basepath}/{latest|version}/{components.yaml}")
+	}
+
+	componentsPath := urlSplit[len(urlSplit)-1]
+	defaultVersion := urlSplit[len(urlSplit)-2]
+
+	baseURL := *rURL
+	baseURL.Path = "/" + strings.Join(urlSplit[:len(urlSplit)-2], "/")
+	baseURL.RawPath = ""
+
+	repo := &httpRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		httpClient:            http.DefaultClient,
+		baseURL:               baseURL,
+		defaultVersion:        defaultVersion,
+		componentsPath:        componentsPath,
+	}
+
+	// process httpRepositoryOptions
+	for _, o := range opts {
+		o(repo)
+	}
+
+	if token, err := configVariablesClient.Get(config.HTTPRepositoryTokenVariable); err == nil {
+		repo.token = token
+	}
+
+	if defaultVersion == httpLatestReleaseLabel {
+		versions, err := repo.GetVersions()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get latest version")
+		}
+		repo.defaultVersion, err = latestRelease(versions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get latest version")
+		}
+	}
+
+	return repo, nil
+}
+
+// get reads a file from the web server, handling authentication.
+func (h *httpRepository) get(elems ...string) ([]byte, error) {
+	u := h.baseURL
+	u.Path = path.Join(append([]string{u.Path}, elems...)...)
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create request for %s", u.String())
+	}
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s", u.String())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, newFileNotFoundError("failed to get %s: not found", u.String())
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to get %s: unexpected status code %d", u.String(), resp.StatusCode)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read response from %s", u.String())
+	}
+	return content, nil
+}
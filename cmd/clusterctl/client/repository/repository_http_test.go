@@ -0,0 +1,212 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// newFakeHTTPRepository sets up a test web server hosting a repository under /repo, with an index listing
+// v0.4.0, v0.4.1, v0.4.2-alpha and foo; only v0.4.1 has files.
+func newFakeHTTPRepository(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repo/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, "versions:\n- v0.4.0\n- v0.4.1\n- v0.4.2-alpha\n- foo\n")
+	})
+	mux.HandleFunc("/repo/v0.4.1/file.yaml", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "content")
+	})
+	return httptest.NewServer(mux)
+}
+
+func Test_httpRepository_newHTTPRepository(t *testing.T) {
+	server := newFakeHTTPRepository(t)
+	defer server.Close()
+
+	type field struct {
+		providerConfig config.Provider
+		variableClient config.VariablesClient
+	}
+	tests := []struct {
+		name               string
+		field              field
+		wantBaseURL        string
+		wantDefaultVersion string
+		wantComponentsPath string
+		wantErr            bool
+	}{
+		{
+			name: "can create a new HTTP repo",
+			field: field{
+				providerConfig: config.NewProvider("test", server.URL+"/repo/v0.4.1/file.yaml", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantBaseURL:        server.URL + "/repo",
+			wantDefaultVersion: "v0.4.1",
+			wantComponentsPath: "file.yaml",
+			wantErr:            false,
+		},
+		{
+			name: "resolves the latest version",
+			field: field{
+				providerConfig: config.NewProvider("test", server.URL+"/repo/latest/file.yaml", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantBaseURL:        server.URL + "/repo",
+			wantDefaultVersion: "v0.4.1",
+			wantComponentsPath: "file.yaml",
+			wantErr:            false,
+		},
+		{
+			name: "missing variableClient",
+			field: field{
+				providerConfig: config.NewProvider("test", server.URL+"/repo/v0.4.1/file.yaml", clusterctlv1.CoreProviderType),
+				variableClient: nil,
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url should be in http(s)",
+			field: field{
+				providerConfig: config.NewProvider("test", "ftp://example.com/repo/v0.4.1/file.yaml", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url should be in http(s)://{host}/{basepath}/{latest|version}/{components.yaml} format",
+			field: field{
+				providerConfig: config.NewProvider("test", server.URL+"/file.yaml", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			repo, err := newHTTPRepository(tt.field.providerConfig, tt.field.variableClient, injectHTTPClient(server.Client()))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(repo.baseURL.String()).To(Equal(tt.wantBaseURL))
+			g.Expect(repo.DefaultVersion()).To(Equal(tt.wantDefaultVersion))
+			g.Expect(repo.ComponentsPath()).To(Equal(tt.wantComponentsPath))
+		})
+	}
+}
+
+func Test_httpRepository_GetVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newFakeHTTPRepository(t)
+	defer server.Close()
+
+	providerConfig := config.NewProvider("test", server.URL+"/repo/v0.4.1/file.yaml", clusterctlv1.CoreProviderType)
+
+	repo, err := newHTTPRepository(providerConfig, test.NewFakeVariableClient(), injectHTTPClient(server.Client()))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	got, err := repo.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(ConsistOf("v0.4.0", "v0.4.1", "v0.4.2-alpha"))
+}
+
+func Test_httpRepository_GetFile(t *testing.T) {
+	server := newFakeHTTPRepository(t)
+	defer server.Close()
+
+	providerConfig := config.NewProvider("test", server.URL+"/repo/v0.4.1/file.yaml", clusterctlv1.CoreProviderType)
+
+	tests := []struct {
+		name           string
+		variableClient config.VariablesClient
+		version        string
+		fileName       string
+		want           []byte
+		wantErr        bool
+	}{
+		{
+			name:           "Version and file exist",
+			variableClient: test.NewFakeVariableClient().WithVar(config.HTTPRepositoryTokenVariable, "token"),
+			version:        "v0.4.1",
+			fileName:       "file.yaml",
+			want:           []byte("content"),
+			wantErr:        false,
+		},
+		{
+			name:           "Empty version defaults to the default version",
+			variableClient: test.NewFakeVariableClient().WithVar(config.HTTPRepositoryTokenVariable, "token"),
+			version:        "",
+			fileName:       "file.yaml",
+			want:           []byte("content"),
+			wantErr:        false,
+		},
+		{
+			name:           "Version does not exist",
+			variableClient: test.NewFakeVariableClient().WithVar(config.HTTPRepositoryTokenVariable, "token"),
+			version:        "v0.4.0",
+			fileName:       "file.yaml",
+			wantErr:        true,
+		},
+		{
+			name:           "Missing token",
+			variableClient: test.NewFakeVariableClient(),
+			version:        "v0.4.1",
+			fileName:       "file.yaml",
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			repo, err := newHTTPRepository(providerConfig, tt.variableClient, injectHTTPClient(server.Client()))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetFile(tt.version, tt.fileName)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,437 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	ociScheme             = "oci"
+	ociLatestReleaseLabel = "latest"
+
+	// ociManifestMediaType is the media type of an OCI image manifest.
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+
+	// ociTitleAnnotation is the layer annotation hosting the file name of an artifact layer;
+	// this is the convention used by OCI artifacts tools like ORAS.
+	ociTitleAnnotation = "org.opencontainers.image.title"
+)
+
+// ociRepository provides support for providers hosted on an OCI registry.
+//
+// Each provider version is pushed to the registry as an OCI artifact tagged with the version; the artifact
+// contains one layer for each file (components YAML, metadata YAML and cluster templates), and the file name
+// is read from the org.opencontainers.image.title layer annotation.
+// The repository URL is expected to be in the form oci://{registry}/{repository}:{latest|version-tag}/{components.yaml}.
+type ociRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	httpClient            *http.Client
+	registry              string
+	repository            string
+	defaultVersion        string
+	rootPath              string
+	componentsPath        string
+	username              string
+	password              string
+	token                 string
+	insecure              bool
+
+	// registryToken is the token issued by the registry authorization service in response to a Bearer
+	// challenge; once obtained, it is used for all the following requests.
+	registryToken string
+}
+
+var _ Repository = &ociRepository{}
+
+type ociRepositoryOption func(*ociRepository)
+
+// injectOCIHTTPClient allows to override the HTTP client used to talk with the registry.
+func injectOCIHTTPClient(c *http.Client) ociRepositoryOption {
+	return func(r *ociRepository) {
+		r.httpClient = c
+	}
+}
+
+// ociManifest defines the subset of an OCI image manifest used by ociRepository.
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+// ociDescriptor defines the subset of an OCI content descriptor used by ociRepository.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociTagList defines the response of the registry tag list API.
+type ociTagList struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// ociTokenResponse defines the response of a registry authorization service; both the token and
+// the access_token fields are used by registries in the wild.
+type ociTokenResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// DefaultVersion returns defaultVersion field of ociRepository struct.
+func (r *ociRepository) DefaultVersion() string {
+	return r.defaultVersion
+}
+
+// RootPath returns rootPath field of ociRepository struct.
+func (r *ociRepository) RootPath() string {
+	return r.rootPath
+}
+
+// ComponentsPath returns componentsPath field of ociRepository struct.
+func (r *ociRepository) ComponentsPath() string {
+	return r.componentsPath
+}
+
+// GetVersions returns the list of versions that are available in the OCI repository.
+// Versions are read from the repository tags, following the Link header when the registry paginates
+// the tag list; tags that are not valid semantic versions are ignored.
+func (r *ociRepository) GetVersions() ([]string, error) {
+	tags := []string{}
+	visited := map[string]bool{}
+	for next := fmt.Sprintf("/v2/%s/tags/list", r.repository); next != "" && !visited[next]; {
+		visited[next] = true
+
+		tagList := &ociTagList{}
+		header, err := r.getJSON(next, "application/json", tagList)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the list of tags for %s/%s", r.registry, r.repository)
+		}
+		tags = append(tags, tagList.Tags...)
+		next = nextLink(header.Get("Link"))
+	}
+	return semanticVersions(tags), nil
+}
+
+// GetFile returns a file for a given provider version.
+func (r *ociRepository) GetFile(version, fileName string) ([]byte, error) {
+	if version == "" {
+		version = r.defaultVersion
+	}
+
+	manifest := &ociManifest{}
+	if _, err := r.getJSON(fmt.Sprintf("/v2/%s/manifests/%s", r.repository, version), ociManifestMediaType, manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to get OCI artifact %s/%s:%s", r.registry, r.repository, version)
+	}
+
+	// search for the file into the artifact layers, retrieving the layer digest
+	absoluteFileName := path.Join(r.rootPath, fileName)
+	var digest string
+	for _, l := range manifest.Layers {
+		if l.Annotations[ociTitleAnnotation] == absoluteFileName {
+			digest = l.Digest
+			break
+		}
+	}
+	if digest == "" {
+		return nil, newFileNotFoundError("failed to get file %q from OCI artifact %s/%s:%s", fileName, r.registry, r.repository, version)
+	}
+
+	content, _, err := r.get(fmt.Sprintf("/v2/%s/blobs/%s", r.repository, digest), "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download file %q from OCI artifact %s/%s:%s", fileName, r.registry, r.repository, version)
+	}
+	return content, nil
+}
+
+// newOCIRepository returns an ociRepository implementation.
+func newOCIRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, opts ...ociRepositoryOption) (*ociRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != ociScheme || rURL.Host == "" {
+		return nil, errors.New("invalid url: an OCI repository url should start with oci://{registry}")
+	}
+
+	// Split the path in {repository}:{version} and {path}/{components.yaml}.
+	// NB. the repository name can't contain ":", so the first ":" in the path is the tag separator.
+	urlPath := strings.TrimPrefix(rURL.Path, "/")
+	tagIndex := strings.Index(urlPath, ":")
+	if tagIndex <= 0 {
+		return nil, errors.New("invalid url: an OCI repository url should be in the form oci://{registry}/{repository}:{latest|version-tag}/{components.yaml}")
+	}
+	repository := urlPath[:tagIndex]
+	tagSplit := strings.SplitN(urlPath[tagIndex+1:], "/", 2)
+	if len(tagSplit) < 2 || tagSplit[0] == "" || tagSplit[1] == "" {
+		return nil, errors.New("invalid url: an OCI repository url should be in the form oci://{registry}/{repository}:{latest|version-tag}/{components.yaml}")
+	}
+	defaultVersion := tagSplit[0]
+
+	// use path's directory as a rootPath
+	rootPath := path.Dir(tagSplit[1])
+	// use the file name (if any) as componentsPath
+	componentsPath := getComponentsPath(tagSplit[1], rootPath)
+
+	repo := &ociRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		httpClient:            http.DefaultClient,
+		registry:              rURL.Host,
+		repository:            repository,
+		defaultVersion:        defaultVersion,
+		rootPath:              rootPath,
+		componentsPath:        componentsPath,
+	}
+
+	// process ociRepositoryOptions
+	for _, o := range opts {
+		o(repo)
+	}
+
+	if token, err := configVariablesClient.Get(config.OCIRegistryTokenVariable); err == nil {
+		repo.token = token
+	}
+	if username, err := configVariablesClient.Get(config.OCIRegistryUsernameVariable); err == nil {
+		repo.username = username
+		if password, err := configVariablesClient.Get(config.OCIRegistryPasswordVariable); err == nil {
+			repo.password = password
+		}
+	}
+	if insecure, err := configVariablesClient.Get(config.OCIRegistryInsecureVariable); err == nil && insecure == "true" {
+		repo.insecure = true
+	}
+
+	if defaultVersion == ociLatestReleaseLabel {
+		versions, err := repo.GetVersions()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get OCI repository latest version")
+		}
+		repo.defaultVersion, err = latestRelease(versions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get OCI repository latest version")
+		}
+	}
+
+	return repo, nil
+}
+
+// getJSON reads an object from the registry API and decodes it; it returns the response header.
+func (r *ociRepository) getJSON(apiPath, accept string, into interface{}) (http.Header, error) {
+	content, header, err := r.get(apiPath, accept)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, into); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode response from %s", apiPath)
+	}
+	return header, nil
+}
+
+// get reads a resource from the registry API, handling authentication; apiPath can include a query, and
+// it can be an absolute URL, as returned by the registry in the Link header.
+// When the registry answers with a Bearer challenge, a token is requested to the authorization service
+// indicated in the challenge, using the configured credentials, and the request is retried with it.
+func (r *ociRepository) get(apiPath, accept string) ([]byte, http.Header, error) {
+	scheme := httpsScheme
+	if r.insecure {
+		scheme = "http"
+	}
+	base := &url.URL{Scheme: scheme, Host: r.registry}
+	ref, err := url.Parse(apiPath)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid registry API path %q", apiPath)
+	}
+	u := base.ResolveReference(ref)
+
+	resp, err := r.do(u, accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+			return nil, nil, errors.Errorf("failed to get %s: unexpected status code %d", u.String(), http.StatusUnauthorized)
+		}
+		if err := r.requestRegistryToken(challenge); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to get %s", u.String())
+		}
+		if resp, err = r.do(u, accept); err != nil {
+			return nil, nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, errors.Errorf("failed to get %s: unexpected status code %d", u.String(), resp.StatusCode)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read response from %s", u.String())
+	}
+	return content, resp.Header, nil
+}
+
+// do sends a GET request to the registry, with the registry token if any, otherwise with the configured credentials.
+func (r *ociRepository) do(u *url.URL, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create request for %s", u.String())
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	// Credentials are sent only to the registry; e.g. blobs can be redirected to a storage service.
+	if u.Host == r.registry {
+		switch {
+		case r.registryToken != "":
+			req.Header.Set("Authorization", "Bearer "+r.registryToken)
+		case r.token != "":
+			req.Header.Set("Authorization", "Bearer "+r.token)
+		case r.username != "":
+			req.SetBasicAuth(r.username, r.password)
+		}
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s", u.String())
+	}
+	return resp, nil
+}
+
+// requestRegistryToken gets a token from the authorization service indicated in a Bearer challenge, e.g.
+// Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:o/r:pull".
+// The username and password, or the configured token as a password, are used to authenticate to the service;
+// without credentials an anonymous token is requested.
+func (r *ociRepository) requestRegistryToken(challenge string) error {
+	params := parseChallengeParams(challenge[len("bearer "):])
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return errors.Errorf("invalid realm in the registry authentication challenge %q", challenge)
+	}
+	if realm.Scheme != httpsScheme && !r.insecure {
+		return errors.Errorf("the registry authorization service %s must use https", realm.String())
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if scope := params["scope"]; scope != "" {
+		query.Set("scope", scope)
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request for %s", realm.String())
+	}
+	switch {
+	case r.username != "":
+		req.SetBasicAuth(r.username, r.password)
+	case r.token != "":
+		req.SetBasicAuth("token", r.token)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get a token from %s", realm.Host)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to get a token from %s: unexpected status code %d", realm.Host, resp.StatusCode)
+	}
+
+	tokenResponse := &ociTokenResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(tokenResponse); err != nil {
+		return errors.Wrapf(err, "failed to decode the token from %s", realm.Host)
+	}
+	r.registryToken = tokenResponse.Token
+	if r.registryToken == "" {
+		r.registryToken = tokenResponse.AccessToken
+	}
+	if r.registryToken == "" {
+		return errors.Errorf("the authorization service %s did not return a token", realm.Host)
+	}
+	return nil
+}
+
+// parseChallengeParams parses the comma separated key="value" parameters of an authentication challenge.
+func parseChallengeParams(s string) map[string]string {
+	params := map[string]string{}
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimLeft(strings.TrimSpace(s), ",") {
+		eq := strings.Index(s, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimSpace(s[eq+1:])
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.Index(s[1:], `"`)
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else {
+			end := strings.Index(s, ",")
+			if end < 0 {
+				end = len(s)
+			}
+			value, s = strings.TrimSpace(s[:end]), s[end:]
+		}
+		params[key] = value
+	}
+	return params
+}
+
+// nextLink returns the target of the rel="next" link in a Link header, e.g.
+// </v2/o/r/tags/list?n=100&last=v0.4.1>; rel="next", or an empty string if there is none.
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		target := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, p := range parts[1:] {
+			if strings.ReplaceAll(strings.TrimSpace(p), " ", "") == `rel="next"` {
+				return target[1 : len(target)-1]
+			}
+		}
+	}
+	return ""
+}
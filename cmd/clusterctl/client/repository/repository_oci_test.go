@@ -0,0 +1,311 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// newFakeOCIRegistry sets up an in-process registry hosting the o/r repository, with an artifact for
+// each of the v0.4.0, v0.4.1, v0.4.2-alpha and foo tags.
+func newFakeOCIRegistry(t *testing.T) (registry string, client *http.Client, teardown func()) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/o/r/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"name": "o/r", "tags": ["v0.4.0", "v0.4.1", "v0.4.2-alpha", "foo"]}`)
+	})
+	mux.HandleFunc("/v2/o/r/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		tag := strings.TrimPrefix(r.URL.Path, "/v2/o/r/manifests/")
+		if tag != "v0.4.0" && tag != "v0.4.1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", ociManifestMediaType)
+		fmt.Fprintf(w, `{"schemaVersion": 2, "layers": [{"mediaType": "application/vnd.cncf.cluster-api.yaml", "digest": "sha256:%s", "annotations": {"%s": "file.yaml"}}]}`, tag, ociTitleAnnotation)
+	})
+	mux.HandleFunc("/v2/o/r/blobs/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprintf(w, "content-%s", strings.TrimPrefix(r.URL.Path, "/v2/o/r/blobs/sha256:"))
+	})
+
+	server := httptest.NewTLSServer(mux)
+	return strings.TrimPrefix(server.URL, "https://"), server.Client(), server.Close
+}
+
+func Test_ociRepository_newOCIRepository(t *testing.T) {
+	registry, client, teardown := newFakeOCIRegistry(t)
+	defer teardown()
+
+	type field struct {
+		providerConfig config.Provider
+		variableClient config.VariablesClient
+	}
+	tests := []struct {
+		name               string
+		field              field
+		wantRepository     string
+		wantDefaultVersion string
+		wantRootPath       string
+		wantComponentsPath string
+		wantErr            bool
+	}{
+		{
+			name: "can create a new OCI repo",
+			field: field{
+				providerConfig: config.NewProvider("test", fmt.Sprintf("oci://%s/o/r:v0.4.1/path/file.yaml", registry), clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantRepository:     "o/r",
+			wantDefaultVersion: "v0.4.1",
+			wantRootPath:       "path",
+			wantComponentsPath: "file.yaml",
+			wantErr:            false,
+		},
+		{
+			name: "resolves the latest version",
+			field: field{
+				providerConfig: config.NewProvider("test", fmt.Sprintf("oci://%s/o/r:latest/file.yaml", registry), clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantRepository:     "o/r",
+			wantDefaultVersion: "v0.4.1",
+			wantRootPath:       ".",
+			wantComponentsPath: "file.yaml",
+			wantErr:            false,
+		},
+		{
+			name: "missing variableClient",
+			field: field{
+				providerConfig: config.NewProvider("test", fmt.Sprintf("oci://%s/o/r:v0.4.1/file.yaml", registry), clusterctlv1.CoreProviderType),
+				variableClient: nil,
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url should be in oci",
+			field: field{
+				providerConfig: config.NewProvider("test", "https://registry.example.com/o/r:v0.4.1/file.yaml", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url should have a tag",
+			field: field{
+				providerConfig: config.NewProvider("test", "oci://registry.example.com/o/r/file.yaml", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider url should have a components file",
+			field: field{
+				providerConfig: config.NewProvider("test", "oci://registry.example.com/o/r:v0.4.1", clusterctlv1.CoreProviderType),
+				variableClient: test.NewFakeVariableClient(),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			oci, err := newOCIRepository(tt.field.providerConfig, tt.field.variableClient, injectOCIHTTPClient(client))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(oci.registry).To(Equal(registry))
+			g.Expect(oci.repository).To(Equal(tt.wantRepository))
+			g.Expect(oci.DefaultVersion()).To(Equal(tt.wantDefaultVersion))
+			g.Expect(oci.RootPath()).To(Equal(tt.wantRootPath))
+			g.Expect(oci.ComponentsPath()).To(Equal(tt.wantComponentsPath))
+		})
+	}
+}
+
+func Test_ociRepository_GetVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	registry, client, teardown := newFakeOCIRegistry(t)
+	defer teardown()
+
+	providerConfig := config.NewProvider("test", fmt.Sprintf("oci://%s/o/r:v0.4.1/file.yaml", registry), clusterctlv1.CoreProviderType)
+
+	oci, err := newOCIRepository(providerConfig, test.NewFakeVariableClient(), injectOCIHTTPClient(client))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	got, err := oci.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(ConsistOf("v0.4.0", "v0.4.1", "v0.4.2-alpha"))
+}
+
+func Test_ociRepository_GetFile(t *testing.T) {
+	registry, client, teardown := newFakeOCIRegistry(t)
+	defer teardown()
+
+	providerConfig := config.NewProvider("test", fmt.Sprintf("oci://%s/o/r:v0.4.1/file.yaml", registry), clusterctlv1.CoreProviderType)
+
+	tests := []struct {
+		name           string
+		variableClient config.VariablesClient
+		version        string
+		fileName       string
+		want           []byte
+		wantErr        bool
+	}{
+		{
+			name:           "Artifact and file exist",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIRegistryTokenVariable, "token"),
+			version:        "v0.4.0",
+			fileName:       "file.yaml",
+			want:           []byte("content-v0.4.0"),
+			wantErr:        false,
+		},
+		{
+			name:           "Empty version defaults to the default version",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIRegistryTokenVariable, "token"),
+			version:        "",
+			fileName:       "file.yaml",
+			want:           []byte("content-v0.4.1"),
+			wantErr:        false,
+		},
+		{
+			name:           "Artifact does not exist",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIRegistryTokenVariable, "token"),
+			version:        "v0.5.0",
+			fileName:       "file.yaml",
+			wantErr:        true,
+		},
+		{
+			name:           "File does not exist",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIRegistryTokenVariable, "token"),
+			version:        "v0.4.1",
+			fileName:       "404.file",
+			wantErr:        true,
+		},
+		{
+			name:           "Missing token",
+			variableClient: test.NewFakeVariableClient(),
+			version:        "v0.4.1",
+			fileName:       "file.yaml",
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			oci, err := newOCIRepository(providerConfig, tt.variableClient, injectOCIHTTPClient(client))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := oci.GetFile(tt.version, tt.fileName)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_ociRepository_BearerChallengeAndPagination(t *testing.T) {
+	g := NewWithT(t)
+
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	// The authorization service issues a registry token to the user configured in clusterctl.
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("service") != "registry" || r.URL.Query().Get("scope") != "repository:o/r:pull" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"token": "registry-token"}`)
+	})
+	// The tag list is paginated, and requires the registry token.
+	mux.HandleFunc("/v2/o/r/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.Header.Get("Authorization") != "Bearer registry-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:o/r:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Query().Get("last") {
+		case "":
+			w.Header().Set("Link", `</v2/o/r/tags/list?n=2&last=v0.4.1>; rel="next"`)
+			fmt.Fprint(w, `{"name": "o/r", "tags": ["v0.4.0", "v0.4.1"]}`)
+		case "v0.4.1":
+			fmt.Fprint(w, `{"name": "o/r", "tags": ["v0.5.0", "foo"]}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+
+	providerConfig := config.NewProvider("test", fmt.Sprintf("oci://%s/o/r:latest/file.yaml", registry), clusterctlv1.CoreProviderType)
+	variableClient := test.NewFakeVariableClient().
+		WithVar(config.OCIRegistryUsernameVariable, "user").
+		WithVar(config.OCIRegistryPasswordVariable, "password")
+
+	oci, err := newOCIRepository(providerConfig, variableClient, injectOCIHTTPClient(server.Client()))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(oci.DefaultVersion()).To(Equal("v0.5.0"))
+
+	got, err := oci.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(ConsistOf("v0.4.0", "v0.4.1", "v0.5.0"))
+
+	_, err = newOCIRepository(providerConfig, test.NewFakeVariableClient(), injectOCIHTTPClient(server.Client()))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func Test_nextLink(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(nextLink(`</v2/o/r/tags/list?n=100&last=v0.4.1>; rel="next"`)).To(Equal("/v2/o/r/tags/list?n=100&last=v0.4.1"))
+	g.Expect(nextLink(`<https://example.com/prev>; rel="prev", <https://example.com/next>; rel="next"`)).To(Equal("https://example.com/next"))
+	g.Expect(nextLink("")).To(BeEmpty())
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// latestRelease returns the latest release in a list of versions, according to semantic version order.
+// Versions that are not in semver format are ignored; prereleases are returned only if no release has been cut.
+func latestRelease(versions []string) (string, error) {
+	var latestTag string
+	var latestPrereleaseTag string
+
+	var latestReleaseVersion *version.Version
+	var latestPrereleaseVersion *version.Version
+
+	for _, v := range versions {
+		sv, err := version.ParseSemantic(v)
+		if err != nil {
+			// discard releases with tags that are not a valid semantic versions (the user can point explicitly to such releases)
+			continue
+		}
+
+		// track prereleases separately
+		if sv.PreRelease() != "" {
+			if latestPrereleaseVersion == nil || latestPrereleaseVersion.LessThan(sv) {
+				latestPrereleaseTag = v
+				latestPrereleaseVersion = sv
+			}
+			continue
+		}
+
+		if latestReleaseVersion == nil || latestReleaseVersion.LessThan(sv) {
+			latestTag = v
+			latestReleaseVersion = sv
+		}
+	}
+
+	// Fall back to returning latest prereleases if no release has been cut or bail if it's also empty
+	if latestTag == "" {
+		if latestPrereleaseTag == "" {
+			return "", errors.New("failed to find releases tagged with a valid semantic version number")
+		}
+
+		return latestPrereleaseTag, nil
+	}
+	return latestTag, nil
+}
+
+// semanticVersions returns the subset of versions that are valid semantic versions.
+func semanticVersions(tags []string) []string {
+	versions := []string{}
+	for _, t := range tags {
+		if _, err := version.ParseSemantic(t); err != nil {
+			// discard tags that are not a valid semantic versions (the user can point explicitly to such releases)
+			continue
+		}
+		versions = append(versions, t)
+	}
+	return versions
+}
@@ -17,11 +17,15 @@ limitations under the License.
 package repository
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
 	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+	goyaml "sigs.k8s.io/yaml"
 )
 
 // Template wraps a YAML file that defines the cluster objects (Cluster, Machines etc.).
@@ -34,6 +38,10 @@ type Template interface {
 	// This value is derived by the template YAML.
 	Variables() []string
 
+	// VariablesSchema defines type, default value, validation rules and description of the template variables.
+	// This value is read from the variables file next to the template YAML, if any; otherwise it is nil.
+	VariablesSchema() *VariablesSchema
+
 	// TargetNamespace where the template objects will be installed.
 	TargetNamespace() string
 
@@ -47,6 +55,7 @@ type Template interface {
 // template implements Template.
 type template struct {
 	variables       []string
+	variablesSchema *VariablesSchema
 	targetNamespace string
 	objs            []unstructured.Unstructured
 }
@@ -58,6 +67,10 @@ func (t *template) Variables() []string {
 	return t.variables
 }
 
+func (t *template) VariablesSchema() *VariablesSchema {
+	return t.variablesSchema
+}
+
 func (t *template) TargetNamespace() string {
 	return t.targetNamespace
 }
@@ -76,6 +89,9 @@ type TemplateInput struct {
 	Processor             yaml.Processor
 	TargetNamespace       string
 	ListVariablesOnly     bool
+
+	// RawVariablesSchema is the content of the variables file next to the template, if any.
+	RawVariablesSchema []byte
 }
 
 // NewTemplate returns a new objects embedding a cluster template YAML file.
@@ -85,14 +101,33 @@ func NewTemplate(input TemplateInput) (*template, error) {
 		return nil, err
 	}
 
+	var variablesSchema *VariablesSchema
+	if len(input.RawVariablesSchema) > 0 {
+		variablesSchema, err = ParseVariablesSchema(input.RawVariablesSchema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if input.ListVariablesOnly {
 		return &template{
 			variables:       variables,
+			variablesSchema: variablesSchema,
 			targetNamespace: input.TargetNamespace,
 		}, nil
 	}
 
-	processedYaml, err := input.Processor.Process(input.RawArtifact, input.ConfigVariablesClient.Get)
+	variablesGetter := input.ConfigVariablesClient.Get
+	if variablesSchema != nil {
+		// Validates all the variables before processing, so users get all the violations at once,
+		// and then applies the default values defined in the schema.
+		if err := variablesSchema.Validate(templateVariableLookup(input)); err != nil {
+			return nil, err
+		}
+		variablesGetter = variablesGetterWithDefaults(input, variablesSchema)
+	}
+
+	processedYaml, err := input.Processor.Process(input.RawArtifact, variablesGetter)
 	if err != nil {
 		return nil, err
 	}
@@ -110,7 +145,58 @@ func NewTemplate(input TemplateInput) (*template, error) {
 
 	return &template{
 		variables:       variables,
+		variablesSchema: variablesSchema,
 		targetNamespace: input.TargetNamespace,
 		objs:            objs,
 	}, nil
 }
+
+// templateVariableLookup returns a func looking up the value of a variable from the config variables client
+// first, and then from the processor, if the processor provides values (e.g. the values file for the GoTemplateProcessor).
+func templateVariableLookup(input TemplateInput) variableLookup {
+	return func(name string) (interface{}, bool) {
+		if v, err := input.ConfigVariablesClient.Get(name); err == nil {
+			return v, true
+		}
+		if vp, ok := input.Processor.(yaml.ValuesProvider); ok {
+			return vp.Value(name)
+		}
+		return nil, false
+	}
+}
+
+// variablesGetterWithDefaults returns a variables getter falling back to the default values defined in the schema
+// for variables without a value.
+func variablesGetterWithDefaults(input TemplateInput, schema *VariablesSchema) func(string) (string, error) {
+	return func(name string) (string, error) {
+		v, err := input.ConfigVariablesClient.Get(name)
+		if err == nil {
+			return v, nil
+		}
+		// NB. values provided by the processor take precedence over defaults; the processor reads them
+		// when the getter returns an error.
+		if vp, ok := input.Processor.(yaml.ValuesProvider); ok {
+			if _, ok := vp.Value(name); ok {
+				return "", err
+			}
+		}
+		if s, ok := schema.Variables[name]; ok && s.Default != nil {
+			return defaultValueString(s.Default)
+		}
+		return "", err
+	}
+}
+
+// defaultValueString returns the default value of a variable as a string; lists and maps are returned in YAML format.
+func defaultValueString(value interface{}) (string, error) {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		out, err := goyaml.Marshal(value)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to convert default value to yaml")
+		}
+		return strings.TrimSuffix(string(out), "\n"), nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
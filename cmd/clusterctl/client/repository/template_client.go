@@ -95,5 +95,49 @@ func (c *templateClient) Get(flavor, targetNamespace string, listVariablesOnly b
 		log.V(1).Info("Using", "Override", name, "Provider", c.provider.ManifestLabel(), "Version", version)
 	}
 
-	return NewTemplate(TemplateInput{rawArtifact, c.configVariablesClient, c.processor, targetNamespace, listVariablesOnly})
+	// read the optional variables file defining the schema of the template variables.
+	rawVariablesSchema, err := c.getVariablesSchema(version, VariablesSchemaFileName(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTemplate(TemplateInput{
+		RawArtifact:           rawArtifact,
+		ConfigVariablesClient: c.configVariablesClient,
+		Processor:             c.processor,
+		TargetNamespace:       targetNamespace,
+		ListVariablesOnly:     listVariablesOnly,
+		RawVariablesSchema:    rawVariablesSchema,
+	})
+}
+
+// getVariablesSchema reads the variables file for a template, reading the local override file if it exists,
+// otherwise reading from the provider repository. Because the variables file is optional, it returns nil if the file
+// does not exist in the provider repository; other errors, e.g. authentication or network errors, are returned.
+func (c *templateClient) getVariablesSchema(version, name string) ([]byte, error) {
+	log := logf.Log
+
+	rawVariablesSchema, err := getLocalOverride(&newOverrideInput{
+		configVariablesClient: c.configVariablesClient,
+		provider:              c.provider,
+		version:               version,
+		filePath:              name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rawVariablesSchema != nil {
+		log.V(1).Info("Using", "Override", name, "Provider", c.provider.ManifestLabel(), "Version", version)
+		return rawVariablesSchema, nil
+	}
+
+	rawVariablesSchema, err = c.repository.GetFile(version, name)
+	if err != nil {
+		if IsFileNotFound(err) {
+			log.V(5).Info("Variables file not available, skipping variables validation", "File", name, "Provider", c.provider.Name(), "Version", version)
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read variables file %q", name)
+	}
+	return rawVariablesSchema, nil
 }
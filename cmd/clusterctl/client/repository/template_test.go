@@ -43,6 +43,7 @@ func Test_newTemplate(t *testing.T) {
 		processor             yaml.Processor
 		targetNamespace       string
 		listVariablesOnly     bool
+		rawVariablesSchema    []byte
 	}
 	type want struct {
 		variables       []string
@@ -84,6 +85,34 @@ func Test_newTemplate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "variable default from the variables schema is used",
+			args: args{
+				rawYaml:               templateMapYaml,
+				configVariablesClient: test.NewFakeVariableClient(),
+				processor:             yaml.NewSimpleProcessor(),
+				targetNamespace:       "ns1",
+				listVariablesOnly:     false,
+				rawVariablesSchema:    []byte(fmt.Sprintf("variables:\n  %s:\n    default: %s\n", variableName, variableValue)),
+			},
+			want: want{
+				variables:       []string{variableName},
+				targetNamespace: "ns1",
+			},
+			wantErr: false,
+		},
+		{
+			name: "returns error if variables do not comply with the variables schema",
+			args: args{
+				rawYaml:               templateMapYaml,
+				configVariablesClient: test.NewFakeVariableClient().WithVar(variableName, variableValue),
+				processor:             yaml.NewSimpleProcessor(),
+				targetNamespace:       "ns1",
+				listVariablesOnly:     false,
+				rawVariablesSchema:    []byte(fmt.Sprintf("variables:\n  %s:\n    type: integer\n", variableName)),
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -95,6 +124,7 @@ func Test_newTemplate(t *testing.T) {
 				Processor:             tt.args.processor,
 				TargetNamespace:       tt.args.targetNamespace,
 				ListVariablesOnly:     tt.args.listVariablesOnly,
+				RawVariablesSchema:    tt.args.rawVariablesSchema,
 			})
 			if tt.wantErr {
 				g.Expect(err).To(HaveOccurred())
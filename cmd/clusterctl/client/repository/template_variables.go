@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// Types supported for cluster template variables.
+const (
+	VariableTypeString  = "string"
+	VariableTypeInteger = "integer"
+	VariableTypeNumber  = "number"
+	VariableTypeBoolean = "boolean"
+	VariableTypeArray   = "array"
+	VariableTypeObject  = "object"
+)
+
+// VariablesSchema defines the variables of a cluster template, as declared by the template
+// author in the variables file next to the template, e.g. cluster-template.variables.yaml.
+type VariablesSchema struct {
+	// Variables defines the variables of the template, by name.
+	Variables map[string]VariableSchema `json:"variables"`
+}
+
+// VariableSchema defines a cluster template variable.
+type VariableSchema struct {
+	// Type of the variable; one of string (default), integer, number, boolean, array or object.
+	Type string `json:"type,omitempty"`
+
+	// Description of the variable.
+	Description string `json:"description,omitempty"`
+
+	// Default value for the variable; variables without a default value are required.
+	Default interface{} `json:"default,omitempty"`
+
+	// Pattern is a regular expression the value of the variable must match.
+	Pattern string `json:"pattern,omitempty"`
+
+	// Enum defines the list of allowed values for the variable.
+	Enum []interface{} `json:"enum,omitempty"`
+}
+
+// VariablesSchemaFileName returns the name of the variables file for a template,
+// e.g. cluster-template-ha.variables.yaml for cluster-template-ha.yaml.
+func VariablesSchemaFileName(templateFileName string) string {
+	return strings.TrimSuffix(templateFileName, path.Ext(templateFileName)) + ".variables.yaml"
+}
+
+// ParseVariablesSchema parses a variables file.
+func ParseVariablesSchema(rawSchema []byte) (*VariablesSchema, error) {
+	schema := &VariablesSchema{}
+	if err := yaml.UnmarshalStrict(rawSchema, schema); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the template variables file")
+	}
+
+	for name, v := range schema.Variables {
+		switch v.Type {
+		case "", VariableTypeString, VariableTypeInteger, VariableTypeNumber, VariableTypeBoolean, VariableTypeArray, VariableTypeObject:
+		default:
+			return nil, errors.Errorf("invalid type %q for variable %s in the template variables file", v.Type, name)
+		}
+		if v.Pattern != "" {
+			if _, err := regexp.Compile(v.Pattern); err != nil {
+				return nil, errors.Wrapf(err, "invalid pattern for variable %s in the template variables file", name)
+			}
+		}
+	}
+	return schema, nil
+}
+
+// Names returns the sorted list of the variables defined in the schema.
+func (s *VariablesSchema) Names() []string {
+	names := make([]string, 0, len(s.Variables))
+	for name := range s.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// variableLookup returns the value of a variable and if the value exists.
+type variableLookup func(name string) (interface{}, bool)
+
+// Validate checks the values of all the variables in the schema, applying defaults, and returns an error
+// listing all the violations at once.
+func (s *VariablesSchema) Validate(lookup variableLookup) error {
+	var violations []string
+	for _, name := range s.Names() {
+		v := s.Variables[name]
+		value, ok := lookup(name)
+		if !ok {
+			if v.Default == nil {
+				violations = append(violations, fmt.Sprintf("%s: value is required", name))
+				continue
+			}
+			value = v.Default
+		}
+		if err := v.validate(value); err != nil {
+			violations = append(violations, fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+
+	if len(violations) > 0 {
+		return &errInvalidVariables{Violations: violations}
+	}
+	return nil
+}
+
+// validate checks a value against the variable schema. Values can be strings, e.g. when
+// read from environment variables or from the clusterctl config file, or typed values, e.g. when read
+// from a values file.
+func (v VariableSchema) validate(value interface{}) error {
+	s, isString := value.(string)
+	switch v.Type {
+	case VariableTypeInteger:
+		if isString {
+			if _, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err != nil {
+				return errors.Errorf("value %q is not an integer", s)
+			}
+			break
+		}
+		switch n := value.(type) {
+		case int, int32, int64:
+		case float64:
+			if n != float64(int64(n)) {
+				return errors.Errorf("value %v is not an integer", value)
+			}
+		default:
+			return errors.Errorf("value %v is not an integer", value)
+		}
+	case VariableTypeNumber:
+		if isString {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err != nil {
+				return errors.Errorf("value %q is not a number", s)
+			}
+			break
+		}
+		switch value.(type) {
+		case int, int32, int64, float32, float64:
+		default:
+			return errors.Errorf("value %v is not a number", value)
+		}
+	case VariableTypeBoolean:
+		if isString {
+			if _, err := strconv.ParseBool(strings.TrimSpace(s)); err != nil {
+				return errors.Errorf("value %q is not a boolean", s)
+			}
+			break
+		}
+		if _, ok := value.(bool); !ok {
+			return errors.Errorf("value %v is not a boolean", value)
+		}
+	case VariableTypeArray:
+		if isString {
+			var l []interface{}
+			if err := yaml.Unmarshal([]byte(s), &l); err != nil {
+				return errors.Errorf("value %q is not an array", s)
+			}
+			break
+		}
+		if _, ok := value.([]interface{}); !ok {
+			return errors.Errorf("value %v is not an array", value)
+		}
+		// NB. pattern and enum apply only to scalar values.
+		return nil
+	case VariableTypeObject:
+		if isString {
+			var m map[string]interface{}
+			if err := yaml.Unmarshal([]byte(s), &m); err != nil {
+				return errors.Errorf("value %q is not an object", s)
+			}
+			break
+		}
+		if _, ok := value.(map[string]interface{}); !ok {
+			return errors.Errorf("value %v is not an object", value)
+		}
+		return nil
+	}
+
+	str := fmt.Sprint(value)
+	if v.Pattern != "" {
+		// NB. the pattern is validated when parsing the schema.
+		if !regexp.MustCompile(v.Pattern).MatchString(str) {
+			return errors.Errorf("value %q does not match the pattern %q", str, v.Pattern)
+		}
+	}
+	if len(v.Enum) > 0 {
+		allowed := make([]string, 0, len(v.Enum))
+		for _, e := range v.Enum {
+			if fmt.Sprint(e) == str {
+				return nil
+			}
+			allowed = append(allowed, fmt.Sprint(e))
+		}
+		return errors.Errorf("value %q is not one of [%s]", str, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+// errInvalidVariables is returned when the values of the template variables do not comply with the variables schema.
+type errInvalidVariables struct {
+	Violations []string
+}
+
+func (e *errInvalidVariables) Error() string {
+	return fmt.Sprintf(
+		"invalid value for template variables:\n  - %s",
+		strings.Join(e.Violations, "\n  - "),
+	)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_VariablesSchemaFileName(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(VariablesSchemaFileName("cluster-template.yaml")).To(Equal("cluster-template.variables.yaml"))
+	g.Expect(VariablesSchemaFileName("cluster-template-ha.yaml")).To(Equal("cluster-template-ha.variables.yaml"))
+	g.Expect(VariablesSchemaFileName("/path/to/cluster-template.yaml")).To(Equal("/path/to/cluster-template.variables.yaml"))
+	g.Expect(VariablesSchemaFileName("template")).To(Equal("template.variables.yaml"))
+}
+
+func Test_ParseVariablesSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		want    *VariablesSchema
+		wantErr bool
+	}{
+		{
+			name: "parses a valid schema",
+			schema: "variables:\n" +
+				"  WORKER_MACHINE_COUNT:\n" +
+				"    type: integer\n" +
+				"    default: 3\n" +
+				"    description: Number of worker machines.\n" +
+				"  INSTANCE_TYPE:\n" +
+				"    enum: [small, large]\n",
+			want: &VariablesSchema{
+				Variables: map[string]VariableSchema{
+					"WORKER_MACHINE_COUNT": {Type: VariableTypeInteger, Default: float64(3), Description: "Number of worker machines."},
+					"INSTANCE_TYPE":        {Enum: []interface{}{"small", "large"}},
+				},
+			},
+		},
+		{
+			name:    "fails for unknown fields",
+			schema:  "variables:\n  FOO:\n    typo: string\n",
+			wantErr: true,
+		},
+		{
+			name:    "fails for unknown types",
+			schema:  "variables:\n  FOO:\n    type: date\n",
+			wantErr: true,
+		},
+		{
+			name:    "fails for invalid patterns",
+			schema:  "variables:\n  FOO:\n    pattern: \"[a-z\"\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := ParseVariablesSchema([]byte(tt.schema))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_VariablesSchema_Validate(t *testing.T) {
+	schema := &VariablesSchema{
+		Variables: map[string]VariableSchema{
+			"CLUSTER_NAME":         {Pattern: "^[a-z0-9-]+$"},
+			"WORKER_MACHINE_COUNT": {Type: VariableTypeInteger, Default: float64(3)},
+			"BASTION":              {Type: VariableTypeBoolean, Default: false},
+			"INSTANCE_TYPE":        {Enum: []interface{}{"small", "large"}, Default: "small"},
+			"POOLS":                {Type: VariableTypeArray, Default: []interface{}{}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		values         map[string]interface{}
+		wantViolations []string
+	}{
+		{
+			name:   "valid values from the variables client",
+			values: map[string]interface{}{"CLUSTER_NAME": "foo", "WORKER_MACHINE_COUNT": "5", "BASTION": "true", "INSTANCE_TYPE": "large", "POOLS": "[a, b]"},
+		},
+		{
+			name:   "valid typed values",
+			values: map[string]interface{}{"CLUSTER_NAME": "foo", "WORKER_MACHINE_COUNT": float64(5), "BASTION": true, "POOLS": []interface{}{"a"}},
+		},
+		{
+			name:   "defaults are applied",
+			values: map[string]interface{}{"CLUSTER_NAME": "foo"},
+		},
+		{
+			name:   "all the violations are returned",
+			values: map[string]interface{}{"WORKER_MACHINE_COUNT": "three", "BASTION": "maybe", "INSTANCE_TYPE": "medium", "POOLS": map[string]interface{}{}},
+			wantViolations: []string{
+				"BASTION: value \"maybe\" is not a boolean",
+				"CLUSTER_NAME: value is required",
+				"INSTANCE_TYPE: value \"medium\" is not one of [small, large]",
+				"POOLS: value map[] is not an array",
+				"WORKER_MACHINE_COUNT: value \"three\" is not an integer",
+			},
+		},
+		{
+			name:   "pattern is validated",
+			values: map[string]interface{}{"CLUSTER_NAME": "Foo_Bar", "WORKER_MACHINE_COUNT": float64(1.5)},
+			wantViolations: []string{
+				"CLUSTER_NAME: value \"Foo_Bar\" does not match the pattern \"^[a-z0-9-]+$\"",
+				"WORKER_MACHINE_COUNT: value 1.5 is not an integer",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := schema.Validate(func(name string) (interface{}, bool) {
+				v, ok := tt.values[name]
+				return v, ok
+			})
+			if len(tt.wantViolations) == 0 {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			e, ok := err.(*errInvalidVariables)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(e.Violations).To(Equal(tt.wantViolations))
+		})
+	}
+}
@@ -19,11 +19,19 @@ package client
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/alpha"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/util"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
 )
 
+// rolloutStatusInterval is the interval for checking the rollout status of cluster-api resources.
+var rolloutStatusInterval = 5 * time.Second
+
 // RolloutOptions carries the base set of options supported by rollout command.
 type RolloutOptions struct {
 	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
@@ -38,6 +46,52 @@ type RolloutOptions struct {
 	Namespace string
 }
 
+// RolloutUndoOptions carries the options supported by rollout undo.
+type RolloutUndoOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Resources for the rollout command
+	Resources []string
+
+	// Namespace where the resource(s) live. If unspecified, the namespace name will be inferred
+	// from the current configuration.
+	Namespace string
+
+	// ToRevision is the revision to rollback to. If 0, the previous revision will be used.
+	ToRevision int64
+}
+
+// RolloutStatusOptions carries the options supported by rollout status.
+type RolloutStatusOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Resources for the rollout command
+	Resources []string
+
+	// Namespace where the resource(s) live. If unspecified, the namespace name will be inferred
+	// from the current configuration.
+	Namespace string
+
+	// Watch the status of the rollout until it's done; if false, the current status is returned.
+	Watch bool
+
+	// Timeout defines how long to wait for the rollout to complete; if 0, there is no timeout.
+	Timeout time.Duration
+}
+
+// RolloutHistory defines the rollout history of a cluster-api resource.
+type RolloutHistory struct {
+	// Resource the history refers to, e.g. machinedeployment/my-md-0.
+	Resource string
+
+	// Revisions of the resource, sorted by revision number.
+	Revisions []alpha.RolloutRevision
+}
+
 func (c *clusterctlClient) RolloutRestart(options RolloutOptions) error {
 	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
 	if err != nil {
@@ -89,6 +143,94 @@ func (c *clusterctlClient) RolloutResume(options RolloutOptions) error {
 	return nil
 }
 
+func (c *clusterctlClient) RolloutHistory(options RolloutOptions) ([]RolloutHistory, error) {
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+	tuples, err := getResourceTuples(clusterClient, options)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]RolloutHistory, 0, len(tuples))
+	for _, t := range tuples {
+		revisions, err := c.alphaClient.Rollout().ObjectHistoryViewer(clusterClient.Proxy(), t, options.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, RolloutHistory{
+			Resource:  fmt.Sprintf("%s/%s", t.Resource, t.Name),
+			Revisions: revisions,
+		})
+	}
+	return history, nil
+}
+
+func (c *clusterctlClient) RolloutUndo(options RolloutUndoOptions) error {
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return err
+	}
+	tuples, err := getResourceTuples(clusterClient, RolloutOptions{
+		Kubeconfig: options.Kubeconfig,
+		Resources:  options.Resources,
+		Namespace:  options.Namespace,
+	})
+	if err != nil {
+		return err
+	}
+	for _, t := range tuples {
+		if err := c.alphaClient.Rollout().ObjectRollbacker(clusterClient.Proxy(), t, options.Namespace, options.ToRevision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *clusterctlClient) RolloutStatus(options RolloutStatusOptions) error {
+	log := logf.Log
+
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return err
+	}
+	tuples, err := getResourceTuples(clusterClient, RolloutOptions{
+		Kubeconfig: options.Kubeconfig,
+		Resources:  options.Resources,
+		Namespace:  options.Namespace,
+	})
+	if err != nil {
+		return err
+	}
+	for _, t := range tuples {
+		var lastMessage string
+		statusFunc := func() (bool, error) {
+			message, done, err := c.alphaClient.Rollout().ObjectStatusViewer(clusterClient.Proxy(), t, options.Namespace)
+			if err != nil {
+				return false, err
+			}
+			if message != lastMessage {
+				log.Info(message)
+				lastMessage = message
+			}
+			return done || !options.Watch, nil
+		}
+
+		if options.Timeout == 0 {
+			err = wait.PollImmediateInfinite(rolloutStatusInterval, statusFunc)
+		} else {
+			err = wait.PollImmediate(rolloutStatusInterval, options.Timeout, statusFunc)
+		}
+		if err == wait.ErrWaitTimeout {
+			return errors.Errorf("timed out waiting for the rollout of %s/%s to complete", t.Resource, t.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func getResourceTuples(clusterClient cluster.Client, options RolloutOptions) ([]util.ResourceTuple, error) {
 	// If the option specifying the Namespace is empty, try to detect it.
 	if options.Namespace == "" {
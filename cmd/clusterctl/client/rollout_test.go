@@ -220,3 +220,85 @@ func Test_clusterctlClient_RolloutResume(t *testing.T) {
 		})
 	}
 }
+
+func Test_clusterctlClient_RolloutHistory(t *testing.T) {
+	tests := genericTestCases()
+	tests = append(tests, rolloutTest{
+		name: "do not return error if machinedeployment found",
+		fields: fields{
+			client: fakeClientForRollout(),
+		},
+		args: args{
+			options: RolloutOptions{
+				Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				Resources:  []string{"machinedeployment/md-1"},
+				Namespace:  "default",
+			},
+		},
+		wantErr: false,
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			history, err := tt.fields.client.RolloutHistory(tt.args.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(history).To(HaveLen(len(tt.args.options.Resources)))
+		})
+	}
+}
+
+func Test_clusterctlClient_RolloutUndo(t *testing.T) {
+	tests := genericTestCases()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := tt.fields.client.RolloutUndo(RolloutUndoOptions{
+				Kubeconfig: tt.args.options.Kubeconfig,
+				Resources:  tt.args.options.Resources,
+				Namespace:  tt.args.options.Namespace,
+			})
+			g.Expect(err).To(HaveOccurred())
+		})
+	}
+}
+
+func Test_clusterctlClient_RolloutStatus(t *testing.T) {
+	tests := genericTestCases()
+	tests = append(tests, rolloutTest{
+		name: "do not return error if machinedeployment found",
+		fields: fields{
+			client: fakeClientForRollout(),
+		},
+		args: args{
+			options: RolloutOptions{
+				Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				Resources:  []string{"machinedeployment/md-1"},
+				Namespace:  "default",
+			},
+		},
+		wantErr: false,
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := tt.fields.client.RolloutStatus(RolloutStatusOptions{
+				Kubeconfig: tt.args.options.Kubeconfig,
+				Resources:  tt.args.options.Resources,
+				Namespace:  tt.args.options.Namespace,
+				Watch:      false,
+			})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// goTemplateFuncs returns the helper functions available in templates rendered by the GoTemplateProcessor.
+// Functions are modeled after the ones provided by Sprig (https://masterminds.github.io/sprig), so they
+// look familiar to users of Helm charts.
+func goTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Functions for managing empty values.
+		"default":  defaultValue,
+		"empty":    empty,
+		"coalesce": coalesce,
+		"ternary":  ternary,
+		"required": required,
+
+		// Functions for strings.
+		"quote":      quote,
+		"squote":     squote,
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       join,
+		"indent":     indent,
+		"nindent":    func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+		"b64enc":     func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec":     b64dec,
+
+		// Functions for numbers.
+		"int":   toInt,
+		"add":   func(a, b interface{}) (int, error) { return applyInt(a, b, func(x, y int) int { return x + y }) },
+		"sub":   func(a, b interface{}) (int, error) { return applyInt(a, b, func(x, y int) int { return x - y }) },
+		"mul":   func(a, b interface{}) (int, error) { return applyInt(a, b, func(x, y int) int { return x * y }) },
+		"until": until,
+
+		// Functions for lists and dictionaries.
+		"list":   func(items ...interface{}) []interface{} { return items },
+		"dict":   dict,
+		"hasKey": func(d map[string]interface{}, key string) bool { _, ok := d[key]; return ok },
+
+		// Functions for encoding.
+		"toYaml": toYaml,
+		"toJson": toJSON,
+	}
+}
+
+// empty returns true if the value is the zero value for its type, e.g. nil, false, 0, "" or an empty list.
+func empty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+// defaultValue returns the given value, or the default if the value is empty.
+func defaultValue(def interface{}, v ...interface{}) interface{} {
+	if len(v) == 0 || empty(v[0]) {
+		return def
+	}
+	return v[0]
+}
+
+// coalesce returns the first value which is not empty.
+func coalesce(v ...interface{}) interface{} {
+	for _, val := range v {
+		if !empty(val) {
+			return val
+		}
+	}
+	return nil
+}
+
+// ternary returns the first value if the condition is true, the second value otherwise.
+func ternary(trueValue, falseValue interface{}, condition bool) interface{} {
+	if condition {
+		return trueValue
+	}
+	return falseValue
+}
+
+// required returns the given value, or an error with the given message if the value is empty.
+func required(msg string, v interface{}) (interface{}, error) {
+	if empty(v) {
+		return nil, errors.New(msg)
+	}
+	return v, nil
+}
+
+func quote(v ...interface{}) string {
+	out := make([]string, 0, len(v))
+	for _, s := range v {
+		if s != nil {
+			out = append(out, strconv.Quote(fmt.Sprint(s)))
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func squote(v ...interface{}) string {
+	out := make([]string, 0, len(v))
+	for _, s := range v {
+		if s != nil {
+			out = append(out, fmt.Sprintf("'%v'", s))
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// join concatenates the items of a list into a string, using the given separator.
+func join(sep string, v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Sprint(v)
+	}
+	out := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		out = append(out, fmt.Sprint(rv.Index(i).Interface()))
+	}
+	return strings.Join(out, sep)
+}
+
+// indent adds the given number of spaces at the beginning of each line.
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+func b64dec(s string) (string, error) {
+	out, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode base64 value")
+	}
+	return string(out), nil
+}
+
+// toInt converts a value to int. It supports numbers and strings, so it can be used with
+// values read both from the variables getter (strings) and from values files (numbers).
+func toInt(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int64:
+		return int(n), nil
+	case float64:
+		return int(n), nil
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to convert %q to int", n)
+		}
+		return i, nil
+	default:
+		return 0, errors.Errorf("failed to convert %v to int", v)
+	}
+}
+
+func applyInt(a, b interface{}, f func(x, y int) int) (int, error) {
+	x, err := toInt(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := toInt(b)
+	if err != nil {
+		return 0, err
+	}
+	return f(x, y), nil
+}
+
+// until returns a list of integers from 0 to n-1, to be used for loops, e.g. {{ range until 3 }}.
+func until(n interface{}) ([]int, error) {
+	count, err := toInt(n)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]int, 0, count)
+	for i := 0; i < count; i++ {
+		out = append(out, i)
+	}
+	return out, nil
+}
+
+// dict returns a dictionary from a list of key/value pairs.
+func dict(v ...interface{}) (map[string]interface{}, error) {
+	if len(v)%2 != 0 {
+		return nil, errors.New("dict requires an even number of arguments")
+	}
+	d := map[string]interface{}{}
+	for i := 0; i < len(v); i += 2 {
+		d[fmt.Sprint(v[i])] = v[i+1]
+	}
+	return d, nil
+}
+
+func toYaml(v interface{}) (string, error) {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to convert value to yaml")
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to convert value to json")
+	}
+	return string(out), nil
+}
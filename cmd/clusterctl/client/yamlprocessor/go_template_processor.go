@@ -0,0 +1,262 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// GoTemplateProcessor is a yaml processor that uses Go text/template to render
+// templates, so templates can use conditionals, loops and the helper functions
+// defined in goTemplateFuncs, e.g. {{ .CLUSTER_NAME | upper }}.
+// Variables are read from the template data using the top-level fields, e.g. .CLUSTER_NAME;
+// values for variables are retrieved first from the variables getter, e.g. os environment
+// variables or the clusterctl config file, then from the values passed using WithValues.
+// See https://golang.org/pkg/text/template for more details.
+type GoTemplateProcessor struct {
+	values map[string]interface{}
+}
+
+var _ Processor = &GoTemplateProcessor{}
+var _ ValuesProvider = &GoTemplateProcessor{}
+
+// GoTemplateProcessorOption is a configuration option supplied to NewGoTemplateProcessor.
+type GoTemplateProcessorOption func(*GoTemplateProcessor)
+
+// WithValues sets the values to be used for the variables that are not provided by the variables getter;
+// differently from the variables getter, values can be of any type, e.g. lists or maps.
+func WithValues(values map[string]interface{}) GoTemplateProcessorOption {
+	return func(tp *GoTemplateProcessor) {
+		tp.values = values
+	}
+}
+
+// NewGoTemplateProcessor returns a GoTemplateProcessor.
+func NewGoTemplateProcessor(opts ...GoTemplateProcessorOption) *GoTemplateProcessor {
+	tp := &GoTemplateProcessor{
+		values: map[string]interface{}{},
+	}
+	for _, o := range opts {
+		o(tp)
+	}
+	return tp
+}
+
+// ReadValues reads the values to be used with a GoTemplateProcessor from a file in YAML format.
+func ReadValues(path string) (map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read values file %q", path)
+	}
+
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse values file %q", path)
+	}
+	return values, nil
+}
+
+// Value returns the value for a variable passed using WithValues, and if the value exists.
+func (tp *GoTemplateProcessor) Value(name string) (interface{}, bool) {
+	v, ok := tp.values[name]
+	return v, ok
+}
+
+// GetTemplateName returns the name of the template that the Go template processor
+// uses. It follows the cluster template naming convention of
+// "cluster-template<-flavor>.yaml".
+func (tp *GoTemplateProcessor) GetTemplateName(_, flavor string) string {
+	name := "cluster-template"
+	if flavor != "" {
+		name = fmt.Sprintf("%s-%s", name, flavor)
+	}
+	name = fmt.Sprintf("%s.yaml", name)
+
+	return name
+}
+
+// GetVariables returns a list of the variables used in the template.
+func (tp *GoTemplateProcessor) GetVariables(rawArtifact []byte) ([]string, error) {
+	_, variables, err := parseGoTemplate(rawArtifact)
+	if err != nil {
+		return nil, err
+	}
+
+	varNames := make([]string, 0, len(variables))
+	for k := range variables {
+		varNames = append(varNames, k)
+	}
+	sort.Strings(varNames)
+	return varNames, nil
+}
+
+// Process returns the final yaml rendered from the template. If there are variables
+// without corresponding values, it will return the raw yaml along with an error.
+// NB. variables are considered optional when used only in conditions, e.g. {{ if .BASTION }},
+// or together with functions managing empty values, e.g. {{ .REPLICAS | default 3 }}.
+func (tp *GoTemplateProcessor) Process(rawArtifact []byte, variablesClient func(string) (string, error)) ([]byte, error) {
+	tmpl, variables, err := parseGoTemplate(rawArtifact)
+	if err != nil {
+		return rawArtifact, err
+	}
+
+	// NB. data is seeded with all the values, so they are available also where variables are not detected,
+	// e.g. in templates defined with {{ define }}.
+	data := make(map[string]interface{}, len(tp.values))
+	for name, v := range tp.values {
+		data[name] = v
+	}
+	var missingVariables []string
+	for name, optional := range variables {
+		if v, err := variablesClient(name); err == nil {
+			data[name] = v
+			continue
+		}
+		if v, ok := tp.values[name]; ok {
+			data[name] = v
+			continue
+		}
+		// keep track of missing variables to return as error later
+		if !optional {
+			missingVariables = append(missingVariables, name)
+		}
+	}
+
+	if len(missingVariables) > 0 {
+		return rawArtifact, &errMissingVariables{missingVariables}
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return rawArtifact, errors.Wrap(err, "failed to render the template")
+	}
+	return out.Bytes(), nil
+}
+
+// parseGoTemplate parses the template and returns a map of the names of the variables used in the template
+// and if they are optional.
+func parseGoTemplate(rawArtifact []byte) (*template.Template, map[string]bool, error) {
+	tmpl, err := template.New("template").Funcs(goTemplateFuncs()).Parse(string(rawArtifact))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse the template")
+	}
+
+	variables := map[string]bool{}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		// NB. in templates defined with {{ define }}, dot is set by the caller, so
+		// only variables referenced from the root of the template data, e.g. $.CLUSTER_NAME, are considered.
+		isRoot := t.Name() == tmpl.Name()
+		inspectGoTemplateNode(t.Tree.Root, isRoot, false, variables)
+	}
+	return tmpl, variables, nil
+}
+
+// optionalGoTemplateFuncs defines the functions that can be used with empty values,
+// so variables passed as arguments are considered optional.
+var optionalGoTemplateFuncs = map[string]bool{
+	"default":  true,
+	"empty":    true,
+	"coalesce": true,
+	"ternary":  true,
+}
+
+// inspectGoTemplateNode recursively walks down a template node and tracks the variables which are
+// fields of the template data, and if the variables are optional.
+// isRoot is true if dot refers to the template data, while optional is true if empty values are allowed.
+func inspectGoTemplateNode(node parse.Node, isRoot, optional bool, variables map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, ln := range n.Nodes {
+			inspectGoTemplateNode(ln, isRoot, optional, variables)
+		}
+	case *parse.ActionNode:
+		inspectGoTemplateNode(n.Pipe, isRoot, optional, variables)
+	case *parse.TemplateNode:
+		inspectGoTemplateNode(n.Pipe, isRoot, optional, variables)
+	case *parse.IfNode:
+		// Nb. conditions can be empty, and dot is not changed.
+		inspectGoTemplateNode(n.Pipe, isRoot, true, variables)
+		inspectGoTemplateNode(n.List, isRoot, optional, variables)
+		inspectGoTemplateNode(n.ElseList, isRoot, optional, variables)
+	case *parse.WithNode:
+		// Nb. conditions can be empty, and dot is set to the condition value.
+		inspectGoTemplateNode(n.Pipe, isRoot, true, variables)
+		inspectGoTemplateNode(n.List, false, optional, variables)
+		inspectGoTemplateNode(n.ElseList, isRoot, optional, variables)
+	case *parse.RangeNode:
+		// Nb. ranges can be empty, and dot is set to the items of the range.
+		inspectGoTemplateNode(n.Pipe, isRoot, true, variables)
+		inspectGoTemplateNode(n.List, false, optional, variables)
+		inspectGoTemplateNode(n.ElseList, isRoot, optional, variables)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			// Nb. the result of a command is passed as the last argument to the next command in the pipeline.
+			cmdOptional := optional || (i+1 < len(n.Cmds) && isOptionalGoTemplateCommand(n.Cmds[i+1]))
+			inspectGoTemplateNode(cmd, isRoot, cmdOptional, variables)
+		}
+	case *parse.CommandNode:
+		argsOptional := optional || isOptionalGoTemplateCommand(n)
+		for _, arg := range n.Args {
+			inspectGoTemplateNode(arg, isRoot, argsOptional, variables)
+		}
+	case *parse.ChainNode:
+		inspectGoTemplateNode(n.Node, isRoot, optional, variables)
+	case *parse.FieldNode:
+		if isRoot {
+			addGoTemplateVariable(n.Ident[0], optional, variables)
+		}
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			addGoTemplateVariable(n.Ident[1], optional, variables)
+		}
+	}
+}
+
+// isOptionalGoTemplateCommand returns true if the command invokes a function that can be used with empty values.
+func isOptionalGoTemplateCommand(cmd *parse.CommandNode) bool {
+	if len(cmd.Args) == 0 {
+		return false
+	}
+	ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+	return ok && optionalGoTemplateFuncs[ident.Ident]
+}
+
+// addGoTemplateVariable tracks a variable; a variable is optional only if it is optional everywhere it is used.
+func addGoTemplateVariable(name string, optional bool, variables map[string]bool) {
+	if wasOptional, ok := variables[name]; ok {
+		variables[name] = wasOptional && optional
+		return
+	}
+	variables[name] = optional
+}
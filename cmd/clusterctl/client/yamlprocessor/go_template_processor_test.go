@@ -0,0 +1,283 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yamlprocessor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func TestGoTemplateProcessor_GetTemplateName(t *testing.T) {
+	g := NewWithT(t)
+	p := NewGoTemplateProcessor()
+	g.Expect(p.GetTemplateName("some-version", "some-flavor")).To(Equal("cluster-template-some-flavor.yaml"))
+	g.Expect(p.GetTemplateName("", "")).To(Equal("cluster-template.yaml"))
+}
+
+func TestGoTemplateProcessor_GetVariables(t *testing.T) {
+	type args struct {
+		data string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "variables used in many places are grouped",
+			args: args{
+				data: "yaml with {{ .A }} {{ .A }} {{ .B | upper }}",
+			},
+			want: []string{"A", "B"},
+		},
+		{
+			name: "variables are sorted",
+			args: args{
+				data: "yaml with {{ .C }}\n{{ .B }}\n{{ .A }}",
+			},
+			want: []string{"A", "B", "C"},
+		},
+		{
+			name: "variables in conditions, functions and pipelines are processed",
+			args: args{
+				data: "{{ if .A }}{{ default \"x\" .B }}{{ end }}{{ .C | default .D }}",
+			},
+			want: []string{"A", "B", "C", "D"},
+		},
+		{
+			name: "fields of the items of a range are not variables",
+			args: args{
+				data: "{{ range .POOLS }}{{ .name }} {{ $.CLUSTER_NAME }}{{ end }}",
+			},
+			want: []string{"CLUSTER_NAME", "POOLS"},
+		},
+		{
+			name: "returns error for invalid templates",
+			args: args{
+				data: "yaml with {{ .A ",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewGoTemplateProcessor()
+			actual, err := p.GetVariables([]byte(tt.args.data))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(actual).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestGoTemplateProcessor_Process(t *testing.T) {
+	type args struct {
+		yaml                  []byte
+		configVariablesClient config.VariablesClient
+		values                map[string]interface{}
+	}
+	tests := []struct {
+		name             string
+		args             args
+		want             []byte
+		wantErr          bool
+		missingVariables []string
+	}{
+		{
+			name: "replaces variables from the variables client",
+			args: args{
+				yaml: []byte("foo {{ .BAR }} {{ .BAR | upper | quote }}"),
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAR", "bar"),
+			},
+			want:    []byte("foo bar \"BAR\""),
+			wantErr: false,
+		},
+		{
+			name: "uses values when the variable doesn't exist in variables client",
+			args: args{
+				yaml: []byte("{{ .BAR }} {{ .BAZ }}"),
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAR", "bar"),
+				values: map[string]interface{}{"BAR": "value-bar", "BAZ": "value-baz"},
+			},
+			want:    []byte("bar value-baz"),
+			wantErr: false,
+		},
+		{
+			name: "supports conditionals and loops",
+			args: args{
+				yaml: []byte("{{ if .BASTION }}bastion\n{{ end }}{{ range .POOLS }}- {{ .name }}: {{ .replicas }}\n{{ end }}{{ range $i := until .COUNT }}{{ $i }}{{ end }}"),
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("COUNT", "3"),
+				values: map[string]interface{}{
+					"POOLS": []interface{}{
+						map[string]interface{}{"name": "pool1", "replicas": float64(2)},
+						map[string]interface{}{"name": "pool2", "replicas": float64(3)},
+					},
+				},
+			},
+			want:    []byte("- pool1: 2\n- pool2: 3\n012"),
+			wantErr: false,
+		},
+		{
+			name: "values are available in templates defined with define",
+			args: args{
+				yaml:                  []byte(`{{ define "pool" }}{{ .name }}-{{ .POOL_SUFFIX }}{{ end }}{{ template "pool" . }}`),
+				configVariablesClient: test.NewFakeVariableClient(),
+				values:                map[string]interface{}{"name": "pool1", "POOL_SUFFIX": "md"},
+			},
+			want:    []byte("pool1-md"),
+			wantErr: false,
+		},
+		{
+			name: "optional variables can be missing",
+			args: args{
+				yaml:                  []byte("{{ .REPLICAS | default 3 }} {{ default \"x\" .FOO }}{{ with .BAR }}{{ . }}{{ end }}"),
+				configVariablesClient: test.NewFakeVariableClient(),
+			},
+			want:    []byte("3 x"),
+			wantErr: false,
+		},
+		{
+			name: "returns error with missing template variables listed (for better ux)",
+			args: args{
+				yaml: []byte("foo {{ .BAR }} {{ .BAZ }} {{ .CAR }} {{ .DAR | default 1 }} {{ if .BAZ }}{{ end }}"),
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("CAR", "car"),
+			},
+			want:             nil,
+			wantErr:          true,
+			missingVariables: []string{"BAR", "BAZ"},
+		},
+		{
+			name: "returns error when the template fails to render",
+			args: args{
+				yaml:                  []byte("{{ required \"BAR must be set\" .BAR }}"),
+				configVariablesClient: test.NewFakeVariableClient().WithVar("BAR", ""),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewGoTemplateProcessor(WithValues(tt.args.values))
+
+			got, err := p.Process(tt.args.yaml, tt.args.configVariablesClient.Get)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				if len(tt.missingVariables) != 0 {
+					e, ok := err.(*errMissingVariables)
+					g.Expect(ok).To(BeTrue())
+					g.Expect(e.Missing).To(ConsistOf(tt.missingVariables))
+				}
+				// we want to ensure that we keep returning the original yaml
+				// as per the intended behavior of Process
+				g.Expect(got).To(Equal(tt.args.yaml))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(string(got)).To(Equal(string(tt.want)))
+		})
+	}
+}
+
+func TestGoTemplateProcessor_funcs(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{
+			name: "string functions",
+			yaml: `{{ "  a-b  " | trim | replace "-" "_" }} {{ "abc" | trimPrefix "a" }} {{ join "," (split "." "a.b.c") }} {{ "foo" | b64enc }}`,
+			want: `a_b bc a,b,c Zm9v`,
+		},
+		{
+			name: "indentation and encoding functions",
+			yaml: `labels:{{ dict "a" "b" | toYaml | nindent 2 }} {{ list 1 2 | toJson }}`,
+			want: "labels:\n  a: b [1,2]",
+		},
+		{
+			name: "number functions",
+			yaml: `{{ add 1 "2" }} {{ sub 5 2 }} {{ mul 2 3 }} {{ int "7" }}`,
+			want: `3 3 6 7`,
+		},
+		{
+			name: "empty values functions",
+			yaml: `{{ coalesce "" "b" }} {{ ternary "yes" "no" true }} {{ empty "" }} {{ hasKey (dict "a" 1) "a" }}`,
+			want: `b yes true true`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewGoTemplateProcessor()
+
+			got, err := p.Process([]byte(tt.yaml), test.NewFakeVariableClient().Get)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(got)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestReadValues(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "yamlprocessor")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "values.yaml")
+	g.Expect(ioutil.WriteFile(path, []byte("BASTION: true\nPOOLS:\n- name: pool1\n"), 0600)).To(Succeed())
+
+	values, err := ReadValues(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(values).To(HaveKeyWithValue("BASTION", true))
+	g.Expect(values).To(HaveKeyWithValue("POOLS", []interface{}{map[string]interface{}{"name": "pool1"}}))
+
+	_, err = ReadValues(filepath.Join(dir, "does-not-exist.yaml"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestGoTemplateProcessor_Value(t *testing.T) {
+	g := NewWithT(t)
+	p := NewGoTemplateProcessor(WithValues(map[string]interface{}{"BASTION": true}))
+
+	v, ok := p.Value("BASTION")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(v).To(Equal(true))
+
+	_, ok = p.Value("POOLS")
+	g.Expect(ok).To(BeFalse())
+}
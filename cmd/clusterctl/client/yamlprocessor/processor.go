@@ -30,3 +30,10 @@ type Processor interface {
 	// yaml with values retrieved from the values getter
 	Process([]byte, func(string) (string, error)) ([]byte, error)
 }
+
+// ValuesProvider defines the methods of processors providing values for the template variables
+// in addition to the values retrieved from the values getter, e.g. the GoTemplateProcessor.
+type ValuesProvider interface {
+	// Value returns the value for a variable, and if the value exists.
+	Value(name string) (interface{}, bool)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type backupOptions struct {
+	fromKubeconfig        string
+	fromKubeconfigContext string
+	namespace             string
+	path                  string
+}
+
+var buo = &backupOptions{}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Backup Cluster API objects and all dependencies from a management cluster.",
+	Long: LongDesc(`
+		Backup Cluster API objects and all dependencies from a management cluster to a directory or to a tarball.
+
+		The objects saved can be restored into a management cluster using clusterctl restore.`),
+
+	Example: Examples(`
+		Backup Cluster API objects and all dependencies from a management cluster to a directory.
+		clusterctl backup --path=./backup
+
+		Backup Cluster API objects and all dependencies from a management cluster to a tarball.
+		clusterctl backup --path=./backup.tar.gz`),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBackup()
+	},
+}
+
+func init() {
+	backupCmd.Flags().StringVar(&buo.fromKubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file for the source management cluster. If unspecified, default discovery rules apply.")
+	backupCmd.Flags().StringVar(&buo.fromKubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file for the source management cluster. If empty, current context will be used.")
+	backupCmd.Flags().StringVarP(&buo.namespace, "namespace", "n", "",
+		"The namespace where the workload cluster is hosted. If unspecified, the current context's namespace is used.")
+	backupCmd.Flags().StringVar(&buo.path, "path", "",
+		"Path to the directory where Cluster API objects will be saved; if the path ends with .tar.gz or .tgz, objects will be saved to a tarball.")
+
+	RootCmd.AddCommand(backupCmd)
+}
+
+func runBackup() error {
+	if buo.path == "" {
+		return errors.New("please specify a backup path using the --path flag")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Backup(client.BackupOptions{
+		FromKubeconfig: client.Kubeconfig{Path: buo.fromKubeconfig, Context: buo.fromKubeconfigContext},
+		Namespace:      buo.namespace,
+		Path:           buo.path,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
@@ -19,10 +19,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
 )
 
 type configClusterOptions struct {
@@ -41,11 +44,19 @@ type configClusterOptions struct {
 	configMapName      string
 	configMapDataKey   string
 
+	templateEngine string
+	valuesFile     string
+
 	listVariables bool
 }
 
 var cc = &configClusterOptions{}
 
+const (
+	simpleTemplateEngine = "simple"
+	goTemplateEngine     = "go-template"
+)
+
 var configClusterClusterCmd = &cobra.Command{
 	Use:   "cluster",
 	Short: "Generate templates for creating workload clusters.",
@@ -93,44 +104,55 @@ var configClusterClusterCmd = &cobra.Command{
 }
 
 func init() {
-	configClusterClusterCmd.Flags().StringVar(&cc.kubeconfig, "kubeconfig", "",
+	addClusterTemplateFlags(configClusterClusterCmd)
+
+	configCmd.AddCommand(configClusterClusterCmd)
+}
+
+// addClusterTemplateFlags adds the flags for generating templates for creating workload clusters to a command.
+func addClusterTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&cc.kubeconfig, "kubeconfig", "",
 		"Path to a kubeconfig file to use for the management cluster. If empty, default discovery rules apply.")
-	configClusterClusterCmd.Flags().StringVar(&cc.kubeconfigContext, "kubeconfig-context", "",
+	cmd.Flags().StringVar(&cc.kubeconfigContext, "kubeconfig-context", "",
 		"Context to be used within the kubeconfig file. If empty, current context will be used.")
 
 	// flags for the template variables
-	configClusterClusterCmd.Flags().StringVarP(&cc.targetNamespace, "target-namespace", "n", "",
+	cmd.Flags().StringVarP(&cc.targetNamespace, "target-namespace", "n", "",
 		"The namespace to use for the workload cluster. If unspecified, the current namespace will be used.")
-	configClusterClusterCmd.Flags().StringVar(&cc.kubernetesVersion, "kubernetes-version", "",
+	cmd.Flags().StringVar(&cc.kubernetesVersion, "kubernetes-version", "",
 		"The Kubernetes version to use for the workload cluster. If unspecified, the value from OS environment variables or the .cluster-api/clusterctl.yaml config file will be used.")
-	configClusterClusterCmd.Flags().Int64Var(&cc.controlPlaneMachineCount, "control-plane-machine-count", 1,
+	cmd.Flags().Int64Var(&cc.controlPlaneMachineCount, "control-plane-machine-count", 1,
 		"The number of control plane machines for the workload cluster.")
-	configClusterClusterCmd.Flags().Int64Var(&cc.workerMachineCount, "worker-machine-count", 0,
+	cmd.Flags().Int64Var(&cc.workerMachineCount, "worker-machine-count", 0,
 		"The number of worker machines for the workload cluster.")
 
 	// flags for the repository source
-	configClusterClusterCmd.Flags().StringVarP(&cc.infrastructureProvider, "infrastructure", "i", "",
+	cmd.Flags().StringVarP(&cc.infrastructureProvider, "infrastructure", "i", "",
 		"The infrastructure provider to read the workload cluster template from. If unspecified, the default infrastructure provider will be used.")
-	configClusterClusterCmd.Flags().StringVarP(&cc.flavor, "flavor", "f", "",
+	cmd.Flags().StringVarP(&cc.flavor, "flavor", "f", "",
 		"The workload cluster template variant to be used when reading from the infrastructure provider repository. If unspecified, the default cluster template will be used.")
 
 	// flags for the url source
-	configClusterClusterCmd.Flags().StringVar(&cc.url, "from", "",
+	cmd.Flags().StringVar(&cc.url, "from", "",
 		"The URL to read the workload cluster template from. If unspecified, the infrastructure provider repository URL will be used")
 
 	// flags for the config map source
-	configClusterClusterCmd.Flags().StringVar(&cc.configMapName, "from-config-map", "",
+	cmd.Flags().StringVar(&cc.configMapName, "from-config-map", "",
 		"The ConfigMap to read the workload cluster template from. This can be used as alternative to read from the provider repository or from an URL")
-	configClusterClusterCmd.Flags().StringVar(&cc.configMapNamespace, "from-config-map-namespace", "",
+	cmd.Flags().StringVar(&cc.configMapNamespace, "from-config-map-namespace", "",
 		"The namespace where the ConfigMap exists. If unspecified, the current namespace will be used")
-	configClusterClusterCmd.Flags().StringVar(&cc.configMapDataKey, "from-config-map-key", "",
+	cmd.Flags().StringVar(&cc.configMapDataKey, "from-config-map-key", "",
 		fmt.Sprintf("The ConfigMap.Data key where the workload cluster template is hosted. If unspecified, %q will be used", client.DefaultCustomTemplateConfigMapKey))
 
+	// flags for the template processor
+	cmd.Flags().StringVar(&cc.templateEngine, "template-engine", "",
+		fmt.Sprintf("The engine used to render the workload cluster template, one of %q or %q. If unspecified, %q is used when a values file is set, otherwise %q.", simpleTemplateEngine, goTemplateEngine, goTemplateEngine, simpleTemplateEngine))
+	cmd.Flags().StringVar(&cc.valuesFile, "values", "",
+		"Path to a values file in YAML format, to be used with the go-template engine for the variables not set in OS environment variables or the .cluster-api/clusterctl.yaml config file.")
+
 	// other flags
-	configClusterClusterCmd.Flags().BoolVar(&cc.listVariables, "list-variables", false,
+	cmd.Flags().BoolVar(&cc.listVariables, "list-variables", false,
 		"Returns the list of variables expected by the template instead of the template yaml")
-
-	configCmd.AddCommand(configClusterClusterCmd)
 }
 
 func runGetClusterTemplate(cmd *cobra.Command, name string) error {
@@ -154,6 +176,12 @@ func runGetClusterTemplate(cmd *cobra.Command, name string) error {
 		templateOptions.WorkerMachineCount = &cc.workerMachineCount
 	}
 
+	yamlProcessor, err := getTemplateProcessor(cc.templateEngine, cc.valuesFile)
+	if err != nil {
+		return err
+	}
+	templateOptions.YamlProcessor = yamlProcessor
+
 	if cc.url != "" {
 		templateOptions.URLSource = &client.URLSourceOptions{
 			URL: cc.url,
@@ -188,16 +216,72 @@ func runGetClusterTemplate(cmd *cobra.Command, name string) error {
 }
 
 func templateListVariablesOutput(template client.Template) error {
-	if len(template.Variables()) > 0 {
+	schema := template.VariablesSchema()
+	if schema == nil {
+		if len(template.Variables()) > 0 {
+			fmt.Println("Variables:")
+			for _, v := range template.Variables() {
+				fmt.Printf("  - %s\n", v)
+			}
+		}
+		fmt.Println()
+		return nil
+	}
+
+	// If the template has a variables schema, prints type, default value, validation rules and description for each variable.
+	// NB. variables used in the template but not defined in the schema are printed without details.
+	names := schema.Names()
+	for _, v := range template.Variables() {
+		if _, ok := schema.Variables[v]; !ok {
+			names = append(names, v)
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) > 0 {
 		fmt.Println("Variables:")
-		for _, v := range template.Variables() {
-			fmt.Printf("  - %s\n", v)
+		for _, name := range names {
+			v, ok := schema.Variables[name]
+			if !ok {
+				fmt.Printf("  - %s\n", name)
+				continue
+			}
+			fmt.Printf("  - %s (%s)\n", name, templateVariableDetails(client.VariableSchema(v)))
+			if v.Description != "" {
+				fmt.Printf("      %s\n", v.Description)
+			}
 		}
 	}
 	fmt.Println()
 	return nil
 }
 
+// templateVariableDetails returns a short summary of type, default value and validation rules of a template variable,
+// e.g. "integer, default: 3" or "string, required, one of: [small, large]".
+func templateVariableDetails(v client.VariableSchema) string {
+	typ := v.Type
+	if typ == "" {
+		typ = "string"
+	}
+	details := []string{typ}
+	if v.Default != nil {
+		details = append(details, fmt.Sprintf("default: %v", v.Default))
+	} else {
+		details = append(details, "required")
+	}
+	if v.Pattern != "" {
+		details = append(details, fmt.Sprintf("pattern: %s", v.Pattern))
+	}
+	if len(v.Enum) > 0 {
+		enum := make([]string, 0, len(v.Enum))
+		for _, e := range v.Enum {
+			enum = append(enum, fmt.Sprint(e))
+		}
+		details = append(details, fmt.Sprintf("one of: [%s]", strings.Join(enum, ", ")))
+	}
+	return strings.Join(details, ", ")
+}
+
 func templateYAMLOutput(template client.Template) error {
 	yaml, err := template.Yaml()
 	if err != nil {
@@ -210,3 +294,34 @@ func templateYAMLOutput(template client.Template) error {
 	}
 	return nil
 }
+
+// getTemplateProcessor returns the yaml processor for a template engine; if the engine is not specified, the go-template
+// engine is used when a values file is set, otherwise the simple engine.
+// NB. a nil processor is returned for the simple engine, so the default processor is used.
+func getTemplateProcessor(engine, valuesFile string) (yamlprocessor.Processor, error) {
+	if engine == "" {
+		engine = simpleTemplateEngine
+		if valuesFile != "" {
+			engine = goTemplateEngine
+		}
+	}
+
+	switch engine {
+	case simpleTemplateEngine:
+		if valuesFile != "" {
+			return nil, errors.Errorf("a values file can be used only with the %q template engine", goTemplateEngine)
+		}
+		return nil, nil
+	case goTemplateEngine:
+		values := map[string]interface{}{}
+		if valuesFile != "" {
+			var err error
+			if values, err = yamlprocessor.ReadValues(valuesFile); err != nil {
+				return nil, err
+			}
+		}
+		return yamlprocessor.NewGoTemplateProcessor(yamlprocessor.WithValues(values)), nil
+	default:
+		return nil, errors.Errorf("invalid template engine %q, must be one of %q or %q", engine, simpleTemplateEngine, goTemplateEngine)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+)
+
+func Test_getTemplateProcessor(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "clusterctl")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+	valuesFile := filepath.Join(dir, "values.yaml")
+	g.Expect(ioutil.WriteFile(valuesFile, []byte("FOO: bar\n"), 0600)).To(Succeed())
+
+	tests := []struct {
+		name       string
+		engine     string
+		valuesFile string
+		wantGo     bool
+		wantErr    bool
+	}{
+		{
+			name:   "defaults to the simple engine",
+			wantGo: false,
+		},
+		{
+			name:       "defaults to the go-template engine with a values file",
+			valuesFile: valuesFile,
+			wantGo:     true,
+		},
+		{
+			name:   "selects the go-template engine without a values file",
+			engine: goTemplateEngine,
+			wantGo: true,
+		},
+		{
+			name:       "rejects a values file with the simple engine",
+			engine:     simpleTemplateEngine,
+			valuesFile: valuesFile,
+			wantErr:    true,
+		},
+		{
+			name:    "rejects unknown engines",
+			engine:  "jinja",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := getTemplateProcessor(tt.engine, tt.valuesFile)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			if !tt.wantGo {
+				g.Expect(got).To(BeNil())
+				return
+			}
+			g.Expect(got).To(BeAssignableToTypeOf(&yamlprocessor.GoTemplateProcessor{}))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var generateClusterClusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "Generate templates for creating workload clusters.",
+	Long: LongDesc(`
+		Generate templates for creating workload clusters.
+
+		clusterctl ships with a list of known providers; if necessary, edit
+		$HOME/.cluster-api/clusterctl.yaml to add new provider or to customize existing ones.
+
+		Each provider configuration links to a repository; clusterctl uses this information
+		to fetch templates when creating a new cluster.
+
+		Templates are processed by replacing variables in the format ${VAR}; when a values file
+		is provided, templates are instead rendered as Go templates, so they can use conditionals,
+		loops and helper functions.`),
+
+	Example: Examples(`
+		# Generates a configuration file for creating workload clusters using
+		# the pre-installed infrastructure and bootstrap providers.
+		clusterctl generate cluster my-cluster
+
+		# Generates a configuration file for creating workload clusters using
+		# a specific version of the AWS infrastructure provider.
+		clusterctl generate cluster my-cluster --infrastructure=aws:v0.4.1
+
+		# Generates a configuration file for creating workload clusters rendering
+		# a Go template stored locally, using the values from a values file.
+		clusterctl generate cluster my-cluster --from ~/workspace/cluster-template.yaml --values ~/workspace/values.yaml
+
+		# Prints the list of variables used in a Go template.
+		clusterctl generate cluster my-cluster --from ~/workspace/cluster-template.yaml --values ~/workspace/values.yaml --list-variables`),
+
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGetClusterTemplate(cmd, args[0])
+	},
+}
+
+func init() {
+	addClusterTemplateFlags(generateClusterClusterCmd)
+
+	generateCmd.AddCommand(generateClusterClusterCmd)
+}
@@ -17,9 +17,13 @@ limitations under the License.
 package cmd
 
 import (
+	"path/filepath"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/homedir"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 )
 
 type moveOptions struct {
@@ -28,7 +32,12 @@ type moveOptions struct {
 	toKubeconfig          string
 	toKubeconfigContext   string
 	namespace             string
+	clusterName           string
+	clusterSelector       string
 	dryRun                bool
+	journal               string
+	resume                bool
+	rollback              bool
 }
 
 var mo = &moveOptions{}
@@ -39,11 +48,27 @@ var moveCmd = &cobra.Command{
 	Long: LongDesc(`
 		Move Cluster API objects and all dependencies between management clusters.
 
-		Note: The destination cluster MUST have the required provider components installed.`),
+		Note: The destination cluster MUST have the required provider components installed.
+
+		The progress of the move is recorded in a journal, so a move that fails can be resumed,
+		or rolled back if it failed before starting to delete objects from the source management cluster.`),
 
 	Example: Examples(`
 		Move Cluster API objects and all dependencies between management clusters.
-		clusterctl move --to-kubeconfig=target-kubeconfig.yaml`),
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml
+
+		Move a single Cluster and all its dependencies, leaving the other Clusters in the namespace in the source management cluster.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --cluster=my-cluster
+
+		Move the Clusters with the given labels and all their dependencies.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --selector=region=eu-west
+
+		Resume a move that failed, continuing from the last completed step.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --resume
+
+		Roll back a move that failed, deleting the objects created in the destination management cluster
+		and unpausing the source management cluster.
+		clusterctl move --to-kubeconfig=target-kubeconfig.yaml --rollback`),
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runMove()
@@ -61,8 +86,18 @@ func init() {
 		"Context to be used within the kubeconfig file for the destination management cluster. If empty, current context will be used.")
 	moveCmd.Flags().StringVarP(&mo.namespace, "namespace", "n", "",
 		"The namespace where the workload cluster is hosted. If unspecified, the current context's namespace is used.")
+	moveCmd.Flags().StringVar(&mo.clusterName, "cluster", "",
+		"The name of the Cluster to be moved. If unspecified, all the Clusters in the namespace are moved.")
+	moveCmd.Flags().StringVarP(&mo.clusterSelector, "selector", "l", "",
+		"Label selector for the Clusters to be moved, e.g. -l key1=value1,key2=value2. If unspecified, all the Clusters in the namespace are moved.")
 	moveCmd.Flags().BoolVar(&mo.dryRun, "dry-run", false,
 		"Enable dry run, don't really perform the move actions")
+	moveCmd.Flags().StringVar(&mo.journal, "journal", filepath.Join(homedir.HomeDir(), config.ConfigFolder, "move-journal.yaml"),
+		"Path to the file where the progress of the move is recorded.")
+	moveCmd.Flags().BoolVar(&mo.resume, "resume", false,
+		"Resume the move recorded in the journal.")
+	moveCmd.Flags().BoolVar(&mo.rollback, "rollback", false,
+		"Roll back the move recorded in the journal, deleting the objects created in the destination management cluster.")
 
 	RootCmd.AddCommand(moveCmd)
 }
@@ -79,10 +114,15 @@ func runMove() error {
 	}
 
 	if err := c.Move(client.MoveOptions{
-		FromKubeconfig: client.Kubeconfig{Path: mo.fromKubeconfig, Context: mo.fromKubeconfigContext},
-		ToKubeconfig:   client.Kubeconfig{Path: mo.toKubeconfig, Context: mo.toKubeconfigContext},
-		Namespace:      mo.namespace,
-		DryRun:         mo.dryRun,
+		FromKubeconfig:  client.Kubeconfig{Path: mo.fromKubeconfig, Context: mo.fromKubeconfigContext},
+		ToKubeconfig:    client.Kubeconfig{Path: mo.toKubeconfig, Context: mo.toKubeconfigContext},
+		Namespace:       mo.namespace,
+		ClusterName:     mo.clusterName,
+		ClusterSelector: mo.clusterSelector,
+		DryRun:          mo.dryRun,
+		JournalPath:     mo.journal,
+		Resume:          mo.resume,
+		Rollback:        mo.rollback,
 	}); err != nil {
 		return err
 	}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type restoreOptions struct {
+	toKubeconfig        string
+	toKubeconfigContext string
+	path                string
+}
+
+var ro = &restoreOptions{}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore Cluster API objects and all dependencies into a management cluster.",
+	Long: LongDesc(`
+		Restore Cluster API objects and all dependencies saved using clusterctl backup into a management cluster.
+
+		Note: The destination cluster MUST have the required provider components installed.`),
+
+	Example: Examples(`
+		Restore Cluster API objects and all dependencies from a directory into a management cluster.
+		clusterctl restore --path=./backup --kubeconfig=target-kubeconfig.yaml`),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRestore()
+	},
+}
+
+func init() {
+	restoreCmd.Flags().StringVar(&ro.toKubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file for the destination management cluster. If unspecified, default discovery rules apply.")
+	restoreCmd.Flags().StringVar(&ro.toKubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file for the destination management cluster. If empty, current context will be used.")
+	restoreCmd.Flags().StringVar(&ro.path, "path", "",
+		"Path to the directory or to the tarball where Cluster API objects were saved by clusterctl backup.")
+
+	RootCmd.AddCommand(restoreCmd)
+}
+
+func runRestore() error {
+	if ro.path == "" {
+		return errors.New("please specify a backup path using the --path flag")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Restore(client.RestoreOptions{
+		ToKubeconfig: client.Kubeconfig{Path: ro.toKubeconfig, Context: ro.toKubeconfigContext},
+		Path:         ro.path,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
@@ -37,7 +37,16 @@ var (
 		clusterctl alpha rollout pause machinedeployment/my-md-0
 
 		# Resume an already paused deployment
-		clusterctl alpha rollout resume machinedeployment/my-md-0`)
+		clusterctl alpha rollout resume machinedeployment/my-md-0
+
+		# View the rollout history of a machinedeployment
+		clusterctl alpha rollout history machinedeployment/my-md-0
+
+		# Rollback to the previous revision of a machinedeployment
+		clusterctl alpha rollout undo machinedeployment/my-md-0
+
+		# Wait for the rollout of a machinedeployment to complete
+		clusterctl alpha rollout status machinedeployment/my-md-0`)
 
 	rolloutCmd = &cobra.Command{
 		Use:     "rollout SUBCOMMAND",
@@ -52,4 +61,7 @@ func init() {
 	rolloutCmd.AddCommand(rollout.NewCmdRolloutRestart(cfgFile))
 	rolloutCmd.AddCommand(rollout.NewCmdRolloutPause(cfgFile))
 	rolloutCmd.AddCommand(rollout.NewCmdRolloutResume(cfgFile))
+	rolloutCmd.AddCommand(rollout.NewCmdRolloutHistory(cfgFile))
+	rolloutCmd.AddCommand(rollout.NewCmdRolloutUndo(cfgFile))
+	rolloutCmd.AddCommand(rollout.NewCmdRolloutStatus(cfgFile))
 }
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+	"sigs.k8s.io/yaml"
+)
+
+// historyOptions is the start of the data required to perform the operation.
+type historyOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	resources         []string
+	namespace         string
+	revision          int64
+}
+
+var historyOpt = &historyOptions{}
+
+var (
+	historyLong = templates.LongDesc(`
+		View the rollout history of cluster-api resources.
+
+	        Revisions are listed together with the changes to the machine template compared to the previous revision. Currently only MachineDeployments support rollout history.`)
+
+	historyExample = templates.Examples(`
+		# View the rollout history of a machinedeployment
+		clusterctl alpha rollout history machinedeployment/my-md-0
+
+		# View the details of the revision 3 of a machinedeployment
+		clusterctl alpha rollout history machinedeployment/my-md-0 --revision=3`)
+)
+
+// NewCmdRolloutHistory returns a Command instance for 'rollout history' sub command
+func NewCmdRolloutHistory(cfgFile string) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:                   "history RESOURCE",
+		DisableFlagsInUseLine: true,
+		Short:                 "View the rollout history of a cluster-api resource",
+		Long:                  historyLong,
+		Example:               historyExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistory(cfgFile, args)
+		},
+	}
+	cmd.Flags().StringVar(&historyOpt.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If unspecified, default discovery rules apply.")
+	cmd.Flags().StringVar(&historyOpt.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	cmd.Flags().StringVar(&historyOpt.namespace, "namespace", "", "Namespace where the resource(s) reside. If unspecified, the defult namespace will be used.")
+	cmd.Flags().Int64Var(&historyOpt.revision, "revision", 0, "See the details, including the machine template, of the revision specified.")
+
+	return cmd
+}
+
+func runHistory(cfgFile string, args []string) error {
+	historyOpt.resources = args
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	history, err := c.RolloutHistory(client.RolloutOptions{
+		Kubeconfig: client.Kubeconfig{Path: historyOpt.kubeconfig, Context: historyOpt.kubeconfigContext},
+		Namespace:  historyOpt.namespace,
+		Resources:  historyOpt.resources,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, h := range history {
+		if historyOpt.revision != 0 {
+			if err := printRevision(h, historyOpt.revision); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fmt.Printf("%s\n", h.Resource)
+		w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+		fmt.Fprintln(w, "REVISION\tMACHINESET\tCREATED")
+		for _, r := range h.Revisions {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", r.Revision, r.MachineSet, r.CreationTimestamp)
+		}
+		w.Flush()
+		fmt.Println("")
+
+		for _, r := range h.Revisions {
+			if r.Diff == "" {
+				continue
+			}
+			fmt.Printf("Changes in revision %d:\n%s\n", r.Revision, r.Diff)
+		}
+	}
+	return nil
+}
+
+func printRevision(h client.RolloutHistory, revision int64) error {
+	for _, r := range h.Revisions {
+		if r.Revision != revision {
+			continue
+		}
+		template, err := yaml.Marshal(r.Template)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal the template of revision %d", revision)
+		}
+		fmt.Printf("%s with revision #%d\nMachineSet: %s\nTemplate:\n%s\n", h.Resource, r.Revision, r.MachineSet, template)
+		if r.Diff != "" {
+			fmt.Printf("Changes compared to the previous revision:\n%s\n", r.Diff)
+		}
+		return nil
+	}
+	return errors.Errorf("unable to find revision %d of %s", revision, h.Resource)
+}
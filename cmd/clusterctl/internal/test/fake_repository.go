@@ -55,7 +55,18 @@ func (f FakeRepository) GetFile(version string, path string) ([]byte, error) {
 			return c, nil
 		}
 	}
-	return nil, errors.Errorf("unable to get file %s for version %s", path, version)
+	return nil, &fileNotFoundError{errors.Errorf("unable to get file %s for version %s", path, version)}
+}
+
+// fileNotFoundError reports that a file does not exist in a FakeRepository; it implements NotFound() bool,
+// the same as the errors returned by the repository implementations when a file does not exist.
+type fileNotFoundError struct {
+	error
+}
+
+// NotFound returns true.
+func (e *fileNotFoundError) NotFound() bool {
+	return true
 }
 
 func (f *FakeRepository) GetVersions() ([]string, error) {
@@ -19,6 +19,8 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -26,6 +28,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
@@ -240,6 +243,8 @@ func patchMachine(ctx context.Context, patchHelper *patch.Helper, machine *clust
 			clusterv1.BootstrapReadyCondition,
 			clusterv1.InfrastructureReadyCondition,
 			clusterv1.DrainingSucceededCondition,
+			clusterv1.PreDrainDeleteHookSucceededCondition,
+			clusterv1.PreTerminateDeleteHookSucceededCondition,
 			clusterv1.MachineHealthCheckSuccededCondition,
 			clusterv1.MachineOwnerRemediatedCondition,
 		}},
@@ -309,7 +314,9 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 		// pre-drain.delete lifecycle hook
 		// Return early without error, will requeue if/when the hook owner removes the annotation.
 		if annotations.HasWithPrefix(clusterv1.PreDrainDeleteHookAnnotationPrefix, m.ObjectMeta.Annotations) {
-			conditions.MarkFalse(m, clusterv1.PreDrainDeleteHookSucceededCondition, clusterv1.WaitingExternalHookReason, clusterv1.ConditionSeverityInfo, "")
+			hooks := deleteHooks(clusterv1.PreDrainDeleteHookAnnotationPrefix, m.ObjectMeta.Annotations)
+			log.Info("Waiting for pre-drain delete hooks", "hooks", hooks)
+			conditions.MarkFalse(m, clusterv1.PreDrainDeleteHookSucceededCondition, clusterv1.WaitingExternalHookReason, clusterv1.ConditionSeverityInfo, "Waiting for hooks %s", strings.Join(hooks, ", "))
 			return ctrl.Result{}, nil
 		}
 		conditions.MarkTrue(m, clusterv1.PreDrainDeleteHookSucceededCondition)
@@ -333,7 +340,7 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 				return ctrl.Result{}, errors.Wrap(err, "failed to patch Machine")
 			}
 
-			if result, err := r.drainNode(ctx, cluster, m.Status.NodeRef.Name); !result.IsZero() || err != nil {
+			if result, err := r.drainNode(ctx, cluster, m); !result.IsZero() || err != nil {
 				if err != nil {
 					conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 					r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDrainNode", "error draining Machine's node %q: %v", m.Status.NodeRef.Name, err)
@@ -349,7 +356,9 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	// pre-term.delete lifecycle hook
 	// Return early without error, will requeue if/when the hook owner removes the annotation.
 	if annotations.HasWithPrefix(clusterv1.PreTerminateDeleteHookAnnotationPrefix, m.ObjectMeta.Annotations) {
-		conditions.MarkFalse(m, clusterv1.PreTerminateDeleteHookSucceededCondition, clusterv1.WaitingExternalHookReason, clusterv1.ConditionSeverityInfo, "")
+		hooks := deleteHooks(clusterv1.PreTerminateDeleteHookAnnotationPrefix, m.ObjectMeta.Annotations)
+		log.Info("Waiting for pre-terminate delete hooks", "hooks", hooks)
+		conditions.MarkFalse(m, clusterv1.PreTerminateDeleteHookSucceededCondition, clusterv1.WaitingExternalHookReason, clusterv1.ConditionSeverityInfo, "Waiting for hooks %s", strings.Join(hooks, ", "))
 		return ctrl.Result{}, nil
 	}
 	conditions.MarkTrue(m, clusterv1.PreTerminateDeleteHookSucceededCondition)
@@ -398,6 +407,28 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	return ctrl.Result{}, nil
 }
 
+// deleteHooks returns the sorted list of the delete lifecycle hooks with the given prefix set on a machine,
+// e.g. "migrate-volumes (owner: storage-controller)" for the annotation
+// "pre-drain.delete.hook.machine.cluster.x-k8s.io/migrate-volumes: storage-controller".
+func deleteHooks(prefix string, machineAnnotations map[string]string) []string {
+	hooks := []string{}
+	for key, owner := range machineAnnotations {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(key, prefix), "/")
+		if name == "" {
+			name = key
+		}
+		if owner != "" {
+			name = fmt.Sprintf("%s (owner: %s)", name, owner)
+		}
+		hooks = append(hooks, name)
+	}
+	sort.Strings(hooks)
+	return hooks
+}
+
 func (r *MachineReconciler) isNodeDrainAllowed(m *clusterv1.Machine) bool {
 	if _, exists := m.ObjectMeta.Annotations[clusterv1.ExcludeNodeDrainingAnnotation]; exists {
 		return false
@@ -491,9 +522,15 @@ func (r *MachineReconciler) isDeleteNodeAllowed(ctx context.Context, cluster *cl
 	}
 }
 
-func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cluster, nodeName string) (ctrl.Result, error) {
+func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, error) {
+	nodeName := m.Status.NodeRef.Name
 	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name, "node", nodeName)
 
+	policy := m.Spec.DrainPolicy
+	if policy == nil {
+		policy = &clusterv1.MachineDrainPolicy{}
+	}
+
 	restConfig, err := remote.RESTConfig(ctx, MachineControllerName, r.Client, util.ObjectKey(cluster))
 	if err != nil {
 		log.Error(err, "Error creating a remote client while deleting Machine, won't retry")
@@ -519,7 +556,7 @@ func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cl
 		Client:              kubeClient,
 		Force:               true,
 		IgnoreAllDaemonSets: true,
-		DeleteLocalData:     true,
+		DeleteLocalData:     policy.DeleteEmptyDirData == nil || *policy.DeleteEmptyDirData,
 		GracePeriodSeconds:  -1,
 		// If a pod is not evicted in 20 seconds, retry the eviction next time the
 		// machine gets reconciled again (to allow other machines to be reconciled).
@@ -537,27 +574,143 @@ func (r *MachineReconciler) drainNode(ctx context.Context, cluster *clusterv1.Cl
 		DryRun: false,
 	}
 
+	if policy.ExcludePodSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(policy.ExcludePodSelector)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to parse the exclude pod selector of the drain policy")
+		}
+		drainer.SkipPod = func(pod corev1.Pod) bool {
+			return selector.Matches(labels.Set(pod.Labels))
+		}
+	}
+
 	if noderefutil.IsNodeUnreachable(node) {
 		// When the node is unreachable and some pods are not evicted for as long as this timeout, we ignore them.
 		drainer.SkipWaitForDeleteTimeoutSeconds = 60 * 5 // 5 minutes
 	}
 
+	if r.drainForceDeleteGracePeriodExceeded(m) {
+		// Pods which cannot be evicted, e.g. because of a PodDisruptionBudget, or are stuck terminating
+		// are deleted without waiting for their graceful termination.
+		log.Info("Drain force delete grace period exceeded, deleting pods without eviction")
+		drainer.DisableEviction = true
+		drainer.GracePeriodSeconds = 0
+	}
+
 	if err := kubedrain.RunCordonOrUncordon(ctx, drainer, node, true); err != nil {
 		// Machine will be re-reconciled after a cordon failure.
 		log.Error(err, "Cordon failed")
 		return ctrl.Result{}, errors.Errorf("unable to cordon node %s: %v", node.Name, err)
 	}
 
-	if err := kubedrain.RunNodeDrain(ctx, drainer, node.Name); err != nil {
+	list, errs := drainer.GetPodsForDeletion(ctx, node.Name)
+	if errs != nil {
 		// Machine will be re-reconciled after a drain failure.
-		log.Error(err, "Drain failed, retry in 20s")
+		log.Error(kerrors.NewAggregate(errs), "Drain failed, retry in 20s")
 		return ctrl.Result{RequeueAfter: 20 * time.Second}, nil
 	}
+	if warnings := list.Warnings(); warnings != "" {
+		log.Info("Drain warnings", "warnings", warnings)
+	}
+
+	// Pod groups are drained one after the other; DeleteOrEvictPods returns only when all the pods in a
+	// group are gone, so the next group is drained only after the previous one is completed.
+	for _, pods := range drainPodGroups(list.Pods(), policy) {
+		if err := drainer.DeleteOrEvictPods(ctx, pods); err != nil {
+			// Machine will be re-reconciled after a drain failure.
+			log.Error(err, "Drain failed, retry in 20s")
+			return ctrl.Result{RequeueAfter: 20 * time.Second}, nil
+		}
+	}
+
+	if policy.WaitForVolumeDetach {
+		node, err := kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, errors.Errorf("unable to get node %q: %v", nodeName, err)
+		}
+		if err == nil && len(node.Status.VolumesAttached) > 0 {
+			log.Info("Waiting for volumes to be detached from node, retry in 20s", "volumes", len(node.Status.VolumesAttached))
+			return ctrl.Result{RequeueAfter: 20 * time.Second}, nil
+		}
+	}
 
 	log.Info("Drain successful")
 	return ctrl.Result{}, nil
 }
 
+// drainForceDeleteGracePeriodExceeded returns true if the force delete grace period of the drain policy
+// has elapsed since the node started draining.
+func (r *MachineReconciler) drainForceDeleteGracePeriodExceeded(machine *clusterv1.Machine) bool {
+	if machine.Spec.DrainPolicy == nil || machine.Spec.DrainPolicy.ForceDeleteGracePeriod == nil {
+		return false
+	}
+
+	// if the draining succeeded condition does not exist
+	if conditions.Get(machine, clusterv1.DrainingSucceededCondition) == nil {
+		return false
+	}
+
+	firstTimeDrain := conditions.GetLastTransitionTime(machine, clusterv1.DrainingSucceededCondition)
+	return time.Since(firstTimeDrain.Time) >= machine.Spec.DrainPolicy.ForceDeleteGracePeriod.Duration
+}
+
+// drainPodGroups splits the pods to be drained into groups which are drained one after the other,
+// according to the order defined by the drain policy.
+func drainPodGroups(pods []corev1.Pod, policy *clusterv1.MachineDrainPolicy) [][]corev1.Pod {
+	if len(pods) == 0 {
+		return nil
+	}
+
+	switch policy.Order {
+	case clusterv1.PriorityMachineDrainOrder:
+		byPriority := map[int32][]corev1.Pod{}
+		for _, pod := range pods {
+			var priority int32
+			if pod.Spec.Priority != nil {
+				priority = *pod.Spec.Priority
+			}
+			byPriority[priority] = append(byPriority[priority], pod)
+		}
+		priorities := make([]int32, 0, len(byPriority))
+		for priority := range byPriority {
+			priorities = append(priorities, priority)
+		}
+		sort.Slice(priorities, func(i, j int) bool { return priorities[i] < priorities[j] })
+
+		groups := make([][]corev1.Pod, 0, len(priorities))
+		for _, priority := range priorities {
+			groups = append(groups, byPriority[priority])
+		}
+		return groups
+	case clusterv1.NamespaceMachineDrainOrder:
+		// Pods in namespaces not listed in the policy are drained last.
+		rank := map[string]int{}
+		for i, namespace := range policy.Namespaces {
+			if _, ok := rank[namespace]; !ok {
+				rank[namespace] = i
+			}
+		}
+		byRank := make([][]corev1.Pod, len(policy.Namespaces)+1)
+		for _, pod := range pods {
+			i, ok := rank[pod.Namespace]
+			if !ok {
+				i = len(policy.Namespaces)
+			}
+			byRank[i] = append(byRank[i], pod)
+		}
+
+		groups := [][]corev1.Pod{}
+		for _, group := range byRank {
+			if len(group) > 0 {
+				groups = append(groups, group)
+			}
+		}
+		return groups
+	default:
+		return [][]corev1.Pod{pods}
+	}
+}
+
 func (r *MachineReconciler) deleteNode(ctx context.Context, cluster *clusterv1.Cluster, name string) error {
 	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
 
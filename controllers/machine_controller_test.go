@@ -1349,6 +1349,245 @@ func TestIsNodeDrainedAllowed(t *testing.T) {
 	}
 }
 
+func TestDrainForceDeleteGracePeriodExceeded(t *testing.T) {
+	drainStartedAt := func(ago time.Duration) clusterv1.MachineStatus {
+		return clusterv1.MachineStatus{
+			Conditions: clusterv1.Conditions{
+				{
+					Type:               clusterv1.DrainingSucceededCondition,
+					Status:             corev1.ConditionFalse,
+					LastTransitionTime: metav1.Time{Time: time.Now().Add(-ago).UTC()},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		drainPolicy *clusterv1.MachineDrainPolicy
+		status      clusterv1.MachineStatus
+		expected    bool
+	}{
+		{
+			name:        "Drain policy is not set",
+			drainPolicy: nil,
+			status:      drainStartedAt(time.Hour),
+			expected:    false,
+		},
+		{
+			name:        "Force delete grace period is not set",
+			drainPolicy: &clusterv1.MachineDrainPolicy{},
+			status:      drainStartedAt(time.Hour),
+			expected:    false,
+		},
+		{
+			name:        "Node draining has not started yet",
+			drainPolicy: &clusterv1.MachineDrainPolicy{ForceDeleteGracePeriod: &metav1.Duration{Duration: time.Second * 60}},
+			status:      clusterv1.MachineStatus{},
+			expected:    false,
+		},
+		{
+			name:        "Force delete grace period is not yet over",
+			drainPolicy: &clusterv1.MachineDrainPolicy{ForceDeleteGracePeriod: &metav1.Duration{Duration: time.Second * 60}},
+			status:      drainStartedAt(time.Second * 30),
+			expected:    false,
+		},
+		{
+			name:        "Force delete grace period is over",
+			drainPolicy: &clusterv1.MachineDrainPolicy{ForceDeleteGracePeriod: &metav1.Duration{Duration: time.Second * 60}},
+			status:      drainStartedAt(time.Second * 70),
+			expected:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"},
+				Spec: clusterv1.MachineSpec{
+					ClusterName: "test-cluster",
+					DrainPolicy: tt.drainPolicy,
+				},
+				Status: tt.status,
+			}
+
+			r := &MachineReconciler{}
+			g.Expect(r.drainForceDeleteGracePeriodExceeded(machine)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestDrainPodGroups(t *testing.T) {
+	newPod := func(namespace, name string, priority *int32) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       corev1.PodSpec{Priority: priority},
+		}
+	}
+	names := func(groups [][]corev1.Pod) [][]string {
+		out := [][]string{}
+		for _, group := range groups {
+			g := []string{}
+			for _, pod := range group {
+				g = append(g, pod.Name)
+			}
+			out = append(out, g)
+		}
+		return out
+	}
+
+	pods := []corev1.Pod{
+		newPod("monitoring", "exporter", pointer.Int32Ptr(1000)),
+		newPod("apps", "web", nil),
+		newPod("databases", "db", pointer.Int32Ptr(100)),
+		newPod("apps", "worker", pointer.Int32Ptr(0)),
+		newPod("kube-system", "dns", pointer.Int32Ptr(2000000000)),
+	}
+
+	tests := []struct {
+		name     string
+		pods     []corev1.Pod
+		policy   *clusterv1.MachineDrainPolicy
+		expected [][]string
+	}{
+		{
+			name:     "No pods to drain",
+			pods:     nil,
+			policy:   &clusterv1.MachineDrainPolicy{Order: clusterv1.PriorityMachineDrainOrder},
+			expected: [][]string{},
+		},
+		{
+			name:     "Parallel order drains all the pods at once",
+			pods:     pods,
+			policy:   &clusterv1.MachineDrainPolicy{},
+			expected: [][]string{{"exporter", "web", "db", "worker", "dns"}},
+		},
+		{
+			name:     "Priority order drains pods in ascending order of priority",
+			pods:     pods,
+			policy:   &clusterv1.MachineDrainPolicy{Order: clusterv1.PriorityMachineDrainOrder},
+			expected: [][]string{{"web", "worker"}, {"db"}, {"exporter"}, {"dns"}},
+		},
+		{
+			name: "Namespace order drains pods in the order of the namespaces, then pods in other namespaces",
+			pods: pods,
+			policy: &clusterv1.MachineDrainPolicy{
+				Order:      clusterv1.NamespaceMachineDrainOrder,
+				Namespaces: []string{"apps", "empty", "databases"},
+			},
+			expected: [][]string{{"web", "worker"}, {"db"}, {"exporter", "dns"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(names(drainPodGroups(tt.pods, tt.policy))).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestDeleteHooks(t *testing.T) {
+	g := NewWithT(t)
+
+	machineAnnotations := map[string]string{
+		clusterv1.PreDrainDeleteHookAnnotationPrefix + "/migrate-volumes": "storage-controller",
+		clusterv1.PreDrainDeleteHookAnnotationPrefix + "/deregister":      "",
+		clusterv1.PreTerminateDeleteHookAnnotationPrefix + "/cmdb":        "cmdb-controller",
+		"unrelated": "value",
+	}
+
+	g.Expect(deleteHooks(clusterv1.PreDrainDeleteHookAnnotationPrefix, machineAnnotations)).To(Equal([]string{
+		"deregister",
+		"migrate-volumes (owner: storage-controller)",
+	}))
+	g.Expect(deleteHooks(clusterv1.PreTerminateDeleteHookAnnotationPrefix, machineAnnotations)).To(Equal([]string{
+		"cmdb (owner: cmdb-controller)",
+	}))
+	g.Expect(deleteHooks(clusterv1.PreTerminateDeleteHookAnnotationPrefix, nil)).To(BeEmpty())
+}
+
+func TestReconcileDeleteLifecycleHooks(t *testing.T) {
+	testCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cluster"},
+	}
+	controlPlaneMachine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "control-plane",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName:             "test-cluster",
+				clusterv1.MachineControlPlaneLabelName: "",
+			},
+		},
+		Spec: clusterv1.MachineSpec{ClusterName: "test-cluster"},
+	}
+	newMachine := func(nodeRef *corev1.ObjectReference, machineAnnotations map[string]string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "delete-me",
+				Namespace:         "default",
+				Labels:            map[string]string{clusterv1.ClusterLabelName: "test-cluster"},
+				Annotations:       machineAnnotations,
+				Finalizers:        []string{clusterv1.MachineFinalizer},
+				DeletionTimestamp: &metav1.Time{Time: time.Now().UTC()},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName:       "test-cluster",
+				InfrastructureRef: corev1.ObjectReference{},
+				Bootstrap:         clusterv1.Bootstrap{DataSecretName: pointer.StringPtr("data")},
+			},
+			Status: clusterv1.MachineStatus{NodeRef: nodeRef},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		machine           *clusterv1.Machine
+		expectedCondition clusterv1.ConditionType
+		expectedMessage   string
+	}{
+		{
+			name: "pre-drain hooks block the node drain",
+			machine: newMachine(&corev1.ObjectReference{Name: "test-node"}, map[string]string{
+				clusterv1.PreDrainDeleteHookAnnotationPrefix + "/migrate-volumes": "storage-controller",
+				clusterv1.PreDrainDeleteHookAnnotationPrefix + "/backup":          "",
+			}),
+			expectedCondition: clusterv1.PreDrainDeleteHookSucceededCondition,
+			expectedMessage:   "Waiting for hooks backup, migrate-volumes (owner: storage-controller)",
+		},
+		{
+			name: "pre-terminate hooks block the infrastructure deletion",
+			machine: newMachine(nil, map[string]string{
+				clusterv1.PreTerminateDeleteHookAnnotationPrefix + "/cmdb": "cmdb-controller",
+			}),
+			expectedCondition: clusterv1.PreTerminateDeleteHookSucceededCondition,
+			expectedMessage:   "Waiting for hooks cmdb (owner: cmdb-controller)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &MachineReconciler{
+				Client: helpers.NewFakeClientWithScheme(scheme.Scheme, testCluster, controlPlaneMachine, tt.machine),
+			}
+
+			res, err := r.reconcileDelete(ctx, testCluster, tt.machine)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(res.IsZero()).To(BeTrue())
+			g.Expect(tt.machine.Finalizers).To(ContainElement(clusterv1.MachineFinalizer))
+
+			condition := conditions.Get(tt.machine, tt.expectedCondition)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			g.Expect(condition.Reason).To(Equal(clusterv1.WaitingExternalHookReason))
+			g.Expect(condition.Message).To(Equal(tt.expectedMessage))
+		})
+	}
+}
+
 func TestIsDeleteNodeAllowed(t *testing.T) {
 	deletionts := metav1.Now()
 
@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// canaryCheckInterval is the interval for checking canary machines while they are not yet verified,
+// so machines failing health checks are detected also if the machine set does not change.
+const canaryCheckInterval = 30 * time.Second
+
+// rolloutCanary implements the logic for the Canary MachineDeploymentStrategyType: a limited number of new machines
+// is rolled out first, and the rollout continues as a rolling update only after the canary machines have been
+// available for the soak period. The rollout is stopped if machines of the new machine set fail health checks.
+func (r *MachineDeploymentReconciler) rolloutCanary(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	newMS, oldMSs, err := r.getAllMachineSetsAndSyncRevision(ctx, d, msList, true)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// newMS can be nil in case there is already a MachineSet associated with this deployment,
+	// but there are only either changes in annotations or MinReadySeconds. Or in other words,
+	// this can be nil if there are changes, but no replacement of existing machines is needed.
+	if newMS == nil {
+		return ctrl.Result{}, nil
+	}
+
+	allMSs := append(oldMSs, newMS)
+
+	// Nothing to verify if there are no old machines to replace, e.g. when the MachineDeployment is created.
+	if mdutil.GetReplicaCountForMachineSets(oldMSs) == 0 {
+		return ctrl.Result{}, r.reconcileRollingUpdate(ctx, d, newMS, oldMSs)
+	}
+
+	// If the rollout has been stopped, wait for the user to fix the problem.
+	if mdutil.IsCanaryAborted(newMS) {
+		log.V(4).Info("Canary rollout is stopped", "machineset", newMS.Name, "reason", newMS.Annotations[clusterv1.CanaryAbortedAnnotation])
+		return ctrl.Result{}, r.syncDeploymentStatus(allMSs, newMS, d)
+	}
+
+	// Stop the rollout if machines of the new machine set are failing health checks.
+	if d.Spec.Strategy.Canary == nil || d.Spec.Strategy.Canary.AbortOnUnhealthy == nil || *d.Spec.Strategy.Canary.AbortOnUnhealthy {
+		unhealthy, err := r.getUnhealthyMachines(ctx, newMS)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if len(unhealthy) > 0 {
+			reason := fmt.Sprintf("Machines %s failed health checks", strings.Join(unhealthy, ", "))
+			if err := r.setMachineSetAnnotation(ctx, newMS, clusterv1.CanaryAbortedAnnotation, reason); err != nil {
+				return ctrl.Result{}, err
+			}
+			log.Info("Stopping canary rollout", "machineset", newMS.Name, "reason", reason)
+			r.recorder.Eventf(d, corev1.EventTypeWarning, "CanaryAborted", "Stopped rollout of MachineSet %q: %s", newMS.Name, reason)
+			return ctrl.Result{}, r.syncDeploymentStatus(allMSs, newMS, d)
+		}
+	}
+
+	// Once canary machines are verified, continue as a rolling update.
+	if mdutil.IsCanaryCompleted(newMS) {
+		return ctrl.Result{RequeueAfter: canaryCheckInterval}, r.reconcileRollingUpdate(ctx, d, newMS, oldMSs)
+	}
+
+	// Scale up the new machine set to the number of canary machines.
+	if err := r.reconcileNewMachineSet(ctx, allMSs, newMS, d); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.syncDeploymentStatus(allMSs, newMS, d); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Wait for canary machines to be available; if canary machines are not available anymore, restart the soak period.
+	canaryReplicas := mdutil.CanaryReplicas(d)
+	if newMS.Status.AvailableReplicas < canaryReplicas {
+		log.V(4).Info("Waiting for canary machines to be available", "machineset", newMS.Name, "available", newMS.Status.AvailableReplicas, "canary", canaryReplicas)
+		if _, ok := newMS.Annotations[clusterv1.CanaryReadyAnnotation]; ok {
+			if err := r.removeMachineSetAnnotation(ctx, newMS, clusterv1.CanaryReadyAnnotation); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: canaryCheckInterval}, nil
+	}
+
+	now := time.Now()
+	readySince, err := time.Parse(time.RFC3339, newMS.Annotations[clusterv1.CanaryReadyAnnotation])
+	if err != nil {
+		readySince = now
+		if err := r.setMachineSetAnnotation(ctx, newMS, clusterv1.CanaryReadyAnnotation, readySince.Format(time.RFC3339)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Wait for the soak period, while checking machines health.
+	var soak time.Duration
+	if d.Spec.Strategy.Canary != nil && d.Spec.Strategy.Canary.SoakSeconds != nil {
+		soak = time.Duration(*d.Spec.Strategy.Canary.SoakSeconds) * time.Second
+	}
+	if remaining := readySince.Add(soak).Sub(now); remaining > 0 {
+		log.V(4).Info("Waiting for canary machines soak period", "machineset", newMS.Name, "remaining", remaining)
+		if remaining > canaryCheckInterval {
+			remaining = canaryCheckInterval
+		}
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	if err := r.setMachineSetAnnotation(ctx, newMS, clusterv1.CanaryCompletedAnnotation, now.Format(time.RFC3339)); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.Info("Canary machines verified, continuing the rollout", "machineset", newMS.Name)
+	r.recorder.Eventf(d, corev1.EventTypeNormal, "CanaryCompleted", "Verified canary machines of MachineSet %q", newMS.Name)
+
+	return ctrl.Result{RequeueAfter: canaryCheckInterval}, r.reconcileRollingUpdate(ctx, d, newMS, oldMSs)
+}
+
+// getUnhealthyMachines returns the names of the machines of a machine set failing health checks.
+func (r *MachineDeploymentReconciler) getUnhealthyMachines(ctx context.Context, ms *clusterv1.MachineSet) ([]string, error) {
+	machines, err := r.getMachinesForMachineSet(ctx, ms)
+	if err != nil {
+		return nil, err
+	}
+
+	var unhealthy []string
+	for _, m := range machines {
+		if conditions.IsFalse(m, clusterv1.MachineHealthCheckSuccededCondition) {
+			unhealthy = append(unhealthy, m.Name)
+		}
+	}
+	return unhealthy, nil
+}
+
+// setMachineSetAnnotation sets an annotation on a machine set.
+func (r *MachineDeploymentReconciler) setMachineSetAnnotation(ctx context.Context, ms *clusterv1.MachineSet, key, value string) error {
+	patchHelper, err := patch.NewHelper(ms, r.Client)
+	if err != nil {
+		return err
+	}
+	if ms.Annotations == nil {
+		ms.Annotations = map[string]string{}
+	}
+	ms.Annotations[key] = value
+	return patchHelper.Patch(ctx, ms)
+}
+
+// removeMachineSetAnnotation removes an annotation from a machine set.
+func (r *MachineDeploymentReconciler) removeMachineSetAnnotation(ctx context.Context, ms *clusterv1.MachineSet, key string) error {
+	patchHelper, err := patch.NewHelper(ms, r.Client)
+	if err != nil {
+		return err
+	}
+	delete(ms.Annotations, key)
+	return patchHelper.Patch(ctx, ms)
+}
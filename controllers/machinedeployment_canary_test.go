@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestGetUnhealthyMachines(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	ms := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "default"},
+		Spec: clusterv1.MachineSetSpec{
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"machineset": "ms"}},
+		},
+	}
+	newMachine := func(name string, labels map[string]string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+		}
+	}
+
+	healthy := newMachine("healthy", ms.Spec.Selector.MatchLabels)
+	conditions.MarkTrue(healthy, clusterv1.MachineHealthCheckSuccededCondition)
+	unknown := newMachine("not-checked", ms.Spec.Selector.MatchLabels)
+	unhealthy := newMachine("unhealthy", ms.Spec.Selector.MatchLabels)
+	conditions.MarkFalse(unhealthy, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityWarning, "")
+	other := newMachine("other-machineset", map[string]string{"machineset": "other"})
+	conditions.MarkFalse(other, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityWarning, "")
+
+	r := &MachineDeploymentReconciler{
+		Client:   fake.NewClientBuilder().WithObjects([]client.Object{ms, healthy, unknown, unhealthy, other}...).Build(),
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	got, err := r.getUnhealthyMachines(ctx, ms)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(ConsistOf("unhealthy"))
+}
+
+func TestGetNewMachineSetResetsCanaryAnnotationsOnRollback(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	template := clusterv1.MachineTemplateSpec{
+		Spec: clusterv1.MachineSpec{
+			ClusterName:       "cluster",
+			InfrastructureRef: corev1.ObjectReference{Kind: "InfrastructureMachineTemplate", Name: "old"},
+		},
+	}
+	d := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "md", Namespace: "default"},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName:     "cluster",
+			MinReadySeconds: pointer.Int32Ptr(0),
+			Strategy:        &clusterv1.MachineDeploymentStrategy{Type: clusterv1.CanaryMachineDeploymentStrategyType},
+			Template:        template,
+		},
+	}
+	// The old machine set has been previously aborted, and the deployment is now rolled back to its template.
+	rolledBack := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rolled-back",
+			Namespace: "default",
+			Annotations: map[string]string{
+				clusterv1.RevisionAnnotation:        "1",
+				clusterv1.CanaryReadyAnnotation:     "2021-01-01T00:00:00Z",
+				clusterv1.CanaryAbortedAnnotation:   "Machines foo failed health checks",
+				clusterv1.CanaryCompletedAnnotation: "2021-01-01T00:00:00Z",
+			},
+		},
+		Spec: clusterv1.MachineSetSpec{Template: template},
+	}
+	current := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "current",
+			Namespace:   "default",
+			Annotations: map[string]string{clusterv1.RevisionAnnotation: "2"},
+		},
+	}
+
+	r := &MachineDeploymentReconciler{
+		Client:   fake.NewClientBuilder().WithObjects(d, rolledBack, current).Build(),
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	_, err := r.getNewMachineSet(ctx, d, []*clusterv1.MachineSet{rolledBack, current}, []*clusterv1.MachineSet{current}, false)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	got := &clusterv1.MachineSet{}
+	g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(rolledBack), got)).To(Succeed())
+	g.Expect(got.Annotations).To(HaveKeyWithValue(clusterv1.RevisionAnnotation, "3"))
+	g.Expect(got.Annotations).NotTo(HaveKey(clusterv1.CanaryReadyAnnotation))
+	g.Expect(got.Annotations).NotTo(HaveKey(clusterv1.CanaryCompletedAnnotation))
+	g.Expect(got.Annotations).NotTo(HaveKey(clusterv1.CanaryAbortedAnnotation))
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/integer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rolloutOnDelete implements the logic for the OnDelete MachineDeploymentStrategyType: machines of the old
+// machine sets are not replaced until they are deleted by the user.
+func (r *MachineDeploymentReconciler) rolloutOnDelete(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) error {
+	newMS, oldMSs, err := r.getAllMachineSetsAndSyncRevision(ctx, d, msList, true)
+	if err != nil {
+		return err
+	}
+
+	// newMS can be nil in case there is already a MachineSet associated with this deployment,
+	// but there are only either changes in annotations or MinReadySeconds. Or in other words,
+	// this can be nil if there are changes, but no replacement of existing machines is needed.
+	if newMS == nil {
+		return nil
+	}
+
+	allMSs := append(oldMSs, newMS)
+
+	// Scale down, if we can.
+	if err := r.reconcileOldMachineSetsOnDelete(ctx, oldMSs, allMSs, d); err != nil {
+		return err
+	}
+
+	if err := r.syncDeploymentStatus(allMSs, newMS, d); err != nil {
+		return err
+	}
+
+	// Scale up, if we can.
+	if err := r.reconcileNewMachineSetOnDelete(ctx, allMSs, newMS, d); err != nil {
+		return err
+	}
+
+	if err := r.syncDeploymentStatus(allMSs, newMS, d); err != nil {
+		return err
+	}
+
+	if mdutil.DeploymentComplete(d, &d.Status) {
+		if err := r.cleanupDeployment(ctx, oldMSs, d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcileOldMachineSetsOnDelete prevents old machine sets from replacing deleted machines, and scales them down
+// to the number of machines that are not being deleted. In case the deployment has been scaled down, old machine sets
+// are scaled down further, before the new machine set.
+func (r *MachineDeploymentReconciler) reconcileOldMachineSetsOnDelete(ctx context.Context, oldMSs []*clusterv1.MachineSet, allMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if deployment.Spec.Replicas == nil {
+		return errors.Errorf("spec replicas for MachineDeployment %q/%q is nil, this is unexpected",
+			deployment.Namespace, deployment.Name)
+	}
+
+	totalReplicas := mdutil.GetReplicaCountForMachineSets(allMSs)
+	scaleDownAmount := totalReplicas - *deployment.Spec.Replicas
+
+	sort.Sort(mdutil.MachineSetsByCreationTimestamp(oldMSs))
+	for _, oldMS := range oldMSs {
+		if oldMS.Spec.Replicas == nil {
+			return errors.Errorf("spec replicas for MachineSet %q/%q is nil, this is unexpected",
+				oldMS.Namespace, oldMS.Name)
+		}
+
+		// Old machine sets must not replace the machines deleted by the user.
+		if _, ok := oldMS.Annotations[clusterv1.DisableMachineCreateAnnotation]; !ok {
+			if err := r.setMachineSetAnnotation(ctx, oldMS, clusterv1.DisableMachineCreateAnnotation, ""); err != nil {
+				return err
+			}
+		}
+
+		if *(oldMS.Spec.Replicas) == 0 {
+			continue
+		}
+
+		machines, err := r.getMachinesForMachineSet(ctx, oldMS)
+		if err != nil {
+			return err
+		}
+		var currentMachineCount int32
+		for _, m := range machines {
+			if m.DeletionTimestamp.IsZero() {
+				currentMachineCount++
+			}
+		}
+
+		newReplicasCount := integer.Int32Min(*(oldMS.Spec.Replicas), currentMachineCount)
+		if scaleDownAmount > 0 {
+			// Nb. the surplus is computed on spec replicas, so it includes the machines deleted by the user.
+			newReplicasCount = integer.Int32Max(integer.Int32Min(newReplicasCount, *(oldMS.Spec.Replicas)-scaleDownAmount), 0)
+		}
+		if newReplicasCount == *(oldMS.Spec.Replicas) {
+			continue
+		}
+
+		scaledDownCount := *(oldMS.Spec.Replicas) - newReplicasCount
+		log.V(4).Info("Scaling down old MachineSet", "machineset", oldMS.Name, "count", scaledDownCount)
+		if err := r.scaleMachineSet(ctx, oldMS, newReplicasCount, deployment); err != nil {
+			return err
+		}
+		scaleDownAmount -= scaledDownCount
+	}
+
+	return nil
+}
+
+// reconcileNewMachineSetOnDelete scales up the new machine set to replace the machines removed from old machine sets.
+func (r *MachineDeploymentReconciler) reconcileNewMachineSetOnDelete(ctx context.Context, allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) error {
+	if deployment.Spec.Replicas == nil {
+		return errors.Errorf("spec replicas for deployment set %v is nil, this is unexpected", deployment.Name)
+	}
+
+	if newMS.Spec.Replicas == nil {
+		return errors.Errorf("spec replicas for machine set %v is nil, this is unexpected", newMS.Name)
+	}
+
+	if *(newMS.Spec.Replicas) > *(deployment.Spec.Replicas) {
+		// Scale down.
+		return r.scaleMachineSet(ctx, newMS, *(deployment.Spec.Replicas), deployment)
+	}
+
+	newReplicasCount, err := mdutil.NewMSNewReplicas(deployment, allMSs, newMS)
+	if err != nil {
+		return err
+	}
+	return r.scaleMachineSet(ctx, newMS, newReplicasCount, deployment)
+}
+
+// getMachinesForMachineSet returns the machines selected by a machine set.
+func (r *MachineDeploymentReconciler) getMachinesForMachineSet(ctx context.Context, ms *clusterv1.MachineSet) ([]*clusterv1.Machine, error) {
+	selectorMap, err := metav1.LabelSelectorAsMap(&ms.Spec.Selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert MachineSet %q label selector to a map", ms.Name)
+	}
+
+	machineList := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machineList, client.InNamespace(ms.Namespace), client.MatchingLabels(selectorMap)); err != nil {
+		return nil, errors.Wrapf(err, "failed to list machines for MachineSet %q", ms.Name)
+	}
+
+	machines := make([]*clusterv1.Machine, 0, len(machineList.Items))
+	for i := range machineList.Items {
+		machines = append(machines, &machineList.Items[i])
+	}
+	return machines, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+func TestReconcileOldMachineSetsOnDelete(t *testing.T) {
+	newMachineSet := func(name string, replicas int32) *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: clusterv1.MachineSetSpec{
+				Replicas: pointer.Int32Ptr(replicas),
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{"machineset": name}},
+			},
+		}
+	}
+	newMachines := func(ms string, count int, deleting int) []client.Object {
+		machines := []client.Object{}
+		for i := 0; i < count; i++ {
+			m := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", ms, i),
+					Namespace: "default",
+					Labels:    map[string]string{"machineset": ms},
+				},
+			}
+			if i < deleting {
+				m.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+				m.Finalizers = []string{clusterv1.MachineFinalizer}
+			}
+			machines = append(machines, m)
+		}
+		return machines
+	}
+
+	tests := []struct {
+		name             string
+		deployment       int32
+		oldMachineSet    *clusterv1.MachineSet
+		machines         []client.Object
+		expectedReplicas int32
+	}{
+		{
+			name:             "old machine set is not scaled down if no machines have been deleted",
+			deployment:       3,
+			oldMachineSet:    newMachineSet("ms-old", 3),
+			machines:         newMachines("ms-old", 3, 0),
+			expectedReplicas: 3,
+		},
+		{
+			name:             "old machine set is scaled down to the machines not being deleted",
+			deployment:       3,
+			oldMachineSet:    newMachineSet("ms-old", 3),
+			machines:         newMachines("ms-old", 3, 1),
+			expectedReplicas: 2,
+		},
+		{
+			name:             "old machine set is scaled down to the machines which still exist",
+			deployment:       3,
+			oldMachineSet:    newMachineSet("ms-old", 3),
+			machines:         newMachines("ms-old", 1, 0),
+			expectedReplicas: 1,
+		},
+		{
+			name:             "old machine set is scaled down if the deployment has been scaled down",
+			deployment:       1,
+			oldMachineSet:    newMachineSet("ms-old", 3),
+			machines:         newMachines("ms-old", 3, 0),
+			expectedReplicas: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+			deployment := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "md", Namespace: "default"},
+				Spec: clusterv1.MachineDeploymentSpec{
+					Replicas: pointer.Int32Ptr(tt.deployment),
+					Strategy: &clusterv1.MachineDeploymentStrategy{
+						Type: clusterv1.OnDeleteMachineDeploymentStrategyType,
+					},
+				},
+			}
+			newMS := newMachineSet("ms-new", 0)
+
+			r := &MachineDeploymentReconciler{
+				Client:   fake.NewClientBuilder().WithObjects(append(tt.machines, tt.oldMachineSet, newMS)...).Build(),
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			oldMSs := []*clusterv1.MachineSet{tt.oldMachineSet}
+			allMSs := []*clusterv1.MachineSet{tt.oldMachineSet, newMS}
+			g.Expect(r.reconcileOldMachineSetsOnDelete(ctx, oldMSs, allMSs, deployment)).To(Succeed())
+
+			got := &clusterv1.MachineSet{}
+			g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(tt.oldMachineSet), got)).To(Succeed())
+			g.Expect(*got.Spec.Replicas).To(Equal(tt.expectedReplicas))
+			g.Expect(got.Annotations).To(HaveKey(clusterv1.DisableMachineCreateAnnotation))
+		})
+	}
+}
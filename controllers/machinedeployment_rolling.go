@@ -41,6 +41,11 @@ func (r *MachineDeploymentReconciler) rolloutRolling(ctx context.Context, d *clu
 		return nil
 	}
 
+	return r.reconcileRollingUpdate(ctx, d, newMS, oldMSs)
+}
+
+// reconcileRollingUpdate scales the new and the old machine sets according to the rolling update params.
+func (r *MachineDeploymentReconciler) reconcileRollingUpdate(ctx context.Context, d *clusterv1.MachineDeployment, newMS *clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet) error {
 	allMSs := append(oldMSs, newMS)
 
 	// Scale up, if we can.
@@ -110,10 +110,28 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *c
 		}
 
 		// Set existing new machine set's annotation
+		oldRevision := msCopy.Annotations[clusterv1.RevisionAnnotation]
 		annotationsUpdated := mdutil.SetNewMachineSetAnnotations(d, msCopy, newRevision, true, log)
 
+		// The new machine set must be allowed to create machines, e.g. when rolling back to the template of an old machine set.
+		if _, ok := msCopy.Annotations[clusterv1.DisableMachineCreateAnnotation]; ok {
+			delete(msCopy.Annotations, clusterv1.DisableMachineCreateAnnotation)
+			annotationsUpdated = true
+		}
+
+		// When rolling back to the template of an old machine set, a new canary rollout must start from scratch,
+		// so the outcome of a previous canary rollout of the same machine set is discarded.
+		if oldRevision != "" && oldRevision != msCopy.Annotations[clusterv1.RevisionAnnotation] {
+			for _, a := range []string{clusterv1.CanaryReadyAnnotation, clusterv1.CanaryCompletedAnnotation, clusterv1.CanaryAbortedAnnotation} {
+				if _, ok := msCopy.Annotations[a]; ok {
+					delete(msCopy.Annotations, a)
+					annotationsUpdated = true
+				}
+			}
+		}
+
 		minReadySecondsNeedsUpdate := msCopy.Spec.MinReadySeconds != *d.Spec.MinReadySeconds
-		deletePolicyNeedsUpdate := d.Spec.Strategy.RollingUpdate.DeletePolicy != nil && msCopy.Spec.DeletePolicy != *d.Spec.Strategy.RollingUpdate.DeletePolicy
+		deletePolicyNeedsUpdate := d.Spec.Strategy.RollingUpdate != nil && d.Spec.Strategy.RollingUpdate.DeletePolicy != nil && msCopy.Spec.DeletePolicy != *d.Spec.Strategy.RollingUpdate.DeletePolicy
 		if annotationsUpdated || minReadySecondsNeedsUpdate || deletePolicyNeedsUpdate {
 			msCopy.Spec.MinReadySeconds = *d.Spec.MinReadySeconds
 
@@ -168,7 +186,7 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *c
 		},
 	}
 
-	if d.Spec.Strategy.RollingUpdate.DeletePolicy != nil {
+	if d.Spec.Strategy.RollingUpdate != nil && d.Spec.Strategy.RollingUpdate.DeletePolicy != nil {
 		newMS.Spec.DeletePolicy = *d.Spec.Strategy.RollingUpdate.DeletePolicy
 	}
 
@@ -401,6 +419,10 @@ func calculateStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet
 			}
 		}
 	}
+	// A canary rollout stopped because of unhealthy machines requires user intervention.
+	if newMS != nil && mdutil.IsCanaryAborted(newMS) {
+		status.Phase = string(clusterv1.MachineDeploymentPhaseFailed)
+	}
 	return status
 }
 
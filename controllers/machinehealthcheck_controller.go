@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -33,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
@@ -45,7 +47,9 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -71,6 +75,23 @@ type MachineHealthCheckReconciler struct {
 
 	controller controller.Controller
 	recorder   record.EventRecorder
+
+	// probePodsMissingSince records since when the pods selected by a PodNotReady probe are
+	// missing on a node, keyed by probePodsKey.
+	probePodsMissingSince     map[string]time.Time
+	probePodsMissingSinceLock sync.Mutex
+
+	// podWatches records the watches of the pods selected by the PodNotReady probes of each
+	// MachineHealthCheck, so they are stopped when the probes change or the MachineHealthCheck is deleted.
+	podWatches     map[types.NamespacedName]*clusterPodsWatches
+	podWatchesLock sync.Mutex
+}
+
+// clusterPodsWatches are the names of the watches of the pods selected by the PodNotReady probes
+// of a MachineHealthCheck in its cluster.
+type clusterPodsWatches struct {
+	cluster client.ObjectKey
+	names   sets.String
 }
 
 func (r *MachineHealthCheckReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
@@ -111,7 +132,8 @@ func (r *MachineHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.R
 		if apierrors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
-			return ctrl.Result{}, nil
+			// The watches of the pods selected by the probes are not garbage collected, so stop them.
+			return ctrl.Result{}, r.stopClusterPodsWatches(req.NamespacedName, nil)
 		}
 
 		// Error reading the object - requeue the request.
@@ -187,14 +209,27 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		return ctrl.Result{}, err
 	}
 
+	// Get a client.Reader bypassing the remote cluster cache, used to read the pods and the leases checked by the probes;
+	// caching them would require to watch all the pods and leases in the remote cluster.
+	remoteAPIReader, err := r.Tracker.GetAPIReader(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		logger.Error(err, "error creating remote cluster reader")
+		return ctrl.Result{}, err
+	}
+
 	if err := r.watchClusterNodes(ctx, cluster); err != nil {
 		logger.Error(err, "error watching nodes on target cluster")
 		return ctrl.Result{}, err
 	}
 
+	if err := r.watchClusterPods(ctx, cluster, m); err != nil {
+		logger.Error(err, "error watching pods on target cluster")
+		return ctrl.Result{}, err
+	}
+
 	// fetch all targets
 	logger.V(3).Info("Finding targets")
-	targets, err := r.getTargetsFromMHC(ctx, remoteClient, m)
+	targets, err := r.getTargetsFromMHC(ctx, remoteClient, remoteAPIReader, m)
 	if err != nil {
 		logger.Error(err, "Failed to fetch targets from MachineHealthCheck")
 		return ctrl.Result{}, err
@@ -211,22 +246,33 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 	// health check all targets and reconcile mhc status
 	healthy, unhealthy, nextCheckTimes := r.healthCheckTargets(targets, logger, m.Spec.NodeStartupTimeout.Duration)
 	m.Status.CurrentHealthy = int32(len(healthy))
+	pruneRecentRemediations(m, time.Now())
 
-	// check MHC current health against MaxUnhealthy
+	// check MHC current health against UnhealthyRange or MaxUnhealthy
 	if !isAllowedRemediation(m) {
 		logger.V(3).Info(
 			"Short-circuiting remediation",
 			"total target", totalTargets,
 			"max unhealthy", m.Spec.MaxUnhealthy,
+			"unhealthy range", m.Spec.UnhealthyRange,
 			"unhealthy targets", len(unhealthy),
 		)
-		message := fmt.Sprintf("Remediation is not allowed, the number of not started or unhealthy machines exceeds maxUnhealthy (total: %v, unhealthy: %v, maxUnhealthy: %v)",
-			totalTargets,
-			len(unhealthy),
-			m.Spec.MaxUnhealthy,
-		)
+		var message string
+		if m.Spec.UnhealthyRange == nil {
+			message = fmt.Sprintf("Remediation is not allowed, the number of not started or unhealthy machines exceeds maxUnhealthy (total: %v, unhealthy: %v, maxUnhealthy: %v)",
+				totalTargets,
+				len(unhealthy),
+				m.Spec.MaxUnhealthy,
+			)
+		} else {
+			message = fmt.Sprintf("Remediation is not allowed, the number of not started or unhealthy machines does not fall within the range (total: %v, unhealthy: %v, unhealthyRange: %v)",
+				totalTargets,
+				len(unhealthy),
+				*m.Spec.UnhealthyRange,
+			)
+		}
 
-		// Remediation not allowed, the number of not started or unhealthy machines exceeds maxUnhealthy
+		// Remediation not allowed, the number of not started or unhealthy machines is out of the allowed range
 		m.Status.RemediationsAllowed = 0
 		conditions.Set(m, &clusterv1.Condition{
 			Type:     clusterv1.RemediationAllowedCondition,
@@ -259,6 +305,7 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		"Remediations are allowed",
 		"total target", totalTargets,
 		"max unhealthy", m.Spec.MaxUnhealthy,
+		"unhealthy range", m.Spec.UnhealthyRange,
 		"unhealthy targets", len(unhealthy),
 	)
 
@@ -274,12 +321,28 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 	errList := r.PatchUnhealthyTargets(ctx, logger, unhealthy, cluster, m)
 	errList = append(errList, r.PatchHealthyTargets(ctx, logger, healthy, cluster, m)...)
 
+	// Remediations exceeding the remediation rate limit are not allowed
+	if rl := m.Spec.RemediationRateLimit; rl != nil {
+		if left := rl.MaxRemediations - m.Status.RemediationsInWindow; left < m.Status.RemediationsAllowed {
+			m.Status.RemediationsAllowed = left
+		}
+		if m.Status.RemediationsAllowed < 0 {
+			m.Status.RemediationsAllowed = 0
+		}
+	}
+
 	// handle update errors
 	if len(errList) > 0 {
 		logger.V(3).Info("Error(s) marking machine, requeueing")
 		return reconcile.Result{}, kerrors.NewAggregate(errList)
 	}
 
+	// If some targets could not be remediated due to the remediation rate limit, requeue
+	// when the oldest remediation leaves the window.
+	if next := nextRemediationAllowed(m, time.Now()); len(unhealthy) > 0 && next > 0 {
+		nextCheckTimes = append(nextCheckTimes, next)
+	}
+
 	if minNextCheck := minDuration(nextCheckTimes); minNextCheck > 0 {
 		logger.V(3).Info("Some targets might go unhealthy. Ensuring a requeue happens", "requeueIn", minNextCheck.Truncate(time.Second).String())
 		return ctrl.Result{RequeueAfter: minNextCheck}, nil
@@ -338,54 +401,64 @@ func (r *MachineHealthCheckReconciler) PatchUnhealthyTargets(ctx context.Context
 					return errList
 				}
 
-				cloneOwnerRef := &metav1.OwnerReference{
-					APIVersion: clusterv1.GroupVersion.String(),
-					Kind:       "Machine",
-					Name:       t.Machine.Name,
-					UID:        t.Machine.UID,
-				}
-
-				from, err := external.Get(ctx, r.Client, m.Spec.RemediationTemplate, t.Machine.Namespace)
-				if err != nil {
-					conditions.MarkFalse(m, clusterv1.ExternalRemediationTemplateAvailable, clusterv1.ExternalRemediationTemplateNotFound, clusterv1.ConditionSeverityError, err.Error())
-					errList = append(errList, errors.Wrapf(err, "error retrieving remediation template %v %q for machine %q in namespace %q within cluster %q", m.Spec.RemediationTemplate.GroupVersionKind(), m.Spec.RemediationTemplate.Name, t.Machine.Name, t.Machine.Namespace, m.Spec.ClusterName))
-					return errList
-				}
-
-				generateTemplateInput := &external.GenerateTemplateInput{
-					Template:    from,
-					TemplateRef: m.Spec.RemediationTemplate,
-					Namespace:   t.Machine.Namespace,
-					ClusterName: t.Machine.ClusterName,
-					OwnerRef:    cloneOwnerRef,
-				}
-				to, err := external.GenerateTemplate(generateTemplateInput)
-				if err != nil {
-					errList = append(errList, errors.Wrapf(err, "failed to create template for remediation request %v %q for machine %q in namespace %q within cluster %q", m.Spec.RemediationTemplate.GroupVersionKind(), m.Spec.RemediationTemplate.Name, t.Machine.Name, t.Machine.Namespace, m.Spec.ClusterName))
-					return errList
-				}
-
-				// Set the Remediation Request to match the Machine name, the name is used to
-				// guarantee uniqueness between runs. A Machine should only ever have a single
-				// remediation object of a specific GVK created.
-				//
-				// NOTE: This doesn't guarantee uniqueness across different MHC objects watching
-				// the same Machine, users are in charge of setting health checks and remediation properly.
-				to.SetName(t.Machine.Name)
-
-				logger.Info("Target has failed health check, creating an external remediation request", "remediation request name", to.GetName(), "target", t.string(), "reason", condition.Reason, "message", condition.Message)
-				// Create the external clone.
-				if err := r.Client.Create(ctx, to); err != nil {
-					conditions.MarkFalse(m, clusterv1.ExternalRemediationRequestAvailable, clusterv1.ExternalRemediationRequestCreationFailed, clusterv1.ConditionSeverityError, err.Error())
-					errList = append(errList, errors.Wrapf(err, "error creating remediation request for machine %q in namespace %q within cluster %q", t.Machine.Name, t.Machine.Namespace, t.Machine.ClusterName))
-					return errList
+				if remediationRateLimited(m) {
+					logger.Info("Target has failed health check, but remediation is rate limited so skipping remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+				} else {
+					cloneOwnerRef := &metav1.OwnerReference{
+						APIVersion: clusterv1.GroupVersion.String(),
+						Kind:       "Machine",
+						Name:       t.Machine.Name,
+						UID:        t.Machine.UID,
+					}
+
+					from, err := external.Get(ctx, r.Client, m.Spec.RemediationTemplate, t.Machine.Namespace)
+					if err != nil {
+						conditions.MarkFalse(m, clusterv1.ExternalRemediationTemplateAvailable, clusterv1.ExternalRemediationTemplateNotFound, clusterv1.ConditionSeverityError, err.Error())
+						errList = append(errList, errors.Wrapf(err, "error retrieving remediation template %v %q for machine %q in namespace %q within cluster %q", m.Spec.RemediationTemplate.GroupVersionKind(), m.Spec.RemediationTemplate.Name, t.Machine.Name, t.Machine.Namespace, m.Spec.ClusterName))
+						return errList
+					}
+
+					generateTemplateInput := &external.GenerateTemplateInput{
+						Template:    from,
+						TemplateRef: m.Spec.RemediationTemplate,
+						Namespace:   t.Machine.Namespace,
+						ClusterName: t.Machine.ClusterName,
+						OwnerRef:    cloneOwnerRef,
+					}
+					to, err := external.GenerateTemplate(generateTemplateInput)
+					if err != nil {
+						errList = append(errList, errors.Wrapf(err, "failed to create template for remediation request %v %q for machine %q in namespace %q within cluster %q", m.Spec.RemediationTemplate.GroupVersionKind(), m.Spec.RemediationTemplate.Name, t.Machine.Name, t.Machine.Namespace, m.Spec.ClusterName))
+						return errList
+					}
+
+					// Set the Remediation Request to match the Machine name, the name is used to
+					// guarantee uniqueness between runs. A Machine should only ever have a single
+					// remediation object of a specific GVK created.
+					//
+					// NOTE: This doesn't guarantee uniqueness across different MHC objects watching
+					// the same Machine, users are in charge of setting health checks and remediation properly.
+					to.SetName(t.Machine.Name)
+
+					logger.Info("Target has failed health check, creating an external remediation request", "remediation request name", to.GetName(), "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+					// Create the external clone.
+					if err := r.Client.Create(ctx, to); err != nil {
+						conditions.MarkFalse(m, clusterv1.ExternalRemediationRequestAvailable, clusterv1.ExternalRemediationRequestCreationFailed, clusterv1.ConditionSeverityError, err.Error())
+						errList = append(errList, errors.Wrapf(err, "error creating remediation request for machine %q in namespace %q within cluster %q", t.Machine.Name, t.Machine.Namespace, t.Machine.ClusterName))
+						return errList
+					}
+					recordRemediation(m, t.Machine.Name)
 				}
 			} else {
 				logger.Info("Target has failed health check, marking for remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
 				// NOTE: MHC is responsible for creating MachineOwnerRemediatedCondition if missing or to trigger another remediation if the previous one is completed;
 				// instead, if a remediation is in already progress, the remediation owner is responsible for completing the process and MHC should not overwrite the condition.
 				if !conditions.Has(t.Machine, clusterv1.MachineOwnerRemediatedCondition) || conditions.IsTrue(t.Machine, clusterv1.MachineOwnerRemediatedCondition) {
-					conditions.MarkFalse(t.Machine, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+					if remediationRateLimited(m) {
+						logger.Info("Remediation is rate limited, skipping marking the target for remediation", "target", t.string())
+					} else {
+						conditions.MarkFalse(t.Machine, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+						recordRemediation(m, t.Machine.Name)
+					}
 				}
 			}
 		}
@@ -517,9 +590,147 @@ func (r *MachineHealthCheckReconciler) watchClusterNodes(ctx context.Context, cl
 	return nil
 }
 
+func (r *MachineHealthCheckReconciler) watchClusterPods(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck) error {
+	// If there is no tracker, don't watch remote pods
+	if r.Tracker == nil {
+		return nil
+	}
+
+	r.podWatchesLock.Lock()
+	defer r.podWatchesLock.Unlock()
+
+	if r.podWatches == nil {
+		r.podWatches = map[types.NamespacedName]*clusterPodsWatches{}
+	}
+	key := types.NamespacedName{Namespace: m.Namespace, Name: m.Name}
+	watches, ok := r.podWatches[key]
+	if !ok {
+		watches = &clusterPodsWatches{cluster: util.ObjectKey(cluster), names: sets.NewString()}
+		r.podWatches[key] = watches
+	}
+
+	// Watch only the pods selected by the probes checking pods; the watches are started for each
+	// MachineHealthCheck, so they can be stopped when its probes change.
+	names := sets.NewString()
+	for _, p := range m.Spec.UnhealthyProbes {
+		if p.Type != clusterv1.PodNotReadyUnhealthyProbeType || p.Pod == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&p.Pod.Selector)
+		if err != nil {
+			return errors.Wrap(err, "failed to build pod selector")
+		}
+
+		name := fmt.Sprintf("machinehealthcheck-watchClusterPods-%s-%s-%s", m.Name, p.Pod.Namespace, selector.String())
+		if err := r.Tracker.Watch(ctx, remote.WatchInput{
+			Name:          name,
+			Cluster:       watches.cluster,
+			Watcher:       r.controller,
+			Kind:          &corev1.Pod{},
+			EventHandler:  handler.EnqueueRequestsFromMapFunc(r.podToMachineHealthCheck),
+			Predicates:    []predicate.Predicate{podReadyChanged()},
+			Namespace:     p.Pod.Namespace,
+			LabelSelector: selector,
+		}); err != nil {
+			return err
+		}
+		watches.names.Insert(name)
+		names.Insert(name)
+	}
+
+	// Stop the watches of the probes removed from the MachineHealthCheck.
+	return r.stopClusterPodsWatchesLH(key, names)
+}
+
+// stopClusterPodsWatches stops the watches of the pods started for a MachineHealthCheck, except the ones to keep.
+func (r *MachineHealthCheckReconciler) stopClusterPodsWatches(mhc types.NamespacedName, keep sets.String) error {
+	r.podWatchesLock.Lock()
+	defer r.podWatchesLock.Unlock()
+
+	return r.stopClusterPodsWatchesLH(mhc, keep)
+}
+
+// stopClusterPodsWatchesLH stops the watches of the pods started for a MachineHealthCheck, except the ones to keep.
+// Note, this method requires r.podWatchesLock to already be held (LH=lock held).
+func (r *MachineHealthCheckReconciler) stopClusterPodsWatchesLH(mhc types.NamespacedName, keep sets.String) error {
+	watches, ok := r.podWatches[mhc]
+	if !ok || r.Tracker == nil {
+		return nil
+	}
+
+	for _, name := range watches.names.List() {
+		if keep.Has(name) {
+			continue
+		}
+		if err := r.Tracker.StopWatch(watches.cluster, name); err != nil {
+			return errors.Wrapf(err, "failed to stop watch %s", name)
+		}
+		watches.names.Delete(name)
+	}
+	if watches.names.Len() == 0 {
+		delete(r.podWatches, mhc)
+	}
+	return nil
+}
+
+// podToMachineHealthCheck maps events from Pod objects to the MachineHealthCheck
+// objects that check the Machine of the node the Pod is running on.
+func (r *MachineHealthCheckReconciler) podToMachineHealthCheck(o client.Object) []reconcile.Request {
+	pod, ok := o.(*corev1.Pod)
+	if !ok {
+		panic(fmt.Sprintf("Expected a corev1.Pod, got %T", o))
+	}
+
+	if pod.Spec.NodeName == "" {
+		return nil
+	}
+
+	machine, err := r.getMachineFromNode(context.TODO(), pod.Spec.NodeName)
+	if machine == nil || err != nil {
+		return nil
+	}
+
+	return r.machineToMachineHealthCheck(machine)
+}
+
+// podReadyChanged returns a predicate filtering the Pod delete events and
+// the Pod update events which change whether the Pod is Ready.
+func podReadyChanged() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return true },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			oldReady, _ := podReadyCondition(oldPod)
+			newReady, _ := podReadyCondition(newPod)
+			return oldReady != newReady
+		},
+	}
+}
+
 // isAllowedRemediation checks the value of the MaxUnhealthy field to determine
 // whether remediation should be allowed or not
 func isAllowedRemediation(mhc *clusterv1.MachineHealthCheck) bool {
+	// UnhealthyRange takes precedence over MaxUnhealthy.
+	if mhc.Spec.UnhealthyRange != nil {
+		min, max, err := clusterv1.ParseUnhealthyRange(*mhc.Spec.UnhealthyRange)
+		if err != nil {
+			return false
+		}
+
+		// Remediation is not allowed if unhealthy is outside of the range
+		unhealthy := unhealthyMachineCount(mhc)
+		return unhealthy >= min && unhealthy <= max
+	}
+
 	// TODO(JoelSpeed): return an error from isAllowedRemediation when maxUnhealthy
 	// is nil, we expect it to be defaulted always.
 	if mhc.Spec.MaxUnhealthy == nil {
@@ -536,6 +747,13 @@ func isAllowedRemediation(mhc *clusterv1.MachineHealthCheck) bool {
 }
 
 func getMaxUnhealthy(mhc *clusterv1.MachineHealthCheck) (int, error) {
+	if mhc.Spec.UnhealthyRange != nil {
+		_, max, err := clusterv1.ParseUnhealthyRange(*mhc.Spec.UnhealthyRange)
+		if err != nil {
+			return 0, err
+		}
+		return max, nil
+	}
 	if mhc.Spec.MaxUnhealthy == nil {
 		return 0, errors.New("spec.maxUnhealthy must be set")
 	}
@@ -552,6 +770,50 @@ func unhealthyMachineCount(mhc *clusterv1.MachineHealthCheck) int {
 	return int(mhc.Status.ExpectedMachines - mhc.Status.CurrentHealthy)
 }
 
+// pruneRecentRemediations drops the remediations which are no longer within the window
+// of the remediation rate limit, and updates the number of remediations in the window.
+func pruneRecentRemediations(mhc *clusterv1.MachineHealthCheck, now time.Time) {
+	var recent []clusterv1.MachineHealthCheckRemediation
+	if rl := mhc.Spec.RemediationRateLimit; rl != nil {
+		for _, r := range mhc.Status.RecentRemediations {
+			if now.Sub(r.Timestamp.Time) < rl.Window.Duration {
+				recent = append(recent, r)
+			}
+		}
+	}
+	mhc.Status.RecentRemediations = recent
+	mhc.Status.RemediationsInWindow = int32(len(recent))
+}
+
+// recordRemediation records a machine marked for remediation, if remediation is rate limited.
+func recordRemediation(mhc *clusterv1.MachineHealthCheck, machineName string) {
+	if mhc.Spec.RemediationRateLimit == nil {
+		return
+	}
+	mhc.Status.RecentRemediations = append(mhc.Status.RecentRemediations, clusterv1.MachineHealthCheckRemediation{
+		Machine:   machineName,
+		Timestamp: metav1.Now(),
+	})
+	mhc.Status.RemediationsInWindow = int32(len(mhc.Status.RecentRemediations))
+}
+
+// remediationRateLimited returns true if no further machines can be marked for remediation
+// within the window of the remediation rate limit.
+func remediationRateLimited(mhc *clusterv1.MachineHealthCheck) bool {
+	rl := mhc.Spec.RemediationRateLimit
+	return rl != nil && mhc.Status.RemediationsInWindow >= rl.MaxRemediations
+}
+
+// nextRemediationAllowed returns how long until a further machine can be marked for remediation,
+// or 0 if remediation is not rate limited.
+func nextRemediationAllowed(mhc *clusterv1.MachineHealthCheck, now time.Time) time.Duration {
+	if !remediationRateLimited(mhc) || len(mhc.Status.RecentRemediations) == 0 {
+		return 0
+	}
+	oldest := mhc.Status.RecentRemediations[0].Timestamp.Time
+	return oldest.Add(mhc.Spec.RemediationRateLimit.Window.Duration).Sub(now)
+}
+
 func machineNames(machines []*clusterv1.Machine) []string {
 	result := make([]string, 0, len(machines))
 	for _, m := range machines {
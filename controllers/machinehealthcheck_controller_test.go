@@ -1667,6 +1667,7 @@ func TestIsAllowedRemediation(t *testing.T) {
 	testCases := []struct {
 		name               string
 		maxUnhealthy       *intstr.IntOrString
+		unhealthyRange     *string
 		expectedMachines   int32
 		currentHealthy     int32
 		allowed            bool
@@ -1728,6 +1729,42 @@ func TestIsAllowedRemediation(t *testing.T) {
 			currentHealthy:   int32(2),
 			allowed:          true,
 		},
+		{
+			name:             "when unhealthyRange is not a range",
+			unhealthyRange:   pointer.StringPtr("abcdef"),
+			expectedMachines: int32(5),
+			currentHealthy:   int32(2),
+			allowed:          false,
+		},
+		{
+			name:             "when current unhealthy is below unhealthyRange",
+			unhealthyRange:   pointer.StringPtr("[2-3]"),
+			expectedMachines: int32(5),
+			currentHealthy:   int32(4),
+			allowed:          false,
+		},
+		{
+			name:             "when current unhealthy is within unhealthyRange",
+			unhealthyRange:   pointer.StringPtr("[2-3]"),
+			expectedMachines: int32(5),
+			currentHealthy:   int32(2),
+			allowed:          true,
+		},
+		{
+			name:             "when current unhealthy is above unhealthyRange",
+			unhealthyRange:   pointer.StringPtr("[2-3]"),
+			expectedMachines: int32(5),
+			currentHealthy:   int32(1),
+			allowed:          false,
+		},
+		{
+			name:             "when unhealthyRange takes precedence over maxUnhealthy",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "100%"},
+			unhealthyRange:   pointer.StringPtr("[0-1]"),
+			expectedMachines: int32(5),
+			currentHealthy:   int32(2),
+			allowed:          false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1737,6 +1774,7 @@ func TestIsAllowedRemediation(t *testing.T) {
 			mhc := &clusterv1.MachineHealthCheck{
 				Spec: clusterv1.MachineHealthCheckSpec{
 					MaxUnhealthy:       tc.maxUnhealthy,
+					UnhealthyRange:     tc.unhealthyRange,
 					NodeStartupTimeout: &metav1.Duration{Duration: 1 * time.Millisecond},
 				},
 				Status: clusterv1.MachineHealthCheckStatus{
@@ -1755,6 +1793,7 @@ func TestGetMaxUnhealthy(t *testing.T) {
 	testCases := []struct {
 		name                 string
 		maxUnhealthy         *intstr.IntOrString
+		unhealthyRange       *string
 		expectedMaxUnhealthy int
 		actualMachineCount   int32
 		expectedErr          error
@@ -1794,6 +1833,14 @@ func TestGetMaxUnhealthy(t *testing.T) {
 			expectedMaxUnhealthy: 4,
 			expectedErr:          nil,
 		},
+		{
+			name:                 "when unhealthyRange is set",
+			maxUnhealthy:         &intstr.IntOrString{Type: intstr.String, StrVal: "100%"},
+			unhealthyRange:       pointer.StringPtr("[1-3]"),
+			actualMachineCount:   7,
+			expectedMaxUnhealthy: 3,
+			expectedErr:          nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1802,7 +1849,8 @@ func TestGetMaxUnhealthy(t *testing.T) {
 
 			mhc := &clusterv1.MachineHealthCheck{
 				Spec: clusterv1.MachineHealthCheckSpec{
-					MaxUnhealthy: tc.maxUnhealthy,
+					MaxUnhealthy:   tc.maxUnhealthy,
+					UnhealthyRange: tc.unhealthyRange,
 				},
 				Status: clusterv1.MachineHealthCheckStatus{
 					ExpectedMachines: tc.actualMachineCount,
@@ -2163,3 +2211,91 @@ func TestPatchTargets(t *testing.T) {
 	// Target with wrong patch helper will fail but the other one will be patched.
 	g.Expect(len(r.PatchHealthyTargets(context.TODO(), log.NullLogger{}, []healthCheckTarget{target1, target3}, defaultCluster, mhc))).To(BeNumerically(">", 0))
 }
+
+func TestPruneRecentRemediations(t *testing.T) {
+	now := time.Now()
+	recent := []clusterv1.MachineHealthCheckRemediation{
+		{Machine: "old", Timestamp: metav1.NewTime(now.Add(-20 * time.Minute))},
+		{Machine: "new", Timestamp: metav1.NewTime(now.Add(-5 * time.Minute))},
+	}
+
+	t.Run("drops remediations outside of the window", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mhc := &clusterv1.MachineHealthCheck{
+			Spec: clusterv1.MachineHealthCheckSpec{
+				RemediationRateLimit: &clusterv1.RemediationRateLimit{MaxRemediations: 1, Window: metav1.Duration{Duration: 10 * time.Minute}},
+			},
+			Status: clusterv1.MachineHealthCheckStatus{RecentRemediations: recent},
+		}
+		pruneRecentRemediations(mhc, now)
+		g.Expect(mhc.Status.RecentRemediations).To(HaveLen(1))
+		g.Expect(mhc.Status.RecentRemediations[0].Machine).To(Equal("new"))
+		g.Expect(mhc.Status.RemediationsInWindow).To(Equal(int32(1)))
+		g.Expect(remediationRateLimited(mhc)).To(BeTrue())
+		g.Expect(nextRemediationAllowed(mhc, now)).To(Equal(5 * time.Minute))
+	})
+
+	t.Run("drops all the remediations without a rate limit", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mhc := &clusterv1.MachineHealthCheck{
+			Status: clusterv1.MachineHealthCheckStatus{RecentRemediations: recent, RemediationsInWindow: 2},
+		}
+		pruneRecentRemediations(mhc, now)
+		g.Expect(mhc.Status.RecentRemediations).To(BeEmpty())
+		g.Expect(mhc.Status.RemediationsInWindow).To(BeZero())
+		g.Expect(remediationRateLimited(mhc)).To(BeFalse())
+		g.Expect(nextRemediationAllowed(mhc, now)).To(BeZero())
+	})
+}
+
+func TestPatchUnhealthyTargetsRateLimited(t *testing.T) {
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+	g := NewWithT(t)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	mhc.Spec.RemediationRateLimit = &clusterv1.RemediationRateLimit{MaxRemediations: 2, Window: metav1.Duration{Duration: 10 * time.Minute}}
+
+	objs := []client.Object{mhc}
+	machines := []*clusterv1.Machine{}
+	for i := 0; i < 3; i++ {
+		machine := newTestMachine(fmt.Sprintf("machine%d", i), namespace, clusterName, "nodeName", labels)
+		conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityWarning, "")
+		machines = append(machines, machine)
+		objs = append(objs, machine)
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	targets := []healthCheckTarget{}
+	for _, machine := range machines {
+		patchHelper, err := patch.NewHelper(machine, cl)
+		g.Expect(err).ToNot(HaveOccurred())
+		targets = append(targets, healthCheckTarget{MHC: mhc, Machine: machine, patchHelper: patchHelper, Node: &corev1.Node{}})
+	}
+
+	// Only the first two targets are marked for remediation, the third exceeds the rate limit.
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, targets, defaultCluster, mhc)).To(BeEmpty())
+	for i, machine := range machines {
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+		g.Expect(conditions.Has(machine, clusterv1.MachineOwnerRemediatedCondition)).To(Equal(i < 2))
+	}
+	g.Expect(mhc.Status.RemediationsInWindow).To(Equal(int32(2)))
+	g.Expect(mhc.Status.RecentRemediations).To(HaveLen(2))
+	g.Expect(mhc.Status.RecentRemediations[0].Machine).To(Equal("machine0"))
+	g.Expect(mhc.Status.RecentRemediations[1].Machine).To(Equal("machine1"))
+}
@@ -23,9 +23,11 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/util/conditions"
@@ -55,6 +57,14 @@ const (
 	EventDetectedUnhealthy string = "DetectedUnhealthy"
 )
 
+const (
+	// nodeLeaseNamespace is the namespace of the leases renewed by the kubelets.
+	nodeLeaseNamespace = "kube-node-lease"
+
+	// defaultNodeLeaseDuration is the duration of the node leases if not specified by the lease.
+	defaultNodeLeaseDuration = 40 * time.Second
+)
+
 // healthCheckTarget contains the information required to perform a health check
 // on the node to determine if any remediation is required.
 type healthCheckTarget struct {
@@ -63,6 +73,14 @@ type healthCheckTarget struct {
 	MHC         *clusterv1.MachineHealthCheck
 	patchHelper *patch.Helper
 	nodeMissing bool
+
+	// Pods are the pods running on the node selected by the PodNotReady probes.
+	Pods []corev1.Pod
+	// PodsMissingSince records since when no pod selected by a PodNotReady probe is running
+	// on the node, keyed by probePodsKey.
+	PodsMissingSince map[string]time.Time
+	// NodeLease is the lease of the node, if checked by a NodeLeaseExpired probe.
+	NodeLease *coordinationv1.Lease
 }
 
 func (t *healthCheckTarget) string() string {
@@ -88,6 +106,7 @@ func (t *healthCheckTarget) nodeName() string {
 // - The Machine did not get a node before `timeoutForMachineToHaveNode` elapses
 // - The Node has gone away
 // - Any condition on the node is matched for the given timeout
+// - Any probe on the node is failing for the given timeout
 // If the target doesn't currently need rememdiation, provide a duration after
 // which the target should next be checked.
 // The target should be requeued after this duration.
@@ -154,12 +173,121 @@ func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachi
 			nextCheckTimes = append(nextCheckTimes, nextCheck)
 		}
 	}
+
+	// check probes
+	for _, p := range t.MHC.Spec.UnhealthyProbes {
+		failing, failingSince, message := t.runProbe(p, now)
+		if !failing {
+			continue
+		}
+
+		// If the probe has been failing for longer than the timeout, return
+		// true with no requeue time.
+		if failingSince.Add(p.Timeout.Duration).Before(now) {
+			conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyProbeReason, clusterv1.ConditionSeverityWarning, "Probe %s on node is failing for more than %s: %s", p.Type, p.Timeout.Duration.String(), message)
+			logger.V(3).Info("Target is unhealthy: probe is failing longer than allowed timeout", "probe", p.Type, "message", message, "timeout", p.Timeout.Duration.String())
+			return true, time.Duration(0)
+		}
+
+		durationUnhealthy := now.Sub(failingSince)
+		nextCheck := p.Timeout.Duration - durationUnhealthy + time.Second
+		if nextCheck > 0 {
+			nextCheckTimes = append(nextCheckTimes, nextCheck)
+		}
+	}
 	return false, minDuration(nextCheckTimes)
 }
 
+// runProbe runs an unhealthy probe against the target, returning whether the probe is failing,
+// since when, and a message describing the failure.
+// Failures without a timestamp, e.g. node labels, are reported as failing since the zero time.
+func (t *healthCheckTarget) runProbe(probe clusterv1.UnhealthyProbe, now time.Time) (bool, time.Time, string) {
+	switch probe.Type {
+	case clusterv1.PodNotReadyUnhealthyProbeType:
+		if probe.Pod == nil {
+			return false, time.Time{}, ""
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&probe.Pod.Selector)
+		if err != nil {
+			return false, time.Time{}, ""
+		}
+		var failing *corev1.Pod
+		var failingSince time.Time
+		found := false
+		for i := range t.Pods {
+			pod := &t.Pods[i]
+			if pod.Namespace != probe.Pod.Namespace || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			found = true
+			ready, since := podReadyCondition(pod)
+			if ready {
+				continue
+			}
+			// Report the pod not Ready for the longest time.
+			if failing == nil || since.Before(failingSince) {
+				failing, failingSince = pod, since
+			}
+		}
+		if !found {
+			// A node without the pods selected by the probe, e.g. because the pod of a DaemonSet
+			// is not scheduled or was deleted, fails the probe.
+			since, ok := t.PodsMissingSince[probePodsKey(t.Node, probe.Pod)]
+			if !ok {
+				since = now
+			}
+			return true, since, fmt.Sprintf("No pod matching the selector is running in namespace %s", probe.Pod.Namespace)
+		}
+		if failing == nil {
+			return false, time.Time{}, ""
+		}
+		return true, failingSince, fmt.Sprintf("Pod %s/%s is not Ready", failing.Namespace, failing.Name)
+	case clusterv1.NodeLabelUnhealthyProbeType:
+		if value, ok := t.Node.Labels[probe.Key]; ok && (probe.Value == "" || value == probe.Value) {
+			return true, time.Time{}, fmt.Sprintf("Label %s=%s is present", probe.Key, value)
+		}
+	case clusterv1.NodeTaintUnhealthyProbeType:
+		for _, taint := range t.Node.Spec.Taints {
+			if taint.Key != probe.Key || (probe.Value != "" && taint.Value != probe.Value) {
+				continue
+			}
+			var since time.Time
+			if taint.TimeAdded != nil {
+				since = taint.TimeAdded.Time
+			}
+			return true, since, fmt.Sprintf("Taint %s=%s:%s is present", taint.Key, taint.Value, taint.Effect)
+		}
+	case clusterv1.NodeLeaseExpiredUnhealthyProbeType:
+		// Nodes without a lease, e.g. when running an older kubelet, are not checked.
+		if t.NodeLease == nil || t.NodeLease.Spec.RenewTime == nil {
+			return false, time.Time{}, ""
+		}
+		leaseDuration := defaultNodeLeaseDuration
+		if t.NodeLease.Spec.LeaseDurationSeconds != nil {
+			leaseDuration = time.Duration(*t.NodeLease.Spec.LeaseDurationSeconds) * time.Second
+		}
+		renewTime := t.NodeLease.Spec.RenewTime.Time
+		if now.Sub(renewTime) > leaseDuration {
+			return true, renewTime, fmt.Sprintf("Lease not renewed since %s", renewTime.Format(time.RFC3339))
+		}
+	}
+	return false, time.Time{}, ""
+}
+
+// podReadyCondition returns whether the pod is Ready, and since when the Ready condition is in the current state.
+func podReadyCondition(pod *corev1.Pod) (bool, time.Time) {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue, c.LastTransitionTime.Time
+		}
+	}
+	return false, pod.CreationTimestamp.Time
+}
+
 // getTargetsFromMHC uses the MachineHealthCheck's selector to fetch machines
 // and their nodes targeted by the health check, ready for health checking.
-func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, clusterClient client.Reader, mhc *clusterv1.MachineHealthCheck) ([]healthCheckTarget, error) {
+// The pods and the leases checked by the probes are read with probeReader.
+func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, clusterClient client.Reader, probeReader client.Reader, mhc *clusterv1.MachineHealthCheck) ([]healthCheckTarget, error) {
 	machines, err := r.getMachinesFromMHC(ctx, mhc)
 	if err != nil {
 		return nil, errors.Wrap(err, "error getting machines from MachineHealthCheck")
@@ -168,6 +296,11 @@ func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, cl
 		return nil, nil
 	}
 
+	podsByNode, err := getProbePodsByNode(ctx, probeReader, mhc)
+	if err != nil {
+		return nil, err
+	}
+
 	targets := []healthCheckTarget{}
 	for k := range machines {
 		patchHelper, err := patch.NewHelper(&machines[k], r.Client)
@@ -189,11 +322,124 @@ func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, cl
 			target.nodeMissing = true
 		}
 		target.Node = node
+		if node != nil {
+			target.Pods = podsByNode[node.Name]
+			target.PodsMissingSince = r.probePodsMissingSinceForNode(mhc, node, target.Pods)
+			target.NodeLease, err = getProbeNodeLease(ctx, probeReader, mhc, node)
+			if err != nil {
+				return nil, err
+			}
+		}
 		targets = append(targets, target)
 	}
 	return targets, nil
 }
 
+// getProbePodsByNode fetches the pods selected by the MachineHealthCheck's
+// PodNotReady probes, grouped by the name of the node they are running on.
+func getProbePodsByNode(ctx context.Context, clusterClient client.Reader, mhc *clusterv1.MachineHealthCheck) (map[string][]corev1.Pod, error) {
+	podsByNode := map[string][]corev1.Pod{}
+	seen := map[types.NamespacedName]bool{}
+	for _, p := range mhc.Spec.UnhealthyProbes {
+		if p.Type != clusterv1.PodNotReadyUnhealthyProbeType || p.Pod == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&p.Pod.Selector)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build pod selector")
+		}
+
+		podList := &corev1.PodList{}
+		if err := clusterClient.List(
+			ctx,
+			podList,
+			client.MatchingLabelsSelector{Selector: selector},
+			client.InNamespace(p.Pod.Namespace),
+		); err != nil {
+			return nil, errors.Wrap(err, "failed to list pods")
+		}
+		for _, pod := range podList.Items {
+			key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+			if pod.Spec.NodeName == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+		}
+	}
+	return podsByNode, nil
+}
+
+// probePodsMissingSinceForNode returns since when no pod selected by each of the MachineHealthCheck's PodNotReady
+// probes is running on the node, keyed by probePodsKey. The time is recorded when the pods are first found missing,
+// and forgotten as soon as they are running again; this avoids remediating the node while a DaemonSet pod is replaced.
+func (r *MachineHealthCheckReconciler) probePodsMissingSinceForNode(mhc *clusterv1.MachineHealthCheck, node *corev1.Node, pods []corev1.Pod) map[string]time.Time {
+	r.probePodsMissingSinceLock.Lock()
+	defer r.probePodsMissingSinceLock.Unlock()
+
+	if r.probePodsMissingSince == nil {
+		r.probePodsMissingSince = map[string]time.Time{}
+	}
+
+	now := time.Now()
+	missingSince := map[string]time.Time{}
+	for _, p := range mhc.Spec.UnhealthyProbes {
+		if p.Type != clusterv1.PodNotReadyUnhealthyProbeType || p.Pod == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&p.Pod.Selector)
+		if err != nil {
+			continue
+		}
+		key := probePodsKey(node, p.Pod)
+		found := false
+		for i := range pods {
+			if pods[i].Namespace == p.Pod.Namespace && selector.Matches(labels.Set(pods[i].Labels)) {
+				found = true
+				break
+			}
+		}
+		if found {
+			delete(r.probePodsMissingSince, key)
+			continue
+		}
+		if _, ok := r.probePodsMissingSince[key]; !ok {
+			r.probePodsMissingSince[key] = now
+		}
+		missingSince[key] = r.probePodsMissingSince[key]
+	}
+	return missingSince
+}
+
+// probePodsKey returns the key identifying the pods selected by a PodNotReady probe on a node.
+func probePodsKey(node *corev1.Node, pod *clusterv1.PodProbeSelector) string {
+	return fmt.Sprintf("%s/%s/%s", node.UID, pod.Namespace, metav1.FormatLabelSelector(&pod.Selector))
+}
+
+// getProbeNodeLease fetches the lease of the node, if checked by one of the
+// MachineHealthCheck's NodeLeaseExpired probes.
+func getProbeNodeLease(ctx context.Context, clusterClient client.Reader, mhc *clusterv1.MachineHealthCheck, node *corev1.Node) (*coordinationv1.Lease, error) {
+	for _, p := range mhc.Spec.UnhealthyProbes {
+		if p.Type != clusterv1.NodeLeaseExpiredUnhealthyProbeType {
+			continue
+		}
+
+		lease := &coordinationv1.Lease{}
+		leaseKey := types.NamespacedName{
+			Namespace: nodeLeaseNamespace,
+			Name:      node.Name,
+		}
+		if err := clusterClient.Get(ctx, leaseKey, lease); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, errors.Wrap(err, "error getting node lease")
+		}
+		return lease, nil
+	}
+	return nil, nil
+}
+
 //getMachinesFromMHC fetches Machines matched by the MachineHealthCheck's
 // label selector
 func (r *MachineHealthCheckReconciler) getMachinesFromMHC(ctx context.Context, mhc *clusterv1.MachineHealthCheck) ([]clusterv1.Machine, error) {
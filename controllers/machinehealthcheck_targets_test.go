@@ -22,11 +22,14 @@ import (
 
 	. "github.com/onsi/gomega"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -143,7 +146,7 @@ func TestGetTargetsFromMHC(t *testing.T) {
 				t.patchHelper = patchHelper
 			}
 
-			targets, err := reconciler.getTargetsFromMHC(ctx, k8sClient, testMHC)
+			targets, err := reconciler.getTargetsFromMHC(ctx, k8sClient, k8sClient, testMHC)
 			gs.Expect(err).ToNot(HaveOccurred())
 
 			gs.Expect(len(targets)).To(Equal(len(tc.expectedTargets)))
@@ -330,6 +333,270 @@ func TestHealthCheckTargets(t *testing.T) {
 	}
 }
 
+func TestGetTargetsFromMHCWithProbes(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	mhcSelector := map[string]string{"cluster": clusterName, "machine-group": "foo"}
+	podLabels := map[string]string{"k8s-app": "cni"}
+
+	testMHC := &clusterv1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-mhc",
+			Namespace: namespace,
+		},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			ClusterName: clusterName,
+			Selector: metav1.LabelSelector{
+				MatchLabels: mhcSelector,
+			},
+			UnhealthyProbes: []clusterv1.UnhealthyProbe{
+				{
+					Type: clusterv1.PodNotReadyUnhealthyProbeType,
+					Pod: &clusterv1.PodProbeSelector{
+						Namespace: "kube-system",
+						Selector:  metav1.LabelSelector{MatchLabels: podLabels},
+					},
+				},
+				{
+					Type:    clusterv1.NodeLeaseExpiredUnhealthyProbeType,
+					Timeout: metav1.Duration{Duration: 2 * time.Minute},
+				},
+			},
+		},
+	}
+
+	newPod := func(name, namespace, nodeName string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+		}
+	}
+
+	testNode1 := newTestNode("node1")
+	testMachine1 := newTestMachine("machine1", namespace, clusterName, testNode1.Name, mhcSelector)
+	testNode2 := newTestNode("node2")
+	testMachine2 := newTestMachine("machine2", namespace, clusterName, testNode2.Name, mhcSelector)
+	lease1 := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: testNode1.Name, Namespace: nodeLeaseNamespace}}
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	k8sClient := fake.NewClientBuilder().WithObjects(
+		testMHC,
+		testNode1, testMachine1, testNode2, testMachine2,
+		lease1,
+		newPod("cni-1", "kube-system", testNode1.Name, podLabels),
+		newPod("cni-2", "kube-system", testNode2.Name, podLabels),
+		newPod("other", "kube-system", testNode1.Name, map[string]string{"k8s-app": "other"}),
+		newPod("cni-other-namespace", "default", testNode1.Name, podLabels),
+	).Build()
+
+	reconciler := &MachineHealthCheckReconciler{
+		Client: k8sClient,
+	}
+
+	targets, err := reconciler.getTargetsFromMHC(ctx, k8sClient, k8sClient, testMHC)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(targets).To(HaveLen(2))
+
+	podNames := func(pods []corev1.Pod) []string {
+		names := []string{}
+		for _, p := range pods {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+	for _, target := range targets {
+		switch target.Machine.Name {
+		case testMachine1.Name:
+			g.Expect(podNames(target.Pods)).To(ConsistOf("cni-1"))
+			g.Expect(target.NodeLease).ToNot(BeNil())
+			g.Expect(target.NodeLease.Name).To(Equal(testNode1.Name))
+		case testMachine2.Name:
+			g.Expect(podNames(target.Pods)).To(ConsistOf("cni-2"))
+			g.Expect(target.NodeLease).To(BeNil())
+		}
+	}
+}
+
+func TestNeedsRemediationWithProbes(t *testing.T) {
+	podProbe := clusterv1.UnhealthyProbe{
+		Type: clusterv1.PodNotReadyUnhealthyProbeType,
+		Pod: &clusterv1.PodProbeSelector{
+			Namespace: "kube-system",
+			Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "cni"}},
+		},
+		Timeout: metav1.Duration{Duration: 5 * time.Minute},
+	}
+	labelProbe := clusterv1.UnhealthyProbe{
+		Type:  clusterv1.NodeLabelUnhealthyProbeType,
+		Key:   "storage.example.com/agent",
+		Value: "failed",
+	}
+	taintProbe := clusterv1.UnhealthyProbe{
+		Type:    clusterv1.NodeTaintUnhealthyProbeType,
+		Key:     "storage.example.com/unavailable",
+		Timeout: metav1.Duration{Duration: 5 * time.Minute},
+	}
+	leaseProbe := clusterv1.UnhealthyProbe{
+		Type:    clusterv1.NodeLeaseExpiredUnhealthyProbeType,
+		Timeout: metav1.Duration{Duration: 5 * time.Minute},
+	}
+
+	cniPod := func(ready corev1.ConditionStatus, since time.Duration) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "cni", Namespace: "kube-system", Labels: map[string]string{"k8s-app": "cni"}},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: ready, LastTransitionTime: metav1.NewTime(time.Now().Add(-since))},
+				},
+			},
+		}
+	}
+	nodeLease := func(renewedAgo time.Duration) *coordinationv1.Lease {
+		renewTime := metav1.NewMicroTime(time.Now().Add(-renewedAgo))
+		return &coordinationv1.Lease{
+			Spec: coordinationv1.LeaseSpec{
+				LeaseDurationSeconds: pointer.Int32Ptr(40),
+				RenewTime:            &renewTime,
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc                     string
+		probe                    clusterv1.UnhealthyProbe
+		node                     *corev1.Node
+		pods                     []corev1.Pod
+		podsMissingFor           time.Duration
+		lease                    *coordinationv1.Lease
+		expectedNeedsRemediation bool
+		expectedNextCheck        time.Duration
+	}{
+		{
+			desc:  "when the pod is Ready",
+			probe: podProbe,
+			pods:  []corev1.Pod{cniPod(corev1.ConditionTrue, 10*time.Minute)},
+		},
+		{
+			desc:              "when the pod is not Ready for shorter than the timeout",
+			probe:             podProbe,
+			pods:              []corev1.Pod{cniPod(corev1.ConditionFalse, 200*time.Second)},
+			expectedNextCheck: 100 * time.Second,
+		},
+		{
+			desc:                     "when the pod is not Ready for longer than the timeout",
+			probe:                    podProbe,
+			pods:                     []corev1.Pod{cniPod(corev1.ConditionFalse, 400*time.Second)},
+			expectedNeedsRemediation: true,
+		},
+		{
+			desc:              "when the pod is missing for shorter than the timeout",
+			probe:             podProbe,
+			podsMissingFor:    200 * time.Second,
+			expectedNextCheck: 100 * time.Second,
+		},
+		{
+			desc:                     "when the pod is missing for longer than the timeout",
+			probe:                    podProbe,
+			podsMissingFor:           400 * time.Second,
+			expectedNeedsRemediation: true,
+		},
+		{
+			desc:  "when the node label has a different value",
+			probe: labelProbe,
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"storage.example.com/agent": "ok"}},
+			},
+		},
+		{
+			desc:  "when the node label is present",
+			probe: labelProbe,
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"storage.example.com/agent": "failed"}},
+			},
+			expectedNeedsRemediation: true,
+		},
+		{
+			desc:  "when the node taint is present for shorter than the timeout",
+			probe: taintProbe,
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+				Spec: corev1.NodeSpec{
+					Taints: []corev1.Taint{{Key: "storage.example.com/unavailable", Effect: corev1.TaintEffectNoExecute, TimeAdded: &metav1.Time{Time: time.Now().Add(-200 * time.Second)}}},
+				},
+			},
+			expectedNextCheck: 100 * time.Second,
+		},
+		{
+			desc:  "when the node taint without a timestamp is present",
+			probe: taintProbe,
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+				Spec: corev1.NodeSpec{
+					Taints: []corev1.Taint{{Key: "storage.example.com/unavailable", Effect: corev1.TaintEffectNoSchedule}},
+				},
+			},
+			expectedNeedsRemediation: true,
+		},
+		{
+			desc:  "when the node lease is renewed",
+			probe: leaseProbe,
+			lease: nodeLease(10 * time.Second),
+		},
+		{
+			desc:              "when the node lease is expired for shorter than the timeout",
+			probe:             leaseProbe,
+			lease:             nodeLease(200 * time.Second),
+			expectedNextCheck: 100 * time.Second,
+		},
+		{
+			desc:                     "when the node lease is expired for longer than the timeout",
+			probe:                    leaseProbe,
+			lease:                    nodeLease(400 * time.Second),
+			expectedNeedsRemediation: true,
+		},
+		{
+			desc:  "when the node lease does not exist",
+			probe: leaseProbe,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := NewWithT(t)
+
+			node := tc.node
+			if node == nil {
+				node = newTestNode("node1")
+			}
+			target := healthCheckTarget{
+				MHC: &clusterv1.MachineHealthCheck{
+					Spec: clusterv1.MachineHealthCheckSpec{
+						UnhealthyProbes: []clusterv1.UnhealthyProbe{tc.probe},
+					},
+				},
+				Machine:   newTestMachine("machine1", "test-mhc", "test-cluster", "node1", nil),
+				Node:      node,
+				Pods:      tc.pods,
+				NodeLease: tc.lease,
+			}
+			if tc.podsMissingFor > 0 {
+				target.PodsMissingSince = map[string]time.Time{
+					probePodsKey(node, tc.probe.Pod): time.Now().Add(-tc.podsMissingFor),
+				}
+			}
+
+			needsRemediation, nextCheck := target.needsRemediation(ctrl.LoggerFrom(ctx), 10*time.Minute)
+			g.Expect(needsRemediation).To(Equal(tc.expectedNeedsRemediation))
+			g.Expect(nextCheck.Truncate(time.Second)).To(Equal(tc.expectedNextCheck))
+			if tc.expectedNeedsRemediation {
+				g.Expect(conditions.GetReason(target.Machine, clusterv1.MachineHealthCheckSuccededCondition)).To(Equal(clusterv1.UnhealthyProbeReason))
+			}
+		})
+	}
+}
+
 func newTestMachine(name, namespace, clusterName, nodeName string, labels map[string]string) *clusterv1.Machine {
 	// Copy the labels so that the map is unique to each test Machine
 	l := make(map[string]string)
@@ -311,6 +311,10 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 	switch {
 	case diff < 0:
 		diff *= -1
+		if _, ok := ms.Annotations[clusterv1.DisableMachineCreateAnnotation]; ok {
+			log.V(2).Info("Too few replicas, but machine creation is disabled", "need", *(ms.Spec.Replicas), "current", len(machines))
+			return nil
+		}
 		log.Info("Too few replicas", "need", *(ms.Spec.Replicas), "creating", diff)
 
 		var (
@@ -140,11 +140,15 @@ func Revision(obj runtime.Object) (int64, error) {
 }
 
 var annotationsToSkip = map[string]bool{
-	corev1.LastAppliedConfigAnnotation:  true,
-	clusterv1.RevisionAnnotation:        true,
-	clusterv1.RevisionHistoryAnnotation: true,
-	clusterv1.DesiredReplicasAnnotation: true,
-	clusterv1.MaxReplicasAnnotation:     true,
+	corev1.LastAppliedConfigAnnotation:       true,
+	clusterv1.RevisionAnnotation:             true,
+	clusterv1.RevisionHistoryAnnotation:      true,
+	clusterv1.DesiredReplicasAnnotation:      true,
+	clusterv1.MaxReplicasAnnotation:          true,
+	clusterv1.CanaryReadyAnnotation:          true,
+	clusterv1.CanaryCompletedAnnotation:      true,
+	clusterv1.CanaryAbortedAnnotation:        true,
+	clusterv1.DisableMachineCreateAnnotation: true,
 
 	// Exclude the conversion annotation, to avoid infinite loops between the conversion webhook
 	// and the MachineDeployment controller syncing the annotations between a MachineDeployment
@@ -318,7 +322,7 @@ func ReplicasAnnotationsNeedUpdate(ms *clusterv1.MachineSet, desiredReplicas, ma
 
 // MaxUnavailable returns the maximum unavailable machines a rolling deployment can take.
 func MaxUnavailable(deployment clusterv1.MachineDeployment) int32 {
-	if !IsRollingUpdate(&deployment) || *(deployment.Spec.Replicas) == 0 {
+	if !IsRollingUpdate(&deployment) || deployment.Spec.Strategy.RollingUpdate == nil || *(deployment.Spec.Replicas) == 0 {
 		return int32(0)
 	}
 	// Error caught by validation
@@ -331,7 +335,7 @@ func MaxUnavailable(deployment clusterv1.MachineDeployment) int32 {
 
 // MaxSurge returns the maximum surge machines a rolling deployment can take.
 func MaxSurge(deployment clusterv1.MachineDeployment) int32 {
-	if !IsRollingUpdate(&deployment) {
+	if !IsRollingUpdate(&deployment) || deployment.Spec.Strategy.RollingUpdate == nil {
 		return int32(0)
 	}
 	// Error caught by validation
@@ -510,9 +514,35 @@ func GetAvailableReplicaCountForMachineSets(machineSets []*clusterv1.MachineSet)
 	return totalAvailableReplicas
 }
 
-// IsRollingUpdate returns true if the strategy type is a rolling update.
+// IsRollingUpdate returns true if the strategy type is a rolling update, or a canary rollout
+// which continues as a rolling update once canary machines are verified.
 func IsRollingUpdate(deployment *clusterv1.MachineDeployment) bool {
-	return deployment.Spec.Strategy.Type == clusterv1.RollingUpdateMachineDeploymentStrategyType
+	return deployment.Spec.Strategy.Type == clusterv1.RollingUpdateMachineDeploymentStrategyType ||
+		deployment.Spec.Strategy.Type == clusterv1.CanaryMachineDeploymentStrategyType
+}
+
+// CanaryReplicas returns the number of canary machines of a deployment using the Canary strategy,
+// which is at least 1 and at most the deployment replicas.
+func CanaryReplicas(deployment *clusterv1.MachineDeployment) int32 {
+	replicas := int32(1)
+	if deployment.Spec.Strategy.Canary != nil && deployment.Spec.Strategy.Canary.Replicas != nil {
+		// Error caught by validation
+		canary, _ := intstrutil.GetValueFromIntOrPercent(deployment.Spec.Strategy.Canary.Replicas, int(*(deployment.Spec.Replicas)), true)
+		replicas = integer.Int32Max(int32(canary), 1)
+	}
+	return integer.Int32Min(replicas, *(deployment.Spec.Replicas))
+}
+
+// IsCanaryCompleted returns true if the canary machines of the machine set have been verified.
+func IsCanaryCompleted(ms *clusterv1.MachineSet) bool {
+	_, ok := ms.Annotations[clusterv1.CanaryCompletedAnnotation]
+	return ok
+}
+
+// IsCanaryAborted returns true if the canary rollout of the machine set has been stopped.
+func IsCanaryAborted(ms *clusterv1.MachineSet) bool {
+	_, ok := ms.Annotations[clusterv1.CanaryAbortedAnnotation]
+	return ok
 }
 
 // DeploymentComplete considers a deployment to be complete once all of its desired replicas
@@ -531,28 +561,53 @@ func DeploymentComplete(deployment *clusterv1.MachineDeployment, newStatus *clus
 func NewMSNewReplicas(deployment *clusterv1.MachineDeployment, allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet) (int32, error) {
 	switch deployment.Spec.Strategy.Type {
 	case clusterv1.RollingUpdateMachineDeploymentStrategyType:
-		// Check if we can scale up.
-		maxSurge, err := intstrutil.GetValueFromIntOrPercent(deployment.Spec.Strategy.RollingUpdate.MaxSurge, int(*(deployment.Spec.Replicas)), true)
-		if err != nil {
-			return 0, err
-		}
-		// Find the total number of machines
-		currentMachineCount := TotalMachineSetsReplicaSum(allMSs)
-		maxTotalMachines := *(deployment.Spec.Replicas) + int32(maxSurge)
-		if currentMachineCount >= maxTotalMachines {
-			// Cannot scale up.
-			return *(newMS.Spec.Replicas), nil
+		return newMSNewReplicasRollingUpdate(deployment, allMSs, newMS)
+	case clusterv1.CanaryMachineDeploymentStrategyType:
+		// Until canary machines are verified, scale up only to the number of canary machines;
+		// nothing to verify if there are no old machines to replace.
+		if getOldReplicaCountForMachineSets(allMSs, newMS) > 0 && !IsCanaryCompleted(newMS) {
+			return integer.Int32Max(*(newMS.Spec.Replicas), CanaryReplicas(deployment)), nil
 		}
-		// Scale up.
-		scaleUpCount := maxTotalMachines - currentMachineCount
-		// Do not exceed the number of desired replicas.
-		scaleUpCount = integer.Int32Min(scaleUpCount, *(deployment.Spec.Replicas)-*(newMS.Spec.Replicas))
-		return *(newMS.Spec.Replicas) + scaleUpCount, nil
+		return newMSNewReplicasRollingUpdate(deployment, allMSs, newMS)
+	case clusterv1.OnDeleteMachineDeploymentStrategyType:
+		// Scale up only to replace the machines removed from old machine sets.
+		return integer.Int32Max(*(deployment.Spec.Replicas)-getOldReplicaCountForMachineSets(allMSs, newMS), 0), nil
 	default:
 		return 0, fmt.Errorf("deployment strategy %v isn't supported", deployment.Spec.Strategy.Type)
 	}
 }
 
+// getOldReplicaCountForMachineSets returns the sum of Replicas of the given machine sets, excluding the new machine set.
+func getOldReplicaCountForMachineSets(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet) int32 {
+	totalReplicas := int32(0)
+	for _, ms := range allMSs {
+		if ms != nil && ms.Name != newMS.Name && ms.Spec.Replicas != nil {
+			totalReplicas += *(ms.Spec.Replicas)
+		}
+	}
+	return totalReplicas
+}
+
+func newMSNewReplicasRollingUpdate(deployment *clusterv1.MachineDeployment, allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet) (int32, error) {
+	// Check if we can scale up.
+	maxSurge, err := intstrutil.GetValueFromIntOrPercent(deployment.Spec.Strategy.RollingUpdate.MaxSurge, int(*(deployment.Spec.Replicas)), true)
+	if err != nil {
+		return 0, err
+	}
+	// Find the total number of machines
+	currentMachineCount := TotalMachineSetsReplicaSum(allMSs)
+	maxTotalMachines := *(deployment.Spec.Replicas) + int32(maxSurge)
+	if currentMachineCount >= maxTotalMachines {
+		// Cannot scale up.
+		return *(newMS.Spec.Replicas), nil
+	}
+	// Scale up.
+	scaleUpCount := maxTotalMachines - currentMachineCount
+	// Do not exceed the number of desired replicas.
+	scaleUpCount = integer.Int32Min(scaleUpCount, *(deployment.Spec.Replicas)-*(newMS.Spec.Replicas))
+	return *(newMS.Spec.Replicas) + scaleUpCount, nil
+}
+
 // IsSaturated checks if the new machine set is saturated by comparing its size with its deployment size.
 // Both the deployment and the machine set have to believe this machine set can own all of the desired
 // replicas in the deployment and the annotation helps in achieving that. All machines of the MachineSet
@@ -528,6 +528,26 @@ func TestNewMSNewReplicas(t *testing.T) {
 			clusterv1.RollingUpdateMachineDeploymentStrategyType,
 			6, 2, 10, 6,
 		},
+		{
+			"canary - scale up only to canary replicas",
+			clusterv1.CanaryMachineDeploymentStrategyType,
+			6, 0, 10, 1,
+		},
+		{
+			"canary - do not scale down",
+			clusterv1.CanaryMachineDeploymentStrategyType,
+			6, 2, 10, 2,
+		},
+		{
+			"on delete - scale up to replace deleted machines",
+			clusterv1.OnDeleteMachineDeploymentStrategyType,
+			6, 0, 10, 1,
+		},
+		{
+			"on delete - can not scale up",
+			clusterv1.OnDeleteMachineDeploymentStrategyType,
+			5, 0, 10, 0,
+		},
 	}
 	newDeployment := generateDeployment("nginx")
 	newRC := generateMS(newDeployment)
@@ -558,6 +578,63 @@ func TestNewMSNewReplicas(t *testing.T) {
 	}
 }
 
+func TestCanaryReplicas(t *testing.T) {
+	tests := []struct {
+		name        string
+		depReplicas int32
+		canary      *clusterv1.MachineCanaryDeployment
+		expected    int32
+	}{
+		{
+			name:        "defaults to 1",
+			depReplicas: 10,
+			expected:    1,
+		},
+		{
+			name:        "absolute number",
+			depReplicas: 10,
+			canary:      &clusterv1.MachineCanaryDeployment{Replicas: intOrStrPtr(intstr.FromInt(3))},
+			expected:    3,
+		},
+		{
+			name:        "percentage is rounded up",
+			depReplicas: 10,
+			canary:      &clusterv1.MachineCanaryDeployment{Replicas: intOrStrPtr(intstr.FromString("15%"))},
+			expected:    2,
+		},
+		{
+			name:        "at least 1",
+			depReplicas: 10,
+			canary:      &clusterv1.MachineCanaryDeployment{Replicas: intOrStrPtr(intstr.FromInt(0))},
+			expected:    1,
+		},
+		{
+			name:        "at most deployment replicas",
+			depReplicas: 2,
+			canary:      &clusterv1.MachineCanaryDeployment{Replicas: intOrStrPtr(intstr.FromInt(3))},
+			expected:    2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			deployment := generateDeployment("nginx")
+			*(deployment.Spec.Replicas) = test.depReplicas
+			deployment.Spec.Strategy = &clusterv1.MachineDeploymentStrategy{
+				Type:   clusterv1.CanaryMachineDeploymentStrategyType,
+				Canary: test.canary,
+			}
+			g.Expect(CanaryReplicas(&deployment)).To(Equal(test.expected))
+		})
+	}
+}
+
+func intOrStrPtr(i intstr.IntOrString) *intstr.IntOrString {
+	return &i
+}
+
 func TestDeploymentComplete(t *testing.T) {
 	deployment := func(desired, current, updated, available, maxUnavailable, maxSurge int32) *clusterv1.MachineDeployment {
 		return &clusterv1.MachineDeployment{
@@ -25,12 +25,16 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
@@ -81,11 +85,44 @@ func (t *ClusterCacheTracker) GetClient(ctx context.Context, cluster client.Obje
 	return accessor.client, nil
 }
 
+// GetAPIReader returns a client.Reader for the given cluster reading directly from its API server, bypassing the cache,
+// e.g. for objects that can't be cached because it would require to watch too many objects in the cluster.
+func (t *ClusterCacheTracker) GetAPIReader(ctx context.Context, cluster client.ObjectKey) (client.Reader, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	accessor, err := t.getClusterAccessorLH(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return accessor.apiReader, nil
+}
+
+// GetRESTConfig returns the rest.Config for the given cluster, e.g. for libraries that create their own clients.
+func (t *ClusterCacheTracker) GetRESTConfig(ctx context.Context, cluster client.ObjectKey) (*rest.Config, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	accessor, err := t.getClusterAccessorLH(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return rest.CopyConfig(accessor.config), nil
+}
+
 // clusterAccessor represents the combination of a delegating client, cache, and watches for a remote cluster.
 type clusterAccessor struct {
-	cache   *stoppableCache
-	client  client.Client
-	watches sets.String
+	ctx       context.Context
+	config    *rest.Config
+	mapper    meta.RESTMapper
+	cache     *stoppableCache
+	client    client.Client
+	apiReader client.Reader
+	watches   sets.String
+	// scopedWatches stops the informers of the scoped watches, keyed by the name of the watch.
+	scopedWatches map[string]context.CancelFunc
 }
 
 // clusterAccessorExists returns true if a clusterAccessor exists for cluster.
@@ -175,9 +212,14 @@ func (t *ClusterCacheTracker) newClusterAccessor(ctx context.Context, cluster cl
 	}
 
 	return &clusterAccessor{
-		cache:   cache,
-		client:  delegatingClient,
-		watches: sets.NewString(),
+		ctx:           cacheCtx,
+		config:        config,
+		mapper:        mapper,
+		cache:         cache,
+		client:        delegatingClient,
+		apiReader:     c,
+		watches:       sets.NewString(),
+		scopedWatches: map[string]context.CancelFunc{},
 	}, nil
 }
 
@@ -225,6 +267,12 @@ type WatchInput struct {
 
 	// Predicates is used to filter resource events.
 	Predicates []predicate.Predicate
+
+	// Namespace and LabelSelector, if set, scope the watch to the objects in the namespace matching the selector.
+	// Scoped watches use a dedicated informer instead of the cache shared by the other watches for the cluster,
+	// which runs until the watch is stopped with StopWatch or the cache of the cluster is stopped.
+	Namespace     string
+	LabelSelector labels.Selector
 }
 
 // Watch watches a remote cluster for resource events. If the watch already exists based on input.Name, this is a no-op.
@@ -247,15 +295,85 @@ func (t *ClusterCacheTracker) Watch(ctx context.Context, input WatchInput) error
 	}
 
 	// Need to create the watch
-	if err := input.Watcher.Watch(source.NewKindWithCache(input.Kind, a.cache), input.EventHandler, input.Predicates...); err != nil {
+	var src source.Source = source.NewKindWithCache(input.Kind, a.cache)
+	var stopInformer context.CancelFunc
+	if input.Namespace != "" || input.LabelSelector != nil {
+		informer, err := t.newScopedInformer(a, input)
+		if err != nil {
+			return errors.Wrap(err, "error creating watch")
+		}
+		src = &source.Informer{Informer: informer}
+
+		informerCtx, cancel := context.WithCancel(a.ctx)
+		go informer.Run(informerCtx.Done())
+		stopInformer = cancel
+	}
+	if err := input.Watcher.Watch(src, input.EventHandler, input.Predicates...); err != nil {
+		if stopInformer != nil {
+			stopInformer()
+		}
 		return errors.Wrap(err, "error creating watch")
 	}
 
 	a.watches.Insert(input.Name)
+	if stopInformer != nil {
+		a.scopedWatches[input.Name] = stopInformer
+	}
 
 	return nil
 }
 
+// StopWatch stops a scoped watch, i.e. a watch with a Namespace or a LabelSelector, by stopping its informer, so the
+// watch does not keep a connection to the remote cluster once it is not required anymore; a new watch with the same
+// name can be created afterwards. If the watch or the cluster don't exist, this is a no-op.
+// NOTE: The other watches use the cache shared by all the watches for the cluster, and they can't be stopped.
+func (t *ClusterCacheTracker) StopWatch(cluster client.ObjectKey, name string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	a, exists := t.clusterAccessors[cluster]
+	if !exists || !a.watches.Has(name) {
+		return nil
+	}
+
+	stopInformer, ok := a.scopedWatches[name]
+	if !ok {
+		return errors.Errorf("watch %q is not a scoped watch and it can't be stopped", name)
+	}
+
+	t.log.V(4).Info("Stopping watch", "namespace", cluster.Namespace, "cluster", cluster.Name, "name", name)
+	stopInformer()
+	delete(a.scopedWatches, name)
+	a.watches.Delete(name)
+	return nil
+}
+
+// newScopedInformer creates an informer for the objects in the namespace and matching the label selector
+// of a scoped watch.
+func (t *ClusterCacheTracker) newScopedInformer(a *clusterAccessor, input WatchInput) (toolscache.SharedIndexInformer, error) {
+	gvk, err := apiutil.GVKForObject(input.Kind, t.scheme)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	restClient, err := apiutil.RESTClientForGVK(gvk, false, a.config, serializer.NewCodecFactory(t.scheme))
+	if err != nil {
+		return nil, err
+	}
+
+	selector := labels.Everything()
+	if input.LabelSelector != nil {
+		selector = input.LabelSelector
+	}
+	listWatch := toolscache.NewFilteredListWatchFromClient(restClient, mapping.Resource.Resource, input.Namespace, func(options *metav1.ListOptions) {
+		options.LabelSelector = selector.String()
+	})
+	return toolscache.NewSharedIndexInformer(listWatch, input.Kind, 0, toolscache.Indexers{}), nil
+}
+
 // healthCheckInput provides the input for the healthCheckCluster method
 type healthCheckInput struct {
 	cluster            client.ObjectKey
@@ -17,9 +17,12 @@ limitations under the License.
 package remote
 
 import (
+	"context"
+
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -41,10 +44,12 @@ func NewTestClusterCacheTracker(log logr.Logger, cl client.Client, scheme *runti
 	}
 
 	testCacheTracker.clusterAccessors[objKey] = &clusterAccessor{
-
-		cache:   nil,
-		client:  delegatingClient,
-		watches: sets.NewString(watchObjects...),
+		config:        &rest.Config{},
+		cache:         nil,
+		client:        delegatingClient,
+		apiReader:     cl,
+		watches:       sets.NewString(watchObjects...),
+		scopedWatches: map[string]context.CancelFunc{},
 	}
 	return testCacheTracker
 }
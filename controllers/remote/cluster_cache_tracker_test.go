@@ -23,6 +23,7 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
@@ -174,6 +175,60 @@ var _ = Describe("ClusterCache Tracker suite", func() {
 				return len(c.ch)
 			}).Should(Equal(0))
 		})
+
+		It("scoped by label selector should stop sending notifications once stopped", func() {
+			By("Creating the scoped watch")
+			Expect(cct.Watch(ctx, WatchInput{
+				Name:          "scoped-watch",
+				Cluster:       util.ObjectKey(clusterA),
+				Watcher:       w,
+				Kind:          &clusterv1.Cluster{},
+				EventHandler:  handler.EnqueueRequestsFromMapFunc(mapper),
+				Namespace:     testNamespace.Name,
+				LabelSelector: labels.SelectorFromSet(labels.Set{"watched": "true"}),
+			})).To(Succeed())
+
+			By("Ensuring no watch notifications arrive for the clusters not matching the selector")
+			Consistently(func() int {
+				return len(c.ch)
+			}).Should(Equal(0))
+
+			By("Labeling the cluster")
+			clusterA.Labels = map[string]string{"watched": "true"}
+			Expect(k8sClient.Update(ctx, clusterA)).Should(Succeed())
+
+			By("Waiting to receive the watch notification")
+			Expect(<-c.ch).To(Equal("mapped-" + clusterA.Name))
+
+			By("Stopping the watch")
+			Expect(cct.StopWatch(util.ObjectKey(clusterA), "scoped-watch")).To(Succeed())
+
+			By("Updating the cluster")
+			clusterA.Annotations["update1"] = "1"
+			Expect(k8sClient.Update(ctx, clusterA)).Should(Succeed())
+
+			By("Ensuring no watch notifications arrive")
+			Consistently(func() int {
+				return len(c.ch)
+			}).Should(Equal(0))
+		})
+
+		It("not scoped should not be stopped", func() {
+			By("Creating the watch")
+			Expect(cct.Watch(ctx, WatchInput{
+				Name:         "watch1",
+				Cluster:      util.ObjectKey(clusterA),
+				Watcher:      w,
+				Kind:         &clusterv1.Cluster{},
+				EventHandler: handler.EnqueueRequestsFromMapFunc(mapper),
+			})).To(Succeed())
+
+			By("Waiting to receive the watch notification")
+			Expect(<-c.ch).To(Equal("mapped-" + clusterA.Name))
+
+			By("Trying to stop the watch")
+			Expect(cct.StopWatch(util.ObjectKey(clusterA), "watch1")).NotTo(Succeed())
+		})
 	})
 })
 
@@ -17,18 +17,51 @@ limitations under the License.
 package v1alpha3
 
 import (
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 func (src *KubeadmControlPlane) ConvertTo(destRaw conversion.Hub) error {
 	dest := destRaw.(*v1alpha4.KubeadmControlPlane)
-	return Convert_v1alpha3_KubeadmControlPlane_To_v1alpha4_KubeadmControlPlane(src, dest, nil)
+	if err := Convert_v1alpha3_KubeadmControlPlane_To_v1alpha4_KubeadmControlPlane(src, dest, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1alpha4.KubeadmControlPlane{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dest.Spec.RolloutStrategy = restored.Spec.RolloutStrategy
+	dest.Spec.EtcdSnapshots = restored.Spec.EtcdSnapshots
+	dest.Spec.EtcdRestore = restored.Spec.EtcdRestore
+	dest.Spec.RolloutBefore = restored.Spec.RolloutBefore
+	dest.Status.CertificatesExpiryDate = restored.Status.CertificatesExpiryDate
+	dest.Spec.RotateCertificateAuthoritiesAfter = restored.Spec.RotateCertificateAuthoritiesAfter
+	dest.Status.CertificateAuthoritiesRotation = restored.Status.CertificateAuthoritiesRotation
+	dest.Spec.RemediationStrategy = restored.Spec.RemediationStrategy
+	dest.Status.RemediationHistory = restored.Status.RemediationHistory
+	dest.Status.LastEtcdRestore = restored.Status.LastEtcdRestore
+	dest.Spec.DrainPolicy = restored.Spec.DrainPolicy
+
+	return nil
 }
 
 func (dest *KubeadmControlPlane) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1alpha4.KubeadmControlPlane)
-	return Convert_v1alpha4_KubeadmControlPlane_To_v1alpha3_KubeadmControlPlane(src, dest, nil)
+	if err := Convert_v1alpha4_KubeadmControlPlane_To_v1alpha3_KubeadmControlPlane(src, dest, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	if err := utilconversion.MarshalData(src, dest); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (src *KubeadmControlPlaneList) ConvertTo(destRaw conversion.Hub) error {
@@ -40,3 +73,13 @@ func (dest *KubeadmControlPlaneList) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1alpha4.KubeadmControlPlaneList)
 	return Convert_v1alpha4_KubeadmControlPlaneList_To_v1alpha3_KubeadmControlPlaneList(src, dest, nil)
 }
+
+func Convert_v1alpha4_KubeadmControlPlaneSpec_To_v1alpha3_KubeadmControlPlaneSpec(in *v1alpha4.KubeadmControlPlaneSpec, out *KubeadmControlPlaneSpec, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because spec.rolloutStrategy, spec.rolloutBefore, spec.etcdSnapshots, spec.etcdRestore, spec.rotateCertificateAuthoritiesAfter and spec.remediationStrategy have been added in v1alpha4.
+	return autoConvert_v1alpha4_KubeadmControlPlaneSpec_To_v1alpha3_KubeadmControlPlaneSpec(in, out, s)
+}
+
+func Convert_v1alpha4_KubeadmControlPlaneStatus_To_v1alpha3_KubeadmControlPlaneStatus(in *v1alpha4.KubeadmControlPlaneStatus, out *KubeadmControlPlaneStatus, s apiconversion.Scope) error {
+	// NOTE: custom conversion func is required because status.certificatesExpiryDate, status.certificateAuthoritiesRotation, status.remediationHistory and status.lastEtcdRestore have been added in v1alpha4.
+	return autoConvert_v1alpha4_KubeadmControlPlaneStatus_To_v1alpha3_KubeadmControlPlaneStatus(in, out, s)
+}
@@ -149,3 +149,77 @@ const (
 	// generate a machine object
 	MachineGenerationFailedReason = "MachineGenerationFailed"
 )
+
+const (
+	// EtcdSnapshotSucceededCondition documents the outcome of the last etcd snapshot taken by the KubeadmControlPlane;
+	// when the condition is true, the message reports the name and the time of the last successful snapshot.
+	// NOTE: This condition exists only if etcd snapshots are enabled.
+	EtcdSnapshotSucceededCondition clusterv1.ConditionType = "EtcdSnapshotSucceeded"
+
+	// EtcdSnapshotFailedReason (Severity=Warning) documents a KubeadmControlPlane failing to take an etcd snapshot
+	// or to store it in the configured sink.
+	EtcdSnapshotFailedReason = "EtcdSnapshotFailed"
+
+	// EtcdSnapshotRetentionFailedReason (Severity=Warning) documents a KubeadmControlPlane failing to delete
+	// snapshots exceeding the configured retention.
+	EtcdSnapshotRetentionFailedReason = "EtcdSnapshotRetentionFailed"
+)
+
+const (
+	// EtcdRestoreSucceededCondition documents the restore of the etcd cluster from the snapshot defined in spec.etcdRestore.
+	// NOTE: This condition exists only if the control plane has been initialized from an etcd snapshot.
+	EtcdRestoreSucceededCondition clusterv1.ConditionType = "EtcdRestoreSucceeded"
+
+	// EtcdRestoreInProgressReason (Severity=Info) documents a KubeadmControlPlane restoring the etcd cluster
+	// on the first control plane machine.
+	EtcdRestoreInProgressReason = "EtcdRestoreInProgress"
+
+	// EtcdRestoreFailedReason (Severity=Warning) documents a KubeadmControlPlane failing to prepare the etcd restore,
+	// e.g. because the snapshot or the cluster certificates cannot be found.
+	EtcdRestoreFailedReason = "EtcdRestoreFailed"
+
+	// EtcdRestoreNotConfiguredReason (Severity=Error) documents a KubeadmControlPlane that cannot restore the etcd cluster
+	// because the controller is not configured for it, i.e. the --management-cluster-endpoint flag is not set.
+	EtcdRestoreNotConfiguredReason = "EtcdRestoreNotConfigured"
+)
+
+const (
+	// MachinesCertificatesValidCondition documents that the certificates of the control plane machines
+	// are not about to expire; the threshold is defined by spec.rolloutBefore.certificatesExpiryDays, or it is
+	// 30 days when not set.
+	// NOTE: The expiry date of the certificates of each machine is reported in the
+	// controlplane.cluster.x-k8s.io/certificates-expiry annotation on the machine.
+	MachinesCertificatesValidCondition clusterv1.ConditionType = "MachinesCertificatesValid"
+
+	// CertificatesExpiringReason (Severity=Warning) documents the certificates of one or more control plane machines
+	// expiring within the threshold; when spec.rolloutBefore.certificatesExpiryDays is set, the machines are rolled out.
+	CertificatesExpiringReason = "CertificatesExpiring"
+)
+
+const (
+	// CertificateAuthoritiesRotatedCondition documents on the Cluster the rotation of the certificate authorities
+	// requested by spec.rotateCertificateAuthoritiesAfter on the KubeadmControlPlane.
+	// NOTE: This condition is set on the Cluster object, and it exists only if a rotation has been requested.
+	CertificateAuthoritiesRotatedCondition clusterv1.ConditionType = "CertificateAuthoritiesRotated"
+
+	// TrustingNewCertificateAuthoritiesReason (Severity=Info) documents the new certificate authorities being added
+	// to the trusted certificates, and the machines of the cluster being rolled out.
+	TrustingNewCertificateAuthoritiesReason = "TrustingNewCertificateAuthorities"
+
+	// SigningWithNewCertificateAuthoritiesReason (Severity=Info) documents the new certificate authorities being used
+	// for signing, and the machines of the cluster being rolled out.
+	SigningWithNewCertificateAuthoritiesReason = "SigningWithNewCertificateAuthorities"
+
+	// RemovingOldCertificateAuthoritiesReason (Severity=Info) documents the old certificate authorities being removed
+	// from the trusted certificates, and the machines of the cluster being rolled out.
+	RemovingOldCertificateAuthoritiesReason = "RemovingOldCertificateAuthorities"
+
+	// CertificateAuthoritiesRotationFailedReason (Severity=Warning) documents a KubeadmControlPlane failing to rotate
+	// the certificate authorities, e.g. because they have not been generated by the KubeadmControlPlane.
+	CertificateAuthoritiesRotationFailedReason = "CertificateAuthoritiesRotationFailed"
+
+	// CertificateAuthoritiesRotationBlockedReason (Severity=Warning) documents the rotation of the certificate authorities
+	// waiting for machines that are not rolled out by the rotation, e.g. the machines of a MachineDeployment using the
+	// OnDelete strategy, or machines not belonging to a MachineDeployment, which must be deleted manually.
+	CertificateAuthoritiesRotationBlockedReason = "CertificateAuthoritiesRotationBlocked"
+)
@@ -19,6 +19,7 @@ package v1alpha4
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 
 	cabpkv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
@@ -37,6 +38,74 @@ const (
 	// KubeadmClusterConfigurationAnnotation is a machine annotation that stores the json-marshalled string of KCP ClusterConfiguration.
 	// This annotation is used to detect any changes in ClusterConfiguration and trigger machine rollout in KCP.
 	KubeadmClusterConfigurationAnnotation = "controlplane.cluster.x-k8s.io/kubeadm-cluster-configuration"
+
+	// CertificatesExpiryAnnotation is a machine annotation that stores the expiry date of the certificates of the machine,
+	// in RFC3339 format. This annotation is used to report the expiry of the certificates and to trigger machine rollout in KCP,
+	// if spec.rolloutBefore.certificatesExpiryDays is set.
+	CertificatesExpiryAnnotation = "controlplane.cluster.x-k8s.io/certificates-expiry"
+
+	// CertificatesExpiryCheckedAnnotation is a machine annotation that stores the time when the expiry date of the certificates
+	// of the machine was last read from the workload cluster, in RFC3339 format. This annotation is used to periodically read
+	// the expiry date again, so certificates renewed in place, e.g. with kubeadm certs renew, are detected.
+	CertificatesExpiryCheckedAnnotation = "controlplane.cluster.x-k8s.io/certificates-expiry-checked"
+
+	// CertificateAuthoritiesGeneratedAnnotation is an annotation on the certificate authority Secrets of a cluster that
+	// stores the time when the certificate authorities were generated by a rotation, in RFC3339 format. This annotation is used to
+	// check if the certificate authorities must be rotated according to spec.rotateCertificateAuthoritiesAfter; when missing,
+	// the creation timestamp of the Secrets is used.
+	CertificateAuthoritiesGeneratedAnnotation = "controlplane.cluster.x-k8s.io/certificate-authorities-generated"
+)
+
+// RolloutStrategyType defines the rollout strategies for a KubeadmControlPlane.
+type RolloutStrategyType string
+
+const (
+	// RollingUpdateStrategyType replaces the old control plane machines by new ones using a rolling update,
+	// i.e. machines are replaced one at a time, either scaling up first or scaling down first.
+	RollingUpdateStrategyType RolloutStrategyType = "RollingUpdate"
+)
+
+// CertificateAuthoritiesRotationPhase defines the phases of the rotation of the certificate authorities of a cluster.
+// The machines of the cluster are rolled out in every phase.
+type CertificateAuthoritiesRotationPhase string
+
+const (
+	// TrustCertificateAuthoritiesRotationPhase adds the new certificate authorities to the trusted certificates,
+	// while the old certificate authorities are still used for signing.
+	TrustCertificateAuthoritiesRotationPhase CertificateAuthoritiesRotationPhase = "Trust"
+
+	// SignCertificateAuthoritiesRotationPhase uses the new certificate authorities for signing,
+	// while the old certificate authorities are still trusted.
+	SignCertificateAuthoritiesRotationPhase CertificateAuthoritiesRotationPhase = "Sign"
+
+	// CleanupCertificateAuthoritiesRotationPhase removes the old certificate authorities from the trusted certificates.
+	CleanupCertificateAuthoritiesRotationPhase CertificateAuthoritiesRotationPhase = "Cleanup"
+)
+
+// SmallControlPlaneRemediationPolicy defines if control planes with less than 3 replicas, which can't tolerate
+// the loss of a machine, are remediated.
+type SmallControlPlaneRemediationPolicy string
+
+const (
+	// NeverSmallControlPlaneRemediationPolicy does not remediate control planes with less than 3 replicas.
+	NeverSmallControlPlaneRemediationPolicy SmallControlPlaneRemediationPolicy = "Never"
+
+	// AcceptRiskSmallControlPlaneRemediationPolicy remediates control planes with less than 3 replicas, accepting
+	// the risk of losing the control plane, and the etcd data not included in the latest etcd snapshot, if any.
+	AcceptRiskSmallControlPlaneRemediationPolicy SmallControlPlaneRemediationPolicy = "AcceptRisk"
+)
+
+// EtcdSnapshotSinkType defines the types of storage for etcd snapshots.
+type EtcdSnapshotSinkType string
+
+const (
+	// SecretEtcdSnapshotSinkType stores etcd snapshots in the management cluster, split in chunks
+	// across Secrets in the namespace of the KubeadmControlPlane.
+	SecretEtcdSnapshotSinkType EtcdSnapshotSinkType = "Secret"
+
+	// ConfigMapEtcdSnapshotSinkType stores etcd snapshots in the management cluster, split in chunks
+	// across ConfigMaps in the namespace of the KubeadmControlPlane.
+	ConfigMapEtcdSnapshotSinkType EtcdSnapshotSinkType = "ConfigMap"
 )
 
 // KubeadmControlPlaneSpec defines the desired state of KubeadmControlPlane.
@@ -64,11 +133,200 @@ type KubeadmControlPlaneSpec struct {
 	// +optional
 	UpgradeAfter *metav1.Time `json:"upgradeAfter,omitempty"`
 
+	// RolloutBefore is a field to indicate a rollout should be performed
+	// if the specified criteria is met.
+	// +optional
+	RolloutBefore *RolloutBefore `json:"rolloutBefore,omitempty"`
+
 	// NodeDrainTimeout is the total amount of time that the controller will spend on draining a controlplane node
 	// The default value is 0, meaning that the node can be drained without any time limitations.
 	// NOTE: NodeDrainTimeout is different from `kubectl drain --timeout`
 	// +optional
 	NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+	// DrainPolicy defines how the controller drains a controlplane node before deleting the machine.
+	// When not set, all the pods are evicted in parallel and emptyDir data is deleted.
+	// +optional
+	DrainPolicy *clusterv1.MachineDrainPolicy `json:"drainPolicy,omitempty"`
+
+	// RolloutStrategy is the strategy to use to replace control plane machines with new ones.
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// EtcdSnapshots enables periodic snapshots of the etcd cluster managed by the control plane.
+	// Snapshots are not supported when using an external etcd cluster.
+	// +optional
+	EtcdSnapshots *EtcdSnapshots `json:"etcdSnapshots,omitempty"`
+
+	// EtcdRestore defines an etcd snapshot to restore when initializing the control plane, e.g. for recovering
+	// a cluster after all the control plane machines have been lost. The first control plane machine is bootstrapped
+	// with the etcd data restored from the snapshot, and the other machines join as usual.
+	// The existing cluster certificates are required, so machines and kubeconfigs of the cluster remain valid.
+	// NOTE: Once the restore completes it is recorded in status.lastEtcdRestore, and the same restore is not performed
+	// again, e.g. when the control plane is initialized again after remediating its only machine.
+	// +optional
+	EtcdRestore *EtcdRestore `json:"etcdRestore,omitempty"`
+
+	// RotateCertificateAuthoritiesAfter is a field to indicate the certificate authorities of the cluster
+	// (i.e. the cluster CA, the front proxy CA and the etcd CA, unless using an external etcd cluster) should be
+	// rotated after the specified time, if they have been generated before it. The rotation happens in three phases,
+	// and all the machines of the cluster are rolled out in every phase; the progress is reported in the
+	// CertificateAuthoritiesRotated condition on the Cluster.
+	// NOTE: Only the certificate authorities generated by the KubeadmControlPlane can be rotated.
+	// +optional
+	RotateCertificateAuthoritiesAfter *metav1.Time `json:"rotateCertificateAuthoritiesAfter,omitempty"`
+
+	// RemediationStrategy defines how the control plane machines marked as unhealthy by a MachineHealthCheck are remediated.
+	// +optional
+	RemediationStrategy *RemediationStrategy `json:"remediationStrategy,omitempty"`
+}
+
+// RolloutBefore describes when a rollout should be performed on the KCP machines.
+type RolloutBefore struct {
+	// CertificatesExpiryDays indicates a rollout needs to be performed if the
+	// certificates of the machine will expire within the specified days.
+	// NOTE: The certificates generated by kubeadm are valid for one year.
+	// +kubebuilder:validation:Minimum=7
+	// +optional
+	CertificatesExpiryDays *int32 `json:"certificatesExpiryDays,omitempty"`
+}
+
+// RolloutStrategy describes how to replace existing machines with new ones.
+type RolloutStrategy struct {
+	// Type of rollout. Currently the only supported strategy is "RollingUpdate".
+	// Default is RollingUpdate.
+	// +kubebuilder:validation:Enum=RollingUpdate
+	// +optional
+	Type RolloutStrategyType `json:"type,omitempty"`
+
+	// Rolling update config params. Present only if RolloutStrategyType = RollingUpdate.
+	// +optional
+	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
+}
+
+// RollingUpdate is used to control the desired behavior of rolling update.
+type RollingUpdate struct {
+	// The maximum number of control plane machines that can be scheduled above the desired number of machines.
+	// Value can be an absolute number, 1 or 0.
+	// Defaults to 1.
+	// Example: when this is set to 0, an old control plane machine is deleted before a new one is created;
+	// this requires at least 3 replicas, so the etcd quorum is preserved while the old machine is removed.
+	// +optional
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+}
+
+// RemediationStrategy defines how the control plane machines are remediated, and how remediation is retried when
+// the machines created as a replacement of unhealthy machines become unhealthy too, e.g. in case of a flapping node.
+type RemediationStrategy struct {
+	// MaxRetry is the maximum number of retries while attempting to remediate an unhealthy machine.
+	// A retry happens when a machine created as a replacement of an unhealthy machine becomes unhealthy too
+	// before MinHealthyPeriod elapses.
+	// If not set, remediation is retried indefinitely.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRetry *int32 `json:"maxRetry,omitempty"`
+
+	// RetryPeriod is the minimum time to wait before retrying remediation, i.e. before remediating a machine created
+	// as a replacement of an unhealthy machine.
+	// If not set, remediation is retried immediately.
+	// +optional
+	RetryPeriod *metav1.Duration `json:"retryPeriod,omitempty"`
+
+	// MinHealthyPeriod is the time after the last remediation when a replacement machine is considered healthy;
+	// when a machine becomes unhealthy after this period, its remediation is not counted as a retry.
+	// Defaults to 1h.
+	// +optional
+	MinHealthyPeriod *metav1.Duration `json:"minHealthyPeriod,omitempty"`
+
+	// SmallControlPlanePolicy defines if control planes with less than 3 replicas are remediated.
+	// A control plane with 1 replica is remediated by deleting the unhealthy machine and initializing the control
+	// plane again, restoring etcd from the latest snapshot if spec.etcdSnapshots is set; all the etcd data is lost
+	// otherwise, unless using an external etcd cluster.
+	// A control plane with 2 replicas is remediated by creating a replacement machine before deleting the unhealthy one.
+	// Defaults to Never.
+	// +kubebuilder:validation:Enum=Never;AcceptRisk
+	// +optional
+	SmallControlPlanePolicy SmallControlPlaneRemediationPolicy `json:"smallControlPlanePolicy,omitempty"`
+}
+
+// EtcdSnapshots defines the schedule and the storage of the etcd snapshots.
+type EtcdSnapshots struct {
+	// Interval is the time between two snapshots.
+	// Defaults to 24h; the minimum interval is 5m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Retention is the number of snapshots to keep; older snapshots are deleted
+	// after a new snapshot is successfully stored.
+	// Defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retention *int32 `json:"retention,omitempty"`
+
+	// Sink defines where snapshots are stored.
+	// +optional
+	Sink EtcdSnapshotSink `json:"sink,omitempty"`
+}
+
+// EtcdSnapshotSink defines the storage of the etcd snapshots.
+type EtcdSnapshotSink struct {
+	// Type of the storage. Supported values are "Secret" and "ConfigMap".
+	// Default is Secret.
+	// NOTE: etcd snapshots contain the Secrets of the workload cluster, and the ConfigMap sink stores them
+	// in plaintext, so ConfigMap should be used only if access to ConfigMaps in the management cluster
+	// is restricted like access to Secrets.
+	// +kubebuilder:validation:Enum=Secret;ConfigMap
+	// +optional
+	Type EtcdSnapshotSinkType `json:"type,omitempty"`
+}
+
+// EtcdRestore defines the etcd snapshot to restore when initializing the control plane.
+// Exactly one of SnapshotName or SecretRef must be set.
+type EtcdRestore struct {
+	// SnapshotName is the name of a snapshot taken by the KubeadmControlPlane, read from
+	// the sink defined in spec.etcdSnapshots, or from the default sink if spec.etcdSnapshots is not set.
+	// +optional
+	SnapshotName string `json:"snapshotName,omitempty"`
+
+	// SecretRef references a key of a Secret containing an etcd snapshot; the Secret must be
+	// in the namespace of the KubeadmControlPlane.
+	// +optional
+	SecretRef *EtcdSnapshotSecretReference `json:"secretRef,omitempty"`
+}
+
+// CertificateAuthoritiesRotationStatus reports the progress of the rotation of the certificate authorities of a cluster.
+type CertificateAuthoritiesRotationStatus struct {
+	// StartTime is the time when the rotation started, and the new certificate authorities were generated.
+	StartTime metav1.Time `json:"startTime"`
+
+	// Phase is the current phase of the rotation.
+	Phase CertificateAuthoritiesRotationPhase `json:"phase"`
+
+	// PhaseStartTime is the time when the current phase started; the machines
+	// of the cluster created before this time are rolled out.
+	PhaseStartTime metav1.Time `json:"phaseStartTime"`
+}
+
+// RemediationRecord records the remediation of a control plane machine.
+type RemediationRecord struct {
+	// Machine is the name of the remediated machine.
+	Machine string `json:"machine"`
+
+	// Timestamp is the time when the machine was remediated.
+	Timestamp metav1.Time `json:"timestamp"`
+
+	// RetryCount is the number of consecutive retries of the remediation, i.e. the number of times the machines
+	// created as a replacement of unhealthy machines have been remediated too.
+	RetryCount int32 `json:"retryCount"`
+}
+
+// EtcdSnapshotSecretReference references a key of a Secret containing an etcd snapshot.
+type EtcdSnapshotSecretReference struct {
+	// Name of the Secret.
+	Name string `json:"name"`
+
+	// Key of the Secret containing the snapshot.
+	Key string `json:"key"`
 }
 
 // KubeadmControlPlaneStatus defines the observed state of KubeadmControlPlane.
@@ -128,6 +386,27 @@ type KubeadmControlPlaneStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// CertificatesExpiryDate is the earliest expiry date of the certificates
+	// of the control plane machines; it is unset until the expiry date of
+	// the certificates has been read for at least one machine.
+	// +optional
+	CertificatesExpiryDate *metav1.Time `json:"certificatesExpiryDate,omitempty"`
+
+	// CertificateAuthoritiesRotation reports the progress of the rotation of the certificate
+	// authorities of the cluster; it is unset when no rotation is in progress.
+	// +optional
+	CertificateAuthoritiesRotation *CertificateAuthoritiesRotationStatus `json:"certificateAuthoritiesRotation,omitempty"`
+
+	// RemediationHistory records the most recent remediations of control plane machines, the last one being
+	// the most recent; at most 5 remediations are recorded.
+	// +optional
+	RemediationHistory []RemediationRecord `json:"remediationHistory,omitempty"`
+
+	// LastEtcdRestore is the spec.etcdRestore of the last completed restore of etcd from a snapshot;
+	// spec.etcdRestore is ignored while it is equal to this field.
+	// +optional
+	LastEtcdRestore *EtcdRestore `json:"lastEtcdRestore,omitempty"`
+
 	// Conditions defines current service state of the KubeadmControlPlane.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
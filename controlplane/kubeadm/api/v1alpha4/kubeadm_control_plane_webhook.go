@@ -20,14 +20,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/coredns/corefile-migration/migration"
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 	"sigs.k8s.io/cluster-api/util/container"
 	"sigs.k8s.io/cluster-api/util/version"
@@ -61,6 +65,32 @@ func (in *KubeadmControlPlane) Default() {
 	if !strings.HasPrefix(in.Spec.Version, "v") {
 		in.Spec.Version = "v" + in.Spec.Version
 	}
+
+	if in.Spec.RolloutStrategy == nil {
+		in.Spec.RolloutStrategy = &RolloutStrategy{}
+	}
+	if in.Spec.RolloutStrategy.Type == "" {
+		in.Spec.RolloutStrategy.Type = RollingUpdateStrategyType
+	}
+	if in.Spec.RolloutStrategy.Type == RollingUpdateStrategyType {
+		if in.Spec.RolloutStrategy.RollingUpdate == nil {
+			in.Spec.RolloutStrategy.RollingUpdate = &RollingUpdate{}
+		}
+		ios1 := intstr.FromInt(1)
+		in.Spec.RolloutStrategy.RollingUpdate.MaxSurge = intstr.ValueOrDefault(in.Spec.RolloutStrategy.RollingUpdate.MaxSurge, ios1)
+	}
+
+	if in.Spec.EtcdSnapshots != nil {
+		if in.Spec.EtcdSnapshots.Interval == nil {
+			in.Spec.EtcdSnapshots.Interval = &metav1.Duration{Duration: 24 * time.Hour}
+		}
+		if in.Spec.EtcdSnapshots.Retention == nil {
+			in.Spec.EtcdSnapshots.Retention = pointer.Int32Ptr(3)
+		}
+		if in.Spec.EtcdSnapshots.Sink.Type == "" {
+			in.Spec.EtcdSnapshots.Sink.Type = SecretEtcdSnapshotSinkType
+		}
+	}
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
@@ -115,7 +145,19 @@ func (in *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 		{spec, "replicas"},
 		{spec, "version"},
 		{spec, "upgradeAfter"},
+		{spec, "rolloutBefore"},
+		{spec, "rolloutBefore", "*"},
 		{spec, "nodeDrainTimeout"},
+		{spec, "drainPolicy"},
+		{spec, "drainPolicy", "*"},
+		{spec, "rolloutStrategy", "*"},
+		{spec, "etcdSnapshots"},
+		{spec, "etcdSnapshots", "*"},
+		{spec, "etcdRestore"},
+		{spec, "etcdRestore", "*"},
+		{spec, "rotateCertificateAuthoritiesAfter"},
+		{spec, "remediationStrategy"},
+		{spec, "remediationStrategy", "*"},
 	}
 
 	allErrs := in.validateCommon()
@@ -270,6 +312,181 @@ func (in *KubeadmControlPlane) validateCommon() (allErrs field.ErrorList) {
 	}
 
 	allErrs = append(allErrs, in.validateCoreDNSImage()...)
+	allErrs = append(allErrs, in.validateRolloutStrategy()...)
+	allErrs = append(allErrs, in.validateRolloutBefore()...)
+	allErrs = append(allErrs, in.validateEtcdSnapshots(externalEtcd)...)
+	allErrs = append(allErrs, in.validateEtcdRestore(externalEtcd)...)
+
+	return allErrs
+}
+
+func (in *KubeadmControlPlane) validateRolloutStrategy() (allErrs field.ErrorList) {
+	if in.Spec.RolloutStrategy == nil {
+		return allErrs
+	}
+
+	if in.Spec.RolloutStrategy.Type != RollingUpdateStrategyType {
+		allErrs = append(
+			allErrs,
+			field.NotSupported(
+				field.NewPath("spec", "rolloutStrategy", "type"),
+				in.Spec.RolloutStrategy.Type,
+				[]string{string(RollingUpdateStrategyType)},
+			),
+		)
+	}
+
+	if in.Spec.RolloutStrategy.RollingUpdate == nil || in.Spec.RolloutStrategy.RollingUpdate.MaxSurge == nil {
+		return allErrs
+	}
+
+	maxSurge := *in.Spec.RolloutStrategy.RollingUpdate.MaxSurge
+	ios0 := intstr.FromInt(0)
+	ios1 := intstr.FromInt(1)
+	if maxSurge != ios0 && maxSurge != ios1 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "rolloutStrategy", "rollingUpdate", "maxSurge"),
+				maxSurge.String(),
+				"must be 1 or 0",
+			),
+		)
+	}
+	if maxSurge == ios0 && in.Spec.Replicas != nil && *in.Spec.Replicas < 3 {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(
+				field.NewPath("spec", "rolloutStrategy", "rollingUpdate", "maxSurge"),
+				"can be 0 only when replicas are at least 3, so the control plane can be scaled down without losing the etcd quorum",
+			),
+		)
+	}
+
+	return allErrs
+}
+
+func (in *KubeadmControlPlane) validateRolloutBefore() (allErrs field.ErrorList) {
+	if in.Spec.RolloutBefore == nil || in.Spec.RolloutBefore.CertificatesExpiryDays == nil {
+		return allErrs
+	}
+
+	// NOTE: The certificates generated by kubeadm are valid for one year, so a threshold longer than that
+	// would trigger a rollout of every new machine.
+	days := *in.Spec.RolloutBefore.CertificatesExpiryDays
+	if days < 7 || days >= 365 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "rolloutBefore", "certificatesExpiryDays"),
+				days,
+				"must be greater than or equal to 7 and less than 365",
+			),
+		)
+	}
+
+	return allErrs
+}
+
+func (in *KubeadmControlPlane) validateEtcdSnapshots(externalEtcd bool) (allErrs field.ErrorList) {
+	if in.Spec.EtcdSnapshots == nil {
+		return allErrs
+	}
+
+	if externalEtcd {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(
+				field.NewPath("spec", "etcdSnapshots"),
+				"cannot be set when using external etcd",
+			),
+		)
+	}
+
+	if in.Spec.EtcdSnapshots.Interval != nil && in.Spec.EtcdSnapshots.Interval.Duration < 5*time.Minute {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "etcdSnapshots", "interval"),
+				in.Spec.EtcdSnapshots.Interval.Duration.String(),
+				"must be at least 5m",
+			),
+		)
+	}
+
+	if in.Spec.EtcdSnapshots.Retention != nil && *in.Spec.EtcdSnapshots.Retention < 1 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "etcdSnapshots", "retention"),
+				*in.Spec.EtcdSnapshots.Retention,
+				"must be greater than 0",
+			),
+		)
+	}
+
+	switch in.Spec.EtcdSnapshots.Sink.Type {
+	case "", SecretEtcdSnapshotSinkType, ConfigMapEtcdSnapshotSinkType:
+	default:
+		allErrs = append(
+			allErrs,
+			field.NotSupported(
+				field.NewPath("spec", "etcdSnapshots", "sink", "type"),
+				in.Spec.EtcdSnapshots.Sink.Type,
+				[]string{string(SecretEtcdSnapshotSinkType), string(ConfigMapEtcdSnapshotSinkType)},
+			),
+		)
+	}
+
+	return allErrs
+}
+
+func (in *KubeadmControlPlane) validateEtcdRestore(externalEtcd bool) (allErrs field.ErrorList) {
+	if in.Spec.EtcdRestore == nil {
+		return allErrs
+	}
+
+	if externalEtcd {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(
+				field.NewPath("spec", "etcdRestore"),
+				"cannot be set when using external etcd",
+			),
+		)
+	}
+
+	if (in.Spec.EtcdRestore.SnapshotName == "") == (in.Spec.EtcdRestore.SecretRef == nil) {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "etcdRestore"),
+				in.Spec.EtcdRestore,
+				"exactly one of snapshotName or secretRef must be set",
+			),
+		)
+	}
+
+	if in.Spec.EtcdRestore.SecretRef != nil {
+		if in.Spec.EtcdRestore.SecretRef.Name == "" {
+			allErrs = append(
+				allErrs,
+				field.Required(
+					field.NewPath("spec", "etcdRestore", "secretRef", "name"),
+					"is required",
+				),
+			)
+		}
+		if in.Spec.EtcdRestore.SecretRef.Key == "" {
+			allErrs = append(
+				allErrs,
+				field.Required(
+					field.NewPath("spec", "etcdRestore", "secretRef", "key"),
+					"is required",
+				),
+			)
+		}
+	}
 
 	return allErrs
 }
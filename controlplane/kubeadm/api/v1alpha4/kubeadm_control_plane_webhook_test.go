@@ -24,7 +24,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	kubeadmv1beta1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 )
@@ -45,6 +47,16 @@ func TestKubeadmControlPlaneDefault(t *testing.T) {
 
 	g.Expect(kcp.Spec.InfrastructureTemplate.Namespace).To(Equal(kcp.Namespace))
 	g.Expect(kcp.Spec.Version).To(Equal("v1.18.3"))
+	g.Expect(kcp.Spec.RolloutStrategy.Type).To(Equal(RollingUpdateStrategyType))
+	g.Expect(kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntVal).To(Equal(int32(1)))
+	g.Expect(kcp.Spec.EtcdSnapshots).To(BeNil())
+
+	kcp.Spec.EtcdSnapshots = &EtcdSnapshots{}
+	kcp.Default()
+
+	g.Expect(kcp.Spec.EtcdSnapshots.Interval.Duration).To(Equal(24 * time.Hour))
+	g.Expect(*kcp.Spec.EtcdSnapshots.Retention).To(Equal(int32(3)))
+	g.Expect(kcp.Spec.EtcdSnapshots.Sink.Type).To(Equal(SecretEtcdSnapshotSinkType))
 }
 
 func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
@@ -92,6 +104,70 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 	invalidVersion2 := valid.DeepCopy()
 	invalidVersion2.Spec.Version = "1.16.6"
 
+	maxSurge0 := intstr.FromInt(0)
+	maxSurge2 := intstr.FromInt(2)
+
+	validScaleInRollout := valid.DeepCopy()
+	validScaleInRollout.Spec.Replicas = pointer.Int32Ptr(3)
+	validScaleInRollout.Spec.RolloutStrategy = &RolloutStrategy{
+		Type:          RollingUpdateStrategyType,
+		RollingUpdate: &RollingUpdate{MaxSurge: &maxSurge0},
+	}
+
+	invalidScaleInRollout := validScaleInRollout.DeepCopy()
+	invalidScaleInRollout.Spec.Replicas = pointer.Int32Ptr(1)
+
+	invalidMaxSurge := validScaleInRollout.DeepCopy()
+	invalidMaxSurge.Spec.RolloutStrategy.RollingUpdate.MaxSurge = &maxSurge2
+
+	validEtcdSnapshots := valid.DeepCopy()
+	validEtcdSnapshots.Spec.EtcdSnapshots = &EtcdSnapshots{
+		Interval:  &metav1.Duration{Duration: time.Hour},
+		Retention: pointer.Int32Ptr(5),
+		Sink:      EtcdSnapshotSink{Type: ConfigMapEtcdSnapshotSinkType},
+	}
+
+	etcdSnapshotsShortInterval := validEtcdSnapshots.DeepCopy()
+	etcdSnapshotsShortInterval.Spec.EtcdSnapshots.Interval = &metav1.Duration{Duration: time.Minute}
+
+	etcdSnapshotsZeroRetention := validEtcdSnapshots.DeepCopy()
+	etcdSnapshotsZeroRetention.Spec.EtcdSnapshots.Retention = pointer.Int32Ptr(0)
+
+	etcdSnapshotsInvalidSink := validEtcdSnapshots.DeepCopy()
+	etcdSnapshotsInvalidSink.Spec.EtcdSnapshots.Sink.Type = "S3"
+
+	etcdSnapshotsExternalEtcd := evenReplicasExternalEtcd.DeepCopy()
+	etcdSnapshotsExternalEtcd.Spec.EtcdSnapshots = validEtcdSnapshots.Spec.EtcdSnapshots.DeepCopy()
+
+	validEtcdRestoreFromSnapshot := valid.DeepCopy()
+	validEtcdRestoreFromSnapshot.Spec.EtcdRestore = &EtcdRestore{SnapshotName: "test-etcd-20210304-050607"}
+
+	validEtcdRestoreFromSecret := valid.DeepCopy()
+	validEtcdRestoreFromSecret.Spec.EtcdRestore = &EtcdRestore{
+		SecretRef: &EtcdSnapshotSecretReference{Name: "snapshot", Key: "snapshot.db"},
+	}
+
+	etcdRestoreWithoutSource := valid.DeepCopy()
+	etcdRestoreWithoutSource.Spec.EtcdRestore = &EtcdRestore{}
+
+	etcdRestoreWithTwoSources := validEtcdRestoreFromSecret.DeepCopy()
+	etcdRestoreWithTwoSources.Spec.EtcdRestore.SnapshotName = "test-etcd-20210304-050607"
+
+	etcdRestoreWithoutSecretKey := validEtcdRestoreFromSecret.DeepCopy()
+	etcdRestoreWithoutSecretKey.Spec.EtcdRestore.SecretRef.Key = ""
+
+	validRolloutBefore := valid.DeepCopy()
+	validRolloutBefore.Spec.RolloutBefore = &RolloutBefore{CertificatesExpiryDays: pointer.Int32Ptr(21)}
+
+	rolloutBeforeTooShort := valid.DeepCopy()
+	rolloutBeforeTooShort.Spec.RolloutBefore = &RolloutBefore{CertificatesExpiryDays: pointer.Int32Ptr(6)}
+
+	rolloutBeforeTooLong := valid.DeepCopy()
+	rolloutBeforeTooLong.Spec.RolloutBefore = &RolloutBefore{CertificatesExpiryDays: pointer.Int32Ptr(365)}
+
+	etcdRestoreExternalEtcd := evenReplicasExternalEtcd.DeepCopy()
+	etcdRestoreExternalEtcd.Spec.EtcdRestore = validEtcdRestoreFromSnapshot.Spec.EtcdRestore.DeepCopy()
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -142,6 +218,91 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 			expectErr: true,
 			kcp:       invalidVersion1,
 		},
+		{
+			name:      "should succeed when maxSurge is 0 with 3 replicas",
+			expectErr: false,
+			kcp:       validScaleInRollout,
+		},
+		{
+			name:      "should return error when maxSurge is 0 with less than 3 replicas",
+			expectErr: true,
+			kcp:       invalidScaleInRollout,
+		},
+		{
+			name:      "should return error when maxSurge is not 0 or 1",
+			expectErr: true,
+			kcp:       invalidMaxSurge,
+		},
+		{
+			name:      "should succeed when given valid etcd snapshots",
+			expectErr: false,
+			kcp:       validEtcdSnapshots,
+		},
+		{
+			name:      "should return error when the etcd snapshots interval is less than 5m",
+			expectErr: true,
+			kcp:       etcdSnapshotsShortInterval,
+		},
+		{
+			name:      "should return error when the etcd snapshots retention is 0",
+			expectErr: true,
+			kcp:       etcdSnapshotsZeroRetention,
+		},
+		{
+			name:      "should return error when the etcd snapshots sink type is not supported",
+			expectErr: true,
+			kcp:       etcdSnapshotsInvalidSink,
+		},
+		{
+			name:      "should return error when etcd snapshots are enabled with external etcd",
+			expectErr: true,
+			kcp:       etcdSnapshotsExternalEtcd,
+		},
+		{
+			name:      "should succeed when restoring etcd from a snapshot name",
+			expectErr: false,
+			kcp:       validEtcdRestoreFromSnapshot,
+		},
+		{
+			name:      "should succeed when restoring etcd from a secret",
+			expectErr: false,
+			kcp:       validEtcdRestoreFromSecret,
+		},
+		{
+			name:      "should return error when restoring etcd without a snapshot",
+			expectErr: true,
+			kcp:       etcdRestoreWithoutSource,
+		},
+		{
+			name:      "should return error when restoring etcd from both a snapshot name and a secret",
+			expectErr: true,
+			kcp:       etcdRestoreWithTwoSources,
+		},
+		{
+			name:      "should return error when restoring etcd from a secret without key",
+			expectErr: true,
+			kcp:       etcdRestoreWithoutSecretKey,
+		},
+		{
+			name:      "should return error when restoring etcd with external etcd",
+			expectErr: true,
+			kcp:       etcdRestoreExternalEtcd,
+		},
+		{
+			name:      "should succeed when certificatesExpiryDays is within the certificates validity",
+			expectErr: false,
+			kcp:       validRolloutBefore,
+		},
+		{
+			name:      "should return error when certificatesExpiryDays is less than 7",
+			expectErr: true,
+			kcp:       rolloutBeforeTooShort,
+		},
+		{
+			name:      "should return error when certificatesExpiryDays is not less than the certificates validity",
+			expectErr: true,
+			kcp:       rolloutBeforeTooLong,
+		},
 	}
 
 	for _, tt := range tests {
@@ -263,6 +424,27 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 	validUpdate.Spec.Replicas = pointer.Int32Ptr(5)
 	now := metav1.NewTime(time.Now())
 	validUpdate.Spec.UpgradeAfter = &now
+	scaleInMaxSurge := intstr.FromInt(0)
+	validUpdate.Spec.RolloutStrategy = &RolloutStrategy{
+		Type:          RollingUpdateStrategyType,
+		RollingUpdate: &RollingUpdate{MaxSurge: &scaleInMaxSurge},
+	}
+	validUpdate.Spec.EtcdSnapshots = &EtcdSnapshots{
+		Interval: &metav1.Duration{Duration: time.Hour},
+	}
+	validUpdate.Spec.EtcdRestore = &EtcdRestore{SnapshotName: "test-etcd-20210304-050607"}
+	validUpdate.Spec.RolloutBefore = &RolloutBefore{CertificatesExpiryDays: pointer.Int32Ptr(14)}
+	validUpdate.Spec.RotateCertificateAuthoritiesAfter = &now
+	validUpdate.Spec.RemediationStrategy = &RemediationStrategy{
+		MaxRetry:                pointer.Int32Ptr(3),
+		RetryPeriod:             &metav1.Duration{Duration: 10 * time.Minute},
+		MinHealthyPeriod:        &metav1.Duration{Duration: 2 * time.Hour},
+		SmallControlPlanePolicy: AcceptRiskSmallControlPlaneRemediationPolicy,
+	}
+	validUpdate.Spec.DrainPolicy = &clusterv1.MachineDrainPolicy{
+		Order:                  clusterv1.PriorityMachineDrainOrder,
+		ForceDeleteGracePeriod: &metav1.Duration{Duration: 10 * time.Minute},
+	}
 
 	scaleToZero := before.DeepCopy()
 	scaleToZero.Spec.Replicas = pointer.Int32Ptr(0)
@@ -0,0 +1,414 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/secret"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileCertificateAuthoritiesRotation rotates the certificate authorities of the cluster when requested by
+// spec.rotateCertificateAuthoritiesAfter; the rotation happens in three phases, and in every phase all the machines
+// of the cluster are rolled out before moving to the next one, so the machines always trust each other:
+// - Trust: the new certificate authorities are added to the trusted certificates;
+// - Sign: the new certificate authorities are used for signing, while the old ones are still trusted;
+// - Cleanup: the old certificate authorities are removed from the trusted certificates.
+// The progress of the rotation is reported in the CertificateAuthoritiesRotated condition on the Cluster.
+func (r *KubeadmControlPlaneReconciler) reconcileCertificateAuthoritiesRotation(ctx context.Context, controlPlane *internal.ControlPlane) (res ctrl.Result, reterr error) {
+	kcp := controlPlane.KCP
+	if kcp.Spec.RotateCertificateAuthoritiesAfter == nil && kcp.Status.CertificateAuthoritiesRotation == nil {
+		return ctrl.Result{}, nil
+	}
+
+	patchHelper, err := patch.NewHelper(controlPlane.Cluster, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to create patch helper for the Cluster")
+	}
+	defer func() {
+		if reterr != nil {
+			conditions.MarkFalse(controlPlane.Cluster, controlplanev1.CertificateAuthoritiesRotatedCondition, controlplanev1.CertificateAuthoritiesRotationFailedReason, clusterv1.ConditionSeverityWarning, reterr.Error())
+		}
+		if err := patchHelper.Patch(ctx, controlPlane.Cluster, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+			controlplanev1.CertificateAuthoritiesRotatedCondition,
+		}}); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, errors.Wrap(err, "failed to patch the Cluster")})
+		}
+	}()
+
+	if kcp.Status.CertificateAuthoritiesRotation == nil {
+		return r.startCertificateAuthoritiesRotation(ctx, controlPlane)
+	}
+	return r.progressCertificateAuthoritiesRotation(ctx, controlPlane)
+}
+
+// startCertificateAuthoritiesRotation generates the new certificate authorities and starts the Trust phase, if
+// the certificate authorities have been generated before spec.rotateCertificateAuthoritiesAfter and the control
+// plane is stable.
+func (r *KubeadmControlPlaneReconciler) startCertificateAuthoritiesRotation(ctx context.Context, controlPlane *internal.ControlPlane) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	kcp := controlPlane.KCP
+
+	now := metav1.Now()
+	rotateAfter := kcp.Spec.RotateCertificateAuthoritiesAfter
+	if !kcp.Status.Initialized || !rotateAfter.Before(&now) {
+		return ctrl.Result{}, nil
+	}
+
+	secrets, err := r.getCertificateAuthoritySecrets(ctx, controlPlane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !certificateAuthoritiesGeneratedBefore(secrets, rotateAfter) {
+		return ctrl.Result{}, nil
+	}
+	for _, s := range secrets {
+		if !util.IsControlledBy(s, kcp) {
+			conditions.MarkFalse(controlPlane.Cluster, controlplanev1.CertificateAuthoritiesRotatedCondition, controlplanev1.CertificateAuthoritiesRotationFailedReason, clusterv1.ConditionSeverityWarning,
+				"The certificate authority in Secret %s has not been generated by the KubeadmControlPlane and it cannot be rotated", s.Name)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// Wait for the control plane to be stable before starting the rotation, so it does not overlap with other rollouts.
+	if len(controlPlane.MachinesNeedingRollout()) > 0 || controlPlane.HasDeletingMachine() {
+		log.Info("Waiting for the control plane to be stable before rotating the certificate authorities")
+		return ctrl.Result{RequeueAfter: certificateAuthoritiesRotationRequeueAfter}, nil
+	}
+
+	log.Info("Starting the rotation of the certificate authorities")
+	for _, s := range secrets {
+		if err := secret.StartCertificateAuthorityRotation(s); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Client.Update(ctx, s); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update Secret %s", s.Name)
+		}
+	}
+	if err := r.regenerateKubeconfigForCertificateAuthoritiesRotation(ctx, controlPlane); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateCertificateAuthorityInClusterInfo(ctx, controlPlane); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	kcp.Status.CertificateAuthoritiesRotation = &controlplanev1.CertificateAuthoritiesRotationStatus{
+		StartTime:      now,
+		Phase:          controlplanev1.TrustCertificateAuthoritiesRotationPhase,
+		PhaseStartTime: now,
+	}
+	markCertificateAuthoritiesRotationPhase(controlPlane.Cluster, kcp.Status.CertificateAuthoritiesRotation.Phase, "Rolling out all the machines")
+	return ctrl.Result{RequeueAfter: certificateAuthoritiesRotationRequeueAfter}, nil
+}
+
+// progressCertificateAuthoritiesRotation moves the rotation to the next phase, once all the machines of the cluster
+// have been rolled out in the current one.
+func (r *KubeadmControlPlaneReconciler) progressCertificateAuthoritiesRotation(ctx context.Context, controlPlane *internal.ControlPlane) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	rotation := controlPlane.KCP.Status.CertificateAuthoritiesRotation
+
+	if err := r.rolloutMachineDeployments(ctx, controlPlane.Cluster, rotation.PhaseStartTime); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pending, blockingOwners, err := r.machinesPendingCertificateAuthoritiesRotation(ctx, controlPlane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(blockingOwners) > 0 {
+		// NOTE: The rotation can't move to the next phase until these machines are replaced, otherwise they would stop
+		// trusting the certificates signed by the new certificate authorities.
+		log.Info("Waiting for machines not rolled out by the rotation of the certificate authorities to be deleted", "owners", blockingOwners)
+		conditions.MarkFalse(controlPlane.Cluster, controlplanev1.CertificateAuthoritiesRotatedCondition, controlplanev1.CertificateAuthoritiesRotationBlockedReason, clusterv1.ConditionSeverityWarning,
+			"The machines of %s are not rolled out by the rotation and must be deleted manually", strings.Join(blockingOwners, ", "))
+		return ctrl.Result{RequeueAfter: certificateAuthoritiesRotationRequeueAfter}, nil
+	}
+	if len(pending) > 0 {
+		markCertificateAuthoritiesRotationPhase(controlPlane.Cluster, rotation.Phase, "Waiting for machines %s to be rolled out", strings.Join(pending, ", "))
+		return ctrl.Result{RequeueAfter: certificateAuthoritiesRotationRequeueAfter}, nil
+	}
+
+	secrets, err := r.getCertificateAuthoritySecrets(ctx, controlPlane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := metav1.Now()
+	switch rotation.Phase {
+	case controlplanev1.TrustCertificateAuthoritiesRotationPhase:
+		if err := r.updateCertificateAuthoritySecrets(ctx, secrets, secret.SwitchCertificateAuthoritySigner); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.regenerateKubeconfigForCertificateAuthoritiesRotation(ctx, controlPlane); err != nil {
+			return ctrl.Result{}, err
+		}
+		rotation.Phase = controlplanev1.SignCertificateAuthoritiesRotationPhase
+	case controlplanev1.SignCertificateAuthoritiesRotationPhase:
+		if err := r.updateCertificateAuthoritySecrets(ctx, secrets, secret.CompleteCertificateAuthorityRotation); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.regenerateKubeconfigForCertificateAuthoritiesRotation(ctx, controlPlane); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.updateCertificateAuthorityInClusterInfo(ctx, controlPlane); err != nil {
+			return ctrl.Result{}, err
+		}
+		rotation.Phase = controlplanev1.CleanupCertificateAuthoritiesRotationPhase
+	case controlplanev1.CleanupCertificateAuthoritiesRotationPhase:
+		generated := rotation.StartTime.UTC().Format(time.RFC3339)
+		if err := r.updateCertificateAuthoritySecrets(ctx, secrets, func(s *corev1.Secret) error {
+			annotations := s.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[controlplanev1.CertificateAuthoritiesGeneratedAnnotation] = generated
+			s.SetAnnotations(annotations)
+			return nil
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info("Completed the rotation of the certificate authorities")
+		controlPlane.KCP.Status.CertificateAuthoritiesRotation = nil
+		conditions.MarkTrue(controlPlane.Cluster, controlplanev1.CertificateAuthoritiesRotatedCondition)
+		return ctrl.Result{}, nil
+	default:
+		return ctrl.Result{}, errors.Errorf("unknown certificate authorities rotation phase %q", rotation.Phase)
+	}
+
+	log.Info("Moving the rotation of the certificate authorities to the next phase", "phase", rotation.Phase)
+	rotation.PhaseStartTime = now
+	markCertificateAuthoritiesRotationPhase(controlPlane.Cluster, rotation.Phase, "Rolling out all the machines")
+	return ctrl.Result{RequeueAfter: certificateAuthoritiesRotationRequeueAfter}, nil
+}
+
+// getCertificateAuthoritySecrets returns the Secrets storing the certificate authorities to be rotated, i.e.
+// the cluster CA, the front proxy CA and, if etcd is managed by the KubeadmControlPlane, the etcd CA.
+func (r *KubeadmControlPlaneReconciler) getCertificateAuthoritySecrets(ctx context.Context, controlPlane *internal.ControlPlane) ([]*corev1.Secret, error) {
+	purposes := []secret.Purpose{secret.ClusterCA, secret.FrontProxyCA}
+	if controlPlane.IsEtcdManaged() {
+		purposes = append(purposes, secret.EtcdCA)
+	}
+
+	secrets := make([]*corev1.Secret, 0, len(purposes))
+	for _, purpose := range purposes {
+		s, err := secret.GetFromNamespacedName(ctx, r.Client, util.ObjectKey(controlPlane.Cluster), purpose)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the %s certificate authority", purpose)
+		}
+		secrets = append(secrets, s)
+	}
+	return secrets, nil
+}
+
+// updateCertificateAuthoritySecrets applies the given change to the Secrets storing the certificate authorities.
+func (r *KubeadmControlPlaneReconciler) updateCertificateAuthoritySecrets(ctx context.Context, secrets []*corev1.Secret, change func(*corev1.Secret) error) error {
+	for _, s := range secrets {
+		if err := change(s); err != nil {
+			return err
+		}
+		if err := r.Client.Update(ctx, s); err != nil {
+			return errors.Wrapf(err, "failed to update Secret %s", s.Name)
+		}
+	}
+	return nil
+}
+
+// regenerateKubeconfigForCertificateAuthoritiesRotation regenerates the kubeconfig of the cluster, so it trusts the
+// certificate authorities of the current phase, and it uses a client certificate signed by the current signer.
+func (r *KubeadmControlPlaneReconciler) regenerateKubeconfigForCertificateAuthoritiesRotation(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	configSecret, err := secret.GetFromNamespacedName(ctx, r.Client, util.ObjectKey(controlPlane.Cluster), secret.Kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve kubeconfig Secret")
+	}
+	// The kubeconfig Secrets not generated by the KubeadmControlPlane must be updated by their owner.
+	if !util.IsControlledBy(configSecret, controlPlane.KCP) {
+		return nil
+	}
+	if err := kubeconfig.RegenerateSecret(ctx, r.Client, configSecret); err != nil {
+		return errors.Wrap(err, "failed to regenerate kubeconfig")
+	}
+	return nil
+}
+
+// updateCertificateAuthorityInClusterInfo updates the certificate authorities trusted by the nodes joining the cluster.
+func (r *KubeadmControlPlaneReconciler) updateCertificateAuthorityInClusterInfo(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	clusterCA, err := secret.GetFromNamespacedName(ctx, r.Client, util.ObjectKey(controlPlane.Cluster), secret.ClusterCA)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the cluster certificate authority")
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err != nil {
+		return errors.Wrap(err, "cannot get remote client to workload cluster")
+	}
+	return workloadCluster.UpdateCertificateAuthorityInClusterInfo(ctx, clusterCA.Data[secret.TLSCrtDataName])
+}
+
+// rolloutMachineDeployments triggers the rollout of the machines of all the MachineDeployments of a cluster by setting
+// the restartedAt annotation on their machine template.
+func (r *KubeadmControlPlaneReconciler) rolloutMachineDeployments(ctx context.Context, cluster *clusterv1.Cluster, restartedAt metav1.Time) error {
+	deployments := &clusterv1.MachineDeploymentList{}
+	if err := r.Client.List(ctx, deployments, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return errors.Wrap(err, "failed to list MachineDeployments")
+	}
+
+	value := restartedAt.UTC().Format(time.RFC3339)
+	var errs []error
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if deployment.Spec.Template.Annotations[restartedAtAnnotation] == value {
+			continue
+		}
+		patchHelper, err := patch.NewHelper(deployment, r.Client)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if deployment.Spec.Template.Annotations == nil {
+			deployment.Spec.Template.Annotations = map[string]string{}
+		}
+		deployment.Spec.Template.Annotations[restartedAtAnnotation] = value
+		if err := patchHelper.Patch(ctx, deployment); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to patch MachineDeployment %s", deployment.Name))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// machinesPendingCertificateAuthoritiesRotation returns the names of the machines of the cluster that have not been
+// rolled out in the current phase of the rotation yet, i.e. the machines created before the phase started, and
+// the machines still being provisioned or deleted.
+// The machines created before the phase started that are not rolled out by the rotation, i.e. the machines not belonging
+// to the KubeadmControlPlane or to a MachineDeployment using a strategy that replaces all the machines, are reported by
+// their owner, so the user knows which machines must be deleted manually.
+func (r *KubeadmControlPlaneReconciler) machinesPendingCertificateAuthoritiesRotation(ctx context.Context, controlPlane *internal.ControlPlane) ([]string, []string, error) {
+	machines, err := r.managementCluster.GetMachinesForCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	phaseStartTime := controlPlane.KCP.Status.CertificateAuthoritiesRotation.PhaseStartTime
+	pending := []string{}
+	blockingOwners := map[string]struct{}{}
+	for _, machine := range machines {
+		if !machine.CreationTimestamp.Before(&phaseStartTime) && machine.Status.NodeRef != nil && machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		pending = append(pending, machine.Name)
+
+		if !machine.CreationTimestamp.Before(&phaseStartTime) || !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		owner, err := r.certificateAuthoritiesRotationBlockingOwner(ctx, controlPlane.KCP, machine)
+		if err != nil {
+			return nil, nil, err
+		}
+		if owner != "" {
+			blockingOwners[owner] = struct{}{}
+		}
+	}
+	sort.Strings(pending)
+
+	owners := make([]string, 0, len(blockingOwners))
+	for owner := range blockingOwners {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	return pending, owners, nil
+}
+
+// certificateAuthoritiesRotationBlockingOwner returns a description of the owner of a machine that is not rolled out by
+// the rotation of the certificate authorities, or an empty string if the machine is rolled out by the KubeadmControlPlane
+// or by its MachineDeployment.
+func (r *KubeadmControlPlaneReconciler) certificateAuthoritiesRotationBlockingOwner(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, machine *clusterv1.Machine) (string, error) {
+	ref := metav1.GetControllerOf(machine)
+	if ref == nil {
+		return fmt.Sprintf("Machine %s", machine.Name), nil
+	}
+	if ref.Kind == "KubeadmControlPlane" && ref.UID == kcp.UID {
+		return "", nil
+	}
+	if ref.Kind != "MachineSet" {
+		return fmt.Sprintf("%s %s", ref.Kind, ref.Name), nil
+	}
+
+	machineSet := &clusterv1.MachineSet{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: machine.Namespace, Name: ref.Name}, machineSet); err != nil {
+		return "", errors.Wrapf(err, "failed to get MachineSet %s", ref.Name)
+	}
+	ref = metav1.GetControllerOf(machineSet)
+	if ref == nil || ref.Kind != "MachineDeployment" {
+		return fmt.Sprintf("MachineSet %s", machineSet.Name), nil
+	}
+
+	deployment := &clusterv1.MachineDeployment{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: machine.Namespace, Name: ref.Name}, deployment); err != nil {
+		return "", errors.Wrapf(err, "failed to get MachineDeployment %s", ref.Name)
+	}
+	if deployment.Spec.Strategy != nil && deployment.Spec.Strategy.Type == clusterv1.OnDeleteMachineDeploymentStrategyType {
+		return fmt.Sprintf("MachineDeployment %s (OnDelete strategy)", deployment.Name), nil
+	}
+	return "", nil
+}
+
+// certificateAuthoritiesGeneratedBefore returns true if any of the certificate authorities in the given Secrets
+// has been generated before the given time.
+func certificateAuthoritiesGeneratedBefore(secrets []*corev1.Secret, t *metav1.Time) bool {
+	for _, s := range secrets {
+		generated := s.CreationTimestamp
+		if value, ok := s.Annotations[controlplanev1.CertificateAuthoritiesGeneratedAnnotation]; ok {
+			if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+				generated = metav1.NewTime(parsed)
+			}
+		}
+		if generated.Before(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// markCertificateAuthoritiesRotationPhase documents the current phase of the rotation in the CertificateAuthoritiesRotated
+// condition on the Cluster.
+func markCertificateAuthoritiesRotationPhase(cluster *clusterv1.Cluster, phase controlplanev1.CertificateAuthoritiesRotationPhase, messageFormat string, messageArgs ...interface{}) {
+	var reason string
+	switch phase {
+	case controlplanev1.TrustCertificateAuthoritiesRotationPhase:
+		reason = controlplanev1.TrustingNewCertificateAuthoritiesReason
+	case controlplanev1.SignCertificateAuthoritiesRotationPhase:
+		reason = controlplanev1.SigningWithNewCertificateAuthoritiesReason
+	case controlplanev1.CleanupCertificateAuthoritiesRotationPhase:
+		reason = controlplanev1.RemovingOldCertificateAuthoritiesReason
+	}
+	conditions.MarkFalse(cluster, controlplanev1.CertificateAuthoritiesRotatedCondition, reason, clusterv1.ConditionSeverityInfo, messageFormat, messageArgs...)
+}
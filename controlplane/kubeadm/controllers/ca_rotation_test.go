@@ -0,0 +1,312 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/cert"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/secret"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileCertificateAuthoritiesRotation(t *testing.T) {
+	// setup returns a control plane with the certificate authorities generated by the KubeadmControlPlane, together
+	// with a reconciler using a fake client storing all the objects of the cluster.
+	setup := func(g *WithT) (*KubeadmControlPlaneReconciler, *internal.ControlPlane) {
+		cluster, kcp, _ := createClusterWithControlPlane()
+		cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "1.2.3.4", Port: 6443}
+		kcp.UID = "kcp-uid"
+		kcp.Status.Initialized = true
+		kcp.Spec.RotateCertificateAuthoritiesAfter = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+
+		// NOTE: The Secrets are not created with the fake client, so they do not get a creation timestamp and they
+		// look like generated before the requested time.
+		owner := *metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"))
+		certificates := secret.NewCertificatesForInitialControlPlane(nil)
+		g.Expect(certificates.Generate()).To(Succeed())
+		objs := []client.Object{cluster.DeepCopy()}
+		for _, c := range certificates {
+			objs = append(objs, c.AsSecret(util.ObjectKey(cluster), owner))
+		}
+		fakeClient := newFakeClient(g, objs...)
+		g.Expect(kubeconfig.CreateSecretWithOwner(ctx, fakeClient, util.ObjectKey(cluster), cluster.Spec.ControlPlaneEndpoint.String(), owner)).To(Succeed())
+
+		r := &KubeadmControlPlaneReconciler{
+			Client: fakeClient,
+			managementCluster: &fakeManagementCluster{
+				Management: &internal.Management{Client: fakeClient},
+				Workload:   fakeWorkloadCluster{},
+			},
+		}
+		controlPlane := &internal.ControlPlane{
+			KCP:      kcp,
+			Cluster:  cluster,
+			Machines: internal.NewFilterableMachineCollection(),
+		}
+		return r, controlPlane
+	}
+	getSecret := func(g *WithT, r *KubeadmControlPlaneReconciler, controlPlane *internal.ControlPlane, purpose secret.Purpose) *corev1.Secret {
+		s, err := secret.GetFromNamespacedName(ctx, r.Client, util.ObjectKey(controlPlane.Cluster), purpose)
+		g.Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+	trustedCertificates := func(g *WithT, s *corev1.Secret) int {
+		certificates, err := cert.ParseCertsPEM(s.Data[secret.TLSCrtDataName])
+		g.Expect(err).NotTo(HaveOccurred())
+		return len(certificates)
+	}
+	getCluster := func(g *WithT, r *KubeadmControlPlaneReconciler, controlPlane *internal.ControlPlane) *clusterv1.Cluster {
+		cluster := &clusterv1.Cluster{}
+		g.Expect(r.Client.Get(ctx, util.ObjectKey(controlPlane.Cluster), cluster)).To(Succeed())
+		return cluster
+	}
+	caPurposes := []secret.Purpose{secret.ClusterCA, secret.FrontProxyCA, secret.EtcdCA}
+
+	t.Run("does nothing if the rotation is scheduled in the future", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r, controlPlane := setup(g)
+		controlPlane.KCP.Spec.RotateCertificateAuthoritiesAfter = &metav1.Time{Time: time.Now().Add(time.Hour)}
+
+		result, err := r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(ctrl.Result{}))
+		g.Expect(controlPlane.KCP.Status.CertificateAuthoritiesRotation).To(BeNil())
+		g.Expect(conditions.Has(getCluster(g, r, controlPlane), controlplanev1.CertificateAuthoritiesRotatedCondition)).To(BeFalse())
+	})
+
+	t.Run("does nothing if the certificate authorities have been generated after the requested time", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r, controlPlane := setup(g)
+		for _, purpose := range caPurposes {
+			s := getSecret(g, r, controlPlane, purpose)
+			s.Annotations = map[string]string{controlplanev1.CertificateAuthoritiesGeneratedAnnotation: time.Now().UTC().Format(time.RFC3339)}
+			g.Expect(r.Client.Update(ctx, s)).To(Succeed())
+		}
+
+		result, err := r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(ctrl.Result{}))
+		g.Expect(controlPlane.KCP.Status.CertificateAuthoritiesRotation).To(BeNil())
+	})
+
+	t.Run("reports a failure if the certificate authorities have not been generated by the KubeadmControlPlane", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r, controlPlane := setup(g)
+		s := getSecret(g, r, controlPlane, secret.FrontProxyCA)
+		s.OwnerReferences = nil
+		g.Expect(r.Client.Update(ctx, s)).To(Succeed())
+
+		_, err := r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(controlPlane.KCP.Status.CertificateAuthoritiesRotation).To(BeNil())
+		c := conditions.Get(getCluster(g, r, controlPlane), controlplanev1.CertificateAuthoritiesRotatedCondition)
+		g.Expect(c).NotTo(BeNil())
+		g.Expect(c.Reason).To(Equal(controlplanev1.CertificateAuthoritiesRotationFailedReason))
+		g.Expect(trustedCertificates(g, getSecret(g, r, controlPlane, secret.ClusterCA))).To(Equal(1))
+	})
+
+	t.Run("starts the rotation by trusting the new certificate authorities", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r, controlPlane := setup(g)
+
+		result, err := r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result.RequeueAfter).To(Equal(certificateAuthoritiesRotationRequeueAfter))
+
+		rotation := controlPlane.KCP.Status.CertificateAuthoritiesRotation
+		g.Expect(rotation).NotTo(BeNil())
+		g.Expect(rotation.Phase).To(Equal(controlplanev1.TrustCertificateAuthoritiesRotationPhase))
+		for _, purpose := range caPurposes {
+			s := getSecret(g, r, controlPlane, purpose)
+			g.Expect(s.Data).To(HaveKey(secret.NextTLSCrtDataName))
+			g.Expect(trustedCertificates(g, s)).To(Equal(2))
+		}
+		g.Expect(conditions.GetReason(getCluster(g, r, controlPlane), controlplanev1.CertificateAuthoritiesRotatedCondition)).To(Equal(controlplanev1.TrustingNewCertificateAuthoritiesReason))
+	})
+
+	t.Run("waits for the machines of the cluster to be rolled out", func(t *testing.T) {
+		g := NewWithT(t)
+
+		phaseStartTime := metav1.Now()
+		oldMachine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "old",
+				CreationTimestamp: metav1.NewTime(phaseStartTime.Add(-time.Hour)),
+			},
+			Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "old"}},
+		}
+		newMachine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "new",
+				CreationTimestamp: metav1.NewTime(phaseStartTime.Add(time.Minute)),
+			},
+			Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "new"}},
+		}
+		deployment := &clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: "md", UID: "md-uid"}}
+		machineSet := &clusterv1.MachineSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            "ms",
+			UID:             "ms-uid",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, clusterv1.GroupVersion.WithKind("MachineDeployment"))},
+		}}
+		oldMachine.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(machineSet, clusterv1.GroupVersion.WithKind("MachineSet"))}
+
+		r, controlPlane := setup(g)
+		for _, obj := range []client.Object{oldMachine, newMachine, deployment, machineSet} {
+			obj.SetNamespace(controlPlane.Cluster.Namespace)
+			obj.SetLabels(map[string]string{clusterv1.ClusterLabelName: controlPlane.Cluster.Name})
+			// NOTE: The objects are created with the underlying client, so the creation timestamp is preserved.
+			g.Expect(r.Client.(*fakeClient).Client.Create(ctx, obj)).To(Succeed())
+		}
+		controlPlane.KCP.Status.CertificateAuthoritiesRotation = &controlplanev1.CertificateAuthoritiesRotationStatus{
+			StartTime:      phaseStartTime,
+			Phase:          controlplanev1.TrustCertificateAuthoritiesRotationPhase,
+			PhaseStartTime: phaseStartTime,
+		}
+
+		result, err := r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result.RequeueAfter).To(Equal(certificateAuthoritiesRotationRequeueAfter))
+		g.Expect(controlPlane.KCP.Status.CertificateAuthoritiesRotation.Phase).To(Equal(controlplanev1.TrustCertificateAuthoritiesRotationPhase))
+
+		g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		g.Expect(deployment.Spec.Template.Annotations).To(HaveKeyWithValue(restartedAtAnnotation, phaseStartTime.UTC().Format(time.RFC3339)))
+
+		c := conditions.Get(getCluster(g, r, controlPlane), controlplanev1.CertificateAuthoritiesRotatedCondition)
+		g.Expect(c).NotTo(BeNil())
+		g.Expect(c.Reason).To(Equal(controlplanev1.TrustingNewCertificateAuthoritiesReason))
+		g.Expect(c.Message).To(ContainSubstring("old"))
+		g.Expect(c.Message).NotTo(ContainSubstring("new"))
+	})
+
+	t.Run("reports the machines not rolled out by the rotation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		phaseStartTime := metav1.Now()
+		onDeleteDeployment := &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "md-ondelete", UID: "md-ondelete-uid"},
+			Spec: clusterv1.MachineDeploymentSpec{
+				Strategy: &clusterv1.MachineDeploymentStrategy{Type: clusterv1.OnDeleteMachineDeploymentStrategyType},
+			},
+		}
+		onDeleteMachineSet := &clusterv1.MachineSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            "ms-ondelete",
+			UID:             "ms-ondelete-uid",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(onDeleteDeployment, clusterv1.GroupVersion.WithKind("MachineDeployment"))},
+		}}
+		standaloneMachineSet := &clusterv1.MachineSet{ObjectMeta: metav1.ObjectMeta{Name: "ms-standalone", UID: "ms-standalone-uid"}}
+		newMachine := func(name string, owner client.Object) *clusterv1.Machine {
+			m := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					CreationTimestamp: metav1.NewTime(phaseStartTime.Add(-time.Hour)),
+				},
+				Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: name}},
+			}
+			if owner != nil {
+				m.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, clusterv1.GroupVersion.WithKind("MachineSet"))}
+			}
+			return m
+		}
+
+		r, controlPlane := setup(g)
+		for _, obj := range []client.Object{
+			onDeleteDeployment, onDeleteMachineSet, standaloneMachineSet,
+			newMachine("ondelete", onDeleteMachineSet), newMachine("standalone-ms", standaloneMachineSet), newMachine("standalone", nil),
+		} {
+			obj.SetNamespace(controlPlane.Cluster.Namespace)
+			obj.SetLabels(map[string]string{clusterv1.ClusterLabelName: controlPlane.Cluster.Name})
+			g.Expect(r.Client.(*fakeClient).Client.Create(ctx, obj)).To(Succeed())
+		}
+		controlPlane.KCP.Status.CertificateAuthoritiesRotation = &controlplanev1.CertificateAuthoritiesRotationStatus{
+			StartTime:      phaseStartTime,
+			Phase:          controlplanev1.TrustCertificateAuthoritiesRotationPhase,
+			PhaseStartTime: phaseStartTime,
+		}
+
+		result, err := r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result.RequeueAfter).To(Equal(certificateAuthoritiesRotationRequeueAfter))
+		g.Expect(controlPlane.KCP.Status.CertificateAuthoritiesRotation.Phase).To(Equal(controlplanev1.TrustCertificateAuthoritiesRotationPhase))
+
+		c := conditions.Get(getCluster(g, r, controlPlane), controlplanev1.CertificateAuthoritiesRotatedCondition)
+		g.Expect(c).NotTo(BeNil())
+		g.Expect(c.Reason).To(Equal(controlplanev1.CertificateAuthoritiesRotationBlockedReason))
+		g.Expect(c.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+		g.Expect(c.Message).To(ContainSubstring("MachineDeployment md-ondelete (OnDelete strategy)"))
+		g.Expect(c.Message).To(ContainSubstring("MachineSet ms-standalone"))
+		g.Expect(c.Message).To(ContainSubstring("Machine standalone"))
+	})
+
+	t.Run("completes the rotation through all the phases", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r, controlPlane := setup(g)
+		_, err := r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		rotation := controlPlane.KCP.Status.CertificateAuthoritiesRotation
+		g.Expect(rotation).NotTo(BeNil())
+		nextKey := getSecret(g, r, controlPlane, secret.ClusterCA).Data[secret.NextTLSKeyDataName]
+
+		// Trust -> Sign.
+		_, err = r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(rotation.Phase).To(Equal(controlplanev1.SignCertificateAuthoritiesRotationPhase))
+		clusterCA := getSecret(g, r, controlPlane, secret.ClusterCA)
+		g.Expect(clusterCA.Data[secret.TLSKeyDataName]).To(Equal(nextKey))
+		g.Expect(clusterCA.Data).NotTo(HaveKey(secret.NextTLSCrtDataName))
+		g.Expect(trustedCertificates(g, clusterCA)).To(Equal(2))
+
+		// Sign -> Cleanup.
+		_, err = r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(rotation.Phase).To(Equal(controlplanev1.CleanupCertificateAuthoritiesRotationPhase))
+		for _, purpose := range caPurposes {
+			g.Expect(trustedCertificates(g, getSecret(g, r, controlPlane, purpose))).To(Equal(1))
+		}
+
+		// Cleanup -> completed.
+		result, err := r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(ctrl.Result{}))
+		g.Expect(controlPlane.KCP.Status.CertificateAuthoritiesRotation).To(BeNil())
+		for _, purpose := range caPurposes {
+			g.Expect(getSecret(g, r, controlPlane, purpose).Annotations).To(HaveKey(controlplanev1.CertificateAuthoritiesGeneratedAnnotation))
+		}
+		g.Expect(conditions.IsTrue(getCluster(g, r, controlPlane), controlplanev1.CertificateAuthoritiesRotatedCondition)).To(BeTrue())
+
+		// A new rotation is not started for the same request.
+		_, err = r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(controlPlane.KCP.Status.CertificateAuthoritiesRotation).To(BeNil())
+	})
+}
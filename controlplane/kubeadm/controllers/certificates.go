@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/machinefilters"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// reconcileCertificateExpiries reads the expiry date of the certificates of the control plane machines from the
+// workload cluster, and stores it in the certificates expiry annotation of each machine.
+// NOTE: The expiry date is read again after certificatesExpiryCheckInterval, because certificates can be renewed
+// in place, e.g. with kubeadm certs renew.
+func (r *KubeadmControlPlaneReconciler) reconcileCertificateExpiries(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	// If the cluster is not yet initialized, there is no way to connect to the workload cluster.
+	if !controlPlane.KCP.Status.Initialized {
+		return nil
+	}
+
+	now := time.Now()
+	machines := controlPlane.Machines.Filter(
+		machinefilters.Not(machinefilters.HasDeletionTimestamp),
+		needsCertificatesExpiryCheck(now),
+	)
+	if len(machines) == 0 {
+		return nil
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err != nil {
+		return errors.Wrap(err, "cannot get remote client to workload cluster")
+	}
+
+	var errs []error
+	for _, machine := range machines {
+		kubeadmConfig, ok := controlPlane.GetKubeadmConfig(machine.Name)
+		if !ok || machine.Status.NodeRef == nil {
+			continue
+		}
+		expiry, err := workloadCluster.GetAPIServerCertificateExpiry(ctx, kubeadmConfig, machine.Status.NodeRef.Name)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to get the certificates expiry for machine %s", machine.Name))
+			continue
+		}
+		annotations := machine.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[controlplanev1.CertificatesExpiryAnnotation] = expiry.UTC().Format(time.RFC3339)
+		annotations[controlplanev1.CertificatesExpiryCheckedAnnotation] = now.UTC().Format(time.RFC3339)
+		machine.SetAnnotations(annotations)
+	}
+
+	if err := controlPlane.PatchMachines(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// needsCertificatesExpiryCheck returns a filter to find the machines whose certificates expiry date has never been
+// read, or has been read more than certificatesExpiryCheckInterval ago.
+func needsCertificatesExpiryCheck(now time.Time) machinefilters.Func {
+	return func(machine *clusterv1.Machine) bool {
+		if machine == nil {
+			return false
+		}
+		if _, ok := machine.Annotations[controlplanev1.CertificatesExpiryAnnotation]; !ok {
+			return true
+		}
+		checked, err := time.Parse(time.RFC3339, machine.Annotations[controlplanev1.CertificatesExpiryCheckedAnnotation])
+		if err != nil {
+			return true
+		}
+		return !now.Before(checked.Add(certificatesExpiryCheckInterval))
+	}
+}
+
+// setCertificatesExpiryStatus reports the earliest expiry date of the certificates of the given machines
+// in the KubeadmControlPlane status, and sets the MachinesCertificatesValid condition accordingly.
+func setCertificatesExpiryStatus(kcp *controlplanev1.KubeadmControlPlane, machines internal.FilterableMachineCollection, now time.Time) {
+	days := int32(defaultCertificatesExpiryDays)
+	if kcp.Spec.RolloutBefore != nil && kcp.Spec.RolloutBefore.CertificatesExpiryDays != nil {
+		days = *kcp.Spec.RolloutBefore.CertificatesExpiryDays
+	}
+	threshold := now.Add(time.Duration(days) * 24 * time.Hour)
+
+	var earliest *time.Time
+	var expiring []string
+	for _, machine := range machines {
+		value, ok := machine.Annotations[controlplanev1.CertificatesExpiryAnnotation]
+		if !ok {
+			continue
+		}
+		expiry, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			continue
+		}
+		if earliest == nil || expiry.Before(*earliest) {
+			earliest = &expiry
+		}
+		if expiry.Before(threshold) {
+			expiring = append(expiring, machine.Name)
+		}
+	}
+
+	if earliest == nil {
+		kcp.Status.CertificatesExpiryDate = nil
+		conditions.Delete(kcp, controlplanev1.MachinesCertificatesValidCondition)
+		return
+	}
+	expiryDate := metav1.NewTime(*earliest)
+	kcp.Status.CertificatesExpiryDate = &expiryDate
+
+	if len(expiring) > 0 {
+		sort.Strings(expiring)
+		conditions.MarkFalse(kcp, controlplanev1.MachinesCertificatesValidCondition, controlplanev1.CertificatesExpiringReason, clusterv1.ConditionSeverityWarning,
+			"Certificates of machines %s expire within %d days", strings.Join(expiring, ", "), days)
+		return
+	}
+	conditions.MarkTrue(kcp, controlplanev1.MachinesCertificatesValidCondition)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileCertificateExpiries(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster, kcp, _ := createClusterWithControlPlane()
+	kcp.Status.Initialized = true
+
+	expiry := time.Date(2022, time.March, 4, 5, 6, 7, 0, time.UTC)
+	newMachine := func(name string, nodeRef string, annotations map[string]string) (*clusterv1.Machine, *bootstrapv1.KubeadmConfig) {
+		kubeadmConfig := &bootstrapv1.KubeadmConfig{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cluster.Namespace}}
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cluster.Namespace, Annotations: annotations},
+			Spec: clusterv1.MachineSpec{
+				Bootstrap: clusterv1.Bootstrap{
+					ConfigRef: &corev1.ObjectReference{Kind: "KubeadmConfig", APIVersion: bootstrapv1.GroupVersion.String(), Name: name},
+				},
+				InfrastructureRef: corev1.ObjectReference{Kind: "GenericInfrastructureMachine", APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4", Name: name},
+			},
+		}
+		if nodeRef != "" {
+			m.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: nodeRef}
+		}
+		return m, kubeadmConfig
+	}
+	withNode, withNodeConfig := newMachine("with-node", "n1", nil)
+	withoutNode, withoutNodeConfig := newMachine("without-node", "", nil)
+	withAnnotation, withAnnotationConfig := newMachine("with-annotation", "n2", map[string]string{
+		controlplanev1.CertificatesExpiryAnnotation:        "2021-01-01T00:00:00Z",
+		controlplanev1.CertificatesExpiryCheckedAnnotation: time.Now().UTC().Format(time.RFC3339),
+	})
+	withOldAnnotation, withOldAnnotationConfig := newMachine("with-old-annotation", "n3", map[string]string{
+		controlplanev1.CertificatesExpiryAnnotation:        "2021-01-01T00:00:00Z",
+		controlplanev1.CertificatesExpiryCheckedAnnotation: time.Now().Add(-certificatesExpiryCheckInterval).UTC().Format(time.RFC3339),
+	})
+
+	fakeClient := newFakeClient(g, withNode, withNodeConfig, withoutNode, withoutNodeConfig, withAnnotation, withAnnotationConfig, withOldAnnotation, withOldAnnotationConfig)
+	controlPlane, err := internal.NewControlPlane(ctx, fakeClient, cluster, kcp, internal.NewFilterableMachineCollection(withNode, withoutNode, withAnnotation, withOldAnnotation))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:            fakeClient,
+		managementCluster: &fakeManagementCluster{Workload: fakeWorkloadCluster{CertificatesExpiry: &expiry}},
+	}
+	g.Expect(r.reconcileCertificateExpiries(ctx, controlPlane)).To(Succeed())
+
+	got := map[string]string{}
+	for _, m := range []*clusterv1.Machine{withNode, withoutNode, withAnnotation, withOldAnnotation} {
+		machine := &clusterv1.Machine{}
+		g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(m), machine)).To(Succeed())
+		got[machine.Name] = machine.Annotations[controlplanev1.CertificatesExpiryAnnotation]
+	}
+	g.Expect(got).To(Equal(map[string]string{
+		"with-node":           "2022-03-04T05:06:07Z",
+		"without-node":        "",
+		"with-annotation":     "2021-01-01T00:00:00Z",
+		"with-old-annotation": "2022-03-04T05:06:07Z",
+	}))
+}
+
+func TestSetCertificatesExpiryStatus(t *testing.T) {
+	now := time.Date(2021, time.March, 4, 0, 0, 0, 0, time.UTC)
+	newMachine := func(name string, expiry time.Time) *clusterv1.Machine {
+		return &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{controlplanev1.CertificatesExpiryAnnotation: expiry.Format(time.RFC3339)},
+		}}
+	}
+
+	t.Run("does not report anything if the expiry of the certificates is not known", func(t *testing.T) {
+		g := NewWithT(t)
+
+		kcp := &controlplanev1.KubeadmControlPlane{}
+		setCertificatesExpiryStatus(kcp, internal.NewFilterableMachineCollection(&clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "m1"}}), now)
+
+		g.Expect(kcp.Status.CertificatesExpiryDate).To(BeNil())
+		g.Expect(conditions.Has(kcp, controlplanev1.MachinesCertificatesValidCondition)).To(BeFalse())
+	})
+
+	t.Run("reports the earliest expiry and valid certificates", func(t *testing.T) {
+		g := NewWithT(t)
+
+		kcp := &controlplanev1.KubeadmControlPlane{}
+		setCertificatesExpiryStatus(kcp, internal.NewFilterableMachineCollection(
+			newMachine("m1", now.Add(60*24*time.Hour)),
+			newMachine("m2", now.Add(40*24*time.Hour)),
+		), now)
+
+		g.Expect(kcp.Status.CertificatesExpiryDate.Time).To(BeTemporally("==", now.Add(40*24*time.Hour)))
+		g.Expect(conditions.IsTrue(kcp, controlplanev1.MachinesCertificatesValidCondition)).To(BeTrue())
+	})
+
+	t.Run("reports expiring certificates within the default threshold", func(t *testing.T) {
+		g := NewWithT(t)
+
+		kcp := &controlplanev1.KubeadmControlPlane{}
+		setCertificatesExpiryStatus(kcp, internal.NewFilterableMachineCollection(
+			newMachine("m1", now.Add(60*24*time.Hour)),
+			newMachine("m2", now.Add(20*24*time.Hour)),
+		), now)
+
+		g.Expect(conditions.IsFalse(kcp, controlplanev1.MachinesCertificatesValidCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(kcp, controlplanev1.MachinesCertificatesValidCondition)).To(Equal(controlplanev1.CertificatesExpiringReason))
+		g.Expect(conditions.GetMessage(kcp, controlplanev1.MachinesCertificatesValidCondition)).To(Equal("Certificates of machines m2 expire within 30 days"))
+	})
+
+	t.Run("uses the threshold from spec.rolloutBefore", func(t *testing.T) {
+		g := NewWithT(t)
+
+		kcp := &controlplanev1.KubeadmControlPlane{}
+		kcp.Spec.RolloutBefore = &controlplanev1.RolloutBefore{CertificatesExpiryDays: pointer.Int32Ptr(10)}
+		setCertificatesExpiryStatus(kcp, internal.NewFilterableMachineCollection(
+			newMachine("m1", now.Add(20*24*time.Hour)),
+		), now)
+
+		g.Expect(conditions.IsTrue(kcp, controlplanev1.MachinesCertificatesValidCondition)).To(BeTrue())
+	})
+}
@@ -30,4 +30,45 @@ const (
 	// dependentCertRequeueAfter is how long to wait before checking again to see if
 	// dependent certificates have been created.
 	dependentCertRequeueAfter = 30 * time.Second
+
+	// etcdSnapshotRetryAfter is how long to wait before trying again to take
+	// an etcd snapshot after a failure.
+	etcdSnapshotRetryAfter = 5 * time.Minute
+
+	// etcdRestoreCredentialsRequeueAfter is how long to wait before checking again
+	// if the token used by the first control plane machine for reading the etcd
+	// snapshot to restore has been generated.
+	etcdRestoreCredentialsRequeueAfter = 5 * time.Second
+
+	// etcdSnapshotTimeout is the maximum time for taking an etcd snapshot and
+	// storing it in the configured sink.
+	etcdSnapshotTimeout = 10 * time.Minute
+
+	// defaultCertificatesExpiryDays is the number of days before the expiry of the
+	// certificates of a machine when the MachinesCertificatesValid condition is
+	// set to false, if spec.rolloutBefore.certificatesExpiryDays is not set.
+	defaultCertificatesExpiryDays = 30
+
+	// certificatesExpiryCheckInterval is how long to wait before reading again the
+	// expiry date of the certificates of a machine, so certificates renewed in place
+	// are detected.
+	certificatesExpiryCheckInterval = 1 * time.Hour
+
+	// certificateAuthoritiesRotationRequeueAfter is how long to wait before checking
+	// again if the machines of a cluster have been rolled out during the rotation
+	// of the certificate authorities.
+	certificateAuthoritiesRotationRequeueAfter = 1 * time.Minute
+
+	// restartedAtAnnotation is the annotation set on the machine template of the
+	// MachineDeployments to roll out their machines, the same used by clusterctl
+	// alpha rollout restart.
+	restartedAtAnnotation = "cluster.x-k8s.io/restartedAt"
+
+	// defaultMinHealthyPeriod is the time after the last remediation when a replacement
+	// machine is considered healthy, if spec.remediationStrategy.minHealthyPeriod is not set.
+	defaultMinHealthyPeriod = 1 * time.Hour
+
+	// remediationHistoryLength is the maximum number of remediations recorded in
+	// status.remediationHistory.
+	remediationHistoryLength = 5
 )
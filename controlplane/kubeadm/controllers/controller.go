@@ -52,10 +52,15 @@ import (
 )
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
-// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete;deletecollection
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;delete;deletecollection
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=create;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=create;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io;bootstrap.cluster.x-k8s.io;controlplane.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinesets,verbs=get;list;watch
 
 // KubeadmControlPlaneReconciler reconciles a KubeadmControlPlane object
 type KubeadmControlPlaneReconciler struct {
@@ -64,6 +69,10 @@ type KubeadmControlPlaneReconciler struct {
 	recorder   record.EventRecorder
 	Tracker    *remote.ClusterCacheTracker
 
+	// ManagementClusterEndpoint is the address of the management cluster API server reachable from the machines,
+	// used by the first control plane machine for reading the snapshot when restoring etcd.
+	ManagementClusterEndpoint string
+
 	managementCluster         internal.ManagementCluster
 	managementClusterUncached internal.ManagementCluster
 }
@@ -227,6 +236,9 @@ func patchKubeadmControlPlane(ctx context.Context, patchHelper *patch.Helper, kc
 			controlplanev1.MachinesReadyCondition,
 			controlplanev1.AvailableCondition,
 			controlplanev1.CertificatesAvailableCondition,
+			controlplanev1.EtcdSnapshotSucceededCondition,
+			controlplanev1.EtcdRestoreSucceededCondition,
+			controlplanev1.MachinesCertificatesValidCondition,
 		}},
 	)
 }
@@ -241,6 +253,9 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 		return ctrl.Result{}, err
 	}
 
+	// Reports if the restore of etcd from a snapshot can't be performed.
+	r.reconcileEtcdRestoreRequirements(kcp)
+
 	// Generate Cluster Certificates if needed
 	config := kcp.Spec.KubeadmConfigSpec.DeepCopy()
 	config.JoinConfiguration = nil
@@ -249,7 +264,13 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 	}
 	certificates := secret.NewCertificatesForInitialControlPlane(config.ClusterConfiguration)
 	controllerRef := metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"))
-	if err := certificates.LookupOrGenerate(ctx, r.Client, util.ObjectKey(cluster), *controllerRef); err != nil {
+	var err error
+	if kcp.Spec.EtcdRestore != nil {
+		err = r.lookupEtcdRestoreCertificates(ctx, cluster, certificates)
+	} else {
+		err = certificates.LookupOrGenerate(ctx, r.Client, util.ObjectKey(cluster), *controllerRef)
+	}
+	if err != nil {
 		log.Error(err, "unable to lookup or create cluster certificates")
 		conditions.MarkFalse(kcp, controlplanev1.CertificatesAvailableCondition, controlplanev1.CertificatesGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 		return ctrl.Result{}, err
@@ -305,12 +326,35 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 		return result, err
 	}
 
+	// Records the expiry date of the certificates of the control plane machines.
+	// NOTE: Errors are not blocking, because the expiry date can be read in a following reconcile.
+	if err := r.reconcileCertificateExpiries(ctx, controlPlane); err != nil {
+		log.Error(err, "failed to reconcile certificate expiries")
+	}
+
 	// Ensures the number of etcd members is in sync with the number of machines/nodes.
 	// NOTE: This is usually required after a machine deletion.
 	if result, err := r.reconcileEtcdMembers(ctx, controlPlane); err != nil || !result.IsZero() {
 		return result, err
 	}
 
+	// Completes the restore of etcd from a snapshot, if in progress.
+	if result, err := r.reconcileEtcdRestore(ctx, controlPlane); err != nil || !result.IsZero() {
+		return result, err
+	}
+
+	// Takes an etcd snapshot if one is due according to the configured schedule.
+	// NOTE: The result is used for requeuing at the time of the next snapshot when no other operation is required.
+	snapshotResult := r.reconcileEtcdSnapshots(ctx, controlPlane)
+
+	// Rotates the certificate authorities of the cluster, if requested.
+	// NOTE: The result is used for requeuing while waiting for the machines to be rolled out, which happens in parallel
+	// with the other operations.
+	rotationResult, err := r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Reconcile unhealthy machines by triggering deletion and requeue if it is considered safe to remediate,
 	// otherwise continue with the other KCP operations.
 	if result, err := r.reconcileUnhealthyMachines(ctx, controlPlane); err != nil || !result.IsZero() {
@@ -379,7 +423,7 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 		return ctrl.Result{}, errors.Wrap(err, "failed to update CoreDNS deployment")
 	}
 
-	return ctrl.Result{}, nil
+	return util.LowestNonZeroResult(snapshotResult, rotationResult), nil
 }
 
 // reconcileDelete handles KubeadmControlPlane deletion.
@@ -473,6 +517,12 @@ func (r *KubeadmControlPlaneReconciler) reconcileControlPlaneConditions(ctx cont
 		return ctrl.Result{}, nil
 	}
 
+	// If there are no machines, e.g. because the only machine of the control plane has been remediated and the control
+	// plane is being initialized again, the workload cluster is not reachable. Return early.
+	if controlPlane.Machines.Len() == 0 {
+		return ctrl.Result{}, nil
+	}
+
 	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
 	if err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "cannot get remote client to workload cluster")
@@ -17,10 +17,15 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"io/ioutil"
+	"time"
 
 	"github.com/blang/semver"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/machinefilters"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -55,8 +60,13 @@ func (f *fakeManagementCluster) GetMachinesForCluster(c context.Context, n clien
 
 type fakeWorkloadCluster struct {
 	*internal.Workload
-	Status            internal.ClusterStatus
-	EtcdMembersResult []string
+	Status                  internal.ClusterStatus
+	EtcdMembersResult       []string
+	EtcdMembersStatusResult []internal.EtcdMemberStatus
+	EtcdSnapshotResult      []byte
+	EtcdSnapshotErr         error
+	// CertificatesExpiry is the expiry date returned for the certificates of any node.
+	CertificatesExpiry *time.Time
 }
 
 func (f fakeWorkloadCluster) ForwardEtcdLeadership(_ context.Context, _ *clusterv1.Machine, _ *clusterv1.Machine) error {
@@ -67,14 +77,26 @@ func (f fakeWorkloadCluster) ReconcileEtcdMembers(ctx context.Context, nodeNames
 	return nil, nil
 }
 
+func (f fakeWorkloadCluster) RemoveStaleControlPlaneNodes(ctx context.Context, nodeNames []string) ([]string, error) {
+	return nil, nil
+}
+
 func (f fakeWorkloadCluster) ClusterStatus(_ context.Context) (internal.ClusterStatus, error) {
 	return f.Status, nil
 }
 
+func (f fakeWorkloadCluster) EtcdMembersStatus(_ context.Context) ([]internal.EtcdMemberStatus, error) {
+	return f.EtcdMembersStatusResult, nil
+}
+
 func (f fakeWorkloadCluster) AllowBootstrapTokensToGetNodes(ctx context.Context) error {
 	return nil
 }
 
+func (f fakeWorkloadCluster) UpdateCertificateAuthorityInClusterInfo(ctx context.Context, caData []byte) error {
+	return nil
+}
+
 func (f fakeWorkloadCluster) ReconcileKubeletRBACRole(ctx context.Context, version semver.Version) error {
 	return nil
 }
@@ -103,10 +125,21 @@ func (f fakeWorkloadCluster) RemoveMachineFromKubeadmConfigMap(ctx context.Conte
 	return nil
 }
 
+func (f fakeWorkloadCluster) EtcdSnapshot(_ context.Context) (io.ReadCloser, error) {
+	if f.EtcdSnapshotErr != nil {
+		return nil, f.EtcdSnapshotErr
+	}
+	return ioutil.NopCloser(bytes.NewReader(f.EtcdSnapshotResult)), nil
+}
+
 func (f fakeWorkloadCluster) EtcdMembers(_ context.Context) ([]string, error) {
 	return f.EtcdMembersResult, nil
 }
 
+func (f fakeWorkloadCluster) GetAPIServerCertificateExpiry(_ context.Context, _ *bootstrapv1.KubeadmConfig, _ string) (*time.Time, error) {
+	return f.CertificatesExpiry, nil
+}
+
 type fakeMigrator struct {
 	migrateCalled    bool
 	migrateErr       error
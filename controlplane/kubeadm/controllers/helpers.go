@@ -243,6 +243,12 @@ func (r *KubeadmControlPlaneReconciler) generateKubeadmConfig(ctx context.Contex
 		Spec: *spec,
 	}
 
+	// If the control plane is initialized again, e.g. after its only machine has been remediated, the bootstrap provider
+	// must run kubeadm init even if the control plane of the cluster has already been initialized.
+	if spec.JoinConfiguration == nil && kcp.Status.Initialized {
+		bootstrapConfig.Annotations = map[string]string{bootstrapv1.ReinitializeControlPlaneAnnotation: ""}
+	}
+
 	if err := r.Client.Create(ctx, bootstrapConfig); err != nil {
 		return nil, errors.Wrap(err, "Failed to create bootstrap configuration")
 	}
@@ -277,6 +283,7 @@ func (r *KubeadmControlPlaneReconciler) generateMachine(ctx context.Context, kcp
 			},
 			FailureDomain:    failureDomain,
 			NodeDrainTimeout: kcp.Spec.NodeDrainTimeout,
+			DrainPolicy:      kcp.Spec.DrainPolicy,
 		},
 	}
 
@@ -579,4 +579,12 @@ func TestKubeadmControlPlaneReconciler_generateKubeadmConfig(t *testing.T) {
 	g.Expect(bootstrapConfig.OwnerReferences).To(HaveLen(1))
 	g.Expect(bootstrapConfig.OwnerReferences).To(ContainElement(expectedOwner))
 	g.Expect(bootstrapConfig.Spec).To(Equal(spec))
+	g.Expect(bootstrapConfig.Annotations).NotTo(HaveKey(bootstrapv1.ReinitializeControlPlaneAnnotation))
+
+	// When the control plane has already been initialized, the config for initializing it again is annotated.
+	kcp.Status.Initialized = true
+	got, err = r.generateKubeadmConfig(ctx, kcp, cluster, spec.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(fakeClient.Get(ctx, client.ObjectKey{Name: got.Name, Namespace: got.Namespace}, bootstrapConfig)).To(Succeed())
+	g.Expect(bootstrapConfig.Annotations).To(HaveKey(bootstrapv1.ReinitializeControlPlaneAnnotation))
 }
@@ -19,8 +19,11 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
@@ -45,12 +48,9 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 		return ctrl.Result{}, nil
 	}
 
-	// Select the machine to be remediated, which is the oldest machine marked as unhealthy.
-	//
-	// NOTE: The current solution is considered acceptable for the most frequent use case (only one unhealthy machine),
-	// however, in the future this could potentially be improved for the scenario where more than one unhealthy machine exists
-	// by considering which machine has lower impact on etcd quorum.
-	machineToBeRemediated := unhealthyMachines.Oldest()
+	// Select the machine to be remediated, which is the machine whose remediation has the lowest impact on etcd;
+	// when more machines have the same impact, the oldest one is selected.
+	machineToBeRemediated := r.selectMachineToBeRemediated(ctx, controlPlane, unhealthyMachines)
 
 	// Returns if the machine is in the process of being deleted.
 	if !machineToBeRemediated.ObjectMeta.DeletionTimestamp.IsZero() {
@@ -79,13 +79,22 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 
 	desiredReplicas := int(*controlPlane.KCP.Spec.Replicas)
 
-	// The cluster MUST have spec.replicas >= 3, because this is the smallest cluster size that allows any etcd failure tolerance.
-	if desiredReplicas < 3 {
+	// The cluster MUST have spec.replicas >= 3, because this is the smallest cluster size that allows any etcd failure tolerance,
+	// unless the risk of remediating a smaller cluster has been accepted in the remediation strategy.
+	if desiredReplicas < 3 && !allowsSmallControlPlaneRemediation(controlPlane.KCP) {
 		log.Info("A control plane machine needs remediation, but the number of desired replicas is less than 3. Skipping remediation", "UnhealthyMachine", machineToBeRemediated.Name, "Replicas", desiredReplicas)
 		conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "KCP can't remediate if there are less than 3 desired replicas")
 		return ctrl.Result{}, nil
 	}
 
+	// A control plane with 1 replica MUST be able to restore etcd from the latest snapshot, if snapshots are enabled,
+	// otherwise it can't be initialized again after deleting its only machine.
+	if desiredReplicas == 1 && controlPlane.KCP.Spec.EtcdSnapshots != nil && controlPlane.IsEtcdManaged() && r.ManagementClusterEndpoint == "" {
+		log.Info("A control plane machine needs remediation, but the controller is not configured for restoring etcd from a snapshot. Skipping remediation", "UnhealthyMachine", machineToBeRemediated.Name)
+		conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "KCP can't remediate a control plane with 1 replica because the --management-cluster-endpoint flag required for restoring etcd from a snapshot is not set")
+		return ctrl.Result{}, nil
+	}
+
 	// The number of replicas MUST be equal to or greater than the desired replicas. This rule ensures that when the cluster
 	// is missing replicas, we skip remediation and instead perform regular scale up/rollout operations first.
 	if controlPlane.Machines.Len() < desiredReplicas {
@@ -101,6 +110,40 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 		return ctrl.Result{}, nil
 	}
 
+	// Remediation MUST respect the limits defined in the remediation strategy. This rule prevents a flapping machine,
+	// and the machines created as its replacement, from being remediated indefinitely.
+	retryCount, retryAfter, canRemediate := remediationRetry(controlPlane.KCP, machineToBeRemediated, time.Now())
+	if !canRemediate {
+		log.Info("A control plane machine needs remediation, but the maximum number of retries has been reached. Skipping remediation", "UnhealthyMachine", machineToBeRemediated.Name, "RetryCount", retryCount)
+		conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "KCP can't remediate this machine because the maximum number of retries (%d) has been reached", *controlPlane.KCP.Spec.RemediationStrategy.MaxRetry)
+		return ctrl.Result{}, nil
+	}
+	if retryAfter > 0 {
+		log.Info("A control plane machine needs remediation, but the retry period since the last remediation has not elapsed yet. Skipping remediation", "UnhealthyMachine", machineToBeRemediated.Name, "RetryAfter", retryAfter)
+		conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "KCP waiting for the retry period to elapse before triggering remediation")
+		return ctrl.Result{RequeueAfter: retryAfter}, nil
+	}
+
+	switch desiredReplicas {
+	// A control plane with 1 replica can't be remediated by a machine joining the control plane, so the unhealthy machine
+	// is deleted, and the control plane initialized again.
+	// NOTE: The workload cluster is not reachable without a healthy control plane machine, so the etcd member and the
+	// kubeadm ConfigMap are not updated.
+	case 1:
+		log.Info("Remediating the only control plane machine, the control plane will be initialized again", "UnhealthyMachine", machineToBeRemediated.Name)
+		return r.deleteMachineToBeRemediated(ctx, controlPlane, machineToBeRemediated, retryCount)
+	// A control plane with 2 replicas can't tolerate the loss of a machine, so a replacement machine is created and must
+	// be healthy before deleting the unhealthy machine.
+	case 2:
+		if controlPlane.Machines.Len() <= desiredReplicas {
+			return r.scaleUpForRemediation(ctx, controlPlane, machineToBeRemediated)
+		}
+		if result, err := r.preflightChecks(ctx, controlPlane, machineToBeRemediated); err != nil || !result.IsZero() {
+			conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "KCP waiting for the replacement machine to be healthy before triggering remediation")
+			return result, err
+		}
+	}
+
 	// Remediation MUST preserve etcd quorum. This rule ensures that we will not remove a member that would result in etcd
 	// losing a majority of members and thus become unable to field new requests.
 	if controlPlane.IsEtcdManaged() {
@@ -140,16 +183,154 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 		return ctrl.Result{}, err
 	}
 
+	return r.deleteMachineToBeRemediated(ctx, controlPlane, machineToBeRemediated, retryCount)
+}
+
+// deleteMachineToBeRemediated deletes the machine to be remediated, and records the remediation in the KCP status.
+func (r *KubeadmControlPlaneReconciler) deleteMachineToBeRemediated(ctx context.Context, controlPlane *internal.ControlPlane, machineToBeRemediated *clusterv1.Machine, retryCount int32) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
 	if err := r.Client.Delete(ctx, machineToBeRemediated); err != nil {
 		conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.RemediationFailedReason, clusterv1.ConditionSeverityError, err.Error())
 		return ctrl.Result{}, errors.Wrapf(err, "failed to delete unhealthy machine %s", machineToBeRemediated.Name)
 	}
 
-	log.Info("Remediating unhealthy machine", "UnhealthyMachine", machineToBeRemediated.Name)
+	log.Info("Remediating unhealthy machine", "UnhealthyMachine", machineToBeRemediated.Name, "RetryCount", retryCount)
 	conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.RemediationInProgressReason, clusterv1.ConditionSeverityWarning, "")
+	recordRemediation(controlPlane.KCP, machineToBeRemediated.Name, time.Now(), retryCount)
 	return ctrl.Result{Requeue: true}, nil
 }
 
+// scaleUpForRemediation creates a machine replacing the machine to be remediated, before the latter is deleted.
+func (r *KubeadmControlPlaneReconciler) scaleUpForRemediation(ctx context.Context, controlPlane *internal.ControlPlane, machineToBeRemediated *clusterv1.Machine) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	bootstrapSpec := controlPlane.JoinControlPlaneConfig()
+	fd := controlPlane.NextFailureDomainForScaleUp()
+	if err := r.cloneConfigsAndGenerateMachine(ctx, controlPlane.Cluster, controlPlane.KCP, bootstrapSpec, fd); err != nil {
+		conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.RemediationFailedReason, clusterv1.ConditionSeverityError, err.Error())
+		return ctrl.Result{}, errors.Wrapf(err, "failed to create a control plane machine replacing unhealthy machine %s", machineToBeRemediated.Name)
+	}
+
+	log.Info("Scaling up the control plane before remediating unhealthy machine", "UnhealthyMachine", machineToBeRemediated.Name)
+	conditions.MarkFalse(machineToBeRemediated, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "KCP waiting for the replacement machine to be healthy before triggering remediation")
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// allowsSmallControlPlaneRemediation returns true if the remediation of control planes with less than 3 replicas
+// has been allowed in the remediation strategy.
+func allowsSmallControlPlaneRemediation(kcp *controlplanev1.KubeadmControlPlane) bool {
+	return kcp.Spec.RemediationStrategy != nil && kcp.Spec.RemediationStrategy.SmallControlPlanePolicy == controlplanev1.AcceptRiskSmallControlPlaneRemediationPolicy
+}
+
+// selectMachineToBeRemediated selects the unhealthy machine whose remediation has the lowest impact on etcd, according
+// to the status of the etcd members; when more machines have the same impact, the oldest one is selected.
+// NOTE: If the status of the etcd members can't be read, the oldest machine is selected.
+func (r *KubeadmControlPlaneReconciler) selectMachineToBeRemediated(ctx context.Context, controlPlane *internal.ControlPlane, unhealthyMachines internal.FilterableMachineCollection) *clusterv1.Machine {
+	log := ctrl.LoggerFrom(ctx)
+
+	if len(unhealthyMachines) == 1 || !controlPlane.IsEtcdManaged() {
+		return unhealthyMachines.Oldest()
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err != nil {
+		log.Error(err, "Failed to create client to workload cluster, selecting the oldest unhealthy machine for remediation")
+		return unhealthyMachines.Oldest()
+	}
+	etcdMembers, err := workloadCluster.EtcdMembersStatus(ctx)
+	if err != nil {
+		log.Error(err, "Failed to get the status of the etcd members, selecting the oldest unhealthy machine for remediation")
+		return unhealthyMachines.Oldest()
+	}
+
+	machines := unhealthyMachines.SortedByCreationTimestamp()
+	sort.SliceStable(machines, func(i, j int) bool {
+		return etcdRemediationPriority(machines[i], etcdMembers) < etcdRemediationPriority(machines[j], etcdMembers)
+	})
+	return machines[0]
+}
+
+// etcdRemediationPriority returns the priority for remediating a machine, where lower values mean a lower impact
+// of the remediation on etcd:
+// - machines being deleted come first, so remediation waits for the deletion to complete;
+// - machines without an etcd member, whose remediation does not impact etcd at all;
+// - machines whose etcd member reports alarms, e.g. NOSPACE;
+// - machines whose etcd member is not responsive;
+// - machines whose etcd member is healthy, the etcd leader being the last one, so leadership is moved only if necessary.
+func etcdRemediationPriority(machine *clusterv1.Machine, etcdMembers []internal.EtcdMemberStatus) int {
+	if !machine.DeletionTimestamp.IsZero() {
+		return 0
+	}
+	if machine.Status.NodeRef == nil {
+		return 1
+	}
+	for _, member := range etcdMembers {
+		if member.Name != machine.Status.NodeRef.Name {
+			continue
+		}
+		switch {
+		case len(member.Alarms) > 0:
+			return 2
+		case !member.Responsive:
+			return 3
+		case !member.IsLeader:
+			return 4
+		default:
+			return 5
+		}
+	}
+	return 1
+}
+
+// remediationRetry checks if the remediation of a machine is a retry, i.e. the machine has been created after the last
+// remediation, and the last remediation happened less than spec.remediationStrategy.minHealthyPeriod ago.
+// It returns the retry count for the remediation, the time to wait before retrying according to
+// spec.remediationStrategy.retryPeriod, and false if spec.remediationStrategy.maxRetry has been reached.
+func remediationRetry(kcp *controlplanev1.KubeadmControlPlane, machine *clusterv1.Machine, now time.Time) (int32, time.Duration, bool) {
+	if len(kcp.Status.RemediationHistory) == 0 {
+		return 0, 0, true
+	}
+	lastRemediation := kcp.Status.RemediationHistory[len(kcp.Status.RemediationHistory)-1]
+	strategy := kcp.Spec.RemediationStrategy
+
+	minHealthyPeriod := defaultMinHealthyPeriod
+	if strategy != nil && strategy.MinHealthyPeriod != nil {
+		minHealthyPeriod = strategy.MinHealthyPeriod.Duration
+	}
+	if !machine.CreationTimestamp.After(lastRemediation.Timestamp.Time) || !now.Before(lastRemediation.Timestamp.Add(minHealthyPeriod)) {
+		return 0, 0, true
+	}
+
+	retryCount := lastRemediation.RetryCount + 1
+	if strategy == nil {
+		return retryCount, 0, true
+	}
+	if strategy.MaxRetry != nil && retryCount > *strategy.MaxRetry {
+		return retryCount, 0, false
+	}
+	if strategy.RetryPeriod != nil {
+		if retryTime := lastRemediation.Timestamp.Add(strategy.RetryPeriod.Duration); now.Before(retryTime) {
+			return retryCount, retryTime.Sub(now), true
+		}
+	}
+	return retryCount, 0, true
+}
+
+// recordRemediation records the remediation of a machine in status.remediationHistory, dropping the oldest remediations
+// exceeding the history length.
+func recordRemediation(kcp *controlplanev1.KubeadmControlPlane, machineName string, now time.Time, retryCount int32) {
+	history := append(kcp.Status.RemediationHistory, controlplanev1.RemediationRecord{
+		Machine:    machineName,
+		Timestamp:  metav1.NewTime(now),
+		RetryCount: retryCount,
+	})
+	if len(history) > remediationHistoryLength {
+		history = history[len(history)-remediationHistoryLength:]
+	}
+	kcp.Status.RemediationHistory = history
+}
+
 // canSafelyRemoveEtcdMember assess if it is possible to remove the member hosted on the machine to be remediated
 // without loosing etcd quorum.
 //
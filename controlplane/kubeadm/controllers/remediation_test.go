@@ -18,7 +18,9 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
@@ -27,10 +29,13 @@ import (
 	"k8s.io/client-go/tools/record"
 	utilpointer "k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -252,6 +257,159 @@ func TestReconcileUnhealthyMachines(t *testing.T) {
 	g.Expect(testEnv.Cleanup(ctx, ns)).To(Succeed())
 }
 
+func TestReconcileUnhealthyMachinesSmallControlPlane(t *testing.T) {
+	t.Run("Remediation deletes the only machine of a control plane with 1 replica if the risk has been accepted", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster, kcp, genericMachineTemplate := createClusterWithControlPlane()
+		kcp.Spec.Replicas = utilpointer.Int32Ptr(1)
+		kcp.Spec.RemediationStrategy = &controlplanev1.RemediationStrategy{
+			SmallControlPlanePolicy: controlplanev1.AcceptRiskSmallControlPlaneRemediationPolicy,
+		}
+
+		m1, _ := createMachineNodePair("m1", cluster, kcp, true)
+		withMachineHealthCheckFailed()(m1)
+
+		fakeClient := &deletionTimestampClient{newFakeClient(g, cluster.DeepCopy(), kcp.DeepCopy(), genericMachineTemplate.DeepCopy(), m1.DeepCopy())}
+		r := &KubeadmControlPlaneReconciler{
+			Client:   fakeClient,
+			recorder: record.NewFakeRecorder(32),
+			// The workload cluster is not used, because it is not reachable without a healthy control plane machine.
+			managementCluster: &fakeManagementCluster{},
+		}
+		controlPlane := &internal.ControlPlane{
+			KCP:      kcp,
+			Cluster:  cluster,
+			Machines: internal.NewFilterableMachineCollection(m1),
+		}
+
+		ret, err := r.reconcileUnhealthyMachines(ctx, controlPlane)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ret).To(Equal(ctrl.Result{Requeue: true}))
+
+		g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(m1), m1)).To(Succeed())
+		g.Expect(m1.DeletionTimestamp.IsZero()).To(BeFalse())
+		g.Expect(kcp.Status.RemediationHistory).To(HaveLen(1))
+		g.Expect(kcp.Status.RemediationHistory[0].Machine).To(Equal("m1"))
+	})
+
+	t.Run("Remediation does not happen for a control plane with 1 replica if etcd can't be restored from the latest snapshot", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster, kcp, genericMachineTemplate := createClusterWithControlPlane()
+		kcp.Spec.Replicas = utilpointer.Int32Ptr(1)
+		kcp.Spec.EtcdSnapshots = &controlplanev1.EtcdSnapshots{}
+		kcp.Spec.RemediationStrategy = &controlplanev1.RemediationStrategy{
+			SmallControlPlanePolicy: controlplanev1.AcceptRiskSmallControlPlaneRemediationPolicy,
+		}
+
+		m1, _ := createMachineNodePair("m1", cluster, kcp, true)
+		withMachineHealthCheckFailed()(m1)
+
+		fakeClient := &deletionTimestampClient{newFakeClient(g, cluster.DeepCopy(), kcp.DeepCopy(), genericMachineTemplate.DeepCopy(), m1.DeepCopy())}
+		r := &KubeadmControlPlaneReconciler{
+			Client:            fakeClient,
+			recorder:          record.NewFakeRecorder(32),
+			managementCluster: &fakeManagementCluster{},
+		}
+		controlPlane := &internal.ControlPlane{
+			KCP:      kcp,
+			Cluster:  cluster,
+			Machines: internal.NewFilterableMachineCollection(m1),
+		}
+
+		ret, err := r.reconcileUnhealthyMachines(ctx, controlPlane)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ret.IsZero()).To(BeTrue())
+
+		g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(m1), m1)).To(Succeed())
+		g.Expect(m1.DeletionTimestamp.IsZero()).To(BeTrue())
+		g.Expect(conditions.GetReason(m1, clusterv1.MachineOwnerRemediatedCondition)).To(Equal(clusterv1.WaitingForRemediationReason))
+		g.Expect(conditions.GetMessage(m1, clusterv1.MachineOwnerRemediatedCondition)).To(ContainSubstring("--management-cluster-endpoint"))
+	})
+
+	t.Run("Remediation scales up a control plane with 2 replicas before deleting the unhealthy machine", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster, kcp, genericMachineTemplate := createClusterWithControlPlane()
+		kcp.Spec.Replicas = utilpointer.Int32Ptr(2)
+		kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1.ClusterConfiguration{
+			Etcd: kubeadmv1.Etcd{External: &kubeadmv1.ExternalEtcd{Endpoints: []string{"https://etcd:2379"}}},
+		}
+		kcp.Spec.RemediationStrategy = &controlplanev1.RemediationStrategy{
+			SmallControlPlanePolicy: controlplanev1.AcceptRiskSmallControlPlaneRemediationPolicy,
+		}
+
+		m1, _ := createMachineNodePair("m1", cluster, kcp, true)
+		withMachineHealthCheckFailed()(m1)
+		m2, _ := createMachineNodePair("m2", cluster, kcp, true)
+		setMachineHealthy(m2)
+
+		fakeClient := &deletionTimestampClient{newFakeClient(g, cluster.DeepCopy(), kcp.DeepCopy(), genericMachineTemplate.DeepCopy(), m1.DeepCopy(), m2.DeepCopy())}
+		r := &KubeadmControlPlaneReconciler{
+			Client:            fakeClient,
+			recorder:          record.NewFakeRecorder(32),
+			managementCluster: &fakeManagementCluster{Workload: fakeWorkloadCluster{}},
+		}
+		controlPlane := &internal.ControlPlane{
+			KCP:      kcp,
+			Cluster:  cluster,
+			Machines: internal.NewFilterableMachineCollection(m1, m2),
+		}
+
+		// The first reconcile creates the replacement machine.
+		ret, err := r.reconcileUnhealthyMachines(ctx, controlPlane)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ret).To(Equal(ctrl.Result{Requeue: true}))
+
+		machines := &clusterv1.MachineList{}
+		g.Expect(fakeClient.List(ctx, machines)).To(Succeed())
+		g.Expect(machines.Items).To(HaveLen(3))
+		g.Expect(kcp.Status.RemediationHistory).To(BeEmpty())
+
+		var m3 *clusterv1.Machine
+		for i := range machines.Items {
+			if machines.Items[i].Name != m1.Name && machines.Items[i].Name != m2.Name {
+				m3 = &machines.Items[i]
+			}
+		}
+		g.Expect(m3).ToNot(BeNil())
+		controlPlane.Machines = internal.NewFilterableMachineCollection(m1, m2, m3)
+
+		// Remediation waits for the replacement machine to be healthy.
+		ret, err = r.reconcileUnhealthyMachines(ctx, controlPlane)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ret).To(Equal(ctrl.Result{RequeueAfter: preflightFailedRequeueAfter}))
+		g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(m1), &clusterv1.Machine{})).To(Succeed())
+
+		// Once the replacement machine is healthy, the unhealthy machine is deleted.
+		setMachineHealthy(m3)
+		ret, err = r.reconcileUnhealthyMachines(ctx, controlPlane)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ret).To(Equal(ctrl.Result{Requeue: true}))
+
+		g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(m1), m1)).To(Succeed())
+		g.Expect(m1.DeletionTimestamp.IsZero()).To(BeFalse())
+		g.Expect(kcp.Status.RemediationHistory).To(HaveLen(1))
+	})
+}
+
+// deletionTimestampClient sets the deletion timestamp instead of deleting objects, like the API server does for objects
+// with finalizers, which are not supported by the fake client.
+type deletionTimestampClient struct {
+	client.Client
+}
+
+func (c *deletionTimestampClient) Delete(ctx context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	now := metav1.Now()
+	current := obj.DeepCopyObject().(client.Object)
+	if err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		return err
+	}
+	current.SetDeletionTimestamp(&now)
+	return c.Client.Update(ctx, current)
+}
+
 func TestCanSafelyRemoveEtcdMember(t *testing.T) {
 	g := NewWithT(t)
 	ctx := context.TODO()
@@ -519,6 +677,197 @@ func TestCanSafelyRemoveEtcdMember(t *testing.T) {
 	g.Expect(testEnv.Cleanup(ctx, ns)).To(Succeed())
 }
 
+func TestSelectMachineToBeRemediated(t *testing.T) {
+	now := time.Now()
+	newMachine := func(name string, age time.Duration) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Status: clusterv1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Kind: "Node", Name: name},
+			},
+		}
+	}
+	m1 := newMachine("m1", 3*time.Hour)
+	m2 := newMachine("m2", 2*time.Hour)
+	m3 := newMachine("m3", 1*time.Hour)
+
+	tests := []struct {
+		name        string
+		etcdMembers []internal.EtcdMemberStatus
+		expected    string
+	}{
+		{
+			name: "selects the oldest machine if all the etcd members are healthy and none is the leader",
+			etcdMembers: []internal.EtcdMemberStatus{
+				{Name: "m1", Responsive: true},
+				{Name: "m2", Responsive: true},
+				{Name: "m3", Responsive: true},
+			},
+			expected: "m1",
+		},
+		{
+			name: "avoids the etcd leader",
+			etcdMembers: []internal.EtcdMemberStatus{
+				{Name: "m1", Responsive: true, IsLeader: true},
+				{Name: "m2", Responsive: true},
+				{Name: "m3", Responsive: true},
+			},
+			expected: "m2",
+		},
+		{
+			name: "selects the machine whose etcd member is not responsive",
+			etcdMembers: []internal.EtcdMemberStatus{
+				{Name: "m1", Responsive: true},
+				{Name: "m2", Responsive: true},
+				{Name: "m3", Responsive: false},
+			},
+			expected: "m3",
+		},
+		{
+			name: "selects the machine whose etcd member has alarms",
+			etcdMembers: []internal.EtcdMemberStatus{
+				{Name: "m1", Responsive: true},
+				{Name: "m2", Responsive: true, Alarms: []etcd.AlarmType{etcd.AlarmNoSpace}},
+				{Name: "m3", Responsive: false},
+			},
+			expected: "m2",
+		},
+		{
+			name: "selects the machine without an etcd member",
+			etcdMembers: []internal.EtcdMemberStatus{
+				{Name: "m1", Responsive: true},
+				{Name: "m2", Responsive: true, Alarms: []etcd.AlarmType{etcd.AlarmNoSpace}},
+			},
+			expected: "m3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			controlPlane := &internal.ControlPlane{
+				KCP:      &controlplanev1.KubeadmControlPlane{},
+				Cluster:  &clusterv1.Cluster{},
+				Machines: internal.NewFilterableMachineCollection(m1, m2, m3),
+			}
+			r := &KubeadmControlPlaneReconciler{
+				managementCluster: &fakeManagementCluster{
+					Workload: fakeWorkloadCluster{
+						EtcdMembersStatusResult: tt.etcdMembers,
+					},
+				},
+			}
+
+			machine := r.selectMachineToBeRemediated(ctx, controlPlane, controlPlane.Machines)
+			g.Expect(machine.Name).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestRemediationRetry(t *testing.T) {
+	now := time.Now()
+	lastRemediation := now.Add(-5 * time.Minute)
+
+	tests := []struct {
+		name               string
+		strategy           *controlplanev1.RemediationStrategy
+		history            []controlplanev1.RemediationRecord
+		machineCreated     time.Time
+		expectedRetryCount int32
+		expectedRetryAfter time.Duration
+		expectedRemediate  bool
+	}{
+		{
+			name:              "first remediation",
+			machineCreated:    now.Add(-time.Hour),
+			expectedRemediate: true,
+		},
+		{
+			name:              "not a retry if the machine has been created before the last remediation",
+			history:           []controlplanev1.RemediationRecord{{Machine: "m0", Timestamp: metav1.NewTime(lastRemediation), RetryCount: 2}},
+			machineCreated:    lastRemediation.Add(-time.Minute),
+			expectedRemediate: true,
+		},
+		{
+			name:               "retry if the machine has been created after the last remediation",
+			history:            []controlplanev1.RemediationRecord{{Machine: "m0", Timestamp: metav1.NewTime(lastRemediation), RetryCount: 2}},
+			machineCreated:     lastRemediation.Add(time.Minute),
+			expectedRetryCount: 3,
+			expectedRemediate:  true,
+		},
+		{
+			name: "not a retry if the last remediation happened before the min healthy period",
+			strategy: &controlplanev1.RemediationStrategy{
+				MinHealthyPeriod: &metav1.Duration{Duration: time.Minute},
+			},
+			history:           []controlplanev1.RemediationRecord{{Machine: "m0", Timestamp: metav1.NewTime(lastRemediation), RetryCount: 2}},
+			machineCreated:    lastRemediation.Add(time.Minute),
+			expectedRemediate: true,
+		},
+		{
+			name: "can't remediate if the max retry has been reached",
+			strategy: &controlplanev1.RemediationStrategy{
+				MaxRetry: utilpointer.Int32Ptr(2),
+			},
+			history:            []controlplanev1.RemediationRecord{{Machine: "m0", Timestamp: metav1.NewTime(lastRemediation), RetryCount: 2}},
+			machineCreated:     lastRemediation.Add(time.Minute),
+			expectedRetryCount: 3,
+			expectedRemediate:  false,
+		},
+		{
+			name: "wait for the retry period",
+			strategy: &controlplanev1.RemediationStrategy{
+				MaxRetry:    utilpointer.Int32Ptr(3),
+				RetryPeriod: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+			history:            []controlplanev1.RemediationRecord{{Machine: "m0", Timestamp: metav1.NewTime(lastRemediation), RetryCount: 2}},
+			machineCreated:     lastRemediation.Add(time.Minute),
+			expectedRetryCount: 3,
+			expectedRetryAfter: 5 * time.Minute,
+			expectedRemediate:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			kcp := &controlplanev1.KubeadmControlPlane{
+				Spec:   controlplanev1.KubeadmControlPlaneSpec{RemediationStrategy: tt.strategy},
+				Status: controlplanev1.KubeadmControlPlaneStatus{RemediationHistory: tt.history},
+			}
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "m1", CreationTimestamp: metav1.NewTime(tt.machineCreated)},
+			}
+
+			retryCount, retryAfter, canRemediate := remediationRetry(kcp, machine, now)
+			g.Expect(retryCount).To(Equal(tt.expectedRetryCount))
+			g.Expect(retryAfter).To(Equal(tt.expectedRetryAfter))
+			g.Expect(canRemediate).To(Equal(tt.expectedRemediate))
+		})
+	}
+}
+
+func TestRecordRemediation(t *testing.T) {
+	g := NewWithT(t)
+
+	kcp := &controlplanev1.KubeadmControlPlane{}
+	now := time.Now()
+	for i := 0; i < remediationHistoryLength+2; i++ {
+		recordRemediation(kcp, fmt.Sprintf("m%d", i), now.Add(time.Duration(i)*time.Minute), int32(i))
+	}
+
+	g.Expect(kcp.Status.RemediationHistory).To(HaveLen(remediationHistoryLength))
+	g.Expect(kcp.Status.RemediationHistory[0].Machine).To(Equal("m2"))
+	last := kcp.Status.RemediationHistory[remediationHistoryLength-1]
+	g.Expect(last.Machine).To(Equal(fmt.Sprintf("m%d", remediationHistoryLength+1)))
+	g.Expect(last.RetryCount).To(Equal(int32(remediationHistoryLength + 1)))
+}
+
 type machineOption func(*clusterv1.Machine)
 
 func withMachineHealthCheckFailed() machineOption {
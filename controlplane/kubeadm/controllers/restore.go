@@ -0,0 +1,304 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd/snapshot"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/secret"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// etcdRestoreCredentialsName returns the name of the ServiceAccount, Role, RoleBinding and token Secret granting the first
+// control plane machine read access to the snapshot to restore.
+func etcdRestoreCredentialsName(cluster *clusterv1.Cluster) string {
+	return fmt.Sprintf("%s-etcd-restore", cluster.Name)
+}
+
+// etcdRestoreNotConfiguredMessage is the message reported when the controller is not configured for restoring etcd.
+const etcdRestoreNotConfiguredMessage = "Restoring etcd from a snapshot requires the address of the management cluster API server reachable from the machines, set with the --management-cluster-endpoint flag of the controller"
+
+// etcdRestorePending returns true if the restore defined in spec.etcdRestore has not been completed yet.
+// NOTE: A completed restore is not performed again, otherwise the data written after the restore would be lost.
+func etcdRestorePending(kcp *controlplanev1.KubeadmControlPlane) bool {
+	return kcp.Spec.EtcdRestore != nil && !reflect.DeepEqual(kcp.Spec.EtcdRestore, kcp.Status.LastEtcdRestore)
+}
+
+// reconcileEtcdRestoreRequirements reports in the EtcdRestoreSucceeded condition whether the restore defined in spec.etcdRestore
+// can't be performed because the controller is not configured for it; the check happens as soon as spec.etcdRestore is set,
+// so the problem is reported before the control plane is scaled down for the restore.
+func (r *KubeadmControlPlaneReconciler) reconcileEtcdRestoreRequirements(kcp *controlplanev1.KubeadmControlPlane) {
+	if etcdRestorePending(kcp) && r.ManagementClusterEndpoint == "" {
+		conditions.MarkFalse(kcp, controlplanev1.EtcdRestoreSucceededCondition, controlplanev1.EtcdRestoreNotConfiguredReason, clusterv1.ConditionSeverityError, etcdRestoreNotConfiguredMessage)
+		return
+	}
+	if conditions.GetReason(kcp, controlplanev1.EtcdRestoreSucceededCondition) == controlplanev1.EtcdRestoreNotConfiguredReason {
+		conditions.Delete(kcp, controlplanev1.EtcdRestoreSucceededCondition)
+	}
+}
+
+// etcdRestoreForInitialization returns the etcd snapshot to restore when initializing the control plane, if any:
+// the snapshot defined in spec.etcdRestore if not restored yet, or the latest snapshot taken by the KubeadmControlPlane
+// when initializing again a control plane whose only machine has been remediated.
+func (r *KubeadmControlPlaneReconciler) etcdRestoreForInitialization(ctx context.Context, controlPlane *internal.ControlPlane) (*controlplanev1.EtcdRestore, error) {
+	kcp := controlPlane.KCP
+	if etcdRestorePending(kcp) {
+		return kcp.Spec.EtcdRestore, nil
+	}
+
+	if !kcp.Status.Initialized || !allowsSmallControlPlaneRemediation(kcp) || kcp.Spec.EtcdSnapshots == nil || !controlPlane.IsEtcdManaged() {
+		return nil, nil
+	}
+	snapshots, err := r.etcdSnapshotSink(controlPlane).List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list etcd snapshots")
+	}
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+	return &controlplanev1.EtcdRestore{SnapshotName: snapshots[len(snapshots)-1].Name}, nil
+}
+
+// prepareEtcdRestore changes the bootstrap config of the first control plane machine so the etcd data dir is restored
+// from the snapshot before kubeadm init; the machine reads the snapshot from the management cluster, using the credentials
+// of a ServiceAccount allowed to read only the objects storing the snapshot.
+// It returns false if the credentials are not available yet.
+// NOTE: The token of the ServiceAccount is part of the bootstrap data of the machine, so it is exposed wherever the infrastructure
+// provider exposes the user data, e.g. the instance metadata service, until the restore completes and the credentials are deleted.
+func (r *KubeadmControlPlaneReconciler) prepareEtcdRestore(ctx context.Context, controlPlane *internal.ControlPlane, restore *controlplanev1.EtcdRestore, bootstrapSpec *bootstrapv1.KubeadmConfigSpec) (bool, error) {
+	objects, source, err := r.etcdRestoreObjects(ctx, controlPlane, restore)
+	if err != nil {
+		return false, err
+	}
+
+	credentials, err := r.reconcileEtcdRestoreCredentials(ctx, controlPlane, objects)
+	if err != nil {
+		return false, err
+	}
+	if credentials == nil {
+		return false, nil
+	}
+
+	if err := snapshot.AddRestoreToConfig(bootstrapSpec, snapshot.RestoreSource{
+		Server:                r.ManagementClusterEndpoint,
+		Namespace:             controlPlane.KCP.Namespace,
+		Objects:               objects,
+		CredentialsSecretName: credentials.Name,
+	}); err != nil {
+		return false, err
+	}
+
+	conditions.MarkFalse(controlPlane.KCP, controlplanev1.EtcdRestoreSucceededCondition, controlplanev1.EtcdRestoreInProgressReason, clusterv1.ConditionSeverityInfo, "Restoring etcd from %s", source)
+	return true, nil
+}
+
+// etcdRestoreObjects returns the objects storing the snapshot to restore, and a description of its source.
+func (r *KubeadmControlPlaneReconciler) etcdRestoreObjects(ctx context.Context, controlPlane *internal.ControlPlane, restore *controlplanev1.EtcdRestore) ([]snapshot.RestoreObject, string, error) {
+	if restore.SnapshotName != "" {
+		source := fmt.Sprintf("snapshot %s", restore.SnapshotName)
+		objects, err := r.etcdSnapshotSink(controlPlane).RestoreObjects(ctx, restore.SnapshotName)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "failed to read %s", source)
+		}
+		return objects, source, nil
+	}
+
+	source := fmt.Sprintf("Secret %s, key %s", restore.SecretRef.Name, restore.SecretRef.Key)
+	s := &corev1.Secret{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: controlPlane.KCP.Namespace, Name: restore.SecretRef.Name}, s); err != nil {
+		return nil, "", errors.Wrapf(err, "failed to get %s", source)
+	}
+	if _, ok := s.Data[restore.SecretRef.Key]; !ok {
+		return nil, "", errors.Errorf("failed to read %s: key not found", source)
+	}
+	return []snapshot.RestoreObject{{Kind: "Secret", Name: restore.SecretRef.Name, Key: restore.SecretRef.Key}}, source, nil
+}
+
+// reconcileEtcdRestoreCredentials creates a ServiceAccount allowed to get only the objects storing the snapshot to restore,
+// and returns its token Secret; nil is returned until the token is generated.
+// NOTE: The credentials are deleted as soon as the restore completes, invalidating the token.
+func (r *KubeadmControlPlaneReconciler) reconcileEtcdRestoreCredentials(ctx context.Context, controlPlane *internal.ControlPlane, objects []snapshot.RestoreObject) (*corev1.Secret, error) {
+	objectMeta := metav1.ObjectMeta{
+		Name:      etcdRestoreCredentialsName(controlPlane.Cluster),
+		Namespace: controlPlane.KCP.Namespace,
+		Labels: map[string]string{
+			clusterv1.ClusterLabelName: controlPlane.Cluster.Name,
+		},
+		OwnerReferences: []metav1.OwnerReference{
+			*metav1.NewControllerRef(controlPlane.KCP, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane")),
+		},
+	}
+
+	resourceNames := map[string][]string{}
+	for _, o := range objects {
+		resource := strings.ToLower(o.Kind) + "s"
+		resourceNames[resource] = append(resourceNames[resource], o.Name)
+	}
+	rules := []rbacv1.PolicyRule{}
+	for _, resource := range []string{"configmaps", "secrets"} {
+		if names, ok := resourceNames[resource]; ok {
+			rules = append(rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{resource}, ResourceNames: names, Verbs: []string{"get"}})
+		}
+	}
+
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: *objectMeta.DeepCopy()}
+	if err := r.Client.Create(ctx, serviceAccount); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, errors.Wrapf(err, "failed to create ServiceAccount %s", serviceAccount.Name)
+	}
+
+	// The Role is updated if left over from a previous attempt to initialize the control plane, because the objects
+	// storing the snapshot can be different.
+	role := &rbacv1.Role{ObjectMeta: *objectMeta.DeepCopy(), Rules: rules}
+	if err := r.Client.Create(ctx, role); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, errors.Wrapf(err, "failed to create Role %s", role.Name)
+		}
+		patch, err := json.Marshal(map[string]interface{}{"rules": rules})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to update Role %s", role.Name)
+		}
+		if err := r.Client.Patch(ctx, &rbacv1.Role{ObjectMeta: *objectMeta.DeepCopy()}, client.RawPatch(types.MergePatchType, patch)); err != nil {
+			return nil, errors.Wrapf(err, "failed to update Role %s", role.Name)
+		}
+	}
+
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: *objectMeta.DeepCopy(),
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: role.Name},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: serviceAccount.Name, Namespace: serviceAccount.Namespace}},
+	}
+	if err := r.Client.Create(ctx, roleBinding); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, errors.Wrapf(err, "failed to create RoleBinding %s", roleBinding.Name)
+	}
+
+	token := &corev1.Secret{ObjectMeta: *objectMeta.DeepCopy(), Type: corev1.SecretTypeServiceAccountToken}
+	token.Annotations = map[string]string{corev1.ServiceAccountNameKey: serviceAccount.Name}
+	if err := r.Client.Create(ctx, token); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, errors.Wrapf(err, "failed to create Secret %s", token.Name)
+	}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(token), token); err != nil {
+		return nil, errors.Wrapf(err, "failed to get Secret %s", token.Name)
+	}
+	if len(token.Data[corev1.ServiceAccountTokenKey]) == 0 || len(token.Data[corev1.ServiceAccountRootCAKey]) == 0 {
+		return nil, nil
+	}
+	return token, nil
+}
+
+// deleteEtcdRestoreCredentials deletes the credentials used by the first control plane machine for reading the snapshot.
+func (r *KubeadmControlPlaneReconciler) deleteEtcdRestoreCredentials(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	objectMeta := metav1.ObjectMeta{
+		Name:      etcdRestoreCredentialsName(controlPlane.Cluster),
+		Namespace: controlPlane.KCP.Namespace,
+	}
+	objs := []client.Object{
+		&corev1.ServiceAccount{ObjectMeta: objectMeta},
+		&corev1.Secret{ObjectMeta: objectMeta},
+		&rbacv1.RoleBinding{ObjectMeta: objectMeta},
+		&rbacv1.Role{ObjectMeta: objectMeta},
+	}
+	errs := []error{}
+	for _, obj := range objs {
+		if err := r.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(err, "failed to delete %T %s", obj, objectMeta.Name))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// lookupEtcdRestoreCertificates looks up the certificates of the cluster when restoring etcd from a snapshot.
+// NOTE: The data restored from the snapshot is bound to the certificates of the cluster the snapshot was taken from,
+// e.g. service account tokens, so the certificates are not generated like when initializing a new control plane.
+func (r *KubeadmControlPlaneReconciler) lookupEtcdRestoreCertificates(ctx context.Context, cluster *clusterv1.Cluster, certificates secret.Certificates) error {
+	if err := certificates.Lookup(ctx, r.Client, util.ObjectKey(cluster)); err != nil {
+		return err
+	}
+	if err := certificates.EnsureAllExist(); err != nil {
+		return errors.Wrap(err, "the certificates of the cluster are required for restoring etcd from a snapshot")
+	}
+	return nil
+}
+
+// reconcileEtcdRestore completes the restore of etcd from a snapshot, once all the machines have a node; the nodes of the
+// machines existing when the snapshot was taken are removed from the workload cluster, and the credentials used for reading
+// the snapshot are deleted. The restore defined in spec.etcdRestore, if any, is recorded in status.lastEtcdRestore, so it is not
+// performed again.
+func (r *KubeadmControlPlaneReconciler) reconcileEtcdRestore(ctx context.Context, controlPlane *internal.ControlPlane) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx, "cluster", controlPlane.Cluster.Name)
+
+	if conditions.GetReason(controlPlane.KCP, controlplanev1.EtcdRestoreSucceededCondition) != controlplanev1.EtcdRestoreInProgressReason {
+		return ctrl.Result{}, nil
+	}
+
+	// If there is no KCP-owned control-plane machines, the restore has not been started yet.
+	if controlPlane.Machines.Len() == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	// Collect all the node names.
+	nodeNames := []string{}
+	for _, machine := range controlPlane.Machines {
+		if machine.Status.NodeRef == nil {
+			// If there are provisioning machines (machines without a node yet), return.
+			return ctrl.Result{}, nil
+		}
+		nodeNames = append(nodeNames, machine.Status.NodeRef.Name)
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "cannot get remote client to workload cluster")
+	}
+
+	removedNodes, err := workloadCluster.RemoveStaleControlPlaneNodes(ctx, nodeNames)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to remove stale control plane nodes")
+	}
+	if len(removedNodes) > 0 {
+		log.Info("Control plane nodes restored from the etcd snapshot removed from the cluster", "nodes", removedNodes)
+	}
+
+	if err := r.deleteEtcdRestoreCredentials(ctx, controlPlane); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if controlPlane.KCP.Spec.EtcdRestore != nil {
+		controlPlane.KCP.Status.LastEtcdRestore = controlPlane.KCP.Spec.EtcdRestore.DeepCopy()
+	}
+	conditions.MarkTrue(controlPlane.KCP, controlplanev1.EtcdRestoreSucceededCondition)
+	r.recorder.Eventf(controlPlane.KCP, corev1.EventTypeNormal, "SuccessfulEtcdRestore", "Restored etcd for cluster %s/%s control plane", controlPlane.Cluster.Namespace, controlPlane.Cluster.Name)
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,381 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	utilpointer "k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd/snapshot"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/secret"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestEtcdRestoreForInitialization(t *testing.T) {
+	newControlPlane := func(initialized bool, policy controlplanev1.SmallControlPlaneRemediationPolicy) *internal.ControlPlane {
+		cluster, kcp, _ := createClusterWithControlPlane()
+		kcp.Spec.Replicas = utilpointer.Int32Ptr(1)
+		kcp.Spec.EtcdSnapshots = &controlplanev1.EtcdSnapshots{}
+		kcp.Spec.RemediationStrategy = &controlplanev1.RemediationStrategy{SmallControlPlanePolicy: policy}
+		kcp.Status.Initialized = initialized
+		return &internal.ControlPlane{
+			KCP:      kcp,
+			Cluster:  cluster,
+			Machines: internal.NewFilterableMachineCollection(),
+		}
+	}
+
+	t.Run("returns spec.etcdRestore if set", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(false, "")
+		controlPlane.KCP.Spec.EtcdRestore = &controlplanev1.EtcdRestore{SnapshotName: "test-etcd-20210304-050607"}
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+
+		restore, err := r.etcdRestoreForInitialization(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(restore).To(Equal(controlPlane.KCP.Spec.EtcdRestore))
+	})
+
+	t.Run("returns the latest snapshot when initializing again a remediated control plane", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(true, controlplanev1.AcceptRiskSmallControlPlaneRemediationPolicy)
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+		sink := r.etcdSnapshotSink(controlPlane)
+		now := time.Now()
+		g.Expect(sink.Save(ctx, "test-etcd-20210304-050607", now.Add(-time.Hour), bytes.NewReader([]byte("old")))).To(Succeed())
+		g.Expect(sink.Save(ctx, "test-etcd-20210304-060607", now, bytes.NewReader([]byte("new")))).To(Succeed())
+
+		restore, err := r.etcdRestoreForInitialization(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(restore).To(Equal(&controlplanev1.EtcdRestore{SnapshotName: "test-etcd-20210304-060607"}))
+	})
+
+	t.Run("returns the latest snapshot when initializing again a remediated control plane after completing spec.etcdRestore", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(true, controlplanev1.AcceptRiskSmallControlPlaneRemediationPolicy)
+		controlPlane.KCP.Spec.EtcdRestore = &controlplanev1.EtcdRestore{SnapshotName: "test-etcd-20210304-050607"}
+		controlPlane.KCP.Status.LastEtcdRestore = controlPlane.KCP.Spec.EtcdRestore.DeepCopy()
+		conditions.MarkTrue(controlPlane.KCP, controlplanev1.EtcdRestoreSucceededCondition)
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+		sink := r.etcdSnapshotSink(controlPlane)
+		now := time.Now()
+		g.Expect(sink.Save(ctx, "test-etcd-20210304-050607", now.Add(-time.Hour), bytes.NewReader([]byte("old")))).To(Succeed())
+		g.Expect(sink.Save(ctx, "test-etcd-20210304-060607", now, bytes.NewReader([]byte("new")))).To(Succeed())
+
+		restore, err := r.etcdRestoreForInitialization(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(restore).To(Equal(&controlplanev1.EtcdRestore{SnapshotName: "test-etcd-20210304-060607"}))
+	})
+
+	t.Run("returns spec.etcdRestore if different from the last completed restore", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(true, controlplanev1.AcceptRiskSmallControlPlaneRemediationPolicy)
+		controlPlane.KCP.Spec.EtcdRestore = &controlplanev1.EtcdRestore{SnapshotName: "test-etcd-20210304-060607"}
+		controlPlane.KCP.Status.LastEtcdRestore = &controlplanev1.EtcdRestore{SnapshotName: "test-etcd-20210304-050607"}
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+
+		restore, err := r.etcdRestoreForInitialization(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(restore).To(Equal(controlPlane.KCP.Spec.EtcdRestore))
+	})
+
+	t.Run("returns nothing when initializing again a remediated control plane without snapshots", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(true, controlplanev1.AcceptRiskSmallControlPlaneRemediationPolicy)
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+
+		restore, err := r.etcdRestoreForInitialization(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(restore).To(BeNil())
+	})
+
+	t.Run("returns nothing if the remediation of small control planes is not allowed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(true, controlplanev1.NeverSmallControlPlaneRemediationPolicy)
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+		sink := r.etcdSnapshotSink(controlPlane)
+		g.Expect(sink.Save(ctx, "test-etcd-20210304-050607", time.Now(), bytes.NewReader([]byte("snapshot")))).To(Succeed())
+
+		restore, err := r.etcdRestoreForInitialization(ctx, controlPlane)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(restore).To(BeNil())
+	})
+}
+
+func TestReconcileEtcdRestoreRequirements(t *testing.T) {
+	t.Run("reports a restore that can't be performed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, kcp, _ := createClusterWithControlPlane()
+		kcp.Spec.EtcdRestore = &controlplanev1.EtcdRestore{SnapshotName: "test-etcd-20210304-050607"}
+		r := &KubeadmControlPlaneReconciler{}
+
+		r.reconcileEtcdRestoreRequirements(kcp)
+		g.Expect(conditions.GetReason(kcp, controlplanev1.EtcdRestoreSucceededCondition)).To(Equal(controlplanev1.EtcdRestoreNotConfiguredReason))
+		g.Expect(*conditions.GetSeverity(kcp, controlplanev1.EtcdRestoreSucceededCondition)).To(Equal(clusterv1.ConditionSeverityError))
+
+		// The condition is removed once the controller is configured.
+		r.ManagementClusterEndpoint = "https://10.0.0.1:6443"
+		r.reconcileEtcdRestoreRequirements(kcp)
+		g.Expect(conditions.Has(kcp, controlplanev1.EtcdRestoreSucceededCondition)).To(BeFalse())
+	})
+
+	t.Run("ignores a completed restore", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, kcp, _ := createClusterWithControlPlane()
+		kcp.Spec.EtcdRestore = &controlplanev1.EtcdRestore{SnapshotName: "test-etcd-20210304-050607"}
+		kcp.Status.LastEtcdRestore = kcp.Spec.EtcdRestore.DeepCopy()
+		conditions.MarkTrue(kcp, controlplanev1.EtcdRestoreSucceededCondition)
+		r := &KubeadmControlPlaneReconciler{}
+
+		r.reconcileEtcdRestoreRequirements(kcp)
+		g.Expect(conditions.IsTrue(kcp, controlplanev1.EtcdRestoreSucceededCondition)).To(BeTrue())
+	})
+}
+
+func TestPrepareEtcdRestore(t *testing.T) {
+	newControlPlane := func(restore *controlplanev1.EtcdRestore) *internal.ControlPlane {
+		cluster, kcp, _ := createClusterWithControlPlane()
+		kcp.UID = "kcp-uid"
+		kcp.Spec.EtcdRestore = restore
+		return &internal.ControlPlane{
+			KCP:      kcp,
+			Cluster:  cluster,
+			Machines: internal.NewFilterableMachineCollection(),
+		}
+	}
+	// generateToken simulates the token controller populating the token Secret of the ServiceAccount.
+	generateToken := func(g *WithT, c client.Client, controlPlane *internal.ControlPlane) {
+		s := &corev1.Secret{}
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: controlPlane.KCP.Namespace, Name: etcdRestoreCredentialsName(controlPlane.Cluster)}, s)).To(Succeed())
+		s.Data = map[string][]byte{corev1.ServiceAccountTokenKey: []byte("token"), corev1.ServiceAccountRootCAKey: []byte("ca")}
+		g.Expect(c.Update(ctx, s)).To(Succeed())
+	}
+
+	t.Run("restores a snapshot taken by the control plane", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(&controlplanev1.EtcdRestore{SnapshotName: "test-etcd-20210304-050607"})
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g), ManagementClusterEndpoint: "https://10.0.0.1:6443"}
+		sink := r.etcdSnapshotSink(controlPlane)
+		g.Expect(sink.Save(ctx, "test-etcd-20210304-050607", time.Now(), bytes.NewReader([]byte("snapshot")))).To(Succeed())
+
+		// The restore waits for the token of the ServiceAccount used for reading the snapshot.
+		bootstrapSpec := controlPlane.InitialControlPlaneConfig()
+		ready, err := r.prepareEtcdRestore(ctx, controlPlane, controlPlane.KCP.Spec.EtcdRestore, bootstrapSpec)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(ready).To(BeFalse())
+		g.Expect(snapshot.HasRestore(bootstrapSpec)).To(BeFalse())
+
+		key := client.ObjectKey{Namespace: controlPlane.KCP.Namespace, Name: etcdRestoreCredentialsName(controlPlane.Cluster)}
+		serviceAccount := &corev1.ServiceAccount{}
+		g.Expect(r.Client.Get(ctx, key, serviceAccount)).To(Succeed())
+		g.Expect(serviceAccount.OwnerReferences).To(HaveLen(1))
+		g.Expect(serviceAccount.OwnerReferences[0].UID).To(Equal(controlPlane.KCP.UID))
+		role := &rbacv1.Role{}
+		g.Expect(r.Client.Get(ctx, key, role)).To(Succeed())
+		g.Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{"test-etcd-20210304-050607-0"},
+			Verbs:         []string{"get"},
+		}}))
+		roleBinding := &rbacv1.RoleBinding{}
+		g.Expect(r.Client.Get(ctx, key, roleBinding)).To(Succeed())
+		g.Expect(roleBinding.RoleRef.Name).To(Equal(role.Name))
+		g.Expect(roleBinding.Subjects).To(ConsistOf(rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: serviceAccount.Name, Namespace: serviceAccount.Namespace}))
+		token := &corev1.Secret{}
+		g.Expect(r.Client.Get(ctx, key, token)).To(Succeed())
+		g.Expect(token.Type).To(Equal(corev1.SecretTypeServiceAccountToken))
+		g.Expect(token.Annotations).To(HaveKeyWithValue(corev1.ServiceAccountNameKey, serviceAccount.Name))
+
+		generateToken(g, r.Client, controlPlane)
+		ready, err = r.prepareEtcdRestore(ctx, controlPlane, controlPlane.KCP.Spec.EtcdRestore, bootstrapSpec)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(ready).To(BeTrue())
+		g.Expect(snapshot.HasRestore(bootstrapSpec)).To(BeTrue())
+		g.Expect(conditions.GetReason(controlPlane.KCP, controlplanev1.EtcdRestoreSucceededCondition)).To(Equal(controlplanev1.EtcdRestoreInProgressReason))
+	})
+
+	t.Run("restores a snapshot from a Secret, updating the Role of a previous attempt", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(&controlplanev1.EtcdRestore{
+			SecretRef: &controlplanev1.EtcdSnapshotSecretReference{Name: "backup", Key: "snapshot.db"},
+		})
+		backup := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: controlPlane.KCP.Namespace, Name: "backup"},
+			Data:       map[string][]byte{"snapshot.db": []byte("snapshot")},
+		}
+		previous := &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Namespace: controlPlane.KCP.Namespace, Name: etcdRestoreCredentialsName(controlPlane.Cluster)},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"previous"}, Verbs: []string{"get"}}},
+		}
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g, backup, previous), ManagementClusterEndpoint: "https://10.0.0.1:6443"}
+
+		bootstrapSpec := controlPlane.InitialControlPlaneConfig()
+		_, err := r.prepareEtcdRestore(ctx, controlPlane, controlPlane.KCP.Spec.EtcdRestore, bootstrapSpec)
+		g.Expect(err).NotTo(HaveOccurred())
+		generateToken(g, r.Client, controlPlane)
+		ready, err := r.prepareEtcdRestore(ctx, controlPlane, controlPlane.KCP.Spec.EtcdRestore, bootstrapSpec)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(ready).To(BeTrue())
+		g.Expect(snapshot.HasRestore(bootstrapSpec)).To(BeTrue())
+
+		role := &rbacv1.Role{}
+		g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(previous), role)).To(Succeed())
+		g.Expect(role.Rules).To(HaveLen(1))
+		g.Expect(role.Rules[0].ResourceNames).To(Equal([]string{"backup"}))
+	})
+
+	t.Run("fails if the snapshot does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(&controlplanev1.EtcdRestore{
+			SecretRef: &controlplanev1.EtcdSnapshotSecretReference{Name: "backup", Key: "snapshot.db"},
+		})
+		backup := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: controlPlane.KCP.Namespace, Name: "backup"},
+			Data:       map[string][]byte{"other": []byte("snapshot")},
+		}
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g, backup), ManagementClusterEndpoint: "https://10.0.0.1:6443"}
+
+		bootstrapSpec := controlPlane.InitialControlPlaneConfig()
+		_, err := r.prepareEtcdRestore(ctx, controlPlane, controlPlane.KCP.Spec.EtcdRestore, bootstrapSpec)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(snapshot.HasRestore(bootstrapSpec)).To(BeFalse())
+	})
+
+	t.Run("fails if the management cluster endpoint is not set", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(&controlplanev1.EtcdRestore{SnapshotName: "test-etcd-20210304-050607"})
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+
+		bootstrapSpec := controlPlane.InitialControlPlaneConfig()
+		_, err := r.prepareEtcdRestore(ctx, controlPlane, controlPlane.KCP.Spec.EtcdRestore, bootstrapSpec)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(snapshot.HasRestore(bootstrapSpec)).To(BeFalse())
+	})
+}
+
+func TestReconcileEtcdRestore(t *testing.T) {
+	newControlPlane := func(machines ...*clusterv1.Machine) *internal.ControlPlane {
+		cluster, kcp, _ := createClusterWithControlPlane()
+		kcp.Spec.EtcdRestore = &controlplanev1.EtcdRestore{SnapshotName: "test-etcd-20210304-050607"}
+		conditions.MarkFalse(kcp, controlplanev1.EtcdRestoreSucceededCondition, controlplanev1.EtcdRestoreInProgressReason, clusterv1.ConditionSeverityInfo, "")
+		return &internal.ControlPlane{
+			KCP:      kcp,
+			Cluster:  cluster,
+			Machines: internal.NewFilterableMachineCollection(machines...),
+		}
+	}
+	newMachine := func(name string, nodeRef string) *clusterv1.Machine {
+		m := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if nodeRef != "" {
+			m.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: nodeRef}
+		}
+		return m
+	}
+
+	t.Run("does nothing if a restore is not in progress", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(newMachine("m1", "n1"))
+		conditions.MarkTrue(controlPlane.KCP, controlplanev1.EtcdRestoreSucceededCondition)
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+
+		g.Expect(r.reconcileEtcdRestore(ctx, controlPlane)).To(Equal(ctrl.Result{}))
+		g.Expect(conditions.IsTrue(controlPlane.KCP, controlplanev1.EtcdRestoreSucceededCondition)).To(BeTrue())
+	})
+
+	t.Run("waits for all the machines to have a node", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(newMachine("m1", ""))
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+
+		g.Expect(r.reconcileEtcdRestore(ctx, controlPlane)).To(Equal(ctrl.Result{}))
+		g.Expect(conditions.GetReason(controlPlane.KCP, controlplanev1.EtcdRestoreSucceededCondition)).To(Equal(controlplanev1.EtcdRestoreInProgressReason))
+	})
+
+	t.Run("completes the restore and deletes the credentials used for reading the snapshot", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane(newMachine("m1", "n1"))
+		objectMeta := metav1.ObjectMeta{Namespace: controlPlane.KCP.Namespace, Name: etcdRestoreCredentialsName(controlPlane.Cluster)}
+		credentials := []client.Object{
+			&corev1.ServiceAccount{ObjectMeta: objectMeta},
+			&corev1.Secret{ObjectMeta: objectMeta},
+			&rbacv1.Role{ObjectMeta: objectMeta},
+			&rbacv1.RoleBinding{ObjectMeta: objectMeta},
+		}
+		r := &KubeadmControlPlaneReconciler{
+			Client:            newFakeClient(g, credentials...),
+			recorder:          record.NewFakeRecorder(32),
+			managementCluster: &fakeManagementCluster{Workload: fakeWorkloadCluster{}},
+		}
+
+		g.Expect(r.reconcileEtcdRestore(ctx, controlPlane)).To(Equal(ctrl.Result{}))
+		g.Expect(conditions.IsTrue(controlPlane.KCP, controlplanev1.EtcdRestoreSucceededCondition)).To(BeTrue())
+		g.Expect(controlPlane.KCP.Status.LastEtcdRestore).To(Equal(controlPlane.KCP.Spec.EtcdRestore))
+		for _, obj := range credentials {
+			err := r.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		}
+	})
+}
+
+func TestLookupEtcdRestoreCertificates(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster, _, _ := createClusterWithControlPlane()
+	r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+
+	certificates := secret.NewCertificatesForInitialControlPlane(&kubeadmv1.ClusterConfiguration{})
+	g.Expect(r.lookupEtcdRestoreCertificates(ctx, cluster, certificates)).NotTo(Succeed())
+
+	g.Expect(certificates.Generate()).To(Succeed())
+	for _, c := range certificates {
+		g.Expect(r.Client.Create(ctx, c.AsSecret(util.ObjectKey(cluster), metav1.OwnerReference{}))).To(Succeed())
+	}
+	certificates = secret.NewCertificatesForInitialControlPlane(&kubeadmv1.ClusterConfiguration{})
+	g.Expect(r.lookupEtcdRestoreCertificates(ctx, cluster, certificates)).To(Succeed())
+}
@@ -51,6 +51,30 @@ func (r *KubeadmControlPlaneReconciler) initializeControlPlane(ctx context.Conte
 	}
 
 	bootstrapSpec := controlPlane.InitialControlPlaneConfig()
+
+	// If required, restore etcd from a snapshot on the first control plane machine.
+	restore, err := r.etcdRestoreForInitialization(ctx, controlPlane)
+	if err == nil && restore != nil && r.ManagementClusterEndpoint == "" {
+		// NOTE: The controller must be restarted for setting the flag, so there is no need to requeue.
+		logger.Info("Waiting for the controller to be configured for restoring etcd from a snapshot, refusing to initialize the control plane")
+		conditions.MarkFalse(kcp, controlplanev1.EtcdRestoreSucceededCondition, controlplanev1.EtcdRestoreNotConfiguredReason, clusterv1.ConditionSeverityError, etcdRestoreNotConfiguredMessage)
+		return ctrl.Result{}, nil
+	}
+	restoreReady := true
+	if err == nil && restore != nil {
+		restoreReady, err = r.prepareEtcdRestore(ctx, controlPlane, restore, bootstrapSpec)
+	}
+	if err != nil {
+		logger.Error(err, "Failed to prepare the etcd restore for the initial control plane Machine")
+		conditions.MarkFalse(kcp, controlplanev1.EtcdRestoreSucceededCondition, controlplanev1.EtcdRestoreFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedEtcdRestore", "Failed to restore etcd for cluster %s/%s control plane: %v", cluster.Namespace, cluster.Name, err)
+		return ctrl.Result{}, err
+	}
+	if !restoreReady {
+		logger.Info("Waiting for the credentials used by the initial control plane Machine for reading the etcd snapshot")
+		return ctrl.Result{RequeueAfter: etcdRestoreCredentialsRequeueAfter}, nil
+	}
+
 	fd := controlPlane.NextFailureDomainForScaleUp()
 	if err := r.cloneConfigsAndGenerateMachine(ctx, cluster, kcp, bootstrapSpec, fd); err != nil {
 		logger.Error(err, "Failed to create initial control plane Machine")
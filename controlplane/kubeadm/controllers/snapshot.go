@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd/snapshot"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// defaultEtcdSnapshotInterval and defaultEtcdSnapshotRetention are used when the corresponding fields are not defaulted by the webhook.
+	defaultEtcdSnapshotInterval  = 24 * time.Hour
+	defaultEtcdSnapshotRetention = 3
+)
+
+// reconcileEtcdSnapshots takes a snapshot of the etcd cluster when the last snapshot is older than the configured interval,
+// and deletes the snapshots exceeding the configured retention.
+// NOTE: Failures are reported on the EtcdSnapshotSucceeded condition and they do not block other KCP operations;
+// the returned result is used for scheduling the next snapshot.
+func (r *KubeadmControlPlaneReconciler) reconcileEtcdSnapshots(ctx context.Context, controlPlane *internal.ControlPlane) ctrl.Result {
+	log := ctrl.LoggerFrom(ctx, "cluster", controlPlane.Cluster.Name)
+	kcp := controlPlane.KCP
+
+	if kcp.Spec.EtcdSnapshots == nil || !controlPlane.IsEtcdManaged() {
+		conditions.Delete(kcp, controlplanev1.EtcdSnapshotSucceededCondition)
+		return ctrl.Result{}
+	}
+
+	// Snapshots can be taken only after the first control plane machine has completed kubeadm init.
+	if !kcp.Status.Initialized {
+		return ctrl.Result{}
+	}
+
+	interval := defaultEtcdSnapshotInterval
+	if kcp.Spec.EtcdSnapshots.Interval != nil {
+		interval = kcp.Spec.EtcdSnapshots.Interval.Duration
+	}
+	retention := defaultEtcdSnapshotRetention
+	if kcp.Spec.EtcdSnapshots.Retention != nil {
+		retention = int(*kcp.Spec.EtcdSnapshots.Retention)
+	}
+
+	sink := r.etcdSnapshotSink(controlPlane)
+	snapshots, err := sink.List(ctx)
+	if err != nil {
+		log.Error(err, "Failed to list etcd snapshots")
+		conditions.MarkFalse(kcp, controlplanev1.EtcdSnapshotSucceededCondition, controlplanev1.EtcdSnapshotFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{RequeueAfter: etcdSnapshotRetryAfter}
+	}
+
+	now := time.Now()
+	if len(snapshots) > 0 {
+		if next := snapshots[len(snapshots)-1].Timestamp.Add(interval); now.Before(next) {
+			return ctrl.Result{RequeueAfter: next.Sub(now)}
+		}
+	}
+
+	// Skip the snapshot if the last attempt failed recently, so a failing snapshot is not retried at every reconcile.
+	if conditions.GetReason(kcp, controlplanev1.EtcdSnapshotSucceededCondition) == controlplanev1.EtcdSnapshotFailedReason {
+		if lastAttempt := conditions.GetLastTransitionTime(kcp, controlplanev1.EtcdSnapshotSucceededCondition); lastAttempt != nil {
+			if next := lastAttempt.Add(etcdSnapshotRetryAfter); now.Before(next) {
+				return ctrl.Result{RequeueAfter: next.Sub(now)}
+			}
+		}
+	}
+
+	name := snapshot.Name(kcp.Name, now)
+	log.Info("Taking etcd snapshot", "snapshot", name)
+	if err := r.takeEtcdSnapshot(ctx, controlPlane, sink, name, now); err != nil {
+		log.Error(err, "Failed to take etcd snapshot", "snapshot", name)
+		// NOTE: The message includes the name of the snapshot, so the last transition time records the time of the last failed attempt.
+		conditions.MarkFalse(kcp, controlplanev1.EtcdSnapshotSucceededCondition, controlplanev1.EtcdSnapshotFailedReason, clusterv1.ConditionSeverityWarning, "Failed to take snapshot %s: %v", name, err)
+		r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedEtcdSnapshot", "Failed to take etcd snapshot %s: %v", name, err)
+		return ctrl.Result{RequeueAfter: etcdSnapshotRetryAfter}
+	}
+
+	succeeded := conditions.TrueCondition(controlplanev1.EtcdSnapshotSucceededCondition)
+	succeeded.Message = fmt.Sprintf("Last snapshot %s taken at %s", name, now.UTC().Format(time.RFC3339))
+	conditions.Set(kcp, succeeded)
+
+	deleted, err := snapshot.Prune(ctx, sink, retention)
+	if err != nil {
+		log.Error(err, "Failed to delete etcd snapshots exceeding retention")
+		conditions.MarkFalse(kcp, controlplanev1.EtcdSnapshotSucceededCondition, controlplanev1.EtcdSnapshotRetentionFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+	}
+	if len(deleted) > 0 {
+		log.Info("Deleted etcd snapshots exceeding retention", "snapshots", deleted)
+	}
+
+	return ctrl.Result{RequeueAfter: interval}
+}
+
+// takeEtcdSnapshot streams a snapshot of the etcd cluster to the sink.
+func (r *KubeadmControlPlaneReconciler) takeEtcdSnapshot(ctx context.Context, controlPlane *internal.ControlPlane, sink snapshot.Sink, name string, timestamp time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, etcdSnapshotTimeout)
+	defer cancel()
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err != nil {
+		return err
+	}
+	reader, err := workloadCluster.EtcdSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	return sink.Save(ctx, name, timestamp, reader)
+}
+
+// etcdSnapshotSink returns the sink for the etcd snapshots of a control plane; snapshots are owned by the KubeadmControlPlane.
+// The default sink is used when snapshots are not enabled, e.g. for reading a snapshot to restore.
+func (r *KubeadmControlPlaneReconciler) etcdSnapshotSink(controlPlane *internal.ControlPlane) snapshot.Sink {
+	owner := *metav1.NewControllerRef(controlPlane.KCP, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"))
+	cluster := util.ObjectKey(controlPlane.Cluster)
+	if controlPlane.KCP.Spec.EtcdSnapshots != nil && controlPlane.KCP.Spec.EtcdSnapshots.Sink.Type == controlplanev1.ConfigMapEtcdSnapshotSinkType {
+		return snapshot.NewConfigMapSink(r.Client, cluster, owner)
+	}
+	return snapshot.NewSecretSink(r.Client, cluster, owner)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd/snapshot"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestReconcileEtcdSnapshots(t *testing.T) {
+	newControlPlane := func() *internal.ControlPlane {
+		cluster, kcp, _ := createClusterWithControlPlane()
+		kcp.UID = "kcp-uid"
+		kcp.Status.Initialized = true
+		kcp.Spec.EtcdSnapshots = &controlplanev1.EtcdSnapshots{
+			Interval:  &metav1.Duration{Duration: time.Hour},
+			Retention: pointer.Int32Ptr(2),
+		}
+		return &internal.ControlPlane{
+			KCP:      kcp,
+			Cluster:  cluster,
+			Machines: internal.NewFilterableMachineCollection(),
+		}
+	}
+
+	t.Run("does nothing if etcd snapshots are not enabled", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane()
+		controlPlane.KCP.Spec.EtcdSnapshots = nil
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+
+		g.Expect(r.reconcileEtcdSnapshots(ctx, controlPlane)).To(Equal(ctrl.Result{}))
+		g.Expect(conditions.Has(controlPlane.KCP, controlplanev1.EtcdSnapshotSucceededCondition)).To(BeFalse())
+	})
+
+	t.Run("does nothing if the control plane is not initialized", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane()
+		controlPlane.KCP.Status.Initialized = false
+		r := &KubeadmControlPlaneReconciler{Client: newFakeClient(g)}
+
+		g.Expect(r.reconcileEtcdSnapshots(ctx, controlPlane)).To(Equal(ctrl.Result{}))
+		g.Expect(conditions.Has(controlPlane.KCP, controlplanev1.EtcdSnapshotSucceededCondition)).To(BeFalse())
+	})
+
+	t.Run("takes a snapshot and deletes the snapshots exceeding retention", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane()
+		r := &KubeadmControlPlaneReconciler{
+			Client:   newFakeClient(g),
+			recorder: record.NewFakeRecorder(32),
+			managementCluster: &fakeManagementCluster{
+				Workload: fakeWorkloadCluster{EtcdSnapshotResult: []byte("snapshot")},
+			},
+		}
+		sink := r.etcdSnapshotSink(controlPlane)
+		now := time.Now()
+		for _, age := range []time.Duration{3 * time.Hour, 2 * time.Hour} {
+			timestamp := now.Add(-age)
+			g.Expect(sink.Save(ctx, snapshot.Name(controlPlane.KCP.Name, timestamp), timestamp, bytes.NewReader([]byte("old")))).To(Succeed())
+		}
+
+		result := r.reconcileEtcdSnapshots(ctx, controlPlane)
+		g.Expect(result.RequeueAfter).To(Equal(time.Hour))
+		g.Expect(conditions.IsTrue(controlPlane.KCP, controlplanev1.EtcdSnapshotSucceededCondition)).To(BeTrue())
+		g.Expect(conditions.GetMessage(controlPlane.KCP, controlplanev1.EtcdSnapshotSucceededCondition)).To(HavePrefix("Last snapshot " + controlPlane.KCP.Name + "-etcd-"))
+
+		snapshots, err := sink.List(ctx)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(snapshots).To(HaveLen(2))
+		g.Expect(snapshots[0].Timestamp).To(BeTemporally("~", now.Add(-2*time.Hour), time.Second))
+		g.Expect(snapshots[1].Size).To(Equal(int64(len("snapshot"))))
+
+		secrets := &corev1.SecretList{}
+		g.Expect(r.Client.List(ctx, secrets)).To(Succeed())
+		for _, s := range secrets.Items {
+			g.Expect(s.OwnerReferences).To(HaveLen(1))
+			g.Expect(s.OwnerReferences[0].UID).To(Equal(controlPlane.KCP.UID))
+		}
+	})
+
+	t.Run("does not take a snapshot before the interval has elapsed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane()
+		controlPlane.KCP.Spec.EtcdSnapshots.Sink.Type = controlplanev1.ConfigMapEtcdSnapshotSinkType
+		r := &KubeadmControlPlaneReconciler{
+			Client:   newFakeClient(g),
+			recorder: record.NewFakeRecorder(32),
+			managementCluster: &fakeManagementCluster{
+				Workload: fakeWorkloadCluster{EtcdSnapshotErr: errors.New("snapshot should not be taken")},
+			},
+		}
+		sink := r.etcdSnapshotSink(controlPlane)
+		timestamp := time.Now().Add(-10 * time.Minute)
+		g.Expect(sink.Save(ctx, snapshot.Name(controlPlane.KCP.Name, timestamp), timestamp, bytes.NewReader([]byte("recent")))).To(Succeed())
+
+		result := r.reconcileEtcdSnapshots(ctx, controlPlane)
+		g.Expect(result.RequeueAfter).To(BeNumerically("~", 50*time.Minute, time.Minute))
+		g.Expect(conditions.Has(controlPlane.KCP, controlplanev1.EtcdSnapshotSucceededCondition)).To(BeFalse())
+
+		configMaps := &corev1.ConfigMapList{}
+		g.Expect(r.Client.List(ctx, configMaps)).To(Succeed())
+		g.Expect(configMaps.Items).To(HaveLen(2))
+	})
+
+	t.Run("reports a failure and does not retry before the retry interval has elapsed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		controlPlane := newControlPlane()
+		recorder := record.NewFakeRecorder(32)
+		r := &KubeadmControlPlaneReconciler{
+			Client:   newFakeClient(g),
+			recorder: recorder,
+			managementCluster: &fakeManagementCluster{
+				Workload: fakeWorkloadCluster{EtcdSnapshotErr: errors.New("etcd is not reachable")},
+			},
+		}
+
+		result := r.reconcileEtcdSnapshots(ctx, controlPlane)
+		g.Expect(result.RequeueAfter).To(Equal(etcdSnapshotRetryAfter))
+		g.Expect(conditions.IsFalse(controlPlane.KCP, controlplanev1.EtcdSnapshotSucceededCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(controlPlane.KCP, controlplanev1.EtcdSnapshotSucceededCondition)).To(Equal(controlplanev1.EtcdSnapshotFailedReason))
+		g.Expect(recorder.Events).To(Receive(ContainSubstring("etcd is not reachable")))
+
+		result = r.reconcileEtcdSnapshots(ctx, controlPlane)
+		g.Expect(result.RequeueAfter).To(BeNumerically("~", etcdSnapshotRetryAfter, time.Minute))
+		g.Expect(recorder.Events).NotTo(Receive())
+	})
+}
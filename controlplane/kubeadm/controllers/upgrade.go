@@ -105,9 +105,20 @@ func (r *KubeadmControlPlaneReconciler) upgradeControlPlane(
 		return ctrl.Result{}, err
 	}
 
-	if status.Nodes <= *kcp.Spec.Replicas {
+	// When maxSurge is 0, an outdated machine is deleted before creating a new one; the preflight checks
+	// ensure all the other members are healthy before scaling down, so the etcd quorum is preserved.
+	maxNodes := *kcp.Spec.Replicas + rolloutMaxSurge(kcp)
+	if status.Nodes < maxNodes {
 		// scaleUp ensures that we don't continue scaling up while waiting for Machines to have NodeRefs
 		return r.scaleUpControlPlane(ctx, cluster, kcp, controlPlane)
 	}
 	return r.scaleDownControlPlane(ctx, cluster, kcp, controlPlane, machinesRequireUpgrade)
 }
+
+// rolloutMaxSurge returns the number of machines that can be created above the desired replicas during a rollout.
+func rolloutMaxSurge(kcp *controlplanev1.KubeadmControlPlane) int32 {
+	if kcp.Spec.RolloutStrategy == nil || kcp.Spec.RolloutStrategy.RollingUpdate == nil || kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge == nil {
+		return 1
+	}
+	return int32(kcp.Spec.RolloutStrategy.RollingUpdate.MaxSurge.IntValue())
+}
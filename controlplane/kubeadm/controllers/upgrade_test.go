@@ -23,9 +23,11 @@ import (
 	. "github.com/onsi/gomega"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -120,6 +122,66 @@ func TestKubeadmControlPlaneReconciler_upgradeControlPlane(t *testing.T) {
 	g.Expect(finalMachine.Items[0].CreationTimestamp.Time).To(BeTemporally(">", initialMachine.Items[0].CreationTimestamp.Time))
 }
 
+func TestKubeadmControlPlaneReconciler_upgradeControlPlaneScaleIn(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster, kcp, genericMachineTemplate := createClusterWithControlPlane()
+	cluster.Spec.ControlPlaneEndpoint.Host = "nodomain.example.com"
+	cluster.Spec.ControlPlaneEndpoint.Port = 6443
+	kcp.Spec.Version = "v1.17.4"
+	kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = nil
+	kcp.Spec.Replicas = pointer.Int32Ptr(3)
+	maxSurge := intstr.FromInt(0)
+	kcp.Spec.RolloutStrategy = &controlplanev1.RolloutStrategy{
+		Type:          controlplanev1.RollingUpdateStrategyType,
+		RollingUpdate: &controlplanev1.RollingUpdate{MaxSurge: &maxSurge},
+	}
+	setKCPHealthy(kcp)
+
+	objs := []client.Object{cluster.DeepCopy(), kcp.DeepCopy(), genericMachineTemplate.DeepCopy()}
+	for _, name := range []string{"m1", "m2", "m3"} {
+		m, _ := createMachineNodePair(name, cluster, kcp, true)
+		setMachineHealthy(m)
+		objs = append(objs, m)
+	}
+	fakeClient := newFakeClient(g, objs...)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		recorder: record.NewFakeRecorder(32),
+		managementCluster: &fakeManagementCluster{
+			Management: &internal.Management{Client: fakeClient},
+			Workload: fakeWorkloadCluster{
+				Status: internal.ClusterStatus{Nodes: 3},
+			},
+		},
+	}
+
+	machines := &clusterv1.MachineList{}
+	g.Expect(fakeClient.List(ctx, machines, client.InNamespace(cluster.Namespace))).To(Succeed())
+	controlPlane := &internal.ControlPlane{
+		KCP:      kcp,
+		Cluster:  cluster,
+		Machines: internal.NewFilterableMachineCollectionFromMachineList(machines),
+	}
+
+	// run upgrade the first time, expect we scale down without creating new machines
+	result, err := r.upgradeControlPlane(ctx, cluster, kcp, controlPlane, controlPlane.Machines)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{Requeue: true}))
+	g.Expect(fakeClient.List(ctx, machines, client.InNamespace(cluster.Namespace))).To(Succeed())
+	g.Expect(machines.Items).To(HaveLen(2))
+
+	// simulate the node of the deleted machine being removed, expect we scale up
+	r.managementCluster.(*fakeManagementCluster).Workload.Status.Nodes--
+	controlPlane.Machines = internal.NewFilterableMachineCollectionFromMachineList(machines)
+	result, err = r.upgradeControlPlane(ctx, cluster, kcp, controlPlane, controlPlane.Machines)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{Requeue: true}))
+	g.Expect(fakeClient.List(ctx, machines, client.InNamespace(cluster.Namespace))).To(Succeed())
+	g.Expect(machines.Items).To(HaveLen(3))
+}
+
 type machineOpt func(*clusterv1.Machine)
 
 func machine(name string, opts ...machineOpt) *clusterv1.Machine {
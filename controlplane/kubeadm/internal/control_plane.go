@@ -186,6 +186,12 @@ func (c *ControlPlane) JoinControlPlaneConfig() *bootstrapv1.KubeadmConfigSpec {
 	return bootstrapSpec
 }
 
+// GetKubeadmConfig returns the KubeadmConfig of a given machine.
+func (c *ControlPlane) GetKubeadmConfig(machineName string) (*bootstrapv1.KubeadmConfig, bool) {
+	kubeadmConfig, ok := c.kubeadmConfigs[machineName]
+	return kubeadmConfig, ok
+}
+
 // GenerateKubeadmConfig generates a new kubeadm config for creating new control plane nodes.
 func (c *ControlPlane) GenerateKubeadmConfig(spec *bootstrapv1.KubeadmConfigSpec) *bootstrapv1.KubeadmConfig {
 	// Create an owner reference without a controller reference because the owning controller is the machine controller
@@ -255,11 +261,24 @@ func (c *ControlPlane) MachinesNeedingRollout() FilterableMachineCollection {
 	return machines.AnyFilter(
 		// Machines that are scheduled for rollout (KCP.Spec.UpgradeAfter set, the UpgradeAfter deadline is expired, and the machine was created before the deadline).
 		machinefilters.ShouldRolloutAfter(&c.reconciliationTime, c.KCP.Spec.UpgradeAfter),
+		// Machines whose certificates are about to expire (KCP.Spec.RolloutBefore set, and the certificates expire within the configured number of days).
+		machinefilters.ShouldRolloutBefore(&c.reconciliationTime, c.KCP.Spec.RolloutBefore),
+		// Machines created before the current phase of the certificate authorities rotation started.
+		machinefilters.ShouldRolloutAfter(&c.reconciliationTime, c.certificateAuthoritiesRotationPhaseStartTime()),
 		// Machines that do not match with KCP config.
 		machinefilters.Not(machinefilters.MatchesKCPConfiguration(c.infraResources, c.kubeadmConfigs, c.KCP)),
 	)
 }
 
+// certificateAuthoritiesRotationPhaseStartTime returns the start time of the current phase of the certificate
+// authorities rotation, if any.
+func (c *ControlPlane) certificateAuthoritiesRotationPhaseStartTime() *metav1.Time {
+	if c.KCP.Status.CertificateAuthoritiesRotation == nil {
+		return nil
+	}
+	return &c.KCP.Status.CertificateAuthoritiesRotation.PhaseStartTime
+}
+
 // UpToDateMachines returns the machines that are up to date with the control
 // plane's configuration and therefore do not require rollout.
 func (c *ControlPlane) UpToDateMachines() FilterableMachineCollection {
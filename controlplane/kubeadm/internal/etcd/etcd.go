@@ -19,6 +19,7 @@ package etcd
 import (
 	"context"
 	"crypto/tls"
+	"io"
 	"net"
 	"time"
 
@@ -45,6 +46,7 @@ type etcd interface {
 	MemberRemove(ctx context.Context, id uint64) (*clientv3.MemberRemoveResponse, error)
 	MemberUpdate(ctx context.Context, id uint64, peerURLs []string) (*clientv3.MemberUpdateResponse, error)
 	MoveLeader(ctx context.Context, id uint64) (*clientv3.MoveLeaderResponse, error)
+	Snapshot(ctx context.Context) (io.ReadCloser, error)
 	Status(ctx context.Context, endpoint string) (*clientv3.StatusResponse, error)
 }
 
@@ -226,6 +228,16 @@ func (c *Client) UpdateMemberPeerURLs(ctx context.Context, id uint64, peerURLs [
 	return members, nil
 }
 
+// Snapshot streams a snapshot of the backend of the etcd member the client is connected to.
+// The caller is responsible for closing the returned reader.
+func (c *Client) Snapshot(ctx context.Context) (io.ReadCloser, error) {
+	snapshot, err := c.EtcdClient.Snapshot(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to take etcd snapshot")
+	}
+	return snapshot, nil
+}
+
 // Alarms retrieves all alarms on a cluster.
 func (c *Client) Alarms(ctx context.Context) ([]MemberAlarm, error) {
 	alarmResponse, err := c.EtcdClient.AlarmList(ctx)
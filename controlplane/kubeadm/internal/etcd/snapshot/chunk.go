@@ -0,0 +1,344 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SnapshotLabelName is the label set on the objects storing an etcd snapshot, with the name of the snapshot as value.
+	SnapshotLabelName = "controlplane.cluster.x-k8s.io/etcd-snapshot"
+
+	// descriptorLabelName is set only on the objects describing a snapshot, so they can be listed without
+	// reading the snapshot data stored in chunks.
+	descriptorLabelName = "controlplane.cluster.x-k8s.io/etcd-snapshot-descriptor"
+
+	// chunksAnnotation, sizeAnnotation and timestampAnnotation are set on the object describing a snapshot.
+	chunksAnnotation    = "controlplane.cluster.x-k8s.io/etcd-snapshot-chunks"
+	sizeAnnotation      = "controlplane.cluster.x-k8s.io/etcd-snapshot-size"
+	timestampAnnotation = "controlplane.cluster.x-k8s.io/etcd-snapshot-timestamp"
+
+	// chunkDataKey is the key of the snapshot data in the objects storing a chunk.
+	chunkDataKey = "snapshot"
+)
+
+// chunkSize is the size of the snapshot data stored in a single object; it is kept well below the 1MB limit
+// for the size of Secrets and ConfigMaps.
+var chunkSize = 512 * 1024
+
+// chunkKind abstracts the kind of the objects storing snapshot chunks.
+type chunkKind interface {
+	kind() string
+	newObject(meta metav1.ObjectMeta, data []byte) client.Object
+	list(ctx context.Context, c client.Client, opts ...client.ListOption) ([]client.Object, error)
+	data(obj client.Object) []byte
+}
+
+// chunkSink is a Sink storing snapshots in the management cluster, split in chunks.
+// Each snapshot is stored in an object describing the snapshot, named after the snapshot, and in one object for each chunk;
+// the object describing the snapshot is created last, so snapshots which are not completely stored are not listed.
+// NOTE: only the objects describing snapshots are listed, and chunks are read or deleted by name or by label, because
+// Secrets and ConfigMaps are not cached by the controller and listing chunks would read all the snapshot data.
+type chunkSink struct {
+	client      client.Client
+	kind        chunkKind
+	namespace   string
+	clusterName string
+	owner       metav1.OwnerReference
+}
+
+// NewSecretSink returns a Sink storing the snapshots of the etcd cluster of a Cluster in Secrets,
+// owned by the given owner.
+func NewSecretSink(c client.Client, cluster client.ObjectKey, owner metav1.OwnerReference) Sink {
+	return &chunkSink{client: c, kind: secretChunks{}, namespace: cluster.Namespace, clusterName: cluster.Name, owner: owner}
+}
+
+// NewConfigMapSink returns a Sink storing the snapshots of the etcd cluster of a Cluster in ConfigMaps,
+// owned by the given owner.
+// NOTE: the snapshots contain all the data of the workload cluster, including Secrets, and they are stored in
+// plaintext; ConfigMaps are usually readable by more users than Secrets, and they are not encrypted at rest.
+func NewConfigMapSink(c client.Client, cluster client.ObjectKey, owner metav1.OwnerReference) Sink {
+	return &chunkSink{client: c, kind: configMapChunks{}, namespace: cluster.Namespace, clusterName: cluster.Name, owner: owner}
+}
+
+// Save stores a snapshot, creating an object for each chunk and then the object describing the snapshot.
+// If storing the snapshot fails, the objects already created are deleted.
+func (s *chunkSink) Save(ctx context.Context, name string, timestamp time.Time, r io.Reader) (reterr error) {
+	existing, err := s.getDescriptor(ctx, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errors.Errorf("failed to store snapshot %s: snapshot already exists", name)
+	}
+
+	// Cleanup the chunks left behind by a previous attempt to store the snapshot, if any.
+	if err := s.Delete(ctx, name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if reterr != nil {
+			// Cleanup the chunks of a snapshot which has not been completely stored; errors are ignored
+			// because the snapshot is not listed anyway.
+			_ = s.Delete(ctx, name)
+		}
+	}()
+
+	buf := make([]byte, chunkSize)
+	var chunks int
+	var size int64
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			chunk := s.kind.newObject(s.objectMeta(name, chunkName(name, chunks)), buf[:n])
+			if err := s.client.Create(ctx, chunk); err != nil {
+				return errors.Wrapf(err, "failed to store chunk %d of snapshot %s", chunks, name)
+			}
+			chunks++
+			size += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read snapshot %s", name)
+		}
+	}
+	if size == 0 {
+		return errors.Errorf("failed to store snapshot %s: snapshot is empty", name)
+	}
+
+	meta := s.objectMeta(name, name)
+	meta.Labels[descriptorLabelName] = ""
+	meta.Annotations = map[string]string{
+		chunksAnnotation:    strconv.Itoa(chunks),
+		sizeAnnotation:      strconv.FormatInt(size, 10),
+		timestampAnnotation: timestamp.UTC().Format(time.RFC3339),
+	}
+	if err := s.client.Create(ctx, s.kind.newObject(meta, nil)); err != nil {
+		return errors.Wrapf(err, "failed to store snapshot %s", name)
+	}
+	return nil
+}
+
+// Open returns a reader fetching the chunks of a snapshot one at a time.
+func (s *chunkSink) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	chunks, err := s.chunks(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &chunkReader{ctx: ctx, sink: s, name: name, chunks: chunks}, nil
+}
+
+// RestoreObjects returns the objects storing the chunks of a snapshot, in order.
+func (s *chunkSink) RestoreObjects(ctx context.Context, name string) ([]RestoreObject, error) {
+	chunks, err := s.chunks(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]RestoreObject, 0, chunks)
+	for i := 0; i < chunks; i++ {
+		objs = append(objs, RestoreObject{Kind: s.kind.kind(), Name: chunkName(name, i), Key: chunkDataKey})
+	}
+	return objs, nil
+}
+
+// List returns the snapshots which are completely stored in the sink.
+func (s *chunkSink) List(ctx context.Context) ([]Snapshot, error) {
+	objs, err := s.kind.list(ctx, s.client, client.InNamespace(s.namespace), client.MatchingLabels{clusterv1.ClusterLabelName: s.clusterName}, client.HasLabels{descriptorLabelName})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list etcd snapshots")
+	}
+
+	snapshots := []Snapshot{}
+	for _, obj := range objs {
+		timestamp, err := time.Parse(time.RFC3339, obj.GetAnnotations()[timestampAnnotation])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the timestamp of snapshot %s", obj.GetName())
+		}
+		size, _ := strconv.ParseInt(obj.GetAnnotations()[sizeAnnotation], 10, 64)
+		snapshots = append(snapshots, Snapshot{Name: obj.GetName(), Timestamp: timestamp, Size: size})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.Before(snapshots[j].Timestamp)
+	})
+	return snapshots, nil
+}
+
+// Delete removes all the objects storing a snapshot.
+func (s *chunkSink) Delete(ctx context.Context, name string) error {
+	obj := s.kind.newObject(metav1.ObjectMeta{}, nil)
+	if err := s.client.DeleteAllOf(ctx, obj, client.InNamespace(s.namespace), client.MatchingLabels{clusterv1.ClusterLabelName: s.clusterName, SnapshotLabelName: name}); err != nil {
+		return errors.Wrapf(err, "failed to delete snapshot %s", name)
+	}
+	return nil
+}
+
+// chunks returns the number of chunks of a snapshot.
+func (s *chunkSink) chunks(ctx context.Context, name string) (int, error) {
+	obj, err := s.getDescriptor(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	if obj == nil {
+		return 0, errors.Errorf("snapshot %s not found", name)
+	}
+	chunks, err := strconv.Atoi(obj.GetAnnotations()[chunksAnnotation])
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get the number of chunks of snapshot %s", name)
+	}
+	return chunks, nil
+}
+
+// getDescriptor returns the object describing a snapshot, or nil if the snapshot does not exist.
+func (s *chunkSink) getDescriptor(ctx context.Context, name string) (client.Object, error) {
+	obj := s.kind.newObject(metav1.ObjectMeta{}, nil)
+	if err := s.client.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: name}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get snapshot %s", name)
+	}
+	if _, ok := obj.GetLabels()[descriptorLabelName]; !ok || obj.GetLabels()[clusterv1.ClusterLabelName] != s.clusterName {
+		return nil, nil
+	}
+	return obj, nil
+}
+
+// chunkName returns the name of the object storing a chunk of a snapshot.
+func chunkName(snapshot string, chunk int) string {
+	return fmt.Sprintf("%s-%d", snapshot, chunk)
+}
+
+func (s *chunkSink) objectMeta(snapshot, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: s.namespace,
+		Labels: map[string]string{
+			clusterv1.ClusterLabelName: s.clusterName,
+			SnapshotLabelName:          snapshot,
+		},
+		OwnerReferences: []metav1.OwnerReference{s.owner},
+	}
+}
+
+// chunkReader reads a snapshot stored in a chunkSink, fetching a chunk at a time.
+type chunkReader struct {
+	ctx    context.Context
+	sink   *chunkSink
+	name   string
+	chunks int
+	next   int
+	buf    bytes.Reader
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.next >= r.chunks {
+			return 0, io.EOF
+		}
+		obj := r.sink.kind.newObject(metav1.ObjectMeta{}, nil)
+		key := client.ObjectKey{Namespace: r.sink.namespace, Name: chunkName(r.name, r.next)}
+		if err := r.sink.client.Get(r.ctx, key, obj); err != nil {
+			return 0, errors.Wrapf(err, "failed to get chunk %d of snapshot %s", r.next, r.name)
+		}
+		r.buf.Reset(r.sink.kind.data(obj))
+		r.next++
+	}
+	return r.buf.Read(p)
+}
+
+func (r *chunkReader) Close() error {
+	return nil
+}
+
+// secretChunks stores snapshot chunks in Secrets.
+type secretChunks struct{}
+
+func (secretChunks) kind() string {
+	return "Secret"
+}
+
+func (secretChunks) newObject(meta metav1.ObjectMeta, data []byte) client.Object {
+	s := &corev1.Secret{ObjectMeta: meta, Type: clusterv1.ClusterSecretType}
+	if data != nil {
+		s.Data = map[string][]byte{chunkDataKey: data}
+	}
+	return s
+}
+
+func (secretChunks) list(ctx context.Context, c client.Client, opts ...client.ListOption) ([]client.Object, error) {
+	list := &corev1.SecretList{}
+	if err := c.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+	objs := make([]client.Object, 0, len(list.Items))
+	for i := range list.Items {
+		objs = append(objs, &list.Items[i])
+	}
+	return objs, nil
+}
+
+func (secretChunks) data(obj client.Object) []byte {
+	return obj.(*corev1.Secret).Data[chunkDataKey]
+}
+
+// configMapChunks stores snapshot chunks in ConfigMaps.
+type configMapChunks struct{}
+
+func (configMapChunks) kind() string {
+	return "ConfigMap"
+}
+
+func (configMapChunks) newObject(meta metav1.ObjectMeta, data []byte) client.Object {
+	cm := &corev1.ConfigMap{ObjectMeta: meta}
+	if data != nil {
+		cm.BinaryData = map[string][]byte{chunkDataKey: data}
+	}
+	return cm
+}
+
+func (configMapChunks) list(ctx context.Context, c client.Client, opts ...client.ListOption) ([]client.Object, error) {
+	list := &corev1.ConfigMapList{}
+	if err := c.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+	objs := make([]client.Object, 0, len(list.Items))
+	for i := range list.Items {
+		objs = append(objs, &list.Items[i])
+	}
+	return objs, nil
+}
+
+func (configMapChunks) data(obj client.Object) []byte {
+	return obj.(*corev1.ConfigMap).BinaryData[chunkDataKey]
+}
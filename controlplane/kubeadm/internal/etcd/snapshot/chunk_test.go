@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestChunkSink(t *testing.T) {
+	defer func(size int) { chunkSize = size }(chunkSize)
+	chunkSize = 4
+
+	cluster := client.ObjectKey{Namespace: "default", Name: "test"}
+	owner := metav1.OwnerReference{APIVersion: "controlplane.cluster.x-k8s.io/v1alpha4", Kind: "KubeadmControlPlane", Name: "test"}
+
+	tests := []struct {
+		name    string
+		newSink func(c client.Client) Sink
+		list    client.ObjectList
+	}{
+		{
+			name: "secret sink",
+			newSink: func(c client.Client) Sink {
+				return NewSecretSink(c, cluster, owner)
+			},
+			list: &corev1.SecretList{},
+		},
+		{
+			name: "config map sink",
+			newSink: func(c client.Client) Sink {
+				return NewConfigMapSink(c, cluster, owner)
+			},
+			list: &corev1.ConfigMapList{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ctx := context.Background()
+
+			c := fake.NewClientBuilder().Build()
+			sink := tt.newSink(c)
+
+			now := time.Now().UTC().Truncate(time.Second)
+			g.Expect(sink.Save(ctx, "second", now, bytes.NewReader([]byte("0123456789")))).To(Succeed())
+			g.Expect(sink.Save(ctx, "first", now.Add(-time.Hour), bytes.NewReader([]byte("abcd")))).To(Succeed())
+
+			// Snapshots are stored in chunks, plus an object describing each snapshot.
+			g.Expect(c.List(ctx, tt.list)).To(Succeed())
+			g.Expect(meta(tt.list)).To(HaveLen(6))
+
+			snapshots, err := sink.List(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(snapshots).To(Equal([]Snapshot{
+				{Name: "first", Timestamp: now.Add(-time.Hour), Size: 4},
+				{Name: "second", Timestamp: now, Size: 10},
+			}))
+
+			r, err := sink.Open(ctx, "second")
+			g.Expect(err).NotTo(HaveOccurred())
+			data, err := ioutil.ReadAll(r)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(r.Close()).To(Succeed())
+			g.Expect(data).To(Equal([]byte("0123456789")))
+
+			_, err = sink.Open(ctx, "third")
+			g.Expect(err).To(HaveOccurred())
+
+			// The machine restoring etcd reads the chunks of a snapshot, in order.
+			restoreObjects, err := sink.RestoreObjects(ctx, "second")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(restoreObjects).To(HaveLen(3))
+			for i, o := range restoreObjects {
+				g.Expect(o.Name).To(Equal(fmt.Sprintf("second-%d", i)))
+				g.Expect(o.Key).To(Equal(chunkDataKey))
+				g.Expect(o.Kind).To(Equal(sink.(*chunkSink).kind.kind()))
+			}
+			_, err = sink.RestoreObjects(ctx, "third")
+			g.Expect(err).To(HaveOccurred())
+
+			// Saving a snapshot with the name of an existing snapshot fails without deleting it.
+			g.Expect(sink.Save(ctx, "first", now, bytes.NewReader([]byte("abcd")))).NotTo(Succeed())
+			// Saving an empty snapshot fails without leaving chunks behind.
+			g.Expect(sink.Save(ctx, "empty", now, bytes.NewReader(nil))).NotTo(Succeed())
+
+			// Chunks left behind by a previous attempt to store a snapshot are replaced.
+			chunkSink := sink.(*chunkSink)
+			g.Expect(c.Create(ctx, chunkSink.kind.newObject(chunkSink.objectMeta("third", "third-0"), []byte("left")))).To(Succeed())
+			g.Expect(c.Create(ctx, chunkSink.kind.newObject(chunkSink.objectMeta("third", "third-1"), []byte("over")))).To(Succeed())
+			g.Expect(sink.Save(ctx, "third", now.Add(time.Hour), bytes.NewReader([]byte("xyz")))).To(Succeed())
+			r, err = sink.Open(ctx, "third")
+			g.Expect(err).NotTo(HaveOccurred())
+			data, err = ioutil.ReadAll(r)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(data).To(Equal([]byte("xyz")))
+			g.Expect(sink.Delete(ctx, "third")).To(Succeed())
+
+			g.Expect(sink.Delete(ctx, "second")).To(Succeed())
+			snapshots, err = sink.List(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(snapshots).To(HaveLen(1))
+			g.Expect(snapshots[0].Name).To(Equal("first"))
+
+			g.Expect(c.List(ctx, tt.list)).To(Succeed())
+			objs := meta(tt.list)
+			g.Expect(objs).To(HaveLen(2))
+			for _, obj := range objs {
+				g.Expect(obj.GetOwnerReferences()).To(ConsistOf(owner))
+				g.Expect(obj.GetLabels()).To(HaveKeyWithValue(SnapshotLabelName, "first"))
+				// Only the object describing the snapshot can be listed by the descriptor label.
+				if obj.GetName() == "first" {
+					g.Expect(obj.GetLabels()).To(HaveKey(descriptorLabelName))
+				} else {
+					g.Expect(obj.GetLabels()).NotTo(HaveKey(descriptorLabelName))
+				}
+			}
+		})
+	}
+}
+
+func meta(list client.ObjectList) []metav1.Object {
+	objs := []metav1.Object{}
+	switch l := list.(type) {
+	case *corev1.SecretList:
+		for i := range l.Items {
+			objs = append(objs, &l.Items[i])
+		}
+	case *corev1.ConfigMapList:
+		for i := range l.Items {
+			objs = append(objs, &l.Items[i])
+		}
+	}
+	return objs
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	// restoreDir is the directory on the machine where the snapshot and the restore script are stored.
+	restoreDir = "/etc/kubernetes/etcd-restore"
+
+	restoreSnapshotPath   = restoreDir + "/snapshot.db"
+	restoreScriptPath     = restoreDir + "/restore.sh"
+	restoreKubeconfigPath = restoreDir + "/kubeconfig"
+	restoreTokenPath      = restoreDir + "/token"
+	restoreCAPath         = restoreDir + "/ca.crt"
+
+	// defaultEtcdDataDir is the etcd data dir used by kubeadm when not configured.
+	defaultEtcdDataDir = "/var/lib/etcd"
+)
+
+// RestoreObject is an object in the management cluster storing a snapshot, or a chunk of it.
+type RestoreObject struct {
+	// Kind of the object, either Secret or ConfigMap.
+	Kind string
+
+	// Name of the object.
+	Name string
+
+	// Key of the snapshot data in the object.
+	Key string
+}
+
+// resource returns the resource of the object, as expected by kubectl.
+func (o RestoreObject) resource() string {
+	return strings.ToLower(o.Kind)
+}
+
+// jsonPath returns the kubectl JSONPath expression reading the base64 encoded snapshot data from the object;
+// Secrets store binary data in the data field, ConfigMaps in the binaryData field.
+func (o RestoreObject) jsonPath() string {
+	field := "data"
+	if o.Kind == "ConfigMap" {
+		field = "binaryData"
+	}
+	return fmt.Sprintf("{.%s.%s}", field, strings.ReplaceAll(o.Key, ".", `\.`))
+}
+
+// RestoreSource defines where the machine restoring etcd reads the snapshot from.
+// NOTE: The snapshot is read by the machine from the management cluster, so the snapshot is not passed in the bootstrap
+// data, which is subject to the size limits of Secrets and of the user data of the infrastructure providers.
+type RestoreSource struct {
+	// Server is the address of the management cluster API server, reachable from the machine.
+	Server string
+
+	// Namespace of the objects storing the snapshot.
+	Namespace string
+
+	// Objects storing the snapshot; the snapshot is the concatenation of their data.
+	Objects []RestoreObject
+
+	// CredentialsSecretName is the name of a service account token Secret, whose token and CA certificate are used
+	// by the machine for reading the objects storing the snapshot.
+	CredentialsSecretName string
+}
+
+// restoreKubeconfig is the kubeconfig used by the machine for reading the snapshot from the management cluster; the token
+// and the CA certificate are stored in separate files, so the kubeconfig does not contain secret data.
+var restoreKubeconfig = template.Must(template.New("kubeconfig").Parse(`apiVersion: v1
+kind: Config
+clusters:
+- name: management
+  cluster:
+    server: {{ .Server }}
+    certificate-authority: {{ .CAPath }}
+users:
+- name: etcd-restore
+  user:
+    tokenFile: {{ .TokenPath }}
+contexts:
+- name: etcd-restore
+  context:
+    cluster: management
+    user: etcd-restore
+    namespace: {{ .Namespace }}
+current-context: etcd-restore
+`))
+
+// restoreScript reads the snapshot from the management cluster and restores the etcd data dir before kubeadm init runs;
+// the etcdctl binary is run from the etcd image used by kubeadm, so it is guaranteed to be compatible with the etcd version.
+// NOTE: The name and the peer URL of the restored member must match the ones used by kubeadm for the local etcd member,
+// so they are derived from the InitConfiguration and fall back to the same defaults used by kubeadm.
+// NOTE: kubeadm init fails if the etcd data dir is not empty; the preflight check can be ignored only using
+// a kubeadm API version newer than the one generated by the bootstrap provider, so the kubeadm config is migrated first,
+// and then the InitConfiguration is patched with kubectl, preserving the preflight errors already ignored, if any.
+var restoreScript = template.Must(template.New("restore").Parse(`#!/bin/bash
+set -euo pipefail
+
+KUBEADM_CONFIG=/run/kubeadm/kubeadm.yaml
+MANAGEMENT_KUBECONFIG={{ .KubeconfigPath }}
+SNAPSHOT={{ .SnapshotPath }}
+DATA_DIR={{ .DataDir }}
+NODE_NAME="{{ .NodeName }}"
+ADVERTISE_ADDRESS="{{ .AdvertiseAddress }}"
+
+if [ -z "${NODE_NAME}" ]; then
+  NODE_NAME="$(hostname | tr '[:upper:]' '[:lower:]')"
+fi
+if [ -z "${ADVERTISE_ADDRESS}" ]; then
+  ADVERTISE_ADDRESS="$(ip -4 route get 1.1.1.1 | awk '/src/ { for (i = 1; i < NF; i++) if ($i == "src") print $(i+1) }')"
+fi
+
+: > "${SNAPSHOT}"
+{{- range .Objects }}
+kubectl --kubeconfig "${MANAGEMENT_KUBECONFIG}" get {{ .Resource }} "{{ .Name }}" -o jsonpath='{{ .JSONPath }}' | base64 -d >> "${SNAPSHOT}"
+{{- end }}
+
+kubeadm config migrate --old-config "${KUBEADM_CONFIG}" --new-config "${KUBEADM_CONFIG}"
+CONFIG_DIR="$(mktemp -d)"
+awk -v dir="${CONFIG_DIR}" '/^---$/ { n++; next } { print > (dir "/" n+0 ".yaml") }' "${KUBEADM_CONFIG}"
+: > "${KUBEADM_CONFIG}"
+for DOC in $(ls "${CONFIG_DIR}" | sort -n); do
+  DOC="${CONFIG_DIR}/${DOC}"
+  if grep -q '^kind: InitConfiguration$' "${DOC}"; then
+    kubectl patch --local -f "${DOC}" --type json -o yaml \
+      -p '[{"op": "add", "path": "/nodeRegistration/ignorePreflightErrors/-", "value": "{{ .PreflightCheck }}"}]' > "${DOC}.patched" ||
+      kubectl patch --local -f "${DOC}" --type json -o yaml \
+        -p '[{"op": "add", "path": "/nodeRegistration/ignorePreflightErrors", "value": ["{{ .PreflightCheck }}"]}]' > "${DOC}.patched"
+    mv "${DOC}.patched" "${DOC}"
+  fi
+  if [ -s "${KUBEADM_CONFIG}" ]; then
+    echo '---' >> "${KUBEADM_CONFIG}"
+  fi
+  cat "${DOC}" >> "${KUBEADM_CONFIG}"
+done
+rm -rf "${CONFIG_DIR}"
+
+ETCD_IMAGE="$(kubeadm config images list --config "${KUBEADM_CONFIG}" 2>/dev/null | grep '/etcd:')"
+ctr -n k8s.io images pull "${ETCD_IMAGE}"
+
+rm -rf "${DATA_DIR}"
+mkdir -p "$(dirname "${DATA_DIR}")"
+ctr -n k8s.io run --rm --net-host \
+  --env ETCDCTL_API=3 \
+  --mount "type=bind,src={{ .RestoreDir }},dst={{ .RestoreDir }},options=rbind:ro" \
+  --mount "type=bind,src=$(dirname "${DATA_DIR}"),dst=$(dirname "${DATA_DIR}"),options=rbind:rw" \
+  "${ETCD_IMAGE}" etcd-restore \
+  etcdctl snapshot restore "${SNAPSHOT}" \
+  --data-dir "${DATA_DIR}" \
+  --name "${NODE_NAME}" \
+  --initial-cluster "${NODE_NAME}=https://${ADVERTISE_ADDRESS}:2380" \
+  --initial-advertise-peer-urls "https://${ADVERTISE_ADDRESS}:2380"
+chmod 0700 "${DATA_DIR}"
+
+rm -f "${SNAPSHOT}" "${MANAGEMENT_KUBECONFIG}" {{ .TokenPath }} {{ .CAPath }}
+`))
+
+// AddRestoreToConfig changes the configuration of the first control plane machine, so the etcd data dir
+// is restored from the snapshot read from the management cluster before kubeadm init runs.
+// NOTE: kubectl is required in the machine image, for reading the snapshot and for patching the kubeadm config.
+func AddRestoreToConfig(spec *bootstrapv1.KubeadmConfigSpec, source RestoreSource) error {
+	if source.Server == "" {
+		return errors.New("the address of the management cluster API server is required for restoring etcd from a snapshot")
+	}
+	if len(source.Objects) == 0 {
+		return errors.New("the objects storing the snapshot are required for restoring etcd from a snapshot")
+	}
+
+	dataDir := etcdDataDir(spec)
+
+	type object struct {
+		Resource string
+		Name     string
+		JSONPath string
+	}
+	values := struct {
+		RestoreDir       string
+		SnapshotPath     string
+		KubeconfigPath   string
+		TokenPath        string
+		CAPath           string
+		Server           string
+		Namespace        string
+		Objects          []object
+		DataDir          string
+		NodeName         string
+		AdvertiseAddress string
+		PreflightCheck   string
+	}{
+		RestoreDir:     restoreDir,
+		SnapshotPath:   restoreSnapshotPath,
+		KubeconfigPath: restoreKubeconfigPath,
+		TokenPath:      restoreTokenPath,
+		CAPath:         restoreCAPath,
+		Server:         source.Server,
+		Namespace:      source.Namespace,
+		DataDir:        dataDir,
+		PreflightCheck: "DirAvailable-" + strings.ReplaceAll(dataDir, "/", "-"),
+	}
+	for _, o := range source.Objects {
+		values.Objects = append(values.Objects, object{Resource: o.resource(), Name: o.Name, JSONPath: o.jsonPath()})
+	}
+	if spec.InitConfiguration != nil {
+		values.NodeName = spec.InitConfiguration.NodeRegistration.Name
+		values.AdvertiseAddress = spec.InitConfiguration.LocalAPIEndpoint.AdvertiseAddress
+	}
+
+	var kubeconfig bytes.Buffer
+	if err := restoreKubeconfig.Execute(&kubeconfig, values); err != nil {
+		return errors.Wrap(err, "failed to generate the etcd restore kubeconfig")
+	}
+	var script bytes.Buffer
+	if err := restoreScript.Execute(&script, values); err != nil {
+		return errors.Wrap(err, "failed to generate the etcd restore script")
+	}
+
+	spec.Files = append(spec.Files,
+		bootstrapv1.File{
+			Path:        restoreTokenPath,
+			Owner:       "root:root",
+			Permissions: "0600",
+			ContentFrom: &bootstrapv1.FileSource{
+				Secret: bootstrapv1.SecretFileSource{Name: source.CredentialsSecretName, Key: corev1.ServiceAccountTokenKey},
+			},
+		},
+		bootstrapv1.File{
+			Path:        restoreCAPath,
+			Owner:       "root:root",
+			Permissions: "0600",
+			ContentFrom: &bootstrapv1.FileSource{
+				Secret: bootstrapv1.SecretFileSource{Name: source.CredentialsSecretName, Key: corev1.ServiceAccountRootCAKey},
+			},
+		},
+		bootstrapv1.File{
+			Path:        restoreKubeconfigPath,
+			Owner:       "root:root",
+			Permissions: "0600",
+			Content:     kubeconfig.String(),
+		},
+		bootstrapv1.File{
+			Path:        restoreScriptPath,
+			Owner:       "root:root",
+			Permissions: "0700",
+			Content:     script.String(),
+		},
+	)
+	spec.PreKubeadmCommands = append(spec.PreKubeadmCommands, restoreScriptPath)
+	return nil
+}
+
+// RemoveRestoreFromConfig reverts the changes applied by AddRestoreToConfig, if any; this allows to compare the
+// configuration of the first control plane machine with the configuration of the KubeadmControlPlane.
+func RemoveRestoreFromConfig(spec *bootstrapv1.KubeadmConfigSpec) {
+	if !HasRestore(spec) {
+		return
+	}
+
+	files := []bootstrapv1.File{}
+	for _, f := range spec.Files {
+		switch f.Path {
+		case restoreTokenPath, restoreCAPath, restoreKubeconfigPath, restoreScriptPath:
+			continue
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		files = nil
+	}
+	spec.Files = files
+
+	// NOTE: the restore script is the last of the commands, so the user defined commands are preserved even if
+	// they include the same command.
+	if n := len(spec.PreKubeadmCommands); n > 0 && spec.PreKubeadmCommands[n-1] == restoreScriptPath {
+		spec.PreKubeadmCommands = spec.PreKubeadmCommands[:n-1]
+	}
+	if len(spec.PreKubeadmCommands) == 0 {
+		spec.PreKubeadmCommands = nil
+	}
+}
+
+// HasRestore returns true if the configuration has been changed by AddRestoreToConfig.
+func HasRestore(spec *bootstrapv1.KubeadmConfigSpec) bool {
+	for _, f := range spec.Files {
+		if f.Path == restoreScriptPath {
+			return true
+		}
+	}
+	return false
+}
+
+// etcdDataDir returns the etcd data dir defined in the configuration, or the kubeadm default.
+func etcdDataDir(spec *bootstrapv1.KubeadmConfigSpec) string {
+	if spec.ClusterConfiguration != nil && spec.ClusterConfiguration.Etcd.Local != nil && spec.ClusterConfiguration.Etcd.Local.DataDir != "" {
+		return spec.ClusterConfiguration.Etcd.Local.DataDir
+	}
+	return defaultEtcdDataDir
+}
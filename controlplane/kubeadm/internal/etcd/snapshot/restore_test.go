@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+)
+
+func TestAddRestoreToConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          *bootstrapv1.KubeadmConfigSpec
+		expectScript  []string
+		expectCommand []string
+	}{
+		{
+			name: "restore with the kubeadm defaults",
+			spec: &bootstrapv1.KubeadmConfigSpec{},
+			expectScript: []string{
+				"DATA_DIR=/var/lib/etcd\n",
+				"NODE_NAME=\"\"\n",
+				`"value": "DirAvailable--var-lib-etcd"`,
+				`get secret "test-etcd-20210304-050607-0" -o jsonpath='{.data.snapshot}' | base64 -d >> "${SNAPSHOT}"`,
+				`get secret "test-etcd-20210304-050607-1" -o jsonpath='{.data.snapshot}' | base64 -d >> "${SNAPSHOT}"`,
+			},
+			expectCommand: []string{restoreScriptPath},
+		},
+		{
+			name: "restore with a custom configuration",
+			spec: &bootstrapv1.KubeadmConfigSpec{
+				ClusterConfiguration: &kubeadmv1.ClusterConfiguration{
+					Etcd: kubeadmv1.Etcd{Local: &kubeadmv1.LocalEtcd{DataDir: "/data/etcd"}},
+				},
+				InitConfiguration: &kubeadmv1.InitConfiguration{
+					NodeRegistration: kubeadmv1.NodeRegistrationOptions{Name: "{{ ds.meta_data.local_hostname }}"},
+					LocalAPIEndpoint: kubeadmv1.APIEndpoint{AdvertiseAddress: "10.0.0.1"},
+				},
+				Files:              []bootstrapv1.File{{Path: "/etc/foo", Content: "foo"}},
+				PreKubeadmCommands: []string{"echo foo", restoreScriptPath},
+			},
+			expectScript: []string{
+				"DATA_DIR=/data/etcd\n",
+				"NODE_NAME=\"{{ ds.meta_data.local_hostname }}\"\n",
+				"ADVERTISE_ADDRESS=\"10.0.0.1\"\n",
+				`"value": "DirAvailable--data-etcd"`,
+			},
+			expectCommand: []string{"echo foo", restoreScriptPath, restoreScriptPath},
+		},
+	}
+
+	source := RestoreSource{
+		Server:    "https://10.0.0.1:6443",
+		Namespace: "default",
+		Objects: []RestoreObject{
+			{Kind: "Secret", Name: "test-etcd-20210304-050607-0", Key: "snapshot"},
+			{Kind: "Secret", Name: "test-etcd-20210304-050607-1", Key: "snapshot"},
+		},
+		CredentialsSecretName: "test-etcd-restore",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			spec := tt.spec.DeepCopy()
+			g.Expect(HasRestore(spec)).To(BeFalse())
+			g.Expect(AddRestoreToConfig(spec, source)).To(Succeed())
+			g.Expect(HasRestore(spec)).To(BeTrue())
+
+			g.Expect(spec.PreKubeadmCommands).To(Equal(tt.expectCommand))
+			g.Expect(spec.Files).To(HaveLen(len(tt.spec.Files) + 4))
+			// The snapshot is not passed in the bootstrap data, only the credentials for reading it.
+			tokenFile := spec.Files[len(spec.Files)-4]
+			g.Expect(tokenFile.Path).To(Equal(restoreTokenPath))
+			g.Expect(tokenFile.ContentFrom.Secret).To(Equal(bootstrapv1.SecretFileSource{Name: "test-etcd-restore", Key: corev1.ServiceAccountTokenKey}))
+			caFile := spec.Files[len(spec.Files)-3]
+			g.Expect(caFile.Path).To(Equal(restoreCAPath))
+			g.Expect(caFile.ContentFrom.Secret).To(Equal(bootstrapv1.SecretFileSource{Name: "test-etcd-restore", Key: corev1.ServiceAccountRootCAKey}))
+			kubeconfigFile := spec.Files[len(spec.Files)-2]
+			g.Expect(kubeconfigFile.Path).To(Equal(restoreKubeconfigPath))
+			g.Expect(kubeconfigFile.Content).To(ContainSubstring("server: https://10.0.0.1:6443\n"))
+			g.Expect(kubeconfigFile.Content).To(ContainSubstring("tokenFile: " + restoreTokenPath + "\n"))
+			g.Expect(kubeconfigFile.Content).To(ContainSubstring("namespace: default\n"))
+			scriptFile := spec.Files[len(spec.Files)-1]
+			g.Expect(scriptFile.Path).To(Equal(restoreScriptPath))
+			for _, s := range tt.expectScript {
+				g.Expect(scriptFile.Content).To(ContainSubstring(s))
+			}
+
+			RemoveRestoreFromConfig(spec)
+			g.Expect(HasRestore(spec)).To(BeFalse())
+			g.Expect(spec).To(Equal(tt.spec))
+		})
+	}
+}
+
+func TestAddRestoreToConfigRequiresServer(t *testing.T) {
+	g := NewWithT(t)
+
+	spec := &bootstrapv1.KubeadmConfigSpec{}
+	g.Expect(AddRestoreToConfig(spec, RestoreSource{
+		Objects: []RestoreObject{{Kind: "Secret", Name: "backup", Key: "snapshot.db"}},
+	})).NotTo(Succeed())
+	g.Expect(HasRestore(spec)).To(BeFalse())
+}
+
+func TestRestoreObjectJSONPath(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(RestoreObject{Kind: "Secret", Name: "backup", Key: "snapshot.db"}.jsonPath()).To(Equal(`{.data.snapshot\.db}`))
+	g.Expect(RestoreObject{Kind: "ConfigMap", Name: "test-etcd-20210304-050607-0", Key: "snapshot"}.jsonPath()).To(Equal(`{.binaryData.snapshot}`))
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snapshot implements the storage of etcd snapshots taken by the KubeadmControlPlane controller.
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// nameTimestampFormat is the format of the timestamp used in snapshot names.
+const nameTimestampFormat = "20060102-150405"
+
+// Snapshot describes an etcd snapshot stored in a Sink.
+type Snapshot struct {
+	// Name of the snapshot.
+	Name string
+
+	// Timestamp is the time the snapshot was taken at.
+	Timestamp time.Time
+
+	// Size of the snapshot, in bytes.
+	Size int64
+}
+
+// Sink stores etcd snapshots.
+// Storing snapshots outside of the management cluster, e.g. in an object storage, requires a new implementation
+// of this interface.
+type Sink interface {
+	// Save stores a snapshot taken at the given time, reading its content from r.
+	Save(ctx context.Context, name string, timestamp time.Time, r io.Reader) error
+
+	// Open returns a reader for the content of a snapshot. The caller is responsible for closing the reader.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// List returns the snapshots stored in the sink, sorted from the oldest to the newest.
+	List(ctx context.Context) ([]Snapshot, error)
+
+	// Delete removes a snapshot from the sink.
+	Delete(ctx context.Context, name string) error
+
+	// RestoreObjects returns the objects in the management cluster storing a snapshot, which are read by the machine
+	// restoring etcd from the snapshot.
+	RestoreObjects(ctx context.Context, name string) ([]RestoreObject, error)
+}
+
+// Name returns the name for a snapshot taken at the given time; the prefix is truncated if required
+// for the name to be a valid label value.
+func Name(prefix string, timestamp time.Time) string {
+	suffix := fmt.Sprintf("-etcd-%s", timestamp.UTC().Format(nameTimestampFormat))
+	if len(prefix)+len(suffix) > validation.LabelValueMaxLength {
+		prefix = prefix[:validation.LabelValueMaxLength-len(suffix)]
+	}
+	return prefix + suffix
+}
+
+// Prune deletes the oldest snapshots stored in the sink, keeping at most retention snapshots,
+// and returns the names of the deleted snapshots.
+func Prune(ctx context.Context, sink Sink, retention int) ([]string, error) {
+	snapshots, err := sink.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) <= retention {
+		return nil, nil
+	}
+
+	deleted := []string{}
+	errs := []error{}
+	for _, s := range snapshots[:len(snapshots)-retention] {
+		if err := sink.Delete(ctx, s.Name); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to delete snapshot %s", s.Name))
+			continue
+		}
+		deleted = append(deleted, s.Name)
+	}
+	return deleted, kerrors.NewAggregate(errs)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestName(t *testing.T) {
+	g := NewWithT(t)
+
+	timestamp := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	g.Expect(Name("test", timestamp)).To(Equal("test-etcd-20210304-050607"))
+
+	name := Name(strings.Repeat("a", 100), timestamp)
+	g.Expect(name).To(HaveLen(validation.LabelValueMaxLength))
+	g.Expect(name).To(HaveSuffix("-etcd-20210304-050607"))
+}
+
+func TestPrune(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	sink := NewSecretSink(fake.NewClientBuilder().Build(), client.ObjectKey{Namespace: "default", Name: "test"}, metav1.OwnerReference{})
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, name := range []string{"a", "b", "c", "d"} {
+		g.Expect(sink.Save(ctx, name, now.Add(time.Duration(i)*time.Minute), bytes.NewReader([]byte(name)))).To(Succeed())
+	}
+
+	deleted, err := Prune(ctx, sink, 4)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deleted).To(BeEmpty())
+
+	deleted, err = Prune(ctx, sink, 2)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deleted).To(Equal([]string{"a", "b"}))
+
+	snapshots, err := sink.List(ctx)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(snapshots).To(HaveLen(2))
+	g.Expect(snapshots[0].Name).To(Equal("c"))
+	g.Expect(snapshots[1].Name).To(Equal("d"))
+}
@@ -19,6 +19,7 @@ package machinefilters
 import (
 	"encoding/json"
 	"reflect"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -28,6 +29,7 @@ import (
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd/snapshot"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -171,6 +173,26 @@ func ShouldRolloutAfter(reconciliationTime, rolloutAfter *metav1.Time) Func {
 	}
 }
 
+// ShouldRolloutBefore returns a filter to find all machines whose certificates
+// expire before reconciliationTime + rolloutBefore.CertificatesExpiryDays.
+func ShouldRolloutBefore(reconciliationTime *metav1.Time, rolloutBefore *controlplanev1.RolloutBefore) Func {
+	return func(machine *clusterv1.Machine) bool {
+		if machine == nil || reconciliationTime == nil || rolloutBefore == nil || rolloutBefore.CertificatesExpiryDays == nil {
+			return false
+		}
+		value, ok := machine.Annotations[controlplanev1.CertificatesExpiryAnnotation]
+		if !ok {
+			return false
+		}
+		expiry, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return false
+		}
+		threshold := reconciliationTime.Add(time.Duration(*rolloutBefore.CertificatesExpiryDays) * 24 * time.Hour)
+		return expiry.Before(threshold)
+	}
+}
+
 // HasAnnotationKey returns a filter to find all machines that have the
 // specified Annotation key present
 func HasAnnotationKey(key string) Func {
@@ -396,4 +418,8 @@ func cleanupConfigFields(kcpConfig *bootstrapv1.KubeadmConfigSpec, machineConfig
 	if machineConfig.Spec.JoinConfiguration != nil && kcpConfig.JoinConfiguration != nil {
 		machineConfig.Spec.JoinConfiguration.TypeMeta = kcpConfig.JoinConfiguration.TypeMeta
 	}
+
+	// Cleanup the files and commands added for restoring etcd on the first control plane machine, because
+	// they are relevant only for the initialization of the control plane.
+	snapshot.RemoveRestoreFromConfig(&machineConfig.Spec)
 }
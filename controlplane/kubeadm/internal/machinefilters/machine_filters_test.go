@@ -167,6 +167,51 @@ func TestShouldRolloutAfter(t *testing.T) {
 	})
 }
 
+func TestShouldRolloutBefore(t *testing.T) {
+	reconciliationTime := metav1.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	rolloutBefore := &controlplanev1.RolloutBefore{CertificatesExpiryDays: pointer.Int32Ptr(7)}
+	machineWithExpiry := func(expiry time.Time) *clusterv1.Machine {
+		m := &clusterv1.Machine{}
+		m.SetAnnotations(map[string]string{controlplanev1.CertificatesExpiryAnnotation: expiry.Format(time.RFC3339)})
+		return m
+	}
+	t.Run("if the machine is nil it returns false", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(machinefilters.ShouldRolloutBefore(&reconciliationTime, rolloutBefore)(nil)).To(BeFalse())
+	})
+	t.Run("if the reconciliationTime is nil it returns false", func(t *testing.T) {
+		g := NewWithT(t)
+		m := machineWithExpiry(reconciliationTime.Add(24 * time.Hour))
+		g.Expect(machinefilters.ShouldRolloutBefore(nil, rolloutBefore)(m)).To(BeFalse())
+	})
+	t.Run("if the rolloutBefore is nil it returns false", func(t *testing.T) {
+		g := NewWithT(t)
+		m := machineWithExpiry(reconciliationTime.Add(24 * time.Hour))
+		g.Expect(machinefilters.ShouldRolloutBefore(&reconciliationTime, nil)(m)).To(BeFalse())
+	})
+	t.Run("if the machine has no certificates expiry annotation it returns false", func(t *testing.T) {
+		g := NewWithT(t)
+		m := &clusterv1.Machine{}
+		g.Expect(machinefilters.ShouldRolloutBefore(&reconciliationTime, rolloutBefore)(m)).To(BeFalse())
+	})
+	t.Run("if the certificates expiry annotation is invalid it returns false", func(t *testing.T) {
+		g := NewWithT(t)
+		m := &clusterv1.Machine{}
+		m.SetAnnotations(map[string]string{controlplanev1.CertificatesExpiryAnnotation: "invalid"})
+		g.Expect(machinefilters.ShouldRolloutBefore(&reconciliationTime, rolloutBefore)(m)).To(BeFalse())
+	})
+	t.Run("if the certificates expire after the threshold it returns false", func(t *testing.T) {
+		g := NewWithT(t)
+		m := machineWithExpiry(reconciliationTime.Add(8 * 24 * time.Hour))
+		g.Expect(machinefilters.ShouldRolloutBefore(&reconciliationTime, rolloutBefore)(m)).To(BeFalse())
+	})
+	t.Run("if the certificates expire before the threshold it returns true", func(t *testing.T) {
+		g := NewWithT(t)
+		m := machineWithExpiry(reconciliationTime.Add(6 * 24 * time.Hour))
+		g.Expect(machinefilters.ShouldRolloutBefore(&reconciliationTime, rolloutBefore)(m)).To(BeTrue())
+	})
+}
+
 func TestHashAnnotationKey(t *testing.T) {
 	t.Run("machine with specified annotation returns true", func(t *testing.T) {
 		g := NewWithT(t)
@@ -26,6 +26,7 @@ import (
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	kubeadmv1beta1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcd/snapshot"
 )
 
 func TestMatchClusterConfiguration(t *testing.T) {
@@ -267,6 +268,25 @@ func TestCleanupConfigFields(t *testing.T) {
 		g.Expect(kcpConfig.JoinConfiguration).ToNot(gomega.BeNil())
 		g.Expect(machineConfig.Spec.JoinConfiguration.TypeMeta).To(gomega.Equal(metav1.TypeMeta{}))
 	})
+	t.Run("Etcd restore files and commands get removed from MachineConfig", func(t *testing.T) {
+		g := gomega.NewWithT(t)
+		kcpConfig := &bootstrapv1.KubeadmConfigSpec{
+			Files:              []bootstrapv1.File{{Path: "/etc/foo", Content: "foo"}},
+			PreKubeadmCommands: []string{"echo foo"},
+		}
+		machineConfig := &bootstrapv1.KubeadmConfig{
+			Spec: *kcpConfig.DeepCopy(),
+		}
+		g.Expect(snapshot.AddRestoreToConfig(&machineConfig.Spec, snapshot.RestoreSource{
+			Server:                "https://10.0.0.1:6443",
+			Namespace:             "default",
+			Objects:               []snapshot.RestoreObject{{Kind: "Secret", Name: "foo-etcd-20210304-050607-0", Key: "snapshot"}},
+			CredentialsSecretName: "foo-etcd-restore",
+		})).To(gomega.Succeed())
+		cleanupConfigFields(kcpConfig, machineConfig)
+		g.Expect(machineConfig.Spec.Files).To(gomega.Equal(kcpConfig.Files))
+		g.Expect(machineConfig.Spec.PreKubeadmCommands).To(gomega.Equal(kcpConfig.PreKubeadmCommands))
+	})
 }
 
 func TestMatchInitOrJoinConfiguration(t *testing.T) {
@@ -25,6 +25,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"fmt"
+	"io"
 	"math/big"
 	"time"
 
@@ -34,7 +35,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/util"
@@ -63,6 +68,8 @@ type WorkloadCluster interface {
 	UpdateStaticPodConditions(ctx context.Context, controlPlane *ControlPlane)
 	UpdateEtcdConditions(ctx context.Context, controlPlane *ControlPlane)
 	EtcdMembers(ctx context.Context) ([]string, error)
+	EtcdMembersStatus(ctx context.Context) ([]EtcdMemberStatus, error)
+	GetAPIServerCertificateExpiry(ctx context.Context, kubeadmConfig *bootstrapv1.KubeadmConfig, nodeName string) (*time.Time, error)
 
 	// Upgrade related tasks.
 	ReconcileKubeletRBACBinding(ctx context.Context, version semver.Version) error
@@ -81,9 +88,14 @@ type WorkloadCluster interface {
 	RemoveNodeFromKubeadmConfigMap(ctx context.Context, nodeName string) error
 	ForwardEtcdLeadership(ctx context.Context, machine *clusterv1.Machine, leaderCandidate *clusterv1.Machine) error
 	AllowBootstrapTokensToGetNodes(ctx context.Context) error
+	UpdateCertificateAuthorityInClusterInfo(ctx context.Context, caData []byte) error
 
 	// State recovery tasks.
 	ReconcileEtcdMembers(ctx context.Context, nodeNames []string) ([]string, error)
+	RemoveStaleControlPlaneNodes(ctx context.Context, nodeNames []string) ([]string, error)
+
+	// Backup tasks.
+	EtcdSnapshot(ctx context.Context) (io.ReadCloser, error)
 }
 
 // Workload defines operations on workload clusters.
@@ -91,6 +103,7 @@ type Workload struct {
 	Client              ctrlclient.Client
 	CoreDNSMigrator     coreDNSMigrator
 	etcdClientGenerator etcdClientFor
+	restConfig          *rest.Config
 }
 
 var _ WorkloadCluster = &Workload{}
@@ -285,6 +298,37 @@ func (w *Workload) RemoveNodeFromKubeadmConfigMap(ctx context.Context, name stri
 	}, 5)
 }
 
+// RemoveStaleControlPlaneNodes deletes the control plane nodes not included in the given list of node names,
+// and removes them from the kubeadm configmap; this is required e.g. after restoring a control plane from an etcd snapshot,
+// which includes the nodes of the machines existing when the snapshot was taken.
+func (w *Workload) RemoveStaleControlPlaneNodes(ctx context.Context, nodeNames []string) ([]string, error) {
+	nodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list control plane nodes")
+	}
+
+	expected := sets.NewString(nodeNames...)
+	removedNodes := []string{}
+	errs := []error{}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if expected.Has(node.Name) {
+			continue
+		}
+
+		removedNodes = append(removedNodes, node.Name)
+		if err := w.RemoveNodeFromKubeadmConfigMap(ctx, node.Name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := w.Client.Delete(ctx, node); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(err, "failed to delete node %s", node.Name))
+		}
+	}
+
+	return removedNodes, kerrors.NewAggregate(errs)
+}
+
 // ClusterStatus holds stats information about the cluster.
 type ClusterStatus struct {
 	// Nodes are a total count of nodes
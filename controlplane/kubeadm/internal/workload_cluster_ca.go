@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package internal
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	clusterInfoConfigMapName = "cluster-info"
+	clusterInfoKubeconfigKey = "kubeconfig"
+)
+
+// UpdateCertificateAuthorityInClusterInfo sets the certificate authority data of the cluster-info ConfigMap, that
+// is used by the joining nodes to discover the cluster; the bootstrap signer in the controller manager takes care
+// of signing the new content for the bootstrap tokens.
+func (w *Workload) UpdateCertificateAuthorityInClusterInfo(ctx context.Context, caData []byte) error {
+	configMapKey := ctrlclient.ObjectKey{Name: clusterInfoConfigMapName, Namespace: metav1.NamespacePublic}
+	clusterInfo, err := w.getConfigMap(ctx, configMapKey)
+	if err != nil {
+		return err
+	}
+
+	config, err := clientcmd.Load([]byte(clusterInfo.Data[clusterInfoKubeconfigKey]))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse the kubeconfig in the cluster-info ConfigMap")
+	}
+	if len(config.Clusters) == 0 {
+		return errors.New("the kubeconfig in the cluster-info ConfigMap does not define any cluster")
+	}
+	for _, cluster := range config.Clusters {
+		cluster.CertificateAuthorityData = caData
+	}
+	out, err := clientcmd.Write(*config)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize the kubeconfig for the cluster-info ConfigMap")
+	}
+
+	clusterInfo.Data[clusterInfoKubeconfigKey] = string(out)
+	if err := w.Client.Update(ctx, clusterInfo); err != nil {
+		return errors.Wrap(err, "error updating cluster-info ConfigMap")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package internal
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateCertificateAuthorityInClusterInfo(t *testing.T) {
+	clusterInfo := func(kubeconfig string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterInfoConfigMapName, Namespace: metav1.NamespacePublic},
+			Data: map[string]string{
+				clusterInfoKubeconfigKey: kubeconfig,
+				"jws-kubeconfig-abcdef":  "signature",
+			},
+		}
+	}
+	validKubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    certificate-authority-data: b2xk
+    server: https://1.2.3.4:6443
+  name: ""
+contexts: null
+current-context: ""
+preferences: {}
+users: null
+`
+
+	tests := []struct {
+		name       string
+		objs       []ctrlclient.Object
+		expectErr  bool
+		expectedCA []byte
+	}{
+		{
+			name:       "updates the certificate authority data",
+			objs:       []ctrlclient.Object{clusterInfo(validKubeconfig)},
+			expectedCA: []byte("new"),
+		},
+		{
+			name:      "returns an error if the ConfigMap does not exist",
+			expectErr: true,
+		},
+		{
+			name:      "returns an error if the kubeconfig does not define any cluster",
+			objs:      []ctrlclient.Object{clusterInfo("apiVersion: v1\nkind: Config\n")},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			w := &Workload{
+				Client: fake.NewClientBuilder().WithObjects(tt.objs...).Build(),
+			}
+			err := w.UpdateCertificateAuthorityInClusterInfo(ctx, []byte("new"))
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got := &corev1.ConfigMap{}
+			g.Expect(w.Client.Get(ctx, ctrlclient.ObjectKey{Name: clusterInfoConfigMapName, Namespace: metav1.NamespacePublic}, got)).To(Succeed())
+			g.Expect(got.Data).To(HaveKey("jws-kubeconfig-abcdef"))
+			config, err := clientcmd.Load([]byte(got.Data[clusterInfoKubeconfigKey]))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(config.Clusters).To(HaveKey(""))
+			g.Expect(config.Clusters[""].CertificateAuthorityData).To(Equal(tt.expectedCA))
+			g.Expect(config.Clusters[""].Server).To(Equal("https://1.2.3.4:6443"))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/proxy"
+)
+
+const defaultAPIServerBindPort = 6443
+
+// GetAPIServerCertificateExpiry returns the expiry date of the serving certificate of the API server running on
+// the given node; it is used as a proxy for the expiry of all the certificates generated by kubeadm on the node,
+// given that all of them are created at the same time with the same validity.
+func (w *Workload) GetAPIServerCertificateExpiry(ctx context.Context, kubeadmConfig *bootstrapv1.KubeadmConfig, nodeName string) (*time.Time, error) {
+	p := proxy.Proxy{
+		Kind:       "pods",
+		Namespace:  metav1.NamespaceSystem,
+		KubeConfig: rest.CopyConfig(w.restConfig),
+		Port:       apiServerBindPort(kubeadmConfig),
+	}
+	dialer, err := proxy.NewDialer(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a dialer for the API server")
+	}
+
+	podName := staticPodName("kube-apiserver", nodeName)
+	conn, err := dialer.DialContextWithAddr(ctx, podName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to the API server pod %s", podName)
+	}
+
+	// NB. The certificate is only inspected, so there is no need to verify it.
+	tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true}) //nolint:gosec
+	defer tlsConn.Close()
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, errors.Wrapf(err, "failed to complete the TLS handshake with the API server pod %s", podName)
+	}
+
+	certificates := tlsConn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return nil, errors.Errorf("the API server pod %s did not present any certificate", podName)
+	}
+	expiry := certificates[0].NotAfter
+	return &expiry, nil
+}
+
+// apiServerBindPort returns the port the API server binds to on a machine, as defined in its KubeadmConfig.
+func apiServerBindPort(kubeadmConfig *bootstrapv1.KubeadmConfig) int {
+	if kubeadmConfig == nil {
+		return defaultAPIServerBindPort
+	}
+	if c := kubeadmConfig.Spec.InitConfiguration; c != nil && c.LocalAPIEndpoint.BindPort != 0 {
+		return int(c.LocalAPIEndpoint.BindPort)
+	}
+	if c := kubeadmConfig.Spec.JoinConfiguration; c != nil && c.ControlPlane != nil && c.ControlPlane.LocalAPIEndpoint.BindPort != 0 {
+		return int(c.ControlPlane.LocalAPIEndpoint.BindPort)
+	}
+	return defaultAPIServerBindPort
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+)
+
+func TestAPIServerBindPort(t *testing.T) {
+	tests := []struct {
+		name          string
+		kubeadmConfig *bootstrapv1.KubeadmConfig
+		expected      int
+	}{
+		{
+			name:          "default port without a KubeadmConfig",
+			kubeadmConfig: nil,
+			expected:      6443,
+		},
+		{
+			name:          "default port without a bind port",
+			kubeadmConfig: &bootstrapv1.KubeadmConfig{Spec: bootstrapv1.KubeadmConfigSpec{InitConfiguration: &kubeadmv1.InitConfiguration{}}},
+			expected:      6443,
+		},
+		{
+			name: "bind port from the init configuration",
+			kubeadmConfig: &bootstrapv1.KubeadmConfig{Spec: bootstrapv1.KubeadmConfigSpec{
+				InitConfiguration: &kubeadmv1.InitConfiguration{LocalAPIEndpoint: kubeadmv1.APIEndpoint{BindPort: 8443}},
+			}},
+			expected: 8443,
+		},
+		{
+			name: "bind port from the join configuration",
+			kubeadmConfig: &bootstrapv1.KubeadmConfig{Spec: bootstrapv1.KubeadmConfigSpec{
+				JoinConfiguration: &kubeadmv1.JoinConfiguration{ControlPlane: &kubeadmv1.JoinControlPlane{LocalAPIEndpoint: kubeadmv1.APIEndpoint{BindPort: 9443}}},
+			}},
+			expected: 9443,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(apiServerBindPort(tt.kubeadmConfig)).To(Equal(tt.expected))
+		})
+	}
+}
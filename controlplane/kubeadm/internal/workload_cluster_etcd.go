@@ -18,6 +18,7 @@ package internal
 
 import (
 	"context"
+	"io"
 
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -199,9 +200,19 @@ func (w *Workload) ForwardEtcdLeadership(ctx context.Context, machine *clusterv1
 	return nil
 }
 
+// EtcdMemberStatus reports the status of an etcd member, as seen from the etcd cluster.
 type EtcdMemberStatus struct {
-	Name       string
+	// Name of the member, which is the name of the node hosting it; it is empty if the member has not started yet.
+	Name string
+
+	// Responsive is true if it is possible to connect to the member, and the member status does not report errors.
 	Responsive bool
+
+	// IsLeader is true if the member is the leader of the etcd cluster.
+	IsLeader bool
+
+	// Alarms is the list of alarms raised for the member, e.g. NOSPACE.
+	Alarms []etcd.AlarmType
 }
 
 // EtcdStatus returns the current status of the etcd cluster
@@ -234,3 +245,90 @@ func (w *Workload) EtcdMembers(ctx context.Context) ([]string, error) {
 	}
 	return names, nil
 }
+
+// EtcdMembersStatus returns the status of all the members of the etcd cluster.
+func (w *Workload) EtcdMembersStatus(ctx context.Context) ([]EtcdMemberStatus, error) {
+	nodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list control plane nodes")
+	}
+	nodeNames := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeNames = append(nodeNames, node.Name)
+	}
+	etcdClient, err := w.etcdClientGenerator.forFirstAvailableNode(ctx, nodeNames)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create etcd client")
+	}
+	defer etcdClient.Close()
+
+	members, err := etcdClient.Members(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list etcd members using etcd client")
+	}
+
+	statuses := make([]EtcdMemberStatus, 0, len(members))
+	for _, member := range members {
+		status := EtcdMemberStatus{
+			Name:       member.Name,
+			Responsive: w.isEtcdMemberResponsive(ctx, member.Name),
+			IsLeader:   member.ID == etcdClient.LeaderID,
+		}
+		for _, alarm := range member.Alarms {
+			if alarm != etcd.AlarmOk {
+				status.Alarms = append(status.Alarms, alarm)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// isEtcdMemberResponsive returns true if it is possible to connect to the etcd member hosted on the given node, and
+// the member status does not report errors.
+func (w *Workload) isEtcdMemberResponsive(ctx context.Context, nodeName string) bool {
+	if nodeName == "" {
+		return false
+	}
+	etcdClient, err := w.etcdClientGenerator.forFirstAvailableNode(ctx, []string{nodeName})
+	if err != nil {
+		return false
+	}
+	defer etcdClient.Close()
+	return len(etcdClient.Errors) == 0
+}
+
+// EtcdSnapshot streams a snapshot of the etcd cluster, taken from the first etcd member which is reachable.
+// The caller is responsible for closing the returned reader, which also closes the connection to etcd.
+func (w *Workload) EtcdSnapshot(ctx context.Context) (io.ReadCloser, error) {
+	nodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list control plane nodes")
+	}
+	nodeNames := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeNames = append(nodeNames, node.Name)
+	}
+	etcdClient, err := w.etcdClientGenerator.forFirstAvailableNode(ctx, nodeNames)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create etcd client")
+	}
+
+	snapshot, err := etcdClient.Snapshot(ctx)
+	if err != nil {
+		etcdClient.Close()
+		return nil, err
+	}
+	return &etcdSnapshotReader{ReadCloser: snapshot, etcdClient: etcdClient}, nil
+}
+
+// etcdSnapshotReader reads a snapshot and closes the etcd client used to take it when closed.
+type etcdSnapshotReader struct {
+	io.ReadCloser
+	etcdClient *etcd.Client
+}
+
+func (r *etcdSnapshotReader) Close() error {
+	defer r.etcdClient.Close()
+	return r.ReadCloser.Close()
+}
@@ -19,6 +19,7 @@ package internal
 import (
 	"context"
 	"errors"
+	"io/ioutil"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -569,6 +570,132 @@ kind: ClusterStatus`,
 
 }
 
+func TestEtcdSnapshot(t *testing.T) {
+	cp1 := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cp1",
+			Labels: map[string]string{
+				labelNodeRoleControlPlane: "",
+			},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		etcdClientGenerator etcdClientFor
+		expectErr           bool
+		expectedSnapshot    []byte
+	}{
+		{
+			name:                "returns an error if it fails to create the etcd client",
+			etcdClientGenerator: &fakeEtcdClientGenerator{forNodesErr: errors.New("no client")},
+			expectErr:           true,
+		},
+		{
+			name: "returns an error if the client fails to take the snapshot",
+			etcdClientGenerator: &fakeEtcdClientGenerator{
+				forNodesClient: &etcd.Client{
+					EtcdClient: &fake2.FakeEtcdClient{
+						ErrorResponse: errors.New("cannot take snapshot"),
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "streams the snapshot",
+			etcdClientGenerator: &fakeEtcdClientGenerator{
+				forNodesClient: &etcd.Client{
+					EtcdClient: &fake2.FakeEtcdClient{
+						SnapshotResponse: []byte("snapshot"),
+					},
+				},
+			},
+			expectedSnapshot: []byte("snapshot"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			w := &Workload{
+				Client:              fake.NewClientBuilder().WithObjects(cp1).Build(),
+				etcdClientGenerator: tt.etcdClientGenerator,
+			}
+			snapshot, err := w.EtcdSnapshot(ctx)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			defer snapshot.Close()
+
+			data, err := ioutil.ReadAll(snapshot)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(data).To(Equal(tt.expectedSnapshot))
+		})
+	}
+}
+
+func TestEtcdMembersStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	newNode := func(name string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{labelNodeRoleControlPlane: ""},
+			},
+		}
+	}
+	clusterClient := &etcd.Client{
+		LeaderID: 1,
+		EtcdClient: &fake2.FakeEtcdClient{
+			EtcdEndpoints: []string{},
+			MemberListResponse: &clientv3.MemberListResponse{
+				Members: []*pb.Member{
+					{Name: "cp1", ID: uint64(1)},
+					{Name: "cp2", ID: uint64(2)},
+					{Name: "cp3", ID: uint64(3)},
+					{Name: "cp4", ID: uint64(4)},
+				},
+			},
+			AlarmResponse: &clientv3.AlarmResponse{
+				Alarms: []*pb.AlarmMember{
+					{MemberID: uint64(4), Alarm: pb.AlarmType_NOSPACE},
+				},
+			},
+		},
+	}
+	w := &Workload{
+		Client: fake.NewClientBuilder().WithObjects(newNode("cp1"), newNode("cp2"), newNode("cp3"), newNode("cp4")).Build(),
+		etcdClientGenerator: &fakeEtcdClientGenerator{
+			forNodesClientFunc: func(nodeNames []string) (*etcd.Client, error) {
+				if len(nodeNames) > 1 {
+					return clusterClient, nil
+				}
+				switch nodeNames[0] {
+				case "cp2":
+					return nil, errors.New("cannot connect")
+				case "cp3":
+					return &etcd.Client{EtcdClient: &fake2.FakeEtcdClient{}, Errors: []string{"some error"}}, nil
+				default:
+					return &etcd.Client{EtcdClient: &fake2.FakeEtcdClient{}}, nil
+				}
+			},
+		},
+	}
+
+	statuses, err := w.EtcdMembersStatus(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(statuses).To(ConsistOf(
+		EtcdMemberStatus{Name: "cp1", Responsive: true, IsLeader: true},
+		EtcdMemberStatus{Name: "cp2", Responsive: false},
+		EtcdMemberStatus{Name: "cp3", Responsive: false},
+		EtcdMemberStatus{Name: "cp4", Responsive: true, Alarms: []etcd.AlarmType{etcd.AlarmNoSpace}},
+	))
+}
+
 type fakeEtcdClientGenerator struct {
 	forNodesClient     *etcd.Client
 	forNodesClientFunc func([]string) (*etcd.Client, error)
@@ -276,6 +276,70 @@ kind: ClusterStatus
 	}
 }
 
+func TestRemoveStaleControlPlaneNodes(t *testing.T) {
+	g := NewWithT(t)
+
+	newNode := func(name string, labels map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	controlPlaneLabels := map[string]string{labelNodeRoleControlPlane: ""}
+	kubeadmConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeadmConfigKey,
+			Namespace: metav1.NamespaceSystem,
+		},
+		Data: map[string]string{
+			clusterStatusKey: `apiEndpoints:
+  new:
+    advertiseAddress: 10.0.0.1
+    bindPort: 6443
+  stale:
+    advertiseAddress: 10.0.0.2
+    bindPort: 6443
+apiVersion: kubeadm.k8s.io/vNbetaM
+kind: ClusterStatus`,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newNode("new", controlPlaneLabels),
+		newNode("stale", controlPlaneLabels),
+		newNode("worker", nil),
+		kubeadmConfig,
+	).Build()
+	w := &Workload{
+		Client: fakeClient,
+	}
+
+	removed, err := w.RemoveStaleControlPlaneNodes(ctx, []string{"new"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(removed).To(ConsistOf("stale"))
+
+	nodes := &corev1.NodeList{}
+	g.Expect(fakeClient.List(ctx, nodes)).To(Succeed())
+	names := []string{}
+	for _, n := range nodes.Items {
+		names = append(names, n.Name)
+	}
+	g.Expect(names).To(ConsistOf("new", "worker"))
+
+	var actualConfig corev1.ConfigMap
+	g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(kubeadmConfig), &actualConfig)).To(Succeed())
+	g.Expect(actualConfig.Data[clusterStatusKey]).To(Equal(`apiEndpoints:
+  new:
+    advertiseAddress: 10.0.0.1
+    bindPort: 6443
+apiVersion: kubeadm.k8s.io/vNbetaM
+kind: ClusterStatus
+`))
+
+	removed, err = w.RemoveStaleControlPlaneNodes(ctx, []string{"new"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(removed).To(BeEmpty())
+}
+
 func TestUpdateKubeletConfigMap(t *testing.T) {
 	kubeletConfig := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
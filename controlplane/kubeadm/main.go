@@ -69,6 +69,7 @@ var (
 	kubeadmControlPlaneConcurrency int
 	syncPeriod                     time.Duration
 	webhookPort                    int
+	managementClusterEndpoint      string
 )
 
 // InitFlags initializes the flags.
@@ -102,6 +103,9 @@ func InitFlags(fs *pflag.FlagSet) {
 
 	fs.IntVar(&webhookPort, "webhook-port", 9443,
 		"Webhook Server port")
+
+	fs.StringVar(&managementClusterEndpoint, "management-cluster-endpoint", "",
+		"The address of the management cluster API server reachable from the workload cluster machines (e.g. https://10.0.0.1:6443). Required for restoring etcd from a snapshot.")
 }
 func main() {
 	rand.Seed(time.Now().UnixNano())
@@ -175,8 +179,9 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 	}
 
 	if err := (&kubeadmcontrolplanecontrollers.KubeadmControlPlaneReconciler{
-		Client:  mgr.GetClient(),
-		Tracker: tracker,
+		Client:                    mgr.GetClient(),
+		Tracker:                   tracker,
+		ManagementClusterEndpoint: managementClusterEndpoint,
 	}).SetupWithManager(ctx, mgr, concurrency(kubeadmControlPlaneConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KubeadmControlPlane")
 		os.Exit(1)
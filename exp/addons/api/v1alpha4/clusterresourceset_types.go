@@ -28,6 +28,10 @@ const (
 
 	// ClusterResourceSetFinalizer is added to the ClusterResourceSet object for additional cleanup logic on deletion.
 	ClusterResourceSetFinalizer = "addons.cluster.x-k8s.io"
+
+	// ClusterResourceSetTemplateAnnotation can be set to "true" on the Secrets and ConfigMaps in the resources of a
+	// ClusterResourceSet, to substitute variables in the format ${VAR} with values from the Cluster the resources are applied to.
+	ClusterResourceSetTemplateAnnotation = "addons.cluster.x-k8s.io/template"
 )
 
 // ANCHOR: ClusterResourceSetSpec
@@ -43,9 +47,29 @@ type ClusterResourceSetSpec struct {
 	Resources []ResourceRef `json:"resources,omitempty"`
 
 	// Strategy is the strategy to be used during applying resources. Defaults to ApplyOnce. This field is immutable.
-	// +kubebuilder:validation:Enum=ApplyOnce
+	// With ApplyOnce, resources are created once and never updated; with Reconcile, resources are applied with
+	// server-side apply every time their data changes, and periodically checked for drift in the remote cluster.
+	// +kubebuilder:validation:Enum=ApplyOnce;Reconcile
 	// +optional
 	Strategy string `json:"strategy,omitempty"`
+
+	// DeletionPolicy defines what happens to the objects applied to a Cluster when the ClusterResourceSet is deleted,
+	// a resource is removed from Resources, or the Cluster no longer matches the ClusterSelector. Defaults to Orphan.
+	// With Orphan, applied objects are left in the Cluster; with Delete, they are deleted from the Cluster.
+	// +kubebuilder:validation:Enum=Orphan;Delete
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// DependsOn is a list of names of ClusterResourceSets in the same namespace, whose resources must be applied
+	// to a Cluster and ready before the resources of this ClusterResourceSet are applied to it.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// WaitForReady makes every resource be applied to a Cluster only after the objects of the previous resources
+	// in Resources are ready: CustomResourceDefinitions must be Established, Deployments Available, and DaemonSets and
+	// StatefulSets rolled out; other objects are ready once applied.
+	// +optional
+	WaitForReady bool `json:"waitForReady,omitempty"`
 }
 
 // ANCHOR_END: ClusterResourceSetSpec
@@ -76,6 +100,22 @@ const (
 	// ClusterResourceSetStrategyApplyOnce is the default strategy a ClusterResourceSet strategy is assigned by
 	// ClusterResourceSet controller after being created if not specified by user.
 	ClusterResourceSetStrategyApplyOnce ClusterResourceSetStrategy = "ApplyOnce"
+
+	// ClusterResourceSetStrategyReconcile reapplies resources to the matching clusters when their data changes,
+	// and repairs objects that are changed or deleted in the remote clusters.
+	ClusterResourceSetStrategyReconcile ClusterResourceSetStrategy = "Reconcile"
+)
+
+// ClusterResourceSetDeletionPolicy is a string representation of a ClusterResourceSet DeletionPolicy.
+type ClusterResourceSetDeletionPolicy string
+
+const (
+	// ClusterResourceSetDeletionPolicyOrphan leaves the applied objects in the Clusters, and it is the default
+	// deletion policy a ClusterResourceSet is assigned if not specified by user.
+	ClusterResourceSetDeletionPolicyOrphan ClusterResourceSetDeletionPolicy = "Orphan"
+
+	// ClusterResourceSetDeletionPolicyDelete deletes the applied objects from the Clusters.
+	ClusterResourceSetDeletionPolicyDelete ClusterResourceSetDeletionPolicy = "Delete"
 )
 
 // SetTypedStrategy sets the Strategy field to the string representation of ClusterResourceSetStrategy.
@@ -46,6 +46,10 @@ func (m *ClusterResourceSet) Default() {
 	if m.Spec.Strategy == "" {
 		m.Spec.Strategy = string(ClusterResourceSetStrategyApplyOnce)
 	}
+	// ClusterResourceSet DeletionPolicy defaults to Orphan.
+	if m.Spec.DeletionPolicy == "" {
+		m.Spec.DeletionPolicy = string(ClusterResourceSetDeletionPolicyOrphan)
+	}
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
@@ -87,6 +91,21 @@ func (m *ClusterResourceSet) validate(old *ClusterResourceSet) error {
 		)
 	}
 
+	// Validate that dependencies are unique, and do not include the ClusterResourceSet itself.
+	dependencies := map[string]bool{}
+	for i, name := range m.Spec.DependsOn {
+		path := field.NewPath("spec", "dependsOn").Index(i)
+		switch {
+		case name == "":
+			allErrs = append(allErrs, field.Required(path, "name must not be empty"))
+		case name == m.Name:
+			allErrs = append(allErrs, field.Invalid(path, name, "a ClusterResourceSet cannot depend on itself"))
+		case dependencies[name]:
+			allErrs = append(allErrs, field.Duplicate(path, name))
+		}
+		dependencies[name] = true
+	}
+
 	if old != nil && old.Spec.Strategy != m.Spec.Strategy {
 		allErrs = append(
 			allErrs,
@@ -31,6 +31,7 @@ func TestClusterResourcesetDefault(t *testing.T) {
 	clusterResourceSet.Default()
 
 	g.Expect(clusterResourceSet.Spec.Strategy).To(Equal(string(ClusterResourceSetStrategyApplyOnce)))
+	g.Expect(clusterResourceSet.Spec.DeletionPolicy).To(Equal(string(ClusterResourceSetDeletionPolicyOrphan)))
 }
 
 func TestClusterResourceSetLabelSelectorAsSelectorValidation(t *testing.T) {
@@ -185,3 +186,52 @@ func TestClusterResourceSetSelectorNotEmptyValidation(t *testing.T) {
 	g.Expect(err).ToNot(BeNil())
 	g.Expect(err.Error()).To(ContainSubstring("selector must not be empty"))
 }
+
+func TestClusterResourceSetDependsOnValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		dependsOn []string
+		expectErr bool
+	}{
+		{
+			name:      "should not return error for valid dependencies",
+			dependsOn: []string{"crs-cni", "crs-csi"},
+			expectErr: false,
+		},
+		{
+			name:      "should return error for empty name",
+			dependsOn: []string{""},
+			expectErr: true,
+		},
+		{
+			name:      "should return error for dependency on itself",
+			dependsOn: []string{"crs-cni", "test-crs"},
+			expectErr: true,
+		},
+		{
+			name:      "should return error for duplicate dependencies",
+			dependsOn: []string{"crs-cni", "crs-cni"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			clusterResourceSet := &ClusterResourceSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-crs"},
+				Spec: ClusterResourceSetSpec{
+					ClusterSelector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"foo": "bar"},
+					},
+					DependsOn: tt.dependsOn,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(clusterResourceSet.ValidateCreate()).NotTo(Succeed())
+				return
+			}
+			g.Expect(clusterResourceSet.ValidateCreate()).To(Succeed())
+		})
+	}
+}
@@ -30,7 +30,8 @@ type ResourceBinding struct {
 	ResourceRef `json:",inline"`
 
 	// Hash is the hash of a resource's data. This can be used to decide if a resource is changed.
-	// For "ApplyOnce" ClusterResourceSet.spec.strategy, this is no-op as that strategy does not act on change.
+	// For "ApplyOnce" ClusterResourceSet.spec.strategy, this is no-op as that strategy does not act on change;
+	// for "Reconcile", resources are applied again when the hash changes.
 	Hash string `json:"hash,omitempty"`
 
 	// LastAppliedTime identifies when this resource was last applied to the cluster.
@@ -39,10 +40,31 @@ type ResourceBinding struct {
 
 	// Applied is to track if a resource is applied to the cluster or not.
 	Applied bool `json:"applied"`
+
+	// AppliedObjects is the list of objects applied to the cluster from the resource.
+	// It is used to delete the objects when the ClusterResourceSet deletionPolicy is Delete.
+	// +optional
+	AppliedObjects []AppliedObjectRef `json:"appliedObjects,omitempty"`
 }
 
 // ANCHOR_END: ResourceBinding
 
+// AppliedObjectRef identifies an object applied to a cluster by a ClusterResourceSet.
+type AppliedObjectRef struct {
+	// APIVersion of the object.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the object.
+	Kind string `json:"kind"`
+
+	// Namespace of the object, empty for cluster-scoped objects.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object.
+	Name string `json:"name"`
+}
+
 // ResourceSetBinding keeps info on all of the resources in a ClusterResourceSet.
 type ResourceSetBinding struct {
 	// ClusterResourceSetName is the name of the ClusterResourceSet that is applied to the owner cluster of the binding.
@@ -64,6 +86,16 @@ func (r *ResourceSetBinding) IsApplied(resourceRef ResourceRef) bool {
 	return false
 }
 
+// GetResource returns the binding of a resource in the ResourceSetBinding, or nil if the resource has never been applied.
+func (r *ResourceSetBinding) GetResource(resourceRef ResourceRef) *ResourceBinding {
+	for i := range r.Resources {
+		if reflect.DeepEqual(r.Resources[i].ResourceRef, resourceRef) {
+			return &r.Resources[i]
+		}
+	}
+	return nil
+}
+
 // SetBinding sets resourceBinding for a resource in resourceSetbinding either by updating the existing one or
 // creating a new one.
 func (r *ResourceSetBinding) SetBinding(resourceBinding ResourceBinding) {
@@ -76,6 +108,16 @@ func (r *ResourceSetBinding) SetBinding(resourceBinding ResourceBinding) {
 	r.Resources = append(r.Resources, resourceBinding)
 }
 
+// DeleteResource removes the binding of a resource from the ResourceSetBinding.
+func (r *ResourceSetBinding) DeleteResource(resourceRef ResourceRef) {
+	for i := range r.Resources {
+		if reflect.DeepEqual(r.Resources[i].ResourceRef, resourceRef) {
+			r.Resources = append(r.Resources[:i], r.Resources[i+1:]...)
+			return
+		}
+	}
+}
+
 // GetOrCreateBinding returns the ResourceSetBinding for a given ClusterResourceSet if exists,
 // otherwise creates one and updates ClusterResourceSet with it.
 func (c *ClusterResourceSetBinding) GetOrCreateBinding(clusterResourceSet *ClusterResourceSet) *ResourceSetBinding {
@@ -168,3 +168,61 @@ func TestSetResourceBinding(t *testing.T) {
 		})
 	}
 }
+
+func TestGetResourceBinding(t *testing.T) {
+	g := NewWithT(t)
+
+	resourceRefApplied := ResourceRef{
+		Name: "applied",
+		Kind: "ConfigMap",
+	}
+	CRSBinding := &ResourceSetBinding{
+		ClusterResourceSetName: "test-clusterResourceSet",
+		Resources: []ResourceBinding{
+			{
+				ResourceRef: resourceRefApplied,
+				Applied:     true,
+				Hash:        "xyz",
+			},
+		},
+	}
+
+	binding := CRSBinding.GetResource(resourceRefApplied)
+	g.Expect(binding).NotTo(BeNil())
+	g.Expect(binding.Hash).To(Equal("xyz"))
+
+	// Changes to the returned binding are reflected in the ResourceSetBinding.
+	binding.Hash = "abc"
+	g.Expect(CRSBinding.Resources[0].Hash).To(Equal("abc"))
+
+	g.Expect(CRSBinding.GetResource(ResourceRef{Name: "applied", Kind: "Secret"})).To(BeNil())
+}
+
+func TestDeleteResourceBinding(t *testing.T) {
+	g := NewWithT(t)
+
+	resourceRefToDelete := ResourceRef{
+		Name: "toDelete",
+		Kind: "ConfigMap",
+	}
+	resourceRefToKeep := ResourceRef{
+		Name: "toKeep",
+		Kind: "ConfigMap",
+	}
+	CRSBinding := &ResourceSetBinding{
+		ClusterResourceSetName: "test-clusterResourceSet",
+		Resources: []ResourceBinding{
+			{ResourceRef: resourceRefToDelete, Applied: true},
+			{ResourceRef: resourceRefToKeep, Applied: true},
+		},
+	}
+
+	CRSBinding.DeleteResource(resourceRefToDelete)
+	g.Expect(CRSBinding.Resources).To(HaveLen(1))
+	g.Expect(CRSBinding.GetResource(resourceRefToDelete)).To(BeNil())
+	g.Expect(CRSBinding.GetResource(resourceRefToKeep)).NotTo(BeNil())
+
+	// Deleting a resource not in the binding is a no-op.
+	CRSBinding.DeleteResource(resourceRefToDelete)
+	g.Expect(CRSBinding.Resources).To(HaveLen(1))
+}
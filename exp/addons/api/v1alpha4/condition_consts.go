@@ -39,4 +39,25 @@ const (
 
 	// WrongSecretType (Severity=Warning) documents at least one of the Secret's type in the resource list is not supported.
 	WrongSecretTypeReason = "WrongSecretType"
+
+	// ProcessingTemplateFailedReason (Severity=Warning) documents at least one of the templated resources could not be
+	// processed for one of the matching clusters, e.g. because a variable has no value.
+	ProcessingTemplateFailedReason = "ProcessingTemplateFailed"
+
+	// WaitingForDependenciesReason (Severity=Info) documents at least one of the resources is not applied yet to one of the
+	// matching clusters, because the ClusterResourceSets it depends on or the previous resources are not ready.
+	WaitingForDependenciesReason = "WaitingForDependencies"
+)
+
+// Conditions and condition Reasons for the HelmChartProxy object
+
+const (
+	// ReleasesReadyCondition documents that the chart of the HelmChartProxy object is deployed to all matching clusters.
+	ReleasesReadyCondition clusterv1.ConditionType = "ReleasesReady"
+
+	// ChartLoadFailedReason (Severity=Warning) documents the chart archive could not be retrieved or loaded.
+	ChartLoadFailedReason = "ChartLoadFailed"
+
+	// ReleaseFailedReason (Severity=Warning) documents the release failed to be rendered or applied to at least one of the matching clusters.
+	ReleaseFailedReason = "ReleaseFailed"
 )
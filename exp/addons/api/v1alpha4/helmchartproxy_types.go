@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+const (
+	// HelmChartProxyDefaultReleaseNamespace is the namespace releases are installed to if not specified by user.
+	HelmChartProxyDefaultReleaseNamespace = "default"
+)
+
+// ANCHOR: HelmChartProxySpec
+
+// HelmChartProxySpec defines the desired state of HelmChartProxy.
+type HelmChartProxySpec struct {
+	// Label selector for Clusters. The Clusters that are
+	// selected by this will be the ones the chart is installed to.
+	// It must match the Cluster labels.
+	ClusterSelector metav1.LabelSelector `json:"clusterSelector"`
+
+	// ReleaseName is the name of the release installed to each Cluster.
+	// +kubebuilder:validation:MinLength=1
+	ReleaseName string `json:"releaseName"`
+
+	// ReleaseNamespace is the namespace the release is installed to; it is created if it does not exist.
+	// Defaults to default.
+	// +optional
+	ReleaseNamespace string `json:"releaseNamespace,omitempty"`
+
+	// Chart is the source of the chart archive.
+	Chart HelmChartSource `json:"chart"`
+
+	// Values is a YAML document with the values used to render the chart, overriding the default values of the chart.
+	// Variables in the format ${VAR} are substituted with values from the Cluster the chart is installed to,
+	// like in templated ClusterResourceSet resources, e.g. ${CLUSTER_NAME}.
+	// +optional
+	Values string `json:"values,omitempty"`
+}
+
+// ANCHOR_END: HelmChartProxySpec
+
+// HelmChartSource defines the source of a chart archive. Exactly one of the sources must be set.
+type HelmChartSource struct {
+	// ConfigMap is a reference to a ConfigMap in the same namespace of the HelmChartProxy,
+	// with the chart archive in its binaryData or data.
+	// +optional
+	ConfigMap *HelmChartArchiveRef `json:"configMap,omitempty"`
+
+	// Secret is a reference to a Secret in the same namespace of the HelmChartProxy, with the chart archive in its data.
+	// +optional
+	Secret *HelmChartArchiveRef `json:"secret,omitempty"`
+
+	// OCI is a reference to a chart stored in an OCI registry.
+	// +optional
+	OCI *HelmChartOCIRef `json:"oci,omitempty"`
+}
+
+// HelmChartArchiveRef is a reference to a chart archive stored in a key of a ConfigMap or Secret,
+// as created by helm package.
+type HelmChartArchiveRef struct {
+	// Name of the ConfigMap or Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key of the chart archive in the ConfigMap or Secret.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// HelmChartOCIRef is a reference to a chart stored in an OCI registry.
+type HelmChartOCIRef struct {
+	// Repository of the chart, e.g. oci://ghcr.io/org/charts/nginx.
+	// +kubebuilder:validation:Pattern=`^oci://`
+	Repository string `json:"repository"`
+
+	// Version of the chart.
+	// +kubebuilder:validation:MinLength=1
+	Version string `json:"version"`
+
+	// CredentialsSecretName is the name of a Secret in the same namespace of the HelmChartProxy, with the
+	// username and password keys used to authenticate to the registry.
+	// +optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+// HelmReleasePhase is a string representation of the status of a release.
+type HelmReleasePhase string
+
+const (
+	// HelmReleasePhaseDeployed is the status of a release whose objects have been applied to the Cluster.
+	HelmReleasePhaseDeployed HelmReleasePhase = "Deployed"
+
+	// HelmReleasePhaseFailed is the status of a release which failed to be rendered or applied to the Cluster.
+	HelmReleasePhaseFailed HelmReleasePhase = "Failed"
+)
+
+// ANCHOR: HelmChartProxyStatus
+
+// HelmChartProxyStatus defines the observed state of HelmChartProxy.
+type HelmChartProxyStatus struct {
+	// Releases is the status of the release in each of the Clusters matching the ClusterSelector.
+	// +optional
+	Releases []HelmReleaseStatus `json:"releases,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently observed HelmChartProxy.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions defines current state of the HelmChartProxy.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// ANCHOR_END: HelmChartProxyStatus
+
+// HelmReleaseStatus is the status of a release in a Cluster.
+type HelmReleaseStatus struct {
+	// ClusterName is the name of the Cluster the release is installed to.
+	ClusterName string `json:"clusterName"`
+
+	// Revision of the release, incremented every time the release is upgraded.
+	// +optional
+	Revision int32 `json:"revision,omitempty"`
+
+	// Status of the release.
+	// +kubebuilder:validation:Enum=Deployed;Failed
+	Status HelmReleasePhase `json:"status"`
+
+	// Chart is the name and version of the chart of the last release, e.g. nginx-1.0.0.
+	// +optional
+	Chart string `json:"chart,omitempty"`
+
+	// Hash is the hash of the chart archive and values of the last release, used to detect changes.
+	// +optional
+	Hash string `json:"hash,omitempty"`
+
+	// LastDeployed is the time the release was last installed or upgraded.
+	// +optional
+	LastDeployed *metav1.Time `json:"lastDeployed,omitempty"`
+
+	// Message is a human readable message with details about the status of the release, e.g. the reason of a failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// GetRelease returns the status of the release in the given Cluster, or nil if there is none.
+func (s *HelmChartProxyStatus) GetRelease(clusterName string) *HelmReleaseStatus {
+	for i := range s.Releases {
+		if s.Releases[i].ClusterName == clusterName {
+			return &s.Releases[i]
+		}
+	}
+	return nil
+}
+
+func (m *HelmChartProxy) GetConditions() clusterv1.Conditions {
+	return m.Status.Conditions
+}
+
+func (m *HelmChartProxy) SetConditions(conditions clusterv1.Conditions) {
+	m.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=helmchartproxies,scope=Namespaced,categories=cluster-api
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Release",type="string",JSONPath=".spec.releaseName",description="Name of the release"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='ReleasesReady')].status",description="Releases are deployed to all matching clusters"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of HelmChartProxy"
+
+// HelmChartProxy is the Schema for the helmchartproxies API.
+type HelmChartProxy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HelmChartProxySpec   `json:"spec,omitempty"`
+	Status HelmChartProxyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HelmChartProxyList contains a list of HelmChartProxy.
+type HelmChartProxyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HelmChartProxy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HelmChartProxy{}, &HelmChartProxyList{})
+}
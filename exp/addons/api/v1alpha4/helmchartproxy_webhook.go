@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// maxReleaseNameLength is the maximum length of release names accepted by Helm.
+const maxReleaseNameLength = 53
+
+func (m *HelmChartProxy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(m).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-addons-cluster-x-k8s-io-v1alpha4-helmchartproxy,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=addons.cluster.x-k8s.io,resources=helmchartproxies,versions=v1alpha4,name=validation.helmchartproxy.addons.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1beta1
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-addons-cluster-x-k8s-io-v1alpha4-helmchartproxy,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=addons.cluster.x-k8s.io,resources=helmchartproxies,versions=v1alpha4,name=default.helmchartproxy.addons.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1beta1
+
+var _ webhook.Defaulter = &HelmChartProxy{}
+var _ webhook.Validator = &HelmChartProxy{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (m *HelmChartProxy) Default() {
+	if m.Spec.ReleaseNamespace == "" {
+		m.Spec.ReleaseNamespace = HelmChartProxyDefaultReleaseNamespace
+	}
+}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (m *HelmChartProxy) ValidateCreate() error {
+	return m.validate(nil)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (m *HelmChartProxy) ValidateUpdate(old runtime.Object) error {
+	oldProxy, ok := old.(*HelmChartProxy)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a HelmChartProxy but got a %T", old))
+	}
+	return m.validate(oldProxy)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (m *HelmChartProxy) ValidateDelete() error {
+	return nil
+}
+
+func (m *HelmChartProxy) validate(old *HelmChartProxy) error {
+	var allErrs field.ErrorList
+
+	// Validate selector parses as Selector
+	selector, err := metav1.LabelSelectorAsSelector(&m.Spec.ClusterSelector)
+	if err != nil {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "clusterSelector"), m.Spec.ClusterSelector, err.Error()),
+		)
+	}
+
+	// Validate that the selector isn't empty as null selectors do not select any objects.
+	if selector != nil && selector.Empty() {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "clusterSelector"), m.Spec.ClusterSelector, "selector must not be empty"),
+		)
+	}
+
+	if errs := validation.IsDNS1123Subdomain(m.Spec.ReleaseName); len(errs) > 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "releaseName"), m.Spec.ReleaseName, strings.Join(errs, "; ")),
+		)
+	} else if len(m.Spec.ReleaseName) > maxReleaseNameLength {
+		allErrs = append(
+			allErrs,
+			field.TooLong(field.NewPath("spec", "releaseName"), m.Spec.ReleaseName, maxReleaseNameLength),
+		)
+	}
+
+	if m.Spec.ReleaseNamespace != "" {
+		if errs := validation.IsDNS1123Label(m.Spec.ReleaseNamespace); len(errs) > 0 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "releaseNamespace"), m.Spec.ReleaseNamespace, strings.Join(errs, "; ")),
+			)
+		}
+	}
+
+	sources := 0
+	if m.Spec.Chart.ConfigMap != nil {
+		sources++
+	}
+	if m.Spec.Chart.Secret != nil {
+		sources++
+	}
+	if m.Spec.Chart.OCI != nil {
+		sources++
+		if !strings.HasPrefix(m.Spec.Chart.OCI.Repository, "oci://") {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "chart", "oci", "repository"), m.Spec.Chart.OCI.Repository, "must start with oci://"),
+			)
+		}
+	}
+	if sources != 1 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "chart"), m.Spec.Chart, "exactly one of configMap, secret or oci must be set"),
+		)
+	}
+
+	// Releases are not uninstalled, so changing their name or namespace would leave the previous release behind.
+	if old != nil && old.Spec.ReleaseName != m.Spec.ReleaseName {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "releaseName"), m.Spec.ReleaseName, "field is immutable"),
+		)
+	}
+
+	if old != nil && old.Spec.ReleaseNamespace != m.Spec.ReleaseNamespace {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "releaseNamespace"), m.Spec.ReleaseNamespace, "field is immutable"),
+		)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("HelmChartProxy").GroupKind(), m.Name, allErrs)
+}
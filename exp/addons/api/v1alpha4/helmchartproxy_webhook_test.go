@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newHelmChartProxy() *HelmChartProxy {
+	return &HelmChartProxy{
+		Spec: HelmChartProxySpec{
+			ClusterSelector:  metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			ReleaseName:      "nginx",
+			ReleaseNamespace: "default",
+			Chart: HelmChartSource{
+				ConfigMap: &HelmChartArchiveRef{Name: "nginx-chart", Key: "nginx-1.0.0.tgz"},
+			},
+		},
+	}
+}
+
+func TestHelmChartProxyDefault(t *testing.T) {
+	g := NewWithT(t)
+	helmChartProxy := &HelmChartProxy{}
+
+	helmChartProxy.Default()
+
+	g.Expect(helmChartProxy.Spec.ReleaseNamespace).To(Equal(HelmChartProxyDefaultReleaseNamespace))
+}
+
+func TestHelmChartProxyValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(m *HelmChartProxy)
+		expectErr bool
+	}{
+		{
+			name:      "should not return error for valid HelmChartProxy",
+			mutate:    func(m *HelmChartProxy) {},
+			expectErr: false,
+		},
+		{
+			name: "should not return error for valid OCI chart source",
+			mutate: func(m *HelmChartProxy) {
+				m.Spec.Chart = HelmChartSource{OCI: &HelmChartOCIRef{Repository: "oci://ghcr.io/charts/nginx", Version: "1.0.0"}}
+			},
+			expectErr: false,
+		},
+		{
+			name: "should return error for invalid selector",
+			mutate: func(m *HelmChartProxy) {
+				m.Spec.ClusterSelector = metav1.LabelSelector{MatchLabels: map[string]string{"-123-foo": "bar"}}
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error for empty selector",
+			mutate: func(m *HelmChartProxy) {
+				m.Spec.ClusterSelector = metav1.LabelSelector{}
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error for missing release name",
+			mutate: func(m *HelmChartProxy) {
+				m.Spec.ReleaseName = ""
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error for too long release name",
+			mutate: func(m *HelmChartProxy) {
+				m.Spec.ReleaseName = strings.Repeat("a", 54)
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error for invalid release namespace",
+			mutate: func(m *HelmChartProxy) {
+				m.Spec.ReleaseNamespace = "Default"
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error without chart source",
+			mutate: func(m *HelmChartProxy) {
+				m.Spec.Chart = HelmChartSource{}
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error with more than one chart source",
+			mutate: func(m *HelmChartProxy) {
+				m.Spec.Chart.Secret = &HelmChartArchiveRef{Name: "nginx-chart", Key: "nginx-1.0.0.tgz"}
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error for invalid OCI repository",
+			mutate: func(m *HelmChartProxy) {
+				m.Spec.Chart = HelmChartSource{OCI: &HelmChartOCIRef{Repository: "ghcr.io/charts/nginx", Version: "1.0.0"}}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			helmChartProxy := newHelmChartProxy()
+			tt.mutate(helmChartProxy)
+
+			if tt.expectErr {
+				g.Expect(helmChartProxy.ValidateCreate()).NotTo(Succeed())
+				g.Expect(helmChartProxy.ValidateUpdate(helmChartProxy)).NotTo(Succeed())
+			} else {
+				g.Expect(helmChartProxy.ValidateCreate()).To(Succeed())
+				g.Expect(helmChartProxy.ValidateUpdate(helmChartProxy)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestHelmChartProxyReleaseImmutable(t *testing.T) {
+	g := NewWithT(t)
+
+	oldHelmChartProxy := newHelmChartProxy()
+
+	newHelmChartProxy := oldHelmChartProxy.DeepCopy()
+	newHelmChartProxy.Spec.Values = "replicas: 3"
+	g.Expect(newHelmChartProxy.ValidateUpdate(oldHelmChartProxy)).To(Succeed())
+
+	newHelmChartProxy = oldHelmChartProxy.DeepCopy()
+	newHelmChartProxy.Spec.ReleaseName = "other"
+	g.Expect(newHelmChartProxy.ValidateUpdate(oldHelmChartProxy)).NotTo(Succeed())
+
+	newHelmChartProxy = oldHelmChartProxy.DeepCopy()
+	newHelmChartProxy.Spec.ReleaseNamespace = "other"
+	g.Expect(newHelmChartProxy.ValidateUpdate(oldHelmChartProxy)).NotTo(Succeed())
+}
+
+func TestHelmChartProxyGetRelease(t *testing.T) {
+	g := NewWithT(t)
+
+	status := &HelmChartProxyStatus{
+		Releases: []HelmReleaseStatus{
+			{ClusterName: "cluster-a", Revision: 1, Status: HelmReleasePhaseDeployed},
+			{ClusterName: "cluster-b", Revision: 2, Status: HelmReleasePhaseFailed},
+		},
+	}
+
+	release := status.GetRelease("cluster-b")
+	g.Expect(release).NotTo(BeNil())
+	g.Expect(release.Revision).To(Equal(int32(2)))
+	g.Expect(status.GetRelease("cluster-c")).To(BeNil())
+}
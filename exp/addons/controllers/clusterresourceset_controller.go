@@ -18,9 +18,8 @@ package controllers
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
-	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -53,6 +52,10 @@ var (
 	ErrSecretTypeNotSupported = errors.New("unsupported secret type")
 )
 
+// DefaultDriftCheckInterval is the default interval at which resources of ClusterResourceSets with the Reconcile
+// strategy are applied again to repair changes made in the remote clusters.
+const DefaultDriftCheckInterval = 5 * time.Minute
+
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=addons.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
@@ -63,6 +66,10 @@ type ClusterResourceSetReconciler struct {
 	Client           client.Client
 	Tracker          *remote.ClusterCacheTracker
 	WatchFilterValue string
+
+	// DriftCheckInterval is the interval at which resources of ClusterResourceSets with the Reconcile strategy
+	// are applied again even if their data did not change. Defaults to DefaultDriftCheckInterval.
+	DriftCheckInterval time.Duration
 }
 
 func (r *ClusterResourceSetReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
@@ -77,7 +84,7 @@ func (r *ClusterResourceSetReconciler) SetupWithManager(ctx context.Context, mgr
 			handler.EnqueueRequestsFromMapFunc(r.resourceToClusterResourceSet),
 			builder.OnlyMetadata,
 			builder.WithPredicates(
-				resourcepredicates.ResourceCreate(ctrl.LoggerFrom(ctx)),
+				resourcepredicates.ResourceCreateOrUpdate(ctrl.LoggerFrom(ctx)),
 			),
 		).
 		Watches(
@@ -85,7 +92,7 @@ func (r *ClusterResourceSetReconciler) SetupWithManager(ctx context.Context, mgr
 			handler.EnqueueRequestsFromMapFunc(r.resourceToClusterResourceSet),
 			builder.OnlyMetadata,
 			builder.WithPredicates(
-				resourcepredicates.AddonsSecretCreate(ctrl.LoggerFrom(ctx)),
+				resourcepredicates.AddonsSecretCreateOrUpdate(ctrl.LoggerFrom(ctx)),
 			),
 		).
 		WithOptions(options).
@@ -145,15 +152,42 @@ func (r *ClusterResourceSetReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return r.reconcileDelete(ctx, clusters, clusterResourceSet)
 	}
 
+	waiting := []string{}
 	for _, cluster := range clusters {
-		if err := r.ApplyClusterResourceSet(ctx, cluster, clusterResourceSet); err != nil {
+		message, err := r.ApplyClusterResourceSet(ctx, cluster, clusterResourceSet)
+		if err != nil {
 			return ctrl.Result{}, err
 		}
+		if message != "" {
+			waiting = append(waiting, fmt.Sprintf("%s: %s", cluster.Name, message))
+		}
+	}
+
+	if err := r.reconcileUnmatchedClusters(ctx, clusters, clusterResourceSet); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Resources waiting for dependencies, or for the objects of previous resources, to be ready are applied in later reconciles.
+	if len(waiting) > 0 {
+		conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.WaitingForDependenciesReason, clusterv1.ConditionSeverityInfo, "%s", strings.Join(waiting, "; "))
+		return ctrl.Result{RequeueAfter: readinessCheckInterval}, nil
+	}
+
+	// Resources applied with the Reconcile strategy are periodically checked for drift in the remote clusters.
+	if clusterResourceSet.Spec.Strategy == string(addonsv1.ClusterResourceSetStrategyReconcile) && len(clusters) > 0 {
+		return ctrl.Result{RequeueAfter: r.driftCheckInterval()}, nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
+func (r *ClusterResourceSetReconciler) driftCheckInterval() time.Duration {
+	if r.DriftCheckInterval > 0 {
+		return r.DriftCheckInterval
+	}
+	return DefaultDriftCheckInterval
+}
+
 // reconcileDelete removes the deleted ClusterResourceSet from all the ClusterResourceSetBindings it is added to.
 func (r *ClusterResourceSetReconciler) reconcileDelete(ctx context.Context, clusters []*clusterv1.Cluster, crs *addonsv1.ClusterResourceSet) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
@@ -172,6 +206,10 @@ func (r *ClusterResourceSetReconciler) reconcileDelete(ctx context.Context, clus
 			return ctrl.Result{}, nil
 		}
 
+		if err := r.deleteAppliedObjects(ctx, cluster, clusterResourceSetBinding, crs); err != nil {
+			return ctrl.Result{}, err
+		}
+
 		// Initialize the patch helper.
 		patchHelper, err := patch.NewHelper(clusterResourceSetBinding, r.Client)
 		if err != nil {
@@ -196,6 +234,103 @@ func (r *ClusterResourceSetReconciler) reconcileDelete(ctx context.Context, clus
 	return ctrl.Result{}, nil
 }
 
+// reconcileUnmatchedClusters deletes the objects applied by a ClusterResourceSet with the Delete deletion policy
+// from the Clusters which no longer match its selector, and removes the ClusterResourceSet from their ClusterResourceSetBindings.
+func (r *ClusterResourceSetReconciler) reconcileUnmatchedClusters(ctx context.Context, clusters []*clusterv1.Cluster, crs *addonsv1.ClusterResourceSet) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if crs.Spec.DeletionPolicy != string(addonsv1.ClusterResourceSetDeletionPolicyDelete) {
+		return nil
+	}
+
+	matched := map[string]bool{}
+	for _, cluster := range clusters {
+		matched[cluster.Name] = true
+	}
+
+	bindingList := &addonsv1.ClusterResourceSetBindingList{}
+	if err := r.Client.List(ctx, bindingList, client.InNamespace(crs.Namespace)); err != nil {
+		return errors.Wrap(err, "failed to list ClusterResourceSetBindings")
+	}
+
+	errList := []error{}
+	for i := range bindingList.Items {
+		clusterResourceSetBinding := &bindingList.Items[i]
+		if matched[clusterResourceSetBinding.Name] || !hasBinding(clusterResourceSetBinding, crs) {
+			continue
+		}
+
+		// ClusterResourceSetBindings have the same name as their Cluster. Objects applied to Clusters being deleted
+		// are deleted together with the Cluster, and the ClusterResourceSetBinding is deleted by its own controller.
+		cluster := &clusterv1.Cluster{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: clusterResourceSetBinding.Namespace, Name: clusterResourceSetBinding.Name}, cluster); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			errList = append(errList, err)
+			continue
+		}
+		if !cluster.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		log.Info("Deleting objects applied to a Cluster that no longer matches the ClusterResourceSet selector", "cluster", cluster.Name)
+		if err := r.deleteAppliedObjects(ctx, cluster, clusterResourceSetBinding, crs); err != nil {
+			errList = append(errList, err)
+			continue
+		}
+
+		patchHelper, err := patch.NewHelper(clusterResourceSetBinding, r.Client)
+		if err != nil {
+			errList = append(errList, err)
+			continue
+		}
+		clusterResourceSetBinding.DeleteBinding(crs)
+		if len(clusterResourceSetBinding.Spec.Bindings) == 0 {
+			if err := r.Client.Delete(ctx, clusterResourceSetBinding); err != nil && !apierrors.IsNotFound(err) {
+				errList = append(errList, errors.Wrapf(err, "failed to delete empty ClusterResourceSetBinding %s", clusterResourceSetBinding.Name))
+			}
+			continue
+		}
+		if err := patchHelper.Patch(ctx, clusterResourceSetBinding); err != nil {
+			errList = append(errList, errors.Wrapf(err, "failed to patch ClusterResourceSetBinding %s", clusterResourceSetBinding.Name))
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// deleteAppliedObjects deletes from a Cluster all the objects applied by a ClusterResourceSet with the Delete deletion policy.
+func (r *ClusterResourceSetReconciler) deleteAppliedObjects(ctx context.Context, cluster *clusterv1.Cluster, clusterResourceSetBinding *addonsv1.ClusterResourceSetBinding, crs *addonsv1.ClusterResourceSet) error {
+	if crs.Spec.DeletionPolicy != string(addonsv1.ClusterResourceSetDeletionPolicyDelete) || !hasBinding(clusterResourceSetBinding, crs) {
+		return nil
+	}
+
+	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get client for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	resourceSetBinding := clusterResourceSetBinding.GetOrCreateBinding(crs)
+	errList := []error{}
+	// Resources are deleted in reverse order of application.
+	for i := len(resourceSetBinding.Resources) - 1; i >= 0; i-- {
+		if err := deleteAppliedObjects(ctx, remoteClient, resourceSetBinding.Resources[i].AppliedObjects); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// hasBinding returns true if the ClusterResourceSet is in the ClusterResourceSetBinding Bindings list.
+func hasBinding(clusterResourceSetBinding *addonsv1.ClusterResourceSetBinding, crs *addonsv1.ClusterResourceSet) bool {
+	for _, binding := range clusterResourceSetBinding.Spec.Bindings {
+		if binding.ClusterResourceSetName == crs.Name {
+			return true
+		}
+	}
+	return false
+}
+
 // getClustersByClusterResourceSetSelector fetches Clusters matched by the ClusterResourceSet's label selector that are in the same namespace as the ClusterResourceSet object.
 func (r *ClusterResourceSetReconciler) getClustersByClusterResourceSetSelector(ctx context.Context, clusterResourceSet *addonsv1.ClusterResourceSet) ([]*clusterv1.Cluster, error) {
 	log := ctrl.LoggerFrom(ctx)
@@ -229,27 +364,32 @@ func (r *ClusterResourceSetReconciler) getClustersByClusterResourceSetSelector(c
 // ApplyClusterResourceSet applies resources in a ClusterResourceSet to a Cluster. Once applied, a record will be added to the
 // cluster's ClusterResourceSetBinding.
 // In ApplyOnce strategy, resources are applied only once to a particular cluster. ClusterResourceSetBinding is used to check if a resource is applied before.
+// In Reconcile strategy, resources are applied with server-side apply when their hash differs from the one in the ClusterResourceSetBinding,
+// or when the drift check interval has elapsed since they were last applied.
 // It applies resources best effort and continue on scenarios like: unsupported resource types, failure during creation, missing resources.
+// Resources are applied only once the ClusterResourceSets listed in DependsOn are applied to the cluster and ready, and,
+// with WaitForReady, once the objects of the previous resources are ready; otherwise, a message describing what the
+// resources are waiting for is returned.
 // TODO: If a resource already exists in the cluster but not applied by ClusterResourceSet, the resource will be updated ?
-func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Context, cluster *clusterv1.Cluster, clusterResourceSet *addonsv1.ClusterResourceSet) error {
+func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Context, cluster *clusterv1.Cluster, clusterResourceSet *addonsv1.ClusterResourceSet) (string, error) {
 	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
 
 	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
 	if err != nil {
 		conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.RemoteClusterClientFailedReason, clusterv1.ConditionSeverityError, err.Error())
-		return err
+		return "", err
 	}
 
 	// Get ClusterResourceSetBinding object for the cluster.
 	clusterResourceSetBinding, err := r.getOrCreateClusterResourceSetBinding(ctx, cluster, clusterResourceSet)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Initialize the patch helper.
 	patchHelper, err := patch.NewHelper(clusterResourceSetBinding, r.Client)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	defer func() {
@@ -261,11 +401,32 @@ func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Conte
 
 	errList := []error{}
 	resourceSetBinding := clusterResourceSetBinding.GetOrCreateBinding(clusterResourceSet)
+	strategy := addonsv1.ClusterResourceSetStrategy(clusterResourceSet.Spec.Strategy)
+
+	// Delete the objects applied from resources which have been removed from the ClusterResourceSet, if the deletion policy is Delete.
+	if clusterResourceSet.Spec.DeletionPolicy == string(addonsv1.ClusterResourceSetDeletionPolicyDelete) {
+		for _, resourceBinding := range append([]addonsv1.ResourceBinding{}, resourceSetBinding.Resources...) {
+			if hasResource(clusterResourceSet, resourceBinding.ResourceRef) {
+				continue
+			}
+			if err := deleteAppliedObjects(ctx, remoteClient, resourceBinding.AppliedObjects); err != nil {
+				log.Error(err, "failed to delete objects applied from a resource removed from the ClusterResourceSet", "Resource kind", resourceBinding.Kind, "Resource name", resourceBinding.Name)
+				errList = append(errList, err)
+				continue
+			}
+			resourceSetBinding.DeleteResource(resourceBinding.ResourceRef)
+		}
+	}
+
+	// Dependencies and previous resources are checked only before actually applying a resource, so resources
+	// already applied are not blocked by them.
+	waitingMessage := ""
+	dependenciesReady := false
 
 	// Iterate all resources and apply them to the cluster and update the resource status in the ClusterResourceSetBinding object.
-	for _, resource := range clusterResourceSet.Spec.Resources {
+	for i, resource := range clusterResourceSet.Spec.Resources {
 		// If resource is already applied successfully and clusterResourceSet mode is "ApplyOnce", continue. (No need to check hash changes here)
-		if resourceSetBinding.IsApplied(resource) {
+		if strategy != addonsv1.ClusterResourceSetStrategyReconcile && resourceSetBinding.IsApplied(resource) {
 			continue
 		}
 
@@ -285,6 +446,61 @@ func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Conte
 			continue
 		}
 
+		dataList, err := normalizeData(unstructuredObj)
+		if err != nil {
+			errList = append(errList, err)
+			continue
+		}
+
+		// Substitute the variables in templated resources with the values from the cluster; the hash is computed
+		// on the processed data, so changes to the cluster are detected as changes to the resource.
+		if isTemplate(unstructuredObj) {
+			if dataList, err = processTemplates(dataList, cluster); err != nil {
+				err = errors.Wrapf(err, "failed to process templated %s %s", resource.Kind, resource.Name)
+				conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ProcessingTemplateFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+				errList = append(errList, err)
+				continue
+			}
+		}
+		hash := computeHash(dataList)
+
+		// If resource is already applied successfully with the same data and clusterResourceSet mode is "Reconcile",
+		// continue until the drift check interval elapses.
+		if strategy == addonsv1.ClusterResourceSetStrategyReconcile && !needsReconcile(resourceSetBinding.GetResource(resource), hash, r.driftCheckInterval(), time.Now()) {
+			continue
+		}
+
+		if !dependenciesReady {
+			message, err := r.checkDependencies(ctx, remoteClient, clusterResourceSetBinding, clusterResourceSet)
+			if err != nil {
+				errList = append(errList, err)
+				break
+			}
+			if message != "" {
+				waitingMessage = message
+				break
+			}
+			dependenciesReady = true
+		}
+
+		if clusterResourceSet.Spec.WaitForReady && i > 0 {
+			message, err := checkResourcesReady(ctx, remoteClient, resourceSetBinding, clusterResourceSet.Spec.Resources[:i])
+			if err != nil {
+				errList = append(errList, err)
+				break
+			}
+			if message != "" {
+				waitingMessage = fmt.Sprintf("waiting for %s %s: %s", resource.Kind, resource.Name, message)
+				break
+			}
+		}
+
+		// Objects applied in previous reconciles are kept track of, so they can be deleted later.
+		var appliedObjects []addonsv1.AppliedObjectRef
+		if previous := resourceSetBinding.GetResource(resource); previous != nil {
+			appliedObjects = previous.AppliedObjects
+		}
+
 		// Set status in ClusterResourceSetBinding in case of early continue due to a failure.
 		// Set only when resource is retrieved successfully.
 		resourceSetBinding.SetBinding(addonsv1.ResourceBinding{
@@ -292,6 +508,7 @@ func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Conte
 			Hash:            "",
 			Applied:         false,
 			LastAppliedTime: &metav1.Time{Time: time.Now().UTC()},
+			AppliedObjects:  appliedObjects,
 		})
 
 		if err := r.patchOwnerRefToResource(ctx, clusterResourceSet, unstructuredObj); err != nil {
@@ -300,44 +517,15 @@ func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Conte
 			errList = append(errList, err)
 		}
 
-		// Since maps are not ordered, we need to order them to get the same hash at each reconcile.
-		keys := make([]string, 0)
-		data, ok := unstructuredObj.UnstructuredContent()["data"]
-		if !ok {
-			errList = append(errList, errors.New("failed to get data field from the resource"))
-			continue
-		}
-
-		unstructuredData := data.(map[string]interface{})
-		for key := range unstructuredData {
-			keys = append(keys, key)
-		}
-		sort.Strings(keys)
-
-		dataList := make([][]byte, 0)
-		for _, key := range keys {
-			val, ok, err := unstructured.NestedString(unstructuredData, key)
-			if !ok || err != nil {
-				errList = append(errList, errors.New("failed to get value field from the resource"))
-				continue
-			}
-
-			byteArr := []byte(val)
-			// If the resource is a Secret, data needs to be decoded.
-			if unstructuredObj.GetKind() == string(addonsv1.SecretClusterResourceSetResourceKind) {
-				byteArr, _ = base64.StdEncoding.DecodeString(val)
-			}
-
-			dataList = append(dataList, byteArr)
-		}
-
 		// Apply all values in the key-value pair of the resource to the cluster.
 		// As there can be multiple key-value pairs in a resource, each value may have multiple objects in it.
 		isSuccessful := true
 		for i := range dataList {
 			data := dataList[i]
 
-			if err := apply(ctx, remoteClient, data); err != nil {
+			applied, err := apply(ctx, remoteClient, data, strategy)
+			appliedObjects = mergeAppliedObjects(appliedObjects, applied)
+			if err != nil {
 				isSuccessful = false
 				log.Error(err, "failed to apply ClusterResourceSet resource", "Resource kind", resource.Kind, "Resource name", resource.Name)
 				conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ApplyFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
@@ -347,18 +535,45 @@ func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Conte
 
 		resourceSetBinding.SetBinding(addonsv1.ResourceBinding{
 			ResourceRef:     resource,
-			Hash:            computeHash(dataList),
+			Hash:            hash,
 			Applied:         isSuccessful,
 			LastAppliedTime: &metav1.Time{Time: time.Now().UTC()},
+			AppliedObjects:  appliedObjects,
 		})
 	}
 	if len(errList) > 0 {
-		return kerrors.NewAggregate(errList)
+		return "", kerrors.NewAggregate(errList)
+	}
+
+	if waitingMessage != "" {
+		return waitingMessage, nil
 	}
 
 	conditions.MarkTrue(clusterResourceSet, addonsv1.ResourcesAppliedCondition)
 
-	return nil
+	return "", nil
+}
+
+// hasResource returns true if the resource is in the ClusterResourceSet Resources list.
+func hasResource(clusterResourceSet *addonsv1.ClusterResourceSet, resourceRef addonsv1.ResourceRef) bool {
+	for _, resource := range clusterResourceSet.Spec.Resources {
+		if resource == resourceRef {
+			return true
+		}
+	}
+	return false
+}
+
+// needsReconcile returns true if a resource must be applied again with the Reconcile strategy, because it was never
+// applied successfully, its data changed since it was last applied, or the drift check interval has elapsed.
+func needsReconcile(binding *addonsv1.ResourceBinding, hash string, interval time.Duration, now time.Time) bool {
+	if binding == nil || !binding.Applied || binding.LastAppliedTime == nil {
+		return true
+	}
+	if binding.Hash != hash {
+		return true
+	}
+	return !now.Before(binding.LastAppliedTime.Add(interval))
 }
 
 // getResource retrieves the requested resource and convert it to unstructured type.
@@ -430,6 +645,15 @@ func (r *ClusterResourceSetReconciler) clusterToClusterResourceSet(o client.Obje
 		return nil
 	}
 
+	// Add the ClusterResourceSets applied to the cluster, so their objects can be deleted if the cluster no longer matches.
+	binding := &addonsv1.ClusterResourceSetBinding{}
+	if err := r.Client.Get(context.TODO(), client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}, binding); err == nil {
+		for _, b := range binding.Spec.Bindings {
+			name := client.ObjectKey{Namespace: cluster.Namespace, Name: b.ClusterResourceSetName}
+			result = append(result, ctrl.Request{NamespacedName: name})
+		}
+	}
+
 	labels := labels.Set(cluster.GetLabels())
 	for i := range resourceList.Items {
 		rs := &resourceList.Items[i]
@@ -29,6 +29,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -403,6 +404,236 @@ metadata:
 		By("Deleting the Cluster")
 		Expect(testEnv.Delete(ctx, testCluster)).To(Succeed())
 	})
+	It("Should update resources when their data changes with the Reconcile strategy", func() {
+		labels := map[string]string{"foo": "bar"}
+		newCMName := fmt.Sprintf("test-configmap-%s", util.RandomString(6))
+		resourceCMName := fmt.Sprintf("resource-configmap-%s", util.RandomString(6))
+		resourceData := func(value string) map[string]string {
+			return map[string]string{
+				"cm": fmt.Sprintf(`metadata:
+ name: %s
+ namespace: default
+kind: ConfigMap
+apiVersion: v1
+data:
+ key: %s`, resourceCMName, value),
+			}
+		}
+
+		By("Creating a ConfigMap with a ConfigMap in its data field")
+		newConfigmap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      newCMName,
+				Namespace: defaultNamespaceName,
+			},
+			Data: resourceData("initial"),
+		}
+		Expect(testEnv.Create(ctx, newConfigmap)).To(Succeed())
+		defer func() {
+			Expect(testEnv.Delete(ctx, newConfigmap)).To(Succeed())
+		}()
+
+		testCluster.SetLabels(labels)
+		Expect(testEnv.Update(ctx, testCluster)).To(Succeed())
+
+		clusterResourceSetInstance := &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterResourceSetName,
+				Namespace: defaultNamespaceName,
+			},
+			Spec: addonsv1.ClusterResourceSetSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: labels,
+				},
+				Resources: []addonsv1.ResourceRef{{Name: newCMName, Kind: "ConfigMap"}},
+				Strategy:  string(addonsv1.ClusterResourceSetStrategyReconcile),
+			},
+		}
+		// Create the ClusterResourceSet.
+		Expect(testEnv.Create(ctx, clusterResourceSetInstance)).To(Succeed())
+
+		resourceCMKey := client.ObjectKey{Namespace: defaultNamespaceName, Name: resourceCMName}
+		resourceCMValue := func() string {
+			cm := &corev1.ConfigMap{}
+			if err := testEnv.Get(ctx, resourceCMKey, cm); err != nil {
+				return ""
+			}
+			return cm.Data["key"]
+		}
+
+		By("Verifying the resource is applied to the cluster")
+		Eventually(resourceCMValue, timeout).Should(Equal("initial"))
+
+		By("Updating the data of the ConfigMap")
+		Expect(testEnv.Get(ctx, client.ObjectKeyFromObject(newConfigmap), newConfigmap)).To(Succeed())
+		newConfigmap.Data = resourceData("updated")
+		Expect(testEnv.Update(ctx, newConfigmap)).To(Succeed())
+
+		By("Verifying the resource is updated in the cluster")
+		Eventually(resourceCMValue, timeout).Should(Equal("updated"))
+
+		Expect(testEnv.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: resourceCMName, Namespace: defaultNamespaceName}})).To(Succeed())
+		Expect(testEnv.Delete(ctx, testCluster)).To(Succeed())
+	})
+	It("Should delete applied objects when a cluster no longer matches a ClusterResourceSet with the Delete deletion policy", func() {
+		labels := map[string]string{"foo": "bar"}
+		newCMName := fmt.Sprintf("test-configmap-%s", util.RandomString(6))
+		resourceCMName := fmt.Sprintf("resource-configmap-%s", util.RandomString(6))
+
+		By("Creating a ConfigMap with a ConfigMap in its data field")
+		newConfigmap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      newCMName,
+				Namespace: defaultNamespaceName,
+			},
+			Data: map[string]string{
+				"cm": fmt.Sprintf(`metadata:
+ name: %s
+ namespace: default
+kind: ConfigMap
+apiVersion: v1`, resourceCMName),
+			},
+		}
+		Expect(testEnv.Create(ctx, newConfigmap)).To(Succeed())
+		defer func() {
+			Expect(testEnv.Delete(ctx, newConfigmap)).To(Succeed())
+		}()
+
+		testCluster.SetLabels(labels)
+		Expect(testEnv.Update(ctx, testCluster)).To(Succeed())
+
+		clusterResourceSetInstance := &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterResourceSetName,
+				Namespace: defaultNamespaceName,
+			},
+			Spec: addonsv1.ClusterResourceSetSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: labels,
+				},
+				Resources:      []addonsv1.ResourceRef{{Name: newCMName, Kind: "ConfigMap"}},
+				DeletionPolicy: string(addonsv1.ClusterResourceSetDeletionPolicyDelete),
+			},
+		}
+		// Create the ClusterResourceSet.
+		Expect(testEnv.Create(ctx, clusterResourceSetInstance)).To(Succeed())
+
+		resourceCMKey := client.ObjectKey{Namespace: defaultNamespaceName, Name: resourceCMName}
+
+		By("Verifying the resource is applied to the cluster and tracked in the ClusterResourceSetBinding")
+		Eventually(func() error {
+			return testEnv.Get(ctx, resourceCMKey, &corev1.ConfigMap{})
+		}, timeout).Should(Succeed())
+		Eventually(func() bool {
+			binding := &addonsv1.ClusterResourceSetBinding{}
+			if err := testEnv.Get(ctx, client.ObjectKey{Namespace: testCluster.Namespace, Name: testCluster.Name}, binding); err != nil {
+				return false
+			}
+			return len(binding.Spec.Bindings) == 1 && len(binding.Spec.Bindings[0].Resources) == 1 &&
+				len(binding.Spec.Bindings[0].Resources[0].AppliedObjects) == 1
+		}, timeout).Should(BeTrue())
+
+		By("Removing the labels from the cluster")
+		testCluster.SetLabels(nil)
+		Expect(testEnv.Update(ctx, testCluster)).To(Succeed())
+
+		By("Verifying the resource is deleted from the cluster")
+		Eventually(func() bool {
+			err := testEnv.Get(ctx, resourceCMKey, &corev1.ConfigMap{})
+			return apierrors.IsNotFound(err)
+		}, timeout).Should(BeTrue())
+
+		Expect(testEnv.Delete(ctx, testCluster)).To(Succeed())
+	})
+	It("Should apply resources only after the ClusterResourceSets they depend on are applied", func() {
+		labels := map[string]string{"foo": "bar"}
+		dependencyName := fmt.Sprintf("clusterresourceset-%s", util.RandomString(6))
+		newCMName := fmt.Sprintf("test-configmap-%s", util.RandomString(6))
+		resourceCMName := fmt.Sprintf("resource-configmap-%s", util.RandomString(6))
+
+		By("Creating a ConfigMap with a ConfigMap in its data field")
+		newConfigmap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      newCMName,
+				Namespace: defaultNamespaceName,
+			},
+			Data: map[string]string{
+				"cm": fmt.Sprintf(`metadata:
+ name: %s
+ namespace: default
+kind: ConfigMap
+apiVersion: v1`, resourceCMName),
+			},
+		}
+		Expect(testEnv.Create(ctx, newConfigmap)).To(Succeed())
+		defer func() {
+			Expect(testEnv.Delete(ctx, newConfigmap)).To(Succeed())
+		}()
+
+		testCluster.SetLabels(labels)
+		Expect(testEnv.Update(ctx, testCluster)).To(Succeed())
+
+		By("Creating a ClusterResourceSet depending on a ClusterResourceSet which does not exist")
+		clusterResourceSetInstance := &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterResourceSetName,
+				Namespace: defaultNamespaceName,
+			},
+			Spec: addonsv1.ClusterResourceSetSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: labels,
+				},
+				Resources: []addonsv1.ResourceRef{{Name: newCMName, Kind: "ConfigMap"}},
+				DependsOn: []string{dependencyName},
+			},
+		}
+		Expect(testEnv.Create(ctx, clusterResourceSetInstance)).To(Succeed())
+
+		crsKey := client.ObjectKey{Namespace: clusterResourceSetInstance.Namespace, Name: clusterResourceSetInstance.Name}
+		resourceCMKey := client.ObjectKey{Namespace: defaultNamespaceName, Name: resourceCMName}
+
+		By("Verifying the ClusterResourceSet is waiting for its dependency")
+		Eventually(func() string {
+			crs := &addonsv1.ClusterResourceSet{}
+			if err := testEnv.Get(ctx, crsKey, crs); err != nil {
+				return ""
+			}
+			return conditions.GetReason(crs, addonsv1.ResourcesAppliedCondition)
+		}, timeout).Should(Equal(addonsv1.WaitingForDependenciesReason))
+		Expect(apierrors.IsNotFound(testEnv.Get(ctx, resourceCMKey, &corev1.ConfigMap{}))).To(BeTrue())
+
+		By("Creating the ClusterResourceSet it depends on")
+		dependency := &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      dependencyName,
+				Namespace: defaultNamespaceName,
+			},
+			Spec: addonsv1.ClusterResourceSetSpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: labels,
+				},
+				Resources: []addonsv1.ResourceRef{{Name: configmapName, Kind: "ConfigMap"}},
+			},
+		}
+		Expect(testEnv.Create(ctx, dependency)).To(Succeed())
+		defer func() {
+			Expect(testEnv.Delete(ctx, dependency)).To(Succeed())
+		}()
+
+		By("Verifying the resource is applied to the cluster")
+		Eventually(func() error {
+			return testEnv.Get(ctx, resourceCMKey, &corev1.ConfigMap{})
+		}, 2*timeout).Should(Succeed())
+		Eventually(func() bool {
+			crs := &addonsv1.ClusterResourceSet{}
+			if err := testEnv.Get(ctx, crsKey, crs); err != nil {
+				return false
+			}
+			return conditions.IsTrue(crs, addonsv1.ResourcesAppliedCondition)
+		}, timeout).Should(BeTrue())
+
+		Expect(testEnv.Delete(ctx, testCluster)).To(Succeed())
+	})
 	It("Should add finalizer after reconcile", func() {
 		dt := metav1.Now()
 		labels := map[string]string{"foo": "bar"}
@@ -21,8 +21,10 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"unicode"
 
 	"github.com/pkg/errors"
@@ -54,10 +56,15 @@ func isJSONList(data []byte) (bool, error) {
 	return bytes.HasPrefix(trim, jsonListPrefix), nil
 }
 
-func apply(ctx context.Context, c client.Client, data []byte) error {
+// clusterResourceSetFieldOwner is the field manager used when applying resources with server-side apply.
+const clusterResourceSetFieldOwner = "cluster-resource-set"
+
+// apply applies the objects in data to the cluster, and returns the objects that have been created or applied.
+// With the ApplyOnce strategy, objects that already exist in the cluster are not returned.
+func apply(ctx context.Context, c client.Client, data []byte, strategy addonsv1.ClusterResourceSetStrategy) ([]addonsv1.AppliedObjectRef, error) {
 	isJSONList, err := isJSONList(data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	objs := []unstructured.Unstructured{}
 	// If it is a json list, convert each list element to an unstructured object.
@@ -75,38 +82,155 @@ func apply(ctx context.Context, c client.Client, data []byte) error {
 		// If it is not a json list, data is either json or yaml format.
 		objs, err = utilyaml.ToUnstructured(data)
 		if err != nil {
-			return errors.Wrapf(err, "failed converting data to unstructured objects")
+			return nil, errors.Wrapf(err, "failed converting data to unstructured objects")
 		}
 	}
 
 	errList := []error{}
+	applied := []addonsv1.AppliedObjectRef{}
 	sortedObjs := utilresource.SortForCreate(objs)
 	for i := range sortedObjs {
-		if err := applyUnstructured(ctx, c, &objs[i]); err != nil {
-			errList = append(errList, err)
+		obj := &sortedObjs[i]
+		switch strategy {
+		case addonsv1.ClusterResourceSetStrategyReconcile:
+			if err := applyUnstructured(ctx, c, obj); err != nil {
+				errList = append(errList, err)
+				continue
+			}
+		default:
+			created, err := createUnstructured(ctx, c, obj)
+			if err != nil {
+				errList = append(errList, err)
+				continue
+			}
+			if !created {
+				continue
+			}
 		}
+		applied = append(applied, appliedObjectRef(obj))
 	}
-	return kerrors.NewAggregate(errList)
+	return applied, kerrors.NewAggregate(errList)
 }
 
-func applyUnstructured(ctx context.Context, c client.Client, obj *unstructured.Unstructured) error {
+// createUnstructured creates the object on the API server, and returns false if the object already exists.
+func createUnstructured(ctx context.Context, c client.Client, obj *unstructured.Unstructured) (bool, error) {
 	// Create the object on the API server.
 	// TODO: Errors are only logged. If needed, exponential backoff or requeuing could be used here for remedying connection glitches etc.
 	if err := c.Create(ctx, obj); err != nil {
 		// The create call is idempotent, so if the object already exists
 		// then do not consider it to be an error.
 		if !apierrors.IsAlreadyExists(err) {
-			return errors.Wrapf(
+			return false, errors.Wrapf(
 				err,
 				"failed to create object %s %s/%s",
 				obj.GroupVersionKind(),
 				obj.GetNamespace(),
 				obj.GetName())
 		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// applyUnstructured applies the object on the API server using server-side apply, so the object is created if
+// it does not exist, and fields changed by other actors are restored to the value in the ClusterResourceSet resource.
+func applyUnstructured(ctx context.Context, c client.Client, obj *unstructured.Unstructured) error {
+	if err := c.Patch(ctx, obj, client.Apply, client.FieldOwner(clusterResourceSetFieldOwner), client.ForceOwnership); err != nil {
+		return errors.Wrapf(
+			err,
+			"failed to apply object %s %s/%s",
+			obj.GroupVersionKind(),
+			obj.GetNamespace(),
+			obj.GetName())
 	}
 	return nil
 }
 
+// appliedObjectRef returns the reference to an object applied to a cluster.
+func appliedObjectRef(obj *unstructured.Unstructured) addonsv1.AppliedObjectRef {
+	return addonsv1.AppliedObjectRef{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// mergeAppliedObjects returns the objects in existing followed by the objects in applied which are not in existing.
+func mergeAppliedObjects(existing, applied []addonsv1.AppliedObjectRef) []addonsv1.AppliedObjectRef {
+	merged := append([]addonsv1.AppliedObjectRef{}, existing...)
+	for _, obj := range applied {
+		found := false
+		for _, e := range existing {
+			if e == obj {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, obj)
+		}
+	}
+	return merged
+}
+
+// deleteAppliedObjects deletes the objects applied to a cluster, in reverse order of creation so e.g. Namespaces
+// and CustomResourceDefinitions are deleted after the objects depending on them. Objects already deleted are ignored.
+func deleteAppliedObjects(ctx context.Context, c client.Client, objs []addonsv1.AppliedObjectRef) error {
+	errList := []error{}
+	for i := len(objs) - 1; i >= 0; i-- {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(objs[i].APIVersion)
+		obj.SetKind(objs[i].Kind)
+		obj.SetNamespace(objs[i].Namespace)
+		obj.SetName(objs[i].Name)
+		if err := c.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			errList = append(errList, errors.Wrapf(
+				err,
+				"failed to delete object %s %s/%s",
+				obj.GroupVersionKind(),
+				obj.GetNamespace(),
+				obj.GetName()))
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// normalizeData returns the values of the data field of a ConfigMap or Secret, sorted by key.
+// Values of Secrets are base64 decoded.
+func normalizeData(resource *unstructured.Unstructured) ([][]byte, error) {
+	// Since maps are not ordered, we need to order them to get the same hash at each reconcile.
+	keys := make([]string, 0)
+	data, ok := resource.UnstructuredContent()["data"]
+	if !ok {
+		return nil, errors.New("failed to get data field from the resource")
+	}
+
+	unstructuredData := data.(map[string]interface{})
+	for key := range unstructuredData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	dataList := make([][]byte, 0)
+	for _, key := range keys {
+		val, ok, err := unstructured.NestedString(unstructuredData, key)
+		if !ok || err != nil {
+			return nil, errors.New("failed to get value field from the resource")
+		}
+
+		byteArr := []byte(val)
+		// If the resource is a Secret, data needs to be decoded.
+		if resource.GetKind() == string(addonsv1.SecretClusterResourceSetResourceKind) {
+			byteArr, _ = base64.StdEncoding.DecodeString(val)
+		}
+
+		dataList = append(dataList, byteArr)
+	}
+
+	return dataList, nil
+}
+
 // getOrCreateClusterResourceSetBinding retrieves ClusterResourceSetBinding resource owned by the cluster or create a new one if not found.
 func (r *ClusterResourceSetReconciler) getOrCreateClusterResourceSetBinding(ctx context.Context, cluster *clusterv1.Cluster, clusterResourceSet *addonsv1.ClusterResourceSet) (*addonsv1.ClusterResourceSetBinding, error) {
 	clusterResourceSetBinding := &addonsv1.ClusterResourceSetBinding{}
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"encoding/base64"
 	"testing"
 	"time"
 
@@ -25,10 +26,12 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -227,3 +230,110 @@ func TestGetConfigMapFromNamespacedName(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeData(t *testing.T) {
+	g := NewWithT(t)
+
+	configMap := &unstructured.Unstructured{}
+	configMap.SetKind(string(addonsv1.ConfigMapClusterResourceSetResourceKind))
+	g.Expect(unstructured.SetNestedStringMap(configMap.Object, map[string]string{"b": "second", "a": "first"}, "data")).To(Succeed())
+
+	dataList, err := normalizeData(configMap)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(dataList).To(Equal([][]byte{[]byte("first"), []byte("second")}))
+
+	secret := &unstructured.Unstructured{}
+	secret.SetKind(string(addonsv1.SecretClusterResourceSetResourceKind))
+	g.Expect(unstructured.SetNestedStringMap(secret.Object, map[string]string{"a": base64.StdEncoding.EncodeToString([]byte("first"))}, "data")).To(Succeed())
+
+	dataList, err = normalizeData(secret)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(dataList).To(Equal([][]byte{[]byte("first")}))
+
+	_, err = normalizeData(&unstructured.Unstructured{Object: map[string]interface{}{}})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestNeedsReconcile(t *testing.T) {
+	now := time.Now()
+	interval := 5 * time.Minute
+
+	tests := []struct {
+		name    string
+		binding *addonsv1.ResourceBinding
+		hash    string
+		want    bool
+	}{
+		{
+			name:    "resource never applied",
+			binding: nil,
+			hash:    "xyz",
+			want:    true,
+		},
+		{
+			name:    "resource failed to apply",
+			binding: &addonsv1.ResourceBinding{Applied: false, Hash: "xyz", LastAppliedTime: &metav1.Time{Time: now}},
+			hash:    "xyz",
+			want:    true,
+		},
+		{
+			name:    "resource data changed",
+			binding: &addonsv1.ResourceBinding{Applied: true, Hash: "xyz", LastAppliedTime: &metav1.Time{Time: now}},
+			hash:    "abc",
+			want:    true,
+		},
+		{
+			name:    "resource applied recently with the same data",
+			binding: &addonsv1.ResourceBinding{Applied: true, Hash: "xyz", LastAppliedTime: &metav1.Time{Time: now.Add(-time.Minute)}},
+			hash:    "xyz",
+			want:    false,
+		},
+		{
+			name:    "drift check interval elapsed",
+			binding: &addonsv1.ResourceBinding{Applied: true, Hash: "xyz", LastAppliedTime: &metav1.Time{Time: now.Add(-interval)}},
+			hash:    "xyz",
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(needsReconcile(tt.binding, tt.hash, interval, now)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestMergeAppliedObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := addonsv1.AppliedObjectRef{APIVersion: "v1", Kind: "Namespace", Name: "ns"}
+	configMap := addonsv1.AppliedObjectRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: "ns", Name: "cm"}
+	secret := addonsv1.AppliedObjectRef{APIVersion: "v1", Kind: "Secret", Namespace: "ns", Name: "secret"}
+
+	g.Expect(mergeAppliedObjects(nil, nil)).To(BeEmpty())
+	g.Expect(mergeAppliedObjects([]addonsv1.AppliedObjectRef{namespace, configMap}, []addonsv1.AppliedObjectRef{configMap, secret})).
+		To(Equal([]addonsv1.AppliedObjectRef{namespace, configMap, secret}))
+}
+
+func TestDeleteAppliedObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap, secret).Build()
+
+	objs := []addonsv1.AppliedObjectRef{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "cm"},
+		// Objects already deleted are ignored.
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "already-deleted"},
+	}
+	g.Expect(deleteAppliedObjects(ctx, c, objs)).To(Succeed())
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(configMap), &corev1.ConfigMap{})).NotTo(Succeed())
+	// Objects not applied by the ClusterResourceSet are not deleted.
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(secret), &corev1.Secret{})).To(Succeed())
+}
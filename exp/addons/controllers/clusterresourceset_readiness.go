@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// readinessCheckInterval is the interval at which ClusterResourceSets waiting for their dependencies, or for
+// the objects of their previous resources, to be ready are reconciled again.
+const readinessCheckInterval = 10 * time.Second
+
+// checkDependencies returns a message describing the first dependency of a ClusterResourceSet which is not applied
+// to the cluster or not ready, or an empty string if all the dependencies are ready.
+func (r *ClusterResourceSetReconciler) checkDependencies(ctx context.Context, c client.Client, clusterResourceSetBinding *addonsv1.ClusterResourceSetBinding, clusterResourceSet *addonsv1.ClusterResourceSet) (string, error) {
+	for _, name := range clusterResourceSet.Spec.DependsOn {
+		dependency := &addonsv1.ClusterResourceSet{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: clusterResourceSet.Namespace, Name: name}, dependency); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Sprintf("waiting for ClusterResourceSet %s to be created", name), nil
+			}
+			return "", errors.Wrapf(err, "failed to get ClusterResourceSet %s", name)
+		}
+
+		resourceSetBinding := getResourceSetBinding(clusterResourceSetBinding, name)
+		if resourceSetBinding == nil {
+			return fmt.Sprintf("waiting for ClusterResourceSet %s to be applied", name), nil
+		}
+		message, err := checkResourcesReady(ctx, c, resourceSetBinding, dependency.Spec.Resources)
+		if err != nil {
+			return "", err
+		}
+		if message != "" {
+			return fmt.Sprintf("waiting for ClusterResourceSet %s: %s", name, message), nil
+		}
+	}
+	return "", nil
+}
+
+// getResourceSetBinding returns the ResourceSetBinding of a ClusterResourceSet, or nil if there is none.
+func getResourceSetBinding(clusterResourceSetBinding *addonsv1.ClusterResourceSetBinding, clusterResourceSetName string) *addonsv1.ResourceSetBinding {
+	for _, binding := range clusterResourceSetBinding.Spec.Bindings {
+		if binding.ClusterResourceSetName == clusterResourceSetName {
+			return binding
+		}
+	}
+	return nil
+}
+
+// checkResourcesReady returns a message describing the first resource which is not applied to the cluster,
+// or whose objects are not ready, or an empty string if the objects of all the resources are ready.
+func checkResourcesReady(ctx context.Context, c client.Client, resourceSetBinding *addonsv1.ResourceSetBinding, resources []addonsv1.ResourceRef) (string, error) {
+	for _, resource := range resources {
+		resourceBinding := resourceSetBinding.GetResource(resource)
+		if resourceBinding == nil || !resourceBinding.Applied {
+			return fmt.Sprintf("%s %s is not applied", resource.Kind, resource.Name), nil
+		}
+		if message, err := checkObjectsReady(ctx, c, resourceBinding.AppliedObjects); err != nil || message != "" {
+			return message, err
+		}
+	}
+	return "", nil
+}
+
+// checkObjectsReady gets the objects from the cluster, and returns a message describing the first object which is not ready,
+// or an empty string if all the objects are ready.
+func checkObjectsReady(ctx context.Context, c client.Client, objs []addonsv1.AppliedObjectRef) (string, error) {
+	for _, ref := range objs {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(ref.APIVersion)
+		obj.SetKind(ref.Kind)
+		if err := c.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Sprintf("%s not found", objectDescription(ref)), nil
+			}
+			return "", errors.Wrapf(err, "failed to get %s", objectDescription(ref))
+		}
+		if ready, reason := isObjectReady(obj); !ready {
+			return fmt.Sprintf("%s is not %s", objectDescription(ref), reason), nil
+		}
+	}
+	return "", nil
+}
+
+// objectDescription returns a description of an object for messages, e.g. Deployment kube-system/coredns.
+func objectDescription(ref addonsv1.AppliedObjectRef) string {
+	if ref.Namespace == "" {
+		return fmt.Sprintf("%s %s", ref.Kind, ref.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+}
+
+// isObjectReady returns true if an object is ready; otherwise, it returns the state the object is expected to reach,
+// e.g. Available. CustomResourceDefinitions must be Established, Deployments Available, and DaemonSets and StatefulSets
+// rolled out; other objects are always ready.
+func isObjectReady(obj *unstructured.Unstructured) (bool, string) {
+	gk := obj.GroupVersionKind().GroupKind()
+	switch gk {
+	case schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:
+		return hasTrueCondition(obj, "Established"), "Established"
+	case schema.GroupKind{Group: "apps", Kind: "Deployment"}:
+		return isObserved(obj) && hasTrueCondition(obj, "Available"), "Available"
+	case schema.GroupKind{Group: "apps", Kind: "DaemonSet"}:
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+		updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedNumberScheduled")
+		available, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberAvailable")
+		return isObserved(obj) && updated >= desired && available >= desired, "rolled out"
+	case schema.GroupKind{Group: "apps", Kind: "StatefulSet"}:
+		replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if !found {
+			replicas = 1
+		}
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		return isObserved(obj) && ready >= replicas, "rolled out"
+	default:
+		return true, ""
+	}
+}
+
+// isObserved returns true if the controller of an object has observed its latest generation.
+func isObserved(obj *unstructured.Unstructured) bool {
+	observedGeneration, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	return observedGeneration >= obj.GetGeneration()
+}
+
+// hasTrueCondition returns true if an object has a condition of the given type with status True.
+func hasTrueCondition(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == conditionType && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsObjectReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		obj       map[string]interface{}
+		wantReady bool
+	}{
+		{
+			name: "CustomResourceDefinition is ready when Established",
+			obj: map[string]interface{}{
+				"apiVersion": "apiextensions.k8s.io/v1",
+				"kind":       "CustomResourceDefinition",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+						map[string]interface{}{"type": "Established", "status": "True"},
+					},
+				},
+			},
+			wantReady: true,
+		},
+		{
+			name: "CustomResourceDefinition is not ready when not Established",
+			obj: map[string]interface{}{
+				"apiVersion": "apiextensions.k8s.io/v1",
+				"kind":       "CustomResourceDefinition",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Established", "status": "False"},
+					},
+				},
+			},
+			wantReady: false,
+		},
+		{
+			name: "Deployment is ready when Available",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"generation": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Available", "status": "True"},
+					},
+				},
+			},
+			wantReady: true,
+		},
+		{
+			name: "Deployment is not ready when its latest generation is not observed",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"generation": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(1),
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Available", "status": "True"},
+					},
+				},
+			},
+			wantReady: false,
+		},
+		{
+			name: "DaemonSet is ready when rolled out",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "DaemonSet",
+				"status": map[string]interface{}{
+					"desiredNumberScheduled": int64(3),
+					"updatedNumberScheduled": int64(3),
+					"numberAvailable":        int64(3),
+				},
+			},
+			wantReady: true,
+		},
+		{
+			name: "DaemonSet is not ready when pods are not available",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "DaemonSet",
+				"status": map[string]interface{}{
+					"desiredNumberScheduled": int64(3),
+					"updatedNumberScheduled": int64(3),
+					"numberAvailable":        int64(2),
+				},
+			},
+			wantReady: false,
+		},
+		{
+			name: "StatefulSet without replicas is ready with one ready replica",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"status": map[string]interface{}{
+					"readyReplicas": int64(1),
+				},
+			},
+			wantReady: true,
+		},
+		{
+			name: "StatefulSet is not ready when replicas are not ready",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"spec":       map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{
+					"readyReplicas": int64(1),
+				},
+			},
+			wantReady: false,
+		},
+		{
+			name: "other objects are always ready",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+			},
+			wantReady: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ready, _ := isObjectReady(&unstructured.Unstructured{Object: tt.obj})
+			g.Expect(ready).To(Equal(tt.wantReady))
+		})
+	}
+}
+
+func TestCheckObjectsReady(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+
+	message, err := checkObjectsReady(context.TODO(), c, []addonsv1.AppliedObjectRef{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "foo"},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(message).To(BeEmpty())
+
+	message, err = checkObjectsReady(context.TODO(), c, []addonsv1.AppliedObjectRef{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "foo"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "bar"},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(message).To(Equal("ConfigMap default/bar not found"))
+}
+
+func TestCheckDependencies(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(addonsv1.AddToScheme(scheme)).To(Succeed())
+
+	dependency := &addonsv1.ClusterResourceSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "dependency", Namespace: "default"},
+		Spec: addonsv1.ClusterResourceSetSpec{
+			Resources: []addonsv1.ResourceRef{{Name: "resource", Kind: "ConfigMap"}},
+		},
+	}
+	dependent := &addonsv1.ClusterResourceSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "dependent", Namespace: "default"},
+		Spec: addonsv1.ClusterResourceSetSpec{
+			DependsOn: []string{"dependency"},
+		},
+	}
+	appliedObject := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "applied", Namespace: "default"}}
+
+	t.Run("waits for a dependency to be created", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &ClusterResourceSetReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+		message, err := r.checkDependencies(context.TODO(), fake.NewClientBuilder().WithScheme(scheme).Build(), &addonsv1.ClusterResourceSetBinding{}, dependent)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(message).To(Equal("waiting for ClusterResourceSet dependency to be created"))
+	})
+
+	t.Run("waits for a dependency to be applied", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &ClusterResourceSetReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(dependency).Build()}
+		message, err := r.checkDependencies(context.TODO(), fake.NewClientBuilder().WithScheme(scheme).Build(), &addonsv1.ClusterResourceSetBinding{}, dependent)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(message).To(Equal("waiting for ClusterResourceSet dependency to be applied"))
+	})
+
+	binding := &addonsv1.ClusterResourceSetBinding{
+		Spec: addonsv1.ClusterResourceSetBindingSpec{
+			Bindings: []*addonsv1.ResourceSetBinding{
+				{
+					ClusterResourceSetName: "dependency",
+					Resources: []addonsv1.ResourceBinding{
+						{
+							ResourceRef: addonsv1.ResourceRef{Name: "resource", Kind: "ConfigMap"},
+							Applied:     true,
+							AppliedObjects: []addonsv1.AppliedObjectRef{
+								{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "applied"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("waits for the objects of a dependency to exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &ClusterResourceSetReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(dependency).Build()}
+		message, err := r.checkDependencies(context.TODO(), fake.NewClientBuilder().WithScheme(scheme).Build(), binding, dependent)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(message).To(Equal("waiting for ClusterResourceSet dependency: ConfigMap default/applied not found"))
+	})
+
+	t.Run("returns no message when dependencies are ready", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &ClusterResourceSetReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(dependency).Build()}
+		message, err := r.checkDependencies(context.TODO(), fake.NewClientBuilder().WithScheme(scheme).WithObjects(appliedObject).Build(), binding, dependent)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(message).To(BeEmpty())
+	})
+}
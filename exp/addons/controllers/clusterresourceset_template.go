@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/drone/envsubst"
+	"github.com/drone/envsubst/parse"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+)
+
+// invalidVariableCharsRegEx matches the characters that are not allowed in variable names.
+var invalidVariableCharsRegEx = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// isTemplate returns true if the resource is marked as a template using the ClusterResourceSetTemplateAnnotation.
+func isTemplate(resource *unstructured.Unstructured) bool {
+	return resource.GetAnnotations()[addonsv1.ClusterResourceSetTemplateAnnotation] == "true"
+}
+
+// clusterVariables returns the variables which can be used in templated resources applied to a Cluster.
+// Label and annotation keys are converted to upper case, and characters not allowed in variable names are
+// replaced with underscores, e.g. the value of the label "example.com/cni" is available as CLUSTER_LABEL_EXAMPLE_COM_CNI.
+func clusterVariables(cluster *clusterv1.Cluster) map[string]string {
+	variables := map[string]string{
+		"CLUSTER_NAME":      cluster.Name,
+		"CLUSTER_NAMESPACE": cluster.Namespace,
+	}
+
+	if network := cluster.Spec.ClusterNetwork; network != nil {
+		if network.Pods != nil && len(network.Pods.CIDRBlocks) > 0 {
+			variables["CLUSTER_POD_CIDR"] = network.Pods.CIDRBlocks[0]
+			variables["CLUSTER_POD_CIDR_BLOCKS"] = strings.Join(network.Pods.CIDRBlocks, ",")
+		}
+		if network.Services != nil && len(network.Services.CIDRBlocks) > 0 {
+			variables["CLUSTER_SERVICE_CIDR"] = network.Services.CIDRBlocks[0]
+			variables["CLUSTER_SERVICE_CIDR_BLOCKS"] = strings.Join(network.Services.CIDRBlocks, ",")
+		}
+		if network.ServiceDomain != "" {
+			variables["CLUSTER_SERVICE_DOMAIN"] = network.ServiceDomain
+		}
+	}
+
+	for k, v := range cluster.GetLabels() {
+		variables[variableName("CLUSTER_LABEL_", k)] = v
+	}
+	for k, v := range cluster.GetAnnotations() {
+		variables[variableName("CLUSTER_ANNOTATION_", k)] = v
+	}
+	return variables
+}
+
+// variableName returns the name of the variable for a label or annotation key.
+func variableName(prefix, key string) string {
+	return prefix + invalidVariableCharsRegEx.ReplaceAllString(strings.ToUpper(key), "_")
+}
+
+// processTemplate substitutes the variables in the data of a templated resource, using the same syntax as the
+// clusterctl simple processor: ${VAR} and ${VAR:=default}. An error is returned if a variable without a default has no value.
+func processTemplate(data []byte, variables map[string]string) ([]byte, error) {
+	tree, err := parse.Parse(string(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse template")
+	}
+
+	missing := map[string]bool{}
+	findMissingVariables(tree.Root, variables, missing)
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, errors.Errorf("value for variables [%s] is not set", strings.Join(names, ", "))
+	}
+
+	processed, err := envsubst.Eval(string(data), func(name string) string {
+		return variables[name]
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to process template")
+	}
+	return []byte(processed), nil
+}
+
+// findMissingVariables walks the parsed template, and adds to missing the variables without a value or a default.
+func findMissingVariables(node parse.Node, variables map[string]string, missing map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, child := range n.Nodes {
+			findMissingVariables(child, variables, missing)
+		}
+	case *parse.FuncNode:
+		// A function with arguments, e.g. ${VAR:=default}, has a default value.
+		if _, ok := variables[n.Param]; !ok && len(n.Args) == 0 {
+			missing[n.Param] = true
+		}
+	}
+}
+
+// processTemplates substitutes the variables in all the data of a templated resource with the values from the Cluster.
+func processTemplates(dataList [][]byte, cluster *clusterv1.Cluster) ([][]byte, error) {
+	variables := clusterVariables(cluster)
+	processed := make([][]byte, 0, len(dataList))
+	for i := range dataList {
+		data, err := processTemplate(dataList[i], variables)
+		if err != nil {
+			return nil, err
+		}
+		processed = append(processed, data)
+	}
+	return processed, nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+)
+
+func TestIsTemplate(t *testing.T) {
+	g := NewWithT(t)
+
+	resource := &unstructured.Unstructured{}
+	g.Expect(isTemplate(resource)).To(BeFalse())
+
+	resource.SetAnnotations(map[string]string{addonsv1.ClusterResourceSetTemplateAnnotation: "false"})
+	g.Expect(isTemplate(resource)).To(BeFalse())
+
+	resource.SetAnnotations(map[string]string{addonsv1.ClusterResourceSetTemplateAnnotation: "true"})
+	g.Expect(isTemplate(resource)).To(BeTrue())
+}
+
+func TestClusterVariables(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-cluster",
+			Namespace:   "test-namespace",
+			Labels:      map[string]string{"cni": "calico", "example.com/region": "eu-west"},
+			Annotations: map[string]string{"owner": "team-a"},
+		},
+		Spec: clusterv1.ClusterSpec{
+			ClusterNetwork: &clusterv1.ClusterNetwork{
+				Pods:          &clusterv1.NetworkRanges{CIDRBlocks: []string{"192.168.0.0/16", "fd00::/48"}},
+				Services:      &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.96.0.0/12"}},
+				ServiceDomain: "cluster.local",
+			},
+		},
+	}
+
+	g.Expect(clusterVariables(cluster)).To(Equal(map[string]string{
+		"CLUSTER_NAME":                     "test-cluster",
+		"CLUSTER_NAMESPACE":                "test-namespace",
+		"CLUSTER_POD_CIDR":                 "192.168.0.0/16",
+		"CLUSTER_POD_CIDR_BLOCKS":          "192.168.0.0/16,fd00::/48",
+		"CLUSTER_SERVICE_CIDR":             "10.96.0.0/12",
+		"CLUSTER_SERVICE_CIDR_BLOCKS":      "10.96.0.0/12",
+		"CLUSTER_SERVICE_DOMAIN":           "cluster.local",
+		"CLUSTER_LABEL_CNI":                "calico",
+		"CLUSTER_LABEL_EXAMPLE_COM_REGION": "eu-west",
+		"CLUSTER_ANNOTATION_OWNER":         "team-a",
+	}))
+
+	// Network variables are not set if the cluster has no cluster network.
+	g.Expect(clusterVariables(&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"}})).
+		NotTo(HaveKey("CLUSTER_POD_CIDR"))
+}
+
+func TestProcessTemplates(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "test-namespace",
+		},
+		Spec: clusterv1.ClusterSpec{
+			ClusterNetwork: &clusterv1.ClusterNetwork{
+				Pods: &clusterv1.NetworkRanges{CIDRBlocks: []string{"192.168.0.0/16"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		data      string
+		want      string
+		expectErr bool
+	}{
+		{
+			name: "substitutes cluster variables",
+			data: "cidr: ${CLUSTER_POD_CIDR}\nname: ${CLUSTER_NAME}",
+			want: "cidr: 192.168.0.0/16\nname: test-cluster",
+		},
+		{
+			name: "uses default values for variables without a value",
+			data: "mtu: ${CLUSTER_LABEL_MTU:=1440}",
+			want: "mtu: 1440",
+		},
+		{
+			name:      "fails for variables without a value or a default",
+			data:      "mtu: ${CLUSTER_LABEL_MTU}",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := processTemplates([][]byte{[]byte(tt.data)}, cluster)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal([][]byte{[]byte(tt.want)}))
+		})
+	}
+}
@@ -0,0 +1,485 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/exp/addons/helm"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// helmReleaseTimeout is the time to wait for each of the hooks of a chart to complete when installing or upgrading a release.
+	helmReleaseTimeout = 5 * time.Minute
+
+	// maxCachedChartArchivesSize is the maximum total size of the chart archives pulled from OCI registries kept in memory.
+	maxCachedChartArchivesSize = 64 * 1024 * 1024
+)
+
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=addons.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=addons.cluster.x-k8s.io,resources=helmchartproxies/status,verbs=get;update;patch
+
+// HelmChartProxyReconciler reconciles a HelmChartProxy object, installing or upgrading a release of its chart
+// in each of the Clusters matching its selector.
+type HelmChartProxyReconciler struct {
+	Client           client.Client
+	Tracker          *remote.ClusterCacheTracker
+	WatchFilterValue string
+
+	// OCIClient is used to pull charts from OCI registries. Defaults to a client using http.DefaultClient.
+	OCIClient *helm.OCIClient
+
+	// ociArchives caches the chart archives pulled from OCI registries, given that published chart versions
+	// are not expected to change.
+	ociArchives chartArchiveCache
+}
+
+func (r *HelmChartProxyReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&addonsv1.HelmChartProxy{}).
+		Watches(
+			&source.Kind{Type: &clusterv1.Cluster{}},
+			handler.EnqueueRequestsFromMapFunc(r.clusterToHelmChartProxy),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.configMapToHelmChartProxy),
+			builder.OnlyMetadata,
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.secretToHelmChartProxy),
+			builder.OnlyMetadata,
+		).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Complete(r)
+
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	return nil
+}
+
+func (r *HelmChartProxyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Fetch the HelmChartProxy instance.
+	helmChartProxy := &addonsv1.HelmChartProxy{}
+	if err := r.Client.Get(ctx, req.NamespacedName, helmChartProxy); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Object not found, return.
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return ctrl.Result{}, err
+	}
+
+	// Releases are not uninstalled when a HelmChartProxy is deleted, so there is nothing to clean up.
+	if !helmChartProxy.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper.
+	patchHelper, err := patch.NewHelper(helmChartProxy, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		// Always attempt to Patch the HelmChartProxy object and status after each reconciliation.
+		if err := patchHelper.Patch(ctx, helmChartProxy, patch.WithStatusObservedGeneration{}); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	clusters, err := r.getClustersByHelmChartProxySelector(ctx, helmChartProxy)
+	if err != nil {
+		log.Error(err, "Failed fetching clusters that matches HelmChartProxy labels", "HelmChartProxy", helmChartProxy.Name)
+		conditions.MarkFalse(helmChartProxy, addonsv1.ReleasesReadyCondition, addonsv1.ClusterMatchFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	// Drop the status of the releases in Clusters which no longer match the selector; releases are not uninstalled.
+	matched := map[string]bool{}
+	for _, cluster := range clusters {
+		matched[cluster.Name] = true
+	}
+	releases := []addonsv1.HelmReleaseStatus{}
+	for _, release := range helmChartProxy.Status.Releases {
+		if matched[release.ClusterName] {
+			releases = append(releases, release)
+		}
+	}
+	helmChartProxy.Status.Releases = releases
+
+	if len(clusters) == 0 {
+		conditions.MarkTrue(helmChartProxy, addonsv1.ReleasesReadyCondition)
+		return ctrl.Result{}, nil
+	}
+
+	archive, err := r.getChartArchive(ctx, helmChartProxy)
+	if err != nil {
+		conditions.MarkFalse(helmChartProxy, addonsv1.ReleasesReadyCondition, addonsv1.ChartLoadFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+	chart, err := helm.LoadArchive(archive)
+	if err != nil {
+		err = errors.Wrap(err, "failed to load chart")
+		conditions.MarkFalse(helmChartProxy, addonsv1.ReleasesReadyCondition, addonsv1.ChartLoadFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		// Retrying does not help until the chart is changed, which triggers a new reconcile.
+		return ctrl.Result{}, nil
+	}
+
+	errList := []error{}
+	for _, cluster := range clusters {
+		if err := r.reconcileRelease(ctx, cluster, helmChartProxy, archive, chart); err != nil {
+			log.Error(err, "failed to install release", "cluster", cluster.Name)
+			errList = append(errList, errors.Wrapf(err, "failed to install release to Cluster %s", cluster.Name))
+		}
+	}
+
+	sort.Slice(helmChartProxy.Status.Releases, func(i, j int) bool {
+		return helmChartProxy.Status.Releases[i].ClusterName < helmChartProxy.Status.Releases[j].ClusterName
+	})
+
+	if len(errList) > 0 {
+		err := kerrors.NewAggregate(errList)
+		conditions.MarkFalse(helmChartProxy, addonsv1.ReleasesReadyCondition, addonsv1.ReleaseFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	conditions.MarkTrue(helmChartProxy, addonsv1.ReleasesReadyCondition)
+	return ctrl.Result{}, nil
+}
+
+// reconcileRelease installs or upgrades the release of a HelmChartProxy in a Cluster, and records its status.
+// A new revision is only installed if the chart or the values have changed since the last successful release.
+func (r *HelmChartProxyReconciler) reconcileRelease(ctx context.Context, cluster *clusterv1.Cluster, helmChartProxy *addonsv1.HelmChartProxy, archive []byte, chart *helmchart.Chart) error {
+	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
+
+	release := helmChartProxy.Status.GetRelease(cluster.Name)
+	if release == nil {
+		helmChartProxy.Status.Releases = append(helmChartProxy.Status.Releases, addonsv1.HelmReleaseStatus{ClusterName: cluster.Name})
+		release = &helmChartProxy.Status.Releases[len(helmChartProxy.Status.Releases)-1]
+	}
+
+	fail := func(err error) error {
+		release.Status = addonsv1.HelmReleasePhaseFailed
+		release.Message = err.Error()
+		return err
+	}
+
+	// Substitute the variables in the values with the values from the cluster.
+	values := map[string]interface{}{}
+	processedValues := []byte{}
+	if helmChartProxy.Spec.Values != "" {
+		var err error
+		if processedValues, err = processTemplate([]byte(helmChartProxy.Spec.Values), clusterVariables(cluster)); err != nil {
+			return fail(errors.Wrap(err, "failed to process values"))
+		}
+		if err := yaml.Unmarshal(processedValues, &values); err != nil {
+			return fail(errors.Wrap(err, "failed to parse values"))
+		}
+	}
+
+	hash := computeHash([][]byte{
+		archive,
+		processedValues,
+		[]byte(helmChartProxy.Spec.ReleaseName),
+		[]byte(releaseNamespace(helmChartProxy)),
+	})
+	if release.Status == addonsv1.HelmReleasePhaseDeployed && release.Hash == hash {
+		return nil
+	}
+
+	release.Hash = hash
+	release.Chart = fmt.Sprintf("%s-%s", chart.Metadata.Name, chart.Metadata.Version)
+
+	config, err := r.Tracker.GetRESTConfig(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return fail(errors.Wrap(err, "failed to get REST config for Cluster"))
+	}
+
+	log.Info("Installing release", "release", helmChartProxy.Spec.ReleaseName)
+	rel, err := helm.InstallOrUpgrade(ctx, config, helm.InstallOrUpgradeInput{
+		ReleaseName: helmChartProxy.Spec.ReleaseName,
+		Namespace:   releaseNamespace(helmChartProxy),
+		Chart:       chart,
+		Values:      values,
+		Timeout:     helmReleaseTimeout,
+	})
+	// Like Helm, every install or upgrade attempt uses a new revision, also if it fails.
+	if rel != nil {
+		release.Revision = int32(rel.Version)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	release.Status = addonsv1.HelmReleasePhaseDeployed
+	release.Message = ""
+	release.LastDeployed = &metav1.Time{Time: rel.Info.LastDeployed.Time.UTC()}
+	return nil
+}
+
+// releaseNamespace returns the namespace the release of a HelmChartProxy is installed to.
+func releaseNamespace(helmChartProxy *addonsv1.HelmChartProxy) string {
+	if helmChartProxy.Spec.ReleaseNamespace == "" {
+		return addonsv1.HelmChartProxyDefaultReleaseNamespace
+	}
+	return helmChartProxy.Spec.ReleaseNamespace
+}
+
+// getChartArchive returns the chart archive of a HelmChartProxy, from a ConfigMap, a Secret or an OCI registry.
+func (r *HelmChartProxyReconciler) getChartArchive(ctx context.Context, helmChartProxy *addonsv1.HelmChartProxy) ([]byte, error) {
+	source := helmChartProxy.Spec.Chart
+	switch {
+	case source.ConfigMap != nil:
+		configMap, err := getConfigMap(ctx, r.Client, client.ObjectKey{Namespace: helmChartProxy.Namespace, Name: source.ConfigMap.Name})
+		if err != nil {
+			return nil, err
+		}
+		if data, ok := configMap.BinaryData[source.ConfigMap.Key]; ok {
+			return data, nil
+		}
+		if data, ok := configMap.Data[source.ConfigMap.Key]; ok {
+			return []byte(data), nil
+		}
+		return nil, errors.Errorf("key %s not found in ConfigMap %s", source.ConfigMap.Key, source.ConfigMap.Name)
+	case source.Secret != nil:
+		secret, err := getSecret(ctx, r.Client, client.ObjectKey{Namespace: helmChartProxy.Namespace, Name: source.Secret.Name})
+		if err != nil {
+			return nil, err
+		}
+		if data, ok := secret.Data[source.Secret.Key]; ok {
+			return data, nil
+		}
+		return nil, errors.Errorf("key %s not found in Secret %s", source.Secret.Key, source.Secret.Name)
+	case source.OCI != nil:
+		return r.pullChartArchive(ctx, helmChartProxy.Namespace, source.OCI)
+	default:
+		return nil, errors.New("chart source is not set")
+	}
+}
+
+// pullChartArchive pulls a chart archive from an OCI registry, or returns it from the cache if it was pulled before.
+func (r *HelmChartProxyReconciler) pullChartArchive(ctx context.Context, namespace string, ref *addonsv1.HelmChartOCIRef) ([]byte, error) {
+	// Archives are only shared by HelmChartProxies using the same credentials, so a private chart is never returned
+	// to a HelmChartProxy in another namespace, or without access to the registry.
+	key := fmt.Sprintf("%s/%s/%s@%s", namespace, ref.CredentialsSecretName, ref.Repository, ref.Version)
+	if archive, ok := r.ociArchives.get(key); ok {
+		return archive, nil
+	}
+
+	var credentials *helm.Credentials
+	if ref.CredentialsSecretName != "" {
+		secret, err := getSecret(ctx, r.Client, client.ObjectKey{Namespace: namespace, Name: ref.CredentialsSecretName})
+		if err != nil {
+			return nil, err
+		}
+		credentials = &helm.Credentials{
+			Username: string(secret.Data["username"]),
+			Password: string(secret.Data["password"]),
+		}
+	}
+
+	ociClient := r.OCIClient
+	if ociClient == nil {
+		ociClient = &helm.OCIClient{}
+	}
+	archive, err := ociClient.Pull(ctx, ref.Repository, ref.Version, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	r.ociArchives.add(key, archive, maxCachedChartArchivesSize)
+	return archive, nil
+}
+
+// chartArchiveCache is a cache of chart archives bounded by their total size, evicting the oldest archives first.
+type chartArchiveCache struct {
+	lock     sync.RWMutex
+	archives map[string][]byte
+	keys     []string
+	size     int
+}
+
+func (c *chartArchiveCache) get(key string) ([]byte, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	archive, ok := c.archives[key]
+	return archive, ok
+}
+
+// add adds an archive to the cache, evicting the oldest archives until the total size is at most maxSize.
+// Archives larger than maxSize are not cached.
+func (c *chartArchiveCache) add(key string, archive []byte, maxSize int) {
+	if len(archive) > maxSize {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.archives == nil {
+		c.archives = map[string][]byte{}
+	}
+	if _, ok := c.archives[key]; ok {
+		return
+	}
+	for len(c.keys) > 0 && c.size+len(archive) > maxSize {
+		c.size -= len(c.archives[c.keys[0]])
+		delete(c.archives, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+	c.archives[key] = archive
+	c.keys = append(c.keys, key)
+	c.size += len(archive)
+}
+
+// getClustersByHelmChartProxySelector fetches Clusters matched by the HelmChartProxy's label selector that are in the same namespace as the HelmChartProxy object.
+func (r *HelmChartProxyReconciler) getClustersByHelmChartProxySelector(ctx context.Context, helmChartProxy *addonsv1.HelmChartProxy) ([]*clusterv1.Cluster, error) {
+	clusterList := &clusterv1.ClusterList{}
+	selector, err := metav1.LabelSelectorAsSelector(&helmChartProxy.Spec.ClusterSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to convert selector")
+	}
+
+	// If a HelmChartProxy has a nil or empty selector, it should match nothing, not everything.
+	if selector.Empty() {
+		return nil, nil
+	}
+
+	if err := r.Client.List(ctx, clusterList, client.InNamespace(helmChartProxy.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters")
+	}
+
+	clusters := []*clusterv1.Cluster{}
+	for i := range clusterList.Items {
+		c := &clusterList.Items[i]
+		if c.DeletionTimestamp.IsZero() {
+			clusters = append(clusters, c)
+		}
+	}
+	return clusters, nil
+}
+
+// clusterToHelmChartProxy is mapper function that maps clusters to the HelmChartProxies matching them,
+// or with a release in them.
+func (r *HelmChartProxyReconciler) clusterToHelmChartProxy(o client.Object) []ctrl.Request {
+	result := []ctrl.Request{}
+
+	cluster, ok := o.(*clusterv1.Cluster)
+	if !ok {
+		panic(fmt.Sprintf("Expected a Cluster but got a %T", o))
+	}
+
+	helmChartProxyList := &addonsv1.HelmChartProxyList{}
+	if err := r.Client.List(context.TODO(), helmChartProxyList, client.InNamespace(cluster.Namespace)); err != nil {
+		return nil
+	}
+
+	labels := labels.Set(cluster.GetLabels())
+	for i := range helmChartProxyList.Items {
+		hcp := &helmChartProxyList.Items[i]
+		name := client.ObjectKey{Namespace: hcp.Namespace, Name: hcp.Name}
+
+		// Add the HelmChartProxies with a release in the cluster, so the release status is dropped if the cluster no longer matches.
+		if hcp.Status.GetRelease(cluster.Name) != nil {
+			result = append(result, ctrl.Request{NamespacedName: name})
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(&hcp.Spec.ClusterSelector)
+		if err != nil {
+			continue
+		}
+
+		// If a HelmChartProxy has a nil or empty selector, it should match nothing, not everything.
+		if selector.Empty() || !selector.Matches(labels) {
+			continue
+		}
+
+		result = append(result, ctrl.Request{NamespacedName: name})
+	}
+	return result
+}
+
+// configMapToHelmChartProxy is mapper function that maps ConfigMaps to the HelmChartProxies using them as chart source.
+func (r *HelmChartProxyReconciler) configMapToHelmChartProxy(o client.Object) []ctrl.Request {
+	return r.sourceToHelmChartProxy(o, func(hcp *addonsv1.HelmChartProxy) bool {
+		return hcp.Spec.Chart.ConfigMap != nil && hcp.Spec.Chart.ConfigMap.Name == o.GetName()
+	})
+}
+
+// secretToHelmChartProxy is mapper function that maps Secrets to the HelmChartProxies using them as chart source,
+// or as registry credentials.
+func (r *HelmChartProxyReconciler) secretToHelmChartProxy(o client.Object) []ctrl.Request {
+	return r.sourceToHelmChartProxy(o, func(hcp *addonsv1.HelmChartProxy) bool {
+		if hcp.Spec.Chart.Secret != nil && hcp.Spec.Chart.Secret.Name == o.GetName() {
+			return true
+		}
+		return hcp.Spec.Chart.OCI != nil && hcp.Spec.Chart.OCI.CredentialsSecretName == o.GetName()
+	})
+}
+
+func (r *HelmChartProxyReconciler) sourceToHelmChartProxy(o client.Object, uses func(*addonsv1.HelmChartProxy) bool) []ctrl.Request {
+	result := []ctrl.Request{}
+
+	helmChartProxyList := &addonsv1.HelmChartProxyList{}
+	if err := r.Client.List(context.TODO(), helmChartProxyList, client.InNamespace(o.GetNamespace())); err != nil {
+		return nil
+	}
+	for i := range helmChartProxyList.Items {
+		hcp := &helmChartProxyList.Items[i]
+		if uses(hcp) {
+			name := client.ObjectKey{Namespace: hcp.Namespace, Name: hcp.Name}
+			result = append(result, ctrl.Request{NamespacedName: name})
+		}
+	}
+	return result
+}
@@ -0,0 +1,495 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/exp/addons/helm"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestChartArchive returns the archive of a chart with a ConfigMap, named after the release,
+// with the greeting value in its data.
+func newTestChartArchive() ([]byte, error) {
+	files := map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: test-chart\nversion: 0.1.0\n",
+		"values.yaml": "greeting: hello\n",
+		"templates/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-config
+data:
+  greeting: {{ .Values.greeting | quote }}
+  revision: {{ .Release.Revision | quote }}
+`,
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: "test-chart/" + name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var _ = Describe("HelmChartProxy Reconciler", func() {
+	var helmChartProxyName string
+	var chartConfigMapName string
+	var testCluster *clusterv1.Cluster
+	var clusterName string
+
+	BeforeEach(func() {
+		helmChartProxyName = fmt.Sprintf("helmchartproxy-%s", util.RandomString(6))
+		chartConfigMapName = fmt.Sprintf("chart-%s", util.RandomString(6))
+
+		clusterName = fmt.Sprintf("cluster-%s", util.RandomString(6))
+		testCluster = &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: defaultNamespaceName}}
+
+		By("Creating the Cluster")
+		Expect(testEnv.Create(ctx, testCluster)).To(Succeed())
+		By("Creating the remote Cluster kubeconfig")
+		Expect(testEnv.CreateKubeconfigSecret(ctx, testCluster)).To(Succeed())
+
+		By("Creating a ConfigMap with the chart archive")
+		archive, err := newTestChartArchive()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(testEnv.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      chartConfigMapName,
+				Namespace: defaultNamespaceName,
+			},
+			BinaryData: map[string][]byte{
+				"test-chart-0.1.0.tgz": archive,
+			},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		By("Deleting the Kubeconfigsecret")
+		Expect(testEnv.Delete(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-kubeconfig",
+				Namespace: defaultNamespaceName,
+			},
+		})).To(Succeed())
+
+		Expect(testEnv.Delete(ctx, &addonsv1.HelmChartProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      helmChartProxyName,
+				Namespace: defaultNamespaceName,
+			},
+		})).To(Succeed())
+		Expect(testEnv.Delete(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      chartConfigMapName,
+				Namespace: defaultNamespaceName,
+			},
+		})).To(Succeed())
+		Expect(testEnv.Delete(ctx, testCluster)).To(Succeed())
+	})
+
+	It("Should install and upgrade the release in a matching cluster", func() {
+		releaseNamespace := fmt.Sprintf("release-%s", util.RandomString(6))
+		labels := map[string]string{"helm": clusterName}
+
+		helmChartProxyInstance := &addonsv1.HelmChartProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      helmChartProxyName,
+				Namespace: defaultNamespaceName,
+			},
+			Spec: addonsv1.HelmChartProxySpec{
+				ClusterSelector: metav1.LabelSelector{
+					MatchLabels: labels,
+				},
+				ReleaseName:      "test",
+				ReleaseNamespace: releaseNamespace,
+				Chart: addonsv1.HelmChartSource{
+					ConfigMap: &addonsv1.HelmChartArchiveRef{Name: chartConfigMapName, Key: "test-chart-0.1.0.tgz"},
+				},
+				Values: "greeting: hello ${CLUSTER_NAME}",
+			},
+		}
+		// Create the HelmChartProxy.
+		Expect(testEnv.Create(ctx, helmChartProxyInstance)).To(Succeed())
+
+		By("Adding the selector labels to the Cluster")
+		clusterPatch := client.MergeFrom(testCluster.DeepCopy())
+		testCluster.SetLabels(labels)
+		Expect(testEnv.Patch(ctx, testCluster, clusterPatch)).To(Succeed())
+
+		By("Verifying the objects of the release are installed to the release namespace")
+		releaseConfigMap := &corev1.ConfigMap{}
+		Eventually(func() bool {
+			if err := testEnv.Get(ctx, client.ObjectKey{Namespace: releaseNamespace, Name: "test-config"}, releaseConfigMap); err != nil {
+				return false
+			}
+			return releaseConfigMap.Data["greeting"] == "hello "+clusterName
+		}, timeout).Should(BeTrue())
+		Expect(releaseConfigMap.Annotations).To(HaveKeyWithValue("meta.helm.sh/release-name", "test"))
+		Expect(releaseConfigMap.Annotations).To(HaveKeyWithValue("meta.helm.sh/release-namespace", releaseNamespace))
+		Expect(releaseConfigMap.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "Helm"))
+
+		By("Verifying the release record is stored in the release namespace")
+		releaseSecret := &corev1.Secret{}
+		Expect(testEnv.Get(ctx, client.ObjectKey{Namespace: releaseNamespace, Name: "sh.helm.release.v1.test.v1"}, releaseSecret)).To(Succeed())
+		Expect(releaseSecret.Labels).To(HaveKeyWithValue("owner", "helm"))
+		Expect(releaseSecret.Labels).To(HaveKeyWithValue("status", "deployed"))
+
+		By("Verifying the release status is reported")
+		Eventually(func() bool {
+			if err := testEnv.Get(ctx, client.ObjectKey{Namespace: defaultNamespaceName, Name: helmChartProxyName}, helmChartProxyInstance); err != nil {
+				return false
+			}
+			release := helmChartProxyInstance.Status.GetRelease(clusterName)
+			return release != nil && release.Status == addonsv1.HelmReleasePhaseDeployed && release.Revision == 1 &&
+				conditions.IsTrue(helmChartProxyInstance, addonsv1.ReleasesReadyCondition)
+		}, timeout).Should(BeTrue())
+		Expect(helmChartProxyInstance.Status.GetRelease(clusterName).Chart).To(Equal("test-chart-0.1.0"))
+
+		By("Updating the values of the HelmChartProxy")
+		Expect(testEnv.Get(ctx, client.ObjectKey{Namespace: defaultNamespaceName, Name: helmChartProxyName}, helmChartProxyInstance)).To(Succeed())
+		helmChartProxyInstance.Spec.Values = "greeting: bye"
+		Expect(testEnv.Update(ctx, helmChartProxyInstance)).To(Succeed())
+
+		By("Verifying the release is upgraded")
+		Eventually(func() bool {
+			if err := testEnv.Get(ctx, client.ObjectKey{Namespace: releaseNamespace, Name: "test-config"}, releaseConfigMap); err != nil {
+				return false
+			}
+			return releaseConfigMap.Data["greeting"] == "bye" && releaseConfigMap.Data["revision"] == "2"
+		}, timeout).Should(BeTrue())
+		Expect(testEnv.Get(ctx, client.ObjectKey{Namespace: releaseNamespace, Name: "sh.helm.release.v1.test.v2"}, releaseSecret)).To(Succeed())
+
+		By("Removing the selector labels from the Cluster")
+		clusterPatch = client.MergeFrom(testCluster.DeepCopy())
+		testCluster.SetLabels(nil)
+		Expect(testEnv.Patch(ctx, testCluster, clusterPatch)).To(Succeed())
+
+		By("Verifying the release status is dropped")
+		Eventually(func() bool {
+			if err := testEnv.Get(ctx, client.ObjectKey{Namespace: defaultNamespaceName, Name: helmChartProxyName}, helmChartProxyInstance); err != nil {
+				return false
+			}
+			return helmChartProxyInstance.Status.GetRelease(clusterName) == nil
+		}, timeout).Should(BeTrue())
+	})
+})
+
+func TestHelmChartProxyGetChartArchive(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	archive := []byte("archive")
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "chart", Namespace: "default"},
+				BinaryData: map[string][]byte{"binary.tgz": archive},
+				Data:       map[string]string{"data.tgz": string(archive)},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "chart", Namespace: "default"},
+				Data:       map[string][]byte{"chart.tgz": archive},
+			},
+		).
+		Build()
+
+	tests := []struct {
+		name    string
+		source  addonsv1.HelmChartSource
+		wantErr bool
+	}{
+		{
+			name:   "archive in ConfigMap binaryData",
+			source: addonsv1.HelmChartSource{ConfigMap: &addonsv1.HelmChartArchiveRef{Name: "chart", Key: "binary.tgz"}},
+		},
+		{
+			name:   "archive in ConfigMap data",
+			source: addonsv1.HelmChartSource{ConfigMap: &addonsv1.HelmChartArchiveRef{Name: "chart", Key: "data.tgz"}},
+		},
+		{
+			name:   "archive in Secret",
+			source: addonsv1.HelmChartSource{Secret: &addonsv1.HelmChartArchiveRef{Name: "chart", Key: "chart.tgz"}},
+		},
+		{
+			name:    "missing key",
+			source:  addonsv1.HelmChartSource{ConfigMap: &addonsv1.HelmChartArchiveRef{Name: "chart", Key: "missing.tgz"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing ConfigMap",
+			source:  addonsv1.HelmChartSource{ConfigMap: &addonsv1.HelmChartArchiveRef{Name: "missing", Key: "binary.tgz"}},
+			wantErr: true,
+		},
+		{
+			name:    "no source",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &HelmChartProxyReconciler{Client: c}
+			helmChartProxy := &addonsv1.HelmChartProxy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       addonsv1.HelmChartProxySpec{Chart: tt.source},
+			}
+			data, err := r.getChartArchive(context.TODO(), helmChartProxy)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(data).To(Equal(archive))
+		})
+	}
+}
+
+func TestHelmChartProxyReconcileRelease(t *testing.T) {
+	archive, err := newTestChartArchive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chart, err := helm.LoadArchive(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+	newHelmChartProxy := func(values string) *addonsv1.HelmChartProxy {
+		return &addonsv1.HelmChartProxy{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: addonsv1.HelmChartProxySpec{
+				ReleaseName:      "test",
+				ReleaseNamespace: "default",
+				Values:           values,
+			},
+		}
+	}
+
+	t.Run("fails if a variable in the values is not set", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &HelmChartProxyReconciler{}
+		helmChartProxy := newHelmChartProxy("greeting: ${NOT_SET}")
+		g.Expect(r.reconcileRelease(context.TODO(), cluster, helmChartProxy, archive, chart)).NotTo(Succeed())
+
+		release := helmChartProxy.Status.GetRelease(cluster.Name)
+		g.Expect(release).NotTo(BeNil())
+		g.Expect(release.Status).To(Equal(addonsv1.HelmReleasePhaseFailed))
+		g.Expect(release.Message).To(ContainSubstring("NOT_SET"))
+		g.Expect(release.Revision).To(BeZero())
+	})
+
+	t.Run("fails if the values are not valid", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &HelmChartProxyReconciler{}
+		helmChartProxy := newHelmChartProxy("- greeting")
+		g.Expect(r.reconcileRelease(context.TODO(), cluster, helmChartProxy, archive, chart)).NotTo(Succeed())
+		g.Expect(helmChartProxy.Status.GetRelease(cluster.Name).Status).To(Equal(addonsv1.HelmReleasePhaseFailed))
+	})
+
+	t.Run("does not upgrade a deployed release if the chart and values did not change", func(t *testing.T) {
+		g := NewWithT(t)
+
+		// The reconciler has no tracker, so the test would fail if the release is installed.
+		r := &HelmChartProxyReconciler{}
+		helmChartProxy := newHelmChartProxy("greeting: hello ${CLUSTER_NAME}")
+		hash := computeHash([][]byte{archive, []byte("greeting: hello test-cluster"), []byte("test"), []byte("default")})
+		helmChartProxy.Status.Releases = []addonsv1.HelmReleaseStatus{
+			{ClusterName: cluster.Name, Revision: 3, Status: addonsv1.HelmReleasePhaseDeployed, Hash: hash},
+		}
+		g.Expect(r.reconcileRelease(context.TODO(), cluster, helmChartProxy, archive, chart)).To(Succeed())
+		g.Expect(helmChartProxy.Status.GetRelease(cluster.Name).Revision).To(Equal(int32(3)))
+	})
+}
+
+func TestHelmChartProxyPullChartArchive(t *testing.T) {
+	g := NewWithT(t)
+
+	archive, err := newTestChartArchive()
+	g.Expect(err).NotTo(HaveOccurred())
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(archive))
+
+	pulls := 0
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/charts/test-chart/manifests/0.1.0":
+			fmt.Fprintf(w, `{"layers": [{"mediaType": "application/vnd.cncf.helm.chart.content.v1.tar+gzip", "digest": %q, "size": %d}]}`, digest, len(archive))
+		case "/v2/charts/test-chart/blobs/" + digest:
+			pulls++
+			_, _ = w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "tenant-a"},
+			Data:       map[string][]byte{"username": []byte("user"), "password": []byte("pass")},
+		}).
+		Build()
+
+	r := &HelmChartProxyReconciler{Client: c, OCIClient: &helm.OCIClient{HTTPClient: registry.Client()}}
+	ref := &addonsv1.HelmChartOCIRef{
+		Repository:            helm.OCIScheme + strings.TrimPrefix(registry.URL, "https://") + "/charts/test-chart",
+		Version:               "0.1.0",
+		CredentialsSecretName: "credentials",
+	}
+	pull := func(namespace string, ref *addonsv1.HelmChartOCIRef) {
+		data, err := r.pullChartArchive(context.TODO(), namespace, ref)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(data).To(Equal(archive))
+	}
+
+	pull("tenant-a", ref)
+	pull("tenant-a", ref)
+	g.Expect(pulls).To(Equal(1))
+
+	// The archive pulled with the credentials of a namespace is not returned to other namespaces,
+	// or to HelmChartProxies without the same credentials.
+	anonymousRef := ref.DeepCopy()
+	anonymousRef.CredentialsSecretName = ""
+	pull("tenant-b", anonymousRef)
+	g.Expect(pulls).To(Equal(2))
+	pull("tenant-a", anonymousRef)
+	g.Expect(pulls).To(Equal(3))
+}
+
+func TestChartArchiveCache(t *testing.T) {
+	g := NewWithT(t)
+
+	c := &chartArchiveCache{}
+	c.add("a", []byte("aaaa"), 10)
+	c.add("b", []byte("bbbb"), 10)
+	_, ok := c.get("a")
+	g.Expect(ok).To(BeTrue())
+
+	// Adding an archive exceeding the maximum size evicts the oldest archives.
+	c.add("c", []byte("cccc"), 10)
+	_, ok = c.get("a")
+	g.Expect(ok).To(BeFalse())
+	archive, ok := c.get("b")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(archive).To(Equal([]byte("bbbb")))
+	_, ok = c.get("c")
+	g.Expect(ok).To(BeTrue())
+
+	// Archives larger than the maximum size are not cached.
+	c.add("d", []byte("ddddddddddd"), 10)
+	_, ok = c.get("d")
+	g.Expect(ok).To(BeFalse())
+	_, ok = c.get("b")
+	g.Expect(ok).To(BeTrue())
+}
+
+func TestClusterToHelmChartProxy(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(addonsv1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+			Labels:    map[string]string{"foo": "bar"},
+		},
+	}
+
+	matching := &addonsv1.HelmChartProxy{
+		ObjectMeta: metav1.ObjectMeta{Name: "matching", Namespace: "default"},
+		Spec: addonsv1.HelmChartProxySpec{
+			ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+		},
+	}
+	notMatching := &addonsv1.HelmChartProxy{
+		ObjectMeta: metav1.ObjectMeta{Name: "not-matching", Namespace: "default"},
+		Spec: addonsv1.HelmChartProxySpec{
+			ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"foo": "baz"}},
+		},
+	}
+	withRelease := &addonsv1.HelmChartProxy{
+		ObjectMeta: metav1.ObjectMeta{Name: "with-release", Namespace: "default"},
+		Spec: addonsv1.HelmChartProxySpec{
+			ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"foo": "baz"}},
+		},
+		Status: addonsv1.HelmChartProxyStatus{
+			Releases: []addonsv1.HelmReleaseStatus{{ClusterName: "test-cluster", Status: addonsv1.HelmReleasePhaseDeployed}},
+		},
+	}
+	otherNamespace := &addonsv1.HelmChartProxy{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "other"},
+		Spec: addonsv1.HelmChartProxySpec{
+			ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+		},
+	}
+
+	r := &HelmChartProxyReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(matching, notMatching, withRelease, otherNamespace).Build(),
+	}
+	requests := r.clusterToHelmChartProxy(cluster)
+	names := []string{}
+	for _, req := range requests {
+		names = append(names, req.Name)
+	}
+	g.Expect(names).To(ConsistOf("matching", "with-release"))
+}
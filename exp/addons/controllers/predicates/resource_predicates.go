@@ -19,7 +19,9 @@ package predicates
 import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
@@ -34,27 +36,61 @@ func ResourceCreate(logger logr.Logger) predicate.Funcs {
 	}
 }
 
+// ResourceCreateOrUpdate returns a predicate that returns true for a create or update event
+func ResourceCreateOrUpdate(logger logr.Logger) predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return true },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return true },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}
+}
+
 // AddonsSecretCreate returns a predicate that returns true for a Secret create event if in addons Secret type
 func AddonsSecretCreate(logger logr.Logger) predicate.Funcs {
 	log := logger.WithValues("predicate", "SecretCreateOrUpdate")
 
 	return predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
-			log = log.WithValues("eventType", "create")
-			s, ok := e.Object.(*corev1.Secret)
-			if !ok {
-				log.V(4).Info("Expected Secret", "secret", e.Object.GetObjectKind().GroupVersionKind().String())
-				return false
-			}
-			if string(s.Type) != string(addonsv1.ClusterResourceSetSecretType) {
-				log.V(4).Info("Expected Secret Type", "type", addonsv1.SecretClusterResourceSetResourceKind,
-					"got", string(s.Type))
-				return false
-			}
-			return true
+			return isAddonsSecret(log.WithValues("eventType", "create"), e.Object)
 		},
 		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
 		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
 		GenericFunc: func(e event.GenericEvent) bool { return false },
 	}
 }
+
+// AddonsSecretCreateOrUpdate returns a predicate that returns true for a Secret create or update event if in addons Secret type
+func AddonsSecretCreateOrUpdate(logger logr.Logger) predicate.Funcs {
+	log := logger.WithValues("predicate", "SecretCreateOrUpdate")
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isAddonsSecret(log.WithValues("eventType", "create"), e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isAddonsSecret(log.WithValues("eventType", "update"), e.ObjectNew)
+		},
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}
+}
+
+// isAddonsSecret returns true if the object is a Secret of the addons Secret type. The type of Secrets watched
+// with metadata only is unknown, so they are accepted and the type is checked when applying them.
+func isAddonsSecret(log logr.Logger, o client.Object) bool {
+	if _, ok := o.(*metav1.PartialObjectMetadata); ok {
+		return true
+	}
+	s, ok := o.(*corev1.Secret)
+	if !ok {
+		log.V(4).Info("Expected Secret", "secret", o.GetObjectKind().GroupVersionKind().String())
+		return false
+	}
+	if string(s.Type) != string(addonsv1.ClusterResourceSetSecretType) {
+		log.V(4).Info("Expected Secret Type", "type", addonsv1.SecretClusterResourceSetResourceKind,
+			"got", string(s.Type))
+		return false
+	}
+	return true
+}
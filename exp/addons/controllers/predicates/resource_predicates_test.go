@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicates
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestAddonsSecretCreateOrUpdate(t *testing.T) {
+	metadataOnlySecret := &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "addons", Namespace: "default"},
+	}
+
+	tests := []struct {
+		name   string
+		object client.Object
+		want   bool
+	}{
+		{
+			name:   "accepts a metadata only Secret, as watched with builder.OnlyMetadata",
+			object: metadataOnlySecret,
+			want:   true,
+		},
+		{
+			name: "accepts a Secret of the addons Secret type",
+			object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "addons", Namespace: "default"},
+				Type:       addonsv1.ClusterResourceSetSecretType,
+			},
+			want: true,
+		},
+		{
+			name: "rejects a Secret of another type",
+			object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "addons", Namespace: "default"},
+				Type:       corev1.SecretTypeOpaque,
+			},
+			want: false,
+		},
+		{
+			name:   "rejects other objects",
+			object: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "addons", Namespace: "default"}},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			p := AddonsSecretCreateOrUpdate(log.Log)
+			g.Expect(p.Create(event.CreateEvent{Object: tt.object})).To(Equal(tt.want))
+			g.Expect(p.Update(event.UpdateEvent{ObjectOld: tt.object, ObjectNew: tt.object})).To(Equal(tt.want))
+			g.Expect(p.Delete(event.DeleteEvent{Object: tt.object})).To(BeFalse())
+		})
+	}
+}
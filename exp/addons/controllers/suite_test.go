@@ -59,6 +59,10 @@ var _ = BeforeSuite(func() {
 	Expect((&ClusterResourceSetBindingReconciler{
 		Client: testEnv,
 	}).SetupWithManager(ctx, testEnv.Manager, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
+	Expect((&HelmChartProxyReconciler{
+		Client:  testEnv,
+		Tracker: trckr,
+	}).SetupWithManager(ctx, testEnv.Manager, controller.Options{MaxConcurrentReconciles: 1})).To(Succeed())
 
 	By("starting the manager")
 	go func() {
@@ -0,0 +1,278 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// OCIScheme is the prefix of references to charts stored in OCI registries.
+	OCIScheme = "oci://"
+
+	ociManifestMediaType  = "application/vnd.oci.image.manifest.v1+json"
+	chartContentMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	maxManifestSize       = 4 * 1024 * 1024
+	maxArchiveSize        = 20 * 1024 * 1024
+	maxTokenResponseSize  = 1024 * 1024
+	wwwAuthenticateHeader = "WWW-Authenticate"
+	bearerChallengePrefix = "bearer "
+	basicChallengePrefix  = "basic "
+)
+
+// Credentials are the credentials used to authenticate to an OCI registry.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// OCIClient pulls chart archives from OCI registries, using the OCI distribution API.
+type OCIClient struct {
+	// HTTPClient is the client used for requests to registries; defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Pull pulls the archive of a chart from an OCI registry, e.g. repository oci://ghcr.io/org/charts/nginx
+// and version 1.0.0. Registries requiring authentication are supported using basic or bearer token authentication.
+func (c *OCIClient) Pull(ctx context.Context, repository, version string, credentials *Credentials) ([]byte, error) {
+	if !strings.HasPrefix(repository, OCIScheme) {
+		return nil, errors.Errorf("invalid OCI repository %q: must start with %s", repository, OCIScheme)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(repository, OCIScheme), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.Errorf("invalid OCI repository %q: must include the registry host and the repository name", repository)
+	}
+	host, name := parts[0], parts[1]
+	if version == "" {
+		return nil, errors.New("chart version is required")
+	}
+
+	s := &ociSession{client: c.httpClient(), credentials: credentials}
+
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, name, version)
+	manifestData, err := s.get(ctx, manifestURL, ociManifestMediaType, maxManifestSize)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get manifest of %s:%s", repository, version)
+	}
+	manifest := struct {
+		Layers []struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+			Size      int64  `json:"size"`
+		} `json:"layers"`
+	}{}
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse manifest of %s:%s", repository, version)
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != chartContentMediaType {
+			continue
+		}
+		if layer.Size > maxArchiveSize {
+			return nil, errors.Errorf("chart archive of %s:%s is larger than %d bytes", repository, version, maxArchiveSize)
+		}
+		blobURL := fmt.Sprintf("https://%s/v2/%s/blobs/%s", host, name, layer.Digest)
+		data, err := s.get(ctx, blobURL, chartContentMediaType, maxArchiveSize)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get chart archive of %s:%s", repository, version)
+		}
+		if digest := fmt.Sprintf("sha256:%x", sha256.Sum256(data)); digest != layer.Digest {
+			return nil, errors.Errorf("digest of the chart archive of %s:%s is %s, expected %s", repository, version, digest, layer.Digest)
+		}
+		return data, nil
+	}
+	return nil, errors.Errorf("%s:%s is not a Helm chart", repository, version)
+}
+
+func (c *OCIClient) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// ociSession keeps track of the authorization used for the requests to a registry.
+type ociSession struct {
+	client        *http.Client
+	credentials   *Credentials
+	authorization string
+}
+
+// get returns the body of a GET request. If the registry requires authentication, the request is
+// retried with the authorization requested by the registry challenge.
+func (s *ociSession) get(ctx context.Context, rawURL, accept string, maxSize int64) ([]byte, error) {
+	resp, err := s.do(ctx, rawURL, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get(wwwAuthenticateHeader)
+		resp.Body.Close()
+		if err := s.authorize(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = s.do(ctx, rawURL, accept); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %q from %s", resp.Status, rawURL)
+	}
+	return readAll(resp.Body, maxSize)
+}
+
+func (s *ociSession) do(ctx context.Context, rawURL, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if s.authorization != "" {
+		req.Header.Set("Authorization", s.authorization)
+	}
+	return s.client.Do(req)
+}
+
+// authorize sets the authorization for the next requests, according to the registry challenge.
+func (s *ociSession) authorize(ctx context.Context, challenge string) error {
+	lower := strings.ToLower(challenge)
+	switch {
+	case strings.HasPrefix(lower, basicChallengePrefix):
+		if s.credentials == nil {
+			return errors.New("registry requires credentials")
+		}
+		s.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(s.credentials.Username+":"+s.credentials.Password))
+		return nil
+	case strings.HasPrefix(lower, bearerChallengePrefix):
+		params := parseChallengeParams(challenge[len(bearerChallengePrefix):])
+		realm, ok := params["realm"]
+		if !ok {
+			return errors.Errorf("invalid registry challenge %q: missing realm", challenge)
+		}
+		tokenURL, err := url.Parse(realm)
+		if err != nil || tokenURL.Host == "" {
+			return errors.Errorf("invalid realm in the registry challenge %q", challenge)
+		}
+		// The credentials are sent to the realm, so it must not be possible to leak them over plain http.
+		if tokenURL.Scheme != "https" {
+			return errors.Errorf("the registry authorization service %s must use https", realm)
+		}
+		query := tokenURL.Query()
+		for _, key := range []string{"service", "scope"} {
+			if v, ok := params[key]; ok {
+				query.Set(key, v)
+			}
+		}
+		tokenURL.RawQuery = query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+		if err != nil {
+			return err
+		}
+		if s.credentials != nil {
+			req.SetBasicAuth(s.credentials.Username, s.credentials.Password)
+		}
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return errors.Wrap(err, "failed to get registry token")
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("unexpected status %q getting registry token", resp.Status)
+		}
+		data, err := readAll(resp.Body, maxTokenResponseSize)
+		if err != nil {
+			return errors.Wrap(err, "failed to read registry token")
+		}
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+		if err := json.Unmarshal(data, &token); err != nil {
+			return errors.Wrap(err, "failed to parse registry token")
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		if token.Token == "" {
+			return errors.New("registry returned an empty token")
+		}
+		s.authorization = "Bearer " + token.Token
+		return nil
+	default:
+		return errors.Errorf("unsupported registry challenge %q", challenge)
+	}
+}
+
+// parseChallengeParams parses the parameters of a challenge, e.g. realm="https://auth.io/token",service="registry.io".
+func parseChallengeParams(s string) map[string]string {
+	params := map[string]string{}
+	for s != "" {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.Index(s, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.Index(s[1:], `"`)
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else {
+			end := strings.Index(s, ",")
+			if end < 0 {
+				value, s = s, ""
+			} else {
+				value, s = s[:end], s[end:]
+			}
+		}
+		params[key] = value
+	}
+	return params
+}
+
+// readAll reads at most maxSize bytes from r, and returns an error if r is larger.
+func readAll(r io.Reader, maxSize int64) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errors.Errorf("response is larger than %d bytes", maxSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// newRegistry returns a test registry serving a chart archive as charts/test-chart:0.1.0.
+// If token is set, the registry requires bearer token authentication, with tokens issued to user:pass.
+func newRegistry(t *testing.T, archive []byte, digest, token string) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "pass" || r.URL.Query().Get("scope") != "repository:charts/test-chart:pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"token": %q}`, token)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			w.Header().Set(wwwAuthenticateHeader, fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:charts/test-chart:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/charts/test-chart/manifests/0.1.0":
+			if r.Header.Get("Accept") != ociManifestMediaType {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			fmt.Fprintf(w, `{"layers": [{"mediaType": "application/vnd.cncf.helm.config.v1+json", "digest": "sha256:abc", "size": 10}, {"mediaType": %q, "digest": %q, "size": %d}]}`,
+				chartContentMediaType, digest, len(archive))
+		case "/v2/charts/test-chart/blobs/" + digest:
+			_, _ = w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server = httptest.NewTLSServer(mux)
+	return server
+}
+
+func TestOCIClientPull(t *testing.T) {
+	archive := newArchive(t, map[string]string{"Chart.yaml": testChartYaml})
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(archive))
+
+	tests := []struct {
+		name        string
+		digest      string
+		token       string
+		repository  string
+		version     string
+		credentials *Credentials
+		wantErr     bool
+	}{
+		{
+			name:       "pull from an anonymous registry",
+			digest:     digest,
+			repository: "charts/test-chart",
+			version:    "0.1.0",
+		},
+		{
+			name:        "pull from a registry requiring authentication",
+			digest:      digest,
+			token:       "test-token",
+			repository:  "charts/test-chart",
+			version:     "0.1.0",
+			credentials: &Credentials{Username: "user", Password: "pass"},
+		},
+		{
+			name:        "fails with invalid credentials",
+			digest:      digest,
+			token:       "test-token",
+			repository:  "charts/test-chart",
+			version:     "0.1.0",
+			credentials: &Credentials{Username: "user", Password: "wrong"},
+			wantErr:     true,
+		},
+		{
+			name:       "fails if the version does not exist",
+			digest:     digest,
+			repository: "charts/test-chart",
+			version:    "0.2.0",
+			wantErr:    true,
+		},
+		{
+			name:       "fails if the digest does not match",
+			digest:     "sha256:0123",
+			repository: "charts/test-chart",
+			version:    "0.1.0",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			server := newRegistry(t, archive, tt.digest, tt.token)
+			defer server.Close()
+
+			c := &OCIClient{HTTPClient: server.Client()}
+			repository := OCIScheme + strings.TrimPrefix(server.URL, "https://") + "/" + tt.repository
+			data, err := c.Pull(context.Background(), repository, tt.version, tt.credentials)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(data).To(Equal(archive))
+		})
+	}
+}
+
+func TestOCIClientPullRejectsInsecureRealm(t *testing.T) {
+	g := NewWithT(t)
+
+	// The token service uses plain http, so the credentials must not be sent to it.
+	credentialsSent := false
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, credentialsSent = r.BasicAuth()
+		fmt.Fprint(w, `{"token": "test-token"}`)
+	}))
+	defer tokenServer.Close()
+
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(wwwAuthenticateHeader, fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, tokenServer.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer registry.Close()
+
+	c := &OCIClient{HTTPClient: registry.Client()}
+	repository := OCIScheme + strings.TrimPrefix(registry.URL, "https://") + "/charts/test-chart"
+	_, err := c.Pull(context.Background(), repository, "0.1.0", &Credentials{Username: "user", Password: "pass"})
+	g.Expect(err).To(MatchError(ContainSubstring("must use https")))
+	g.Expect(credentialsSent).To(BeFalse())
+}
+
+func TestOCIClientPullInvalidRepository(t *testing.T) {
+	c := &OCIClient{}
+	for _, repository := range []string{"ghcr.io/charts/test-chart", "oci://ghcr.io", "oci:///charts/test-chart"} {
+		g := NewWithT(t)
+
+		_, err := c.Pull(context.Background(), repository, "0.1.0", nil)
+		g.Expect(err).To(HaveOccurred())
+	}
+}
+
+func TestParseChallengeParams(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(parseChallengeParams(`realm="https://auth.io/token",service="registry.io", scope="repository:a/b:pull,push"`)).To(Equal(map[string]string{
+		"realm":   "https://auth.io/token",
+		"service": "registry.io",
+		"scope":   "repository:a/b:pull,push",
+	}))
+	g.Expect(parseChallengeParams(`realm=registry`)).To(Equal(map[string]string{"realm": "registry"}))
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package helm installs Helm charts to workload clusters using the Helm SDK, and pulls chart archives
+// from OCI registries.
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// releaseStorageDriver is the Helm storage driver used for the release records, i.e. Secrets in the release
+	// namespace, like the default of the Helm CLI, so the releases can also be managed with the Helm CLI.
+	releaseStorageDriver = "secret"
+
+	// maxHistory is the maximum number of revisions kept for a release, like the default of helm upgrade.
+	maxHistory = 10
+)
+
+// LoadArchive loads a chart from a gzipped tar archive, as created by helm package, and checks that
+// the dependencies of the chart are included in the archive.
+func LoadArchive(data []byte) (*chart.Chart, error) {
+	c, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if c.Metadata.Dependencies != nil {
+		if err := action.CheckDependencies(c, c.Metadata.Dependencies); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// InstallOrUpgradeInput defines the release to install or upgrade.
+type InstallOrUpgradeInput struct {
+	// ReleaseName is the name of the release.
+	ReleaseName string
+
+	// Namespace is the namespace of the release; it is created if it does not exist.
+	Namespace string
+
+	// Chart is the chart of the release.
+	Chart *chart.Chart
+
+	// Values are the values used to render the chart, overriding the default values of the chart.
+	Values map[string]interface{}
+
+	// Timeout is the time to wait for each of the hooks of the chart to complete.
+	Timeout time.Duration
+}
+
+// InstallOrUpgrade installs a release to the cluster of the given rest.Config, or upgrades it if it already exists,
+// like helm upgrade --install does. Helm renders the chart with the capabilities of the cluster, installs the CRDs
+// of the chart on install, runs the hooks, deletes the objects dropped from the previous revision, and stores
+// a record of every revision in the release namespace.
+// The returned release, if any, reports the revision installed, also if it failed.
+func InstallOrUpgrade(ctx context.Context, config *rest.Config, input InstallOrUpgradeInput) (*release.Release, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	cfg := &action.Configuration{}
+	getter := &restClientGetter{config: config, namespace: input.Namespace}
+	if err := cfg.Init(getter, input.Namespace, releaseStorageDriver, func(format string, v ...interface{}) {
+		log.V(4).Info(fmt.Sprintf(format, v...))
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to initialize Helm")
+	}
+	return installOrUpgrade(cfg, input)
+}
+
+// installOrUpgrade installs or upgrades a release using the given Helm configuration.
+func installOrUpgrade(cfg *action.Configuration, input InstallOrUpgradeInput) (*release.Release, error) {
+	history := action.NewHistory(cfg)
+	history.Max = 1
+	if _, err := history.Run(input.ReleaseName); err != nil {
+		if !errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, errors.Wrapf(err, "failed to get history of release %s", input.ReleaseName)
+		}
+
+		install := action.NewInstall(cfg)
+		install.ReleaseName = input.ReleaseName
+		install.Namespace = input.Namespace
+		install.CreateNamespace = true
+		install.Timeout = input.Timeout
+		rel, err := install.Run(input.Chart, input.Values)
+		if err != nil {
+			return rel, errors.Wrapf(err, "failed to install release %s", input.ReleaseName)
+		}
+		return rel, nil
+	}
+
+	upgrade := action.NewUpgrade(cfg)
+	upgrade.Namespace = input.Namespace
+	upgrade.Timeout = input.Timeout
+	upgrade.MaxHistory = maxHistory
+	rel, err := upgrade.Run(input.ReleaseName, input.Chart, input.Values)
+	if err != nil {
+		return rel, errors.Wrapf(err, "failed to upgrade release %s", input.ReleaseName)
+	}
+	return rel, nil
+}
+
+// restClientGetter provides the clients for a cluster to the Helm SDK, using a rest.Config instead of a kubeconfig file.
+type restClientGetter struct {
+	config    *rest.Config
+	namespace string
+}
+
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.config), nil
+}
+
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(rest.CopyConfig(g.config))
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	discoveryClient, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+	return restmapper.NewShortcutExpander(mapper, discoveryClient), nil
+}
+
+// ToRawKubeConfigLoader returns a client config only defining the namespace, which is the only information
+// the Helm SDK reads from it when the other clients are provided.
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return clientcmd.NewDefaultClientConfig(clientcmdapi.Config{}, &clientcmd.ConfigOverrides{
+		Context: clientcmdapi.Context{Namespace: g.namespace},
+	})
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// newArchive returns a chart archive with the given files, relative to the chart directory.
+func newArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: "test-chart/" + name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const testChartYaml = `apiVersion: v2
+name: test-chart
+version: 0.1.0
+appVersion: 1.2.3
+`
+
+func TestLoadArchive(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		wantErr       bool
+		wantTemplates int
+		wantCRDs      int
+	}{
+		{
+			name: "chart with values, templates and CRDs",
+			files: map[string]string{
+				"Chart.yaml":                testChartYaml,
+				"values.yaml":               "replicas: 1\n",
+				"templates/service.yaml":    "kind: Service",
+				"templates/deployment.yaml": "kind: Deployment",
+				"crds/crd.yaml":             "kind: CustomResourceDefinition",
+			},
+			wantTemplates: 2,
+			wantCRDs:      1,
+		},
+		{
+			name:    "chart without Chart.yaml",
+			files:   map[string]string{"values.yaml": "replicas: 1\n"},
+			wantErr: true,
+		},
+		{
+			name: "chart with a missing dependency",
+			files: map[string]string{
+				"Chart.yaml": testChartYaml + "dependencies:\n- name: dep\n  version: 1.0.0\n  repository: https://example.com\n",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c, err := LoadArchive(newArchive(t, tt.files))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(c.Metadata.Name).To(Equal("test-chart"))
+			g.Expect(c.Templates).To(HaveLen(tt.wantTemplates))
+			g.Expect(c.CRDObjects()).To(HaveLen(tt.wantCRDs))
+		})
+	}
+}
+
+func TestInstallOrUpgrade(t *testing.T) {
+	archive := newArchive(t, map[string]string{
+		"Chart.yaml":  testChartYaml,
+		"values.yaml": "greeting: hello\n",
+		"templates/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-config
+data:
+  greeting: {{ .Values.greeting | quote }}
+  revision: {{ .Release.Revision | quote }}
+  {{- if and (.Capabilities.APIVersions.Has "apps/v1") (semverCompare ">=1.0.0" .Capabilities.KubeVersion.Version) }}
+  apps: "true"
+  {{- end }}
+`,
+		"templates/hook.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-hook
+  annotations:
+    helm.sh/hook: post-install,post-upgrade
+`,
+	})
+	c, err := LoadArchive(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newConfiguration := func() (*action.Configuration, *kubefake.FailingKubeClient) {
+		kubeClient := &kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard}}
+		return &action.Configuration{
+			Releases:     storage.Init(driver.NewMemory()),
+			KubeClient:   kubeClient,
+			Capabilities: chartutil.DefaultCapabilities,
+			Log:          func(format string, v ...interface{}) {},
+		}, kubeClient
+	}
+	input := InstallOrUpgradeInput{
+		ReleaseName: "test",
+		Namespace:   "test-namespace",
+		Chart:       c,
+		Values:      map[string]interface{}{"greeting": "hi"},
+		Timeout:     time.Minute,
+	}
+
+	t.Run("installs and upgrades a release", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cfg, _ := newConfiguration()
+		rel, err := installOrUpgrade(cfg, input)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(rel.Version).To(Equal(1))
+		g.Expect(rel.Info.Status).To(Equal(release.StatusDeployed))
+		g.Expect(rel.Manifest).To(ContainSubstring(`greeting: "hi"`))
+		g.Expect(rel.Manifest).To(ContainSubstring(`apps: "true"`))
+		// Hooks are run by Helm, and they are not part of the objects of the release.
+		g.Expect(rel.Manifest).NotTo(ContainSubstring("test-hook"))
+		g.Expect(rel.Hooks).To(HaveLen(1))
+
+		rel, err = installOrUpgrade(cfg, input)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(rel.Version).To(Equal(2))
+		g.Expect(rel.Info.Status).To(Equal(release.StatusDeployed))
+		g.Expect(rel.Manifest).To(ContainSubstring(`revision: "2"`))
+
+		previous, err := cfg.Releases.Get("test", 1)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(previous.Info.Status).To(Equal(release.StatusSuperseded))
+	})
+
+	t.Run("upgrades a release whose install failed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cfg, kubeClient := newConfiguration()
+		kubeClient.WatchUntilReadyError = errors.New("hook failed")
+		rel, err := installOrUpgrade(cfg, input)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(rel).NotTo(BeNil())
+		g.Expect(rel.Version).To(Equal(1))
+		g.Expect(rel.Info.Status).To(Equal(release.StatusFailed))
+
+		kubeClient.WatchUntilReadyError = nil
+		rel, err = installOrUpgrade(cfg, input)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(rel.Version).To(Equal(2))
+		g.Expect(rel.Info.Status).To(Equal(release.StatusDeployed))
+	})
+}
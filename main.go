@@ -67,6 +67,7 @@ var (
 	machineDeploymentConcurrency  int
 	machinePoolConcurrency        int
 	clusterResourceSetConcurrency int
+	helmChartProxyConcurrency     int
 	machineHealthCheckConcurrency int
 	syncPeriod                    time.Duration
 	webhookPort                   int
@@ -128,6 +129,9 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&clusterResourceSetConcurrency, "clusterresourceset-concurrency", 10,
 		"Number of cluster resource sets to process simultaneously")
 
+	fs.IntVar(&helmChartProxyConcurrency, "helmchartproxy-concurrency", 10,
+		"Number of helm chart proxies to process simultaneously")
+
 	fs.IntVar(&machineHealthCheckConcurrency, "machinehealthcheck-concurrency", 10,
 		"Number of machine health checks to process simultaneously")
 
@@ -287,6 +291,17 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		}
 	}
 
+	if feature.Gates.Enabled(feature.HelmChartProxy) {
+		if err := (&addonscontrollers.HelmChartProxyReconciler{
+			Client:           mgr.GetClient(),
+			Tracker:          tracker,
+			WatchFilterValue: watchFilterValue,
+		}).SetupWithManager(ctx, mgr, concurrency(helmChartProxyConcurrency)); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "HelmChartProxy")
+			os.Exit(1)
+		}
+	}
+
 	if err := (&controllers.MachineHealthCheckReconciler{
 		Client:           mgr.GetClient(),
 		Tracker:          tracker,
@@ -332,6 +347,13 @@ func setupWebhooks(mgr ctrl.Manager) {
 		}
 	}
 
+	if feature.Gates.Enabled(feature.HelmChartProxy) {
+		if err := (&addonsv1.HelmChartProxy{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HelmChartProxy")
+			os.Exit(1)
+		}
+	}
+
 	if err := (&clusterv1.MachineHealthCheck{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "MachineHealthCheck")
 		os.Exit(1)
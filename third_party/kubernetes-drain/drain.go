@@ -71,6 +71,10 @@ type Helper struct {
 
 	// OnPodDeletedOrEvicted is called when a pod is evicted/deleted; for printing progress output
 	OnPodDeletedOrEvicted func(pod *corev1.Pod, usingEviction bool)
+
+	// SkipPod is called for every pod on the node before the other filters; pods for which
+	// it returns true are neither deleted nor evicted
+	SkipPod func(pod corev1.Pod) bool
 }
 
 type waitForDeleteParams struct {
@@ -139,6 +139,7 @@ func makePodDeleteStatusWithError(message string) podDeleteStatus {
 // message will be retained if there are any warnings.
 func (d *Helper) makeFilters() []podFilter {
 	return []podFilter{
+		d.skipPodFilter,
 		d.skipDeletedFilter,
 		d.daemonSetFilter,
 		d.mirrorPodFilter,
@@ -242,3 +243,10 @@ func (d *Helper) skipDeletedFilter(pod corev1.Pod) podDeleteStatus {
 	}
 	return makePodDeleteStatusOkay()
 }
+
+func (d *Helper) skipPodFilter(pod corev1.Pod) podDeleteStatus {
+	if d.SkipPod != nil && d.SkipPod(pod) {
+		return makePodDeleteStatusSkip()
+	}
+	return makePodDeleteStatusOkay()
+}
@@ -226,6 +226,9 @@ func generateKubeconfig(ctx context.Context, c client.Client, clusterName client
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to generate a kubeconfig")
 	}
+	// Trust all the certificates in the CA Secret, which contains both the old and the new certificate authority
+	// while the cluster CA is being rotated.
+	cfg.Clusters[clusterName.Name].CertificateAuthorityData = clusterCA.Data[secret.TLSCrtDataName]
 
 	out, err := clientcmd.Write(*cfg)
 	if err != nil {
@@ -427,3 +427,43 @@ func TestRegenerateClientCerts(t *testing.T) {
 
 	g.Expect(newCert.NotAfter).To(BeTemporally(">", oldCert.NotAfter))
 }
+
+func TestRegenerateSecretTrustsAllCertificateAuthorities(t *testing.T) {
+	g := NewWithT(t)
+	caKey, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+	caCert, err := getTestCACert(caKey)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	otherKey, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+	otherCert, err := getTestCACert(otherKey)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	bundle := append(certs.EncodeCertPEM(caCert), certs.EncodeCertPEM(otherCert)...)
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test1-ca",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			secret.TLSKeyDataName: certs.EncodePrivateKeyPEM(caKey),
+			secret.TLSCrtDataName: bundle,
+		},
+	}
+
+	configSecret := validSecret.DeepCopy()
+	c := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(configSecret, caSecret).Build()
+
+	g.Expect(RegenerateSecret(ctx, c, configSecret)).To(Succeed())
+
+	newSecret := &corev1.Secret{}
+	g.Expect(c.Get(ctx, util.ObjectKey(configSecret), newSecret)).To(Succeed())
+	newConfig, err := clientcmd.Load(newSecret.Data[secret.KubeconfigDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(newConfig.Clusters["test1"].CertificateAuthorityData).To(Equal(bundle))
+
+	clientCert, err := certs.DecodeCertPEM(newConfig.AuthInfos["test1-admin"].ClientCertificateData)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(clientCert.CheckSignatureFrom(caCert)).To(Succeed())
+}
@@ -29,6 +29,14 @@ const (
 	// TLSCrtDataName is the key used to store a TLS certificate in the secret's data field.
 	TLSCrtDataName = "tls.crt"
 
+	// NextTLSKeyDataName is the key used to store the private key of a new certificate authority in the secret's data field,
+	// while the certificate authority is being rotated.
+	NextTLSKeyDataName = "next.tls.key"
+
+	// NextTLSCrtDataName is the key used to store the certificate of a new certificate authority in the secret's data field,
+	// while the certificate authority is being rotated.
+	NextTLSCrtDataName = "next.tls.crt"
+
 	// Kubeconfig is the secret name suffix storing the Cluster Kubeconfig.
 	Kubeconfig = Purpose("kubeconfig")
 
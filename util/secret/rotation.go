@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"bytes"
+	"crypto/x509"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/cert"
+	"sigs.k8s.io/cluster-api/util/certs"
+)
+
+// The rotation of a certificate authority stored in a Secret happens in three steps, so that every step can be rolled out
+// to all the machines of a cluster before moving to the next one:
+// 1. StartCertificateAuthorityRotation adds a new certificate authority to the trusted certificates;
+// 2. SwitchCertificateAuthoritySigner uses the new certificate authority for signing, while the old one is still trusted;
+// 3. CompleteCertificateAuthorityRotation removes the old certificate authority from the trusted certificates.
+// NOTE: The certificate authority used for signing is always the first certificate in the Secret, matching its private key.
+
+// StartCertificateAuthorityRotation generates a new certificate authority and adds it to the certificates trusted in the
+// given Secret; the current certificate authority is still used for signing.
+// Calling this func on a Secret where the rotation is already started is a no-op.
+func StartCertificateAuthorityRotation(s *corev1.Secret) error {
+	if _, ok := s.Data[NextTLSCrtDataName]; ok {
+		return nil
+	}
+
+	trusted, err := parseCertificates(s, TLSCrtDataName)
+	if err != nil {
+		return err
+	}
+	kp, err := generateCACert()
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate a new certificate authority for Secret %s", s.Name)
+	}
+	next, err := certs.DecodeCertPEM(kp.Cert)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode the new certificate authority for Secret %s", s.Name)
+	}
+
+	s.Data[TLSCrtDataName] = encodeCertificates(append(trusted, next)...)
+	s.Data[NextTLSCrtDataName] = kp.Cert
+	s.Data[NextTLSKeyDataName] = kp.Key
+	return nil
+}
+
+// SwitchCertificateAuthoritySigner makes the new certificate authority generated by StartCertificateAuthorityRotation the
+// one used for signing; the old certificate authority is still trusted.
+// Calling this func on a Secret where the signer is already switched is a no-op.
+func SwitchCertificateAuthoritySigner(s *corev1.Secret) error {
+	nextKey, ok := s.Data[NextTLSKeyDataName]
+	if !ok {
+		return nil
+	}
+
+	nextCerts, err := parseCertificates(s, NextTLSCrtDataName)
+	if err != nil {
+		return err
+	}
+	trusted, err := parseCertificates(s, TLSCrtDataName)
+	if err != nil {
+		return err
+	}
+
+	bundle := []*x509.Certificate{nextCerts[0]}
+	for _, c := range trusted {
+		if !c.Equal(nextCerts[0]) {
+			bundle = append(bundle, c)
+		}
+	}
+
+	s.Data[TLSCrtDataName] = encodeCertificates(bundle...)
+	s.Data[TLSKeyDataName] = nextKey
+	delete(s.Data, NextTLSCrtDataName)
+	delete(s.Data, NextTLSKeyDataName)
+	return nil
+}
+
+// CompleteCertificateAuthorityRotation removes all the certificates but the one of the certificate authority used for
+// signing from the certificates trusted in the given Secret.
+func CompleteCertificateAuthorityRotation(s *corev1.Secret) error {
+	if _, ok := s.Data[NextTLSCrtDataName]; ok {
+		return errors.Errorf("the signer of the certificate authority in Secret %s has not been switched yet", s.Name)
+	}
+
+	trusted, err := parseCertificates(s, TLSCrtDataName)
+	if err != nil {
+		return err
+	}
+	s.Data[TLSCrtDataName] = encodeCertificates(trusted[0])
+	return nil
+}
+
+// parseCertificates returns the certificates stored in the given key of a Secret.
+func parseCertificates(s *corev1.Secret, key string) ([]*x509.Certificate, error) {
+	data, ok := s.Data[key]
+	if !ok {
+		return nil, errors.Errorf("missing data for key %s in Secret %s", key, s.Name)
+	}
+	certificates, err := cert.ParseCertsPEM(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the certificates for key %s in Secret %s", key, s.Name)
+	}
+	return certificates, nil
+}
+
+// encodeCertificates returns the PEM encoding of a list of certificates.
+func encodeCertificates(certificates ...*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, c := range certificates {
+		buf.Write(certs.EncodeCertPEM(c))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret_test
+
+import (
+	"crypto/x509"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/cert"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestCertificateAuthorityRotation(t *testing.T) {
+	g := NewWithT(t)
+
+	ca := &secret.Certificate{Purpose: secret.ClusterCA}
+	g.Expect(ca.Generate()).To(Succeed())
+	s := ca.AsSecret(client.ObjectKey{Namespace: "default", Name: "test"}, metav1.OwnerReference{})
+	oldCert, oldKey := s.Data[secret.TLSCrtDataName], s.Data[secret.TLSKeyDataName]
+
+	parse := func(s *corev1.Secret, key string) []*x509.Certificate {
+		certificates, err := cert.ParseCertsPEM(s.Data[key])
+		g.Expect(err).NotTo(HaveOccurred())
+		return certificates
+	}
+	old := parse(s, secret.TLSCrtDataName)[0]
+
+	// Completing a rotation which has not been started is a no-op.
+	g.Expect(secret.CompleteCertificateAuthorityRotation(s)).To(Succeed())
+	g.Expect(s.Data[secret.TLSCrtDataName]).To(Equal(oldCert))
+
+	// The new certificate authority is trusted, but the old one is still used for signing.
+	g.Expect(secret.StartCertificateAuthorityRotation(s)).To(Succeed())
+	next := parse(s, secret.NextTLSCrtDataName)[0]
+	trusted := parse(s, secret.TLSCrtDataName)
+	g.Expect(trusted).To(HaveLen(2))
+	g.Expect(trusted[0].Equal(old)).To(BeTrue())
+	g.Expect(trusted[1].Equal(next)).To(BeTrue())
+	g.Expect(s.Data[secret.TLSKeyDataName]).To(Equal(oldKey))
+	nextKey := s.Data[secret.NextTLSKeyDataName]
+	g.Expect(nextKey).NotTo(BeEmpty())
+
+	// Starting the rotation again does not generate another certificate authority.
+	g.Expect(secret.StartCertificateAuthorityRotation(s)).To(Succeed())
+	g.Expect(parse(s, secret.TLSCrtDataName)).To(HaveLen(2))
+
+	// The rotation cannot be completed until the signer is switched.
+	g.Expect(secret.CompleteCertificateAuthorityRotation(s)).NotTo(Succeed())
+
+	// The new certificate authority is used for signing, and the old one is still trusted.
+	g.Expect(secret.SwitchCertificateAuthoritySigner(s)).To(Succeed())
+	trusted = parse(s, secret.TLSCrtDataName)
+	g.Expect(trusted).To(HaveLen(2))
+	g.Expect(trusted[0].Equal(next)).To(BeTrue())
+	g.Expect(trusted[1].Equal(old)).To(BeTrue())
+	g.Expect(s.Data[secret.TLSKeyDataName]).To(Equal(nextKey))
+	g.Expect(s.Data).NotTo(HaveKey(secret.NextTLSCrtDataName))
+	g.Expect(s.Data).NotTo(HaveKey(secret.NextTLSKeyDataName))
+
+	// Switching the signer again is a no-op.
+	g.Expect(secret.SwitchCertificateAuthoritySigner(s)).To(Succeed())
+	g.Expect(parse(s, secret.TLSCrtDataName)[0].Equal(next)).To(BeTrue())
+
+	// Only the new certificate authority is trusted.
+	g.Expect(secret.CompleteCertificateAuthorityRotation(s)).To(Succeed())
+	trusted = parse(s, secret.TLSCrtDataName)
+	g.Expect(trusted).To(HaveLen(1))
+	g.Expect(trusted[0].Equal(next)).To(BeTrue())
+
+	// The new certificate authority matches its private key.
+	key, err := certs.DecodePrivateKeyPEM(s.Data[secret.TLSKeyDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(key.Public()).To(Equal(trusted[0].PublicKey))
+}